@@ -0,0 +1,87 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+// e2csim starts a simulation HTTP API whose nodes run E2C validators. The
+// network is then driven with p2psim, for example:
+//
+//     $ e2csim --delta 200 &
+//     $ p2psim node create --name v1 --key <key1> --properties e2c.validator=<addr1>,e2c.validator=<addr2>,e2c.validator=<addr3>
+//     ...
+//     $ p2psim node connect v1 v2
+//     $ p2psim node rpc v1 e2csim_setLinkDelay <addr2> 300
+//     $ p2psim node stop v2
+//
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/consensus/e2c/simulation"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/simulations"
+	"github.com/ethereum/go-ethereum/p2p/simulations/adapters"
+)
+
+var (
+	adapterType = flag.String("adapter", "sim", `node adapter to use (one of "sim" or "exec")`)
+	addr        = flag.String("addr", "0.0.0.0:8888", "listen address of the simulation API")
+	delta       = flag.Int64("delta", int64(simulation.DefaultConfig.Delta), "E2C synchrony bound in milliseconds")
+	verbosity   = flag.Int("verbosity", int(log.LvlInfo), "log verbosity (0-5)")
+)
+
+func main() {
+	flag.Parse()
+
+	log.Root().SetHandler(log.LvlFilterHandler(log.Lvl(*verbosity), log.StreamHandler(os.Stderr, log.TerminalFormat(false))))
+
+	config := *simulation.DefaultConfig
+	config.Delta = time.Duration(*delta)
+
+	services := simulation.Lifecycles(&config)
+	adapters.RegisterLifecycles(services)
+
+	var adapter adapters.NodeAdapter
+	switch *adapterType {
+	case "sim":
+		log.Info("using sim adapter")
+		adapter = adapters.NewSimAdapter(services)
+
+	case "exec":
+		tmpdir, err := ioutil.TempDir("", "e2csim")
+		if err != nil {
+			log.Crit("error creating temp dir", "err", err)
+		}
+		defer os.RemoveAll(tmpdir)
+		log.Info("using exec adapter", "tmpdir", tmpdir)
+		adapter = adapters.NewExecAdapter(tmpdir)
+
+	default:
+		log.Crit(fmt.Sprintf("unknown node adapter %q", *adapterType))
+	}
+
+	log.Info("starting e2c simulation server", "addr", *addr, "delta", config.Delta)
+	network := simulations.NewNetwork(adapter, &simulations.NetworkConfig{
+		DefaultService: simulation.ServiceName,
+	})
+	if err := http.ListenAndServe(*addr, simulations.NewServer(network)); err != nil {
+		log.Crit("error starting simulation server", "err", err)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"reflect"
 	"unicode"
 
@@ -34,6 +35,7 @@ import (
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/private"
 	"github.com/ethereum/go-ethereum/private/engine"
+	"github.com/ethereum/go-ethereum/private/engine/embedded"
 	"github.com/naoina/toml"
 	"gopkg.in/urfave/cli.v1"
 )
@@ -194,8 +196,18 @@ func makeFullNode(ctx *cli.Context) (*node.Node, ethapi.Backend) {
 		utils.RegisterRaftService(stack, ctx, &cfg.Node, ethService)
 	}
 
+	if ptm, ok := private.P.(*embedded.PrivateTransactionManager); ok {
+		utils.RegisterEmbeddedPTMService(stack, ptm)
+	} else if private.IsQuorumPrivacyEnabled() && ctx.GlobalBool(utils.QuorumPTMPersistentCacheFlag.Name) {
+		// the embedded private transaction manager reads payloads from disk already
+		utils.RegisterPrivatePayloadCache(stack, ethService, uint64(ctx.GlobalInt(utils.QuorumPTMPersistentCacheSizeFlag.Name))*1024*1024, ctx.GlobalUint64(utils.QuorumPTMPersistentCacheWarmBlocksFlag.Name))
+	}
+
 	if private.IsQuorumPrivacyEnabled() {
 		utils.RegisterExtensionService(stack, ethService)
+		if ctx.GlobalBool(utils.QuorumEnablePrivateStateChecker.Name) {
+			utils.RegisterPrivateStateChecker(stack, ethService)
+		}
 	}
 	// End Quorum
 
@@ -304,9 +316,24 @@ func QuorumSetupPrivacyConfiguration(ctx *cli.Context) (http.Config, error) {
 	if ctx.GlobalIsSet(utils.QuorumPTMUrlFlag.Name) {
 		cfg.SetHttpUrl(ctx.GlobalString(utils.QuorumPTMUrlFlag.Name))
 	}
+	if ctx.GlobalBool(utils.QuorumPTMEmbeddedFlag.Name) {
+		cfg.SetEmbedded(filepath.Join(utils.MakeDataDir(ctx), "ptm"))
+	}
 	if ctx.GlobalIsSet(utils.QuorumPTMTimeoutFlag.Name) {
 		cfg.SetTimeout(ctx.GlobalUint(utils.QuorumPTMTimeoutFlag.Name))
 	}
+	if ctx.GlobalIsSet(utils.QuorumPTMRetriesFlag.Name) {
+		cfg.SetRetries(ctx.GlobalUint(utils.QuorumPTMRetriesFlag.Name))
+	}
+	if ctx.GlobalIsSet(utils.QuorumPTMCircuitBreakerThresholdFlag.Name) {
+		cfg.SetCircuitBreakerThreshold(ctx.GlobalUint(utils.QuorumPTMCircuitBreakerThresholdFlag.Name))
+	}
+	if ctx.GlobalIsSet(utils.QuorumPTMCircuitBreakerCooldownFlag.Name) {
+		cfg.SetCircuitBreakerCooldown(ctx.GlobalUint(utils.QuorumPTMCircuitBreakerCooldownFlag.Name))
+	}
+	if ctx.GlobalIsSet(utils.QuorumPTMHealthCheckIntervalFlag.Name) {
+		cfg.SetHealthCheckInterval(ctx.GlobalUint(utils.QuorumPTMHealthCheckIntervalFlag.Name))
+	}
 	if ctx.GlobalIsSet(utils.QuorumPTMDialTimeoutFlag.Name) {
 		cfg.SetDialTimeout(ctx.GlobalUint(utils.QuorumPTMDialTimeoutFlag.Name))
 	}
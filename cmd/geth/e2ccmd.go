@@ -0,0 +1,148 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	e2cBackend "github.com/ethereum/go-ethereum/consensus/e2c/backend"
+	"github.com/ethereum/go-ethereum/node"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	E2CCalibrateDurationFlag = cli.DurationFlag{
+		Name:  "duration",
+		Usage: "How long to sample the delays measured by the node",
+		Value: time.Minute,
+	}
+	E2CCalibrateMarginFlag = cli.Float64Flag{
+		Name:  "margin",
+		Usage: "Safety factor applied to the highest observed delay",
+		Value: 2,
+	}
+
+	e2cCommand = cli.Command{
+		Name:     "e2c",
+		Usage:    "Manage E2C consensus",
+		Category: "MISCELLANEOUS COMMANDS",
+		Subcommands: []cli.Command{
+			{
+				Name:      "calibrate",
+				Usage:     "Recommend a synchrony bound (Δ) from the delays a running validator observes",
+				Action:    utils.MigrateFlags(e2cCalibrate),
+				ArgsUsage: "[endpoint]",
+				Flags: append([]cli.Flag{
+					utils.DataDirFlag,
+					E2CCalibrateDurationFlag,
+					E2CCalibrateMarginFlag,
+				}, rpcClientFlags...),
+				Description: `
+    geth e2c calibrate [endpoint]
+
+Attaches to a running E2C validator and samples the delays it measures to the
+other validators with its pings. When done, it prints the highest one-way delay
+to every validator and recommends a Δ of that delay times the margin, rounded up
+to 10ms. E2C is only safe while every message arrives within Δ, so run this while
+the network is under its usual load.`,
+			},
+		},
+	}
+)
+
+// e2cCalibrate samples e2c_synchrony on a running node and recommends a Δ
+func e2cCalibrate(ctx *cli.Context) error {
+	endpoint := ctx.Args().First()
+	if endpoint == "" {
+		path := node.DefaultDataDir()
+		if ctx.GlobalIsSet(utils.DataDirFlag.Name) {
+			path = ctx.GlobalString(utils.DataDirFlag.Name)
+		}
+		endpoint = filepath.Join(path, "geth.ipc")
+	}
+	client, err := dialRPC(endpoint, ctx)
+	if err != nil {
+		utils.Fatalf("Unable to attach to geth: %v", err)
+	}
+	defer client.Close()
+
+	var (
+		duration = ctx.Duration(E2CCalibrateDurationFlag.Name)
+		margin   = ctx.Float64(E2CCalibrateMarginFlag.Name)
+		delta    uint64
+		highest  = make(map[common.Address]*e2cBackend.PeerDelay)
+		ticker   = time.NewTicker(time.Second)
+		deadline = time.After(duration)
+	)
+	defer ticker.Stop()
+	if margin < 1 {
+		utils.Fatalf("Margin must be at least 1, got %v", margin)
+	}
+
+	fmt.Printf("Sampling delays for %v...\n", duration)
+	for done := false; !done; {
+		select {
+		case <-ticker.C:
+		case <-deadline:
+			done = true
+		}
+		var sync e2cBackend.Synchrony
+		if err := client.Call(&sync, "e2c_synchrony"); err != nil {
+			utils.Fatalf("Failed to read delays: %v", err)
+		}
+		delta = sync.Delta
+		for addr, peer := range sync.Peers {
+			if h, ok := highest[addr]; !ok || peer.MaxDelay > h.MaxDelay {
+				highest[addr] = peer
+			}
+		}
+	}
+	if len(highest) == 0 {
+		utils.Fatalf("No delays measured, is the node a running validator with pings enabled?")
+	}
+
+	addrs := make([]common.Address, 0, len(highest))
+	for addr := range highest {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return highest[addrs[i]].MaxDelay > highest[addrs[j]].MaxDelay })
+
+	fmt.Printf("\n%-42s %12s %12s %8s\n", "validator", "max delay", "last rtt", "samples")
+	for _, addr := range addrs {
+		peer := highest[addr]
+		fmt.Printf("%-42s %10.2fms %10.2fms %8d\n", addr.Hex(), peer.MaxDelay, peer.RTT, peer.Samples)
+	}
+
+	worst := highest[addrs[0]].MaxDelay
+	recommended := uint64(math.Ceil(worst*margin/10) * 10)
+	if recommended == 0 {
+		recommended = 10
+	}
+	fmt.Printf("\nHighest delay: %.2fms, current Δ: %dms, recommended Δ: %dms\n", worst, delta, recommended)
+	if worst > float64(delta) {
+		fmt.Println("Observed delays exceed the current Δ, E2C's safety guarantees do not hold")
+	} else if recommended > delta {
+		fmt.Println("The current Δ leaves less margin than requested")
+	}
+	return nil
+}
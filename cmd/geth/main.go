@@ -165,6 +165,8 @@ var (
 		configFileFlag,
 		// Quorum
 		utils.PrivateCacheTrieJournalFlag,
+		utils.PrivateGCFlag,
+		utils.PrivateGCRetainFlag,
 		utils.QuorumImmutabilityThreshold,
 		utils.EnableNodePermissionFlag,
 		utils.RaftModeFlag,
@@ -172,9 +174,21 @@ var (
 		utils.RaftJoinExistingFlag,
 		utils.RaftPortFlag,
 		utils.RaftDNSEnabledFlag,
+		utils.RaftSnapshotCompressionFlag,
+		utils.RaftStopHandOffFlag,
+		utils.RaftAutoPromoteFlag,
+		utils.RaftPromotionLagFlag,
+		utils.RaftPromotionPeriodFlag,
+		utils.RaftMaxVotersFlag,
+		utils.RaftMaxBlockGasFlag,
+		utils.RaftMaxBlockTxsFlag,
+		utils.RaftMinBlockTxsFlag,
+		utils.RaftMinBlockTxsDeadlineFlag,
+		utils.RaftPrioritySendersFlag,
 		utils.EmitCheckpointsFlag,
 		utils.IstanbulRequestTimeoutFlag,
 		utils.IstanbulBlockPeriodFlag,
+		utils.E2CSafetyMonitorFlag,
 		utils.PluginSettingsFlag,
 		utils.PluginSkipVerifyFlag,
 		utils.PluginLocalVerifyFlag,
@@ -182,11 +196,21 @@ var (
 		utils.AllowedFutureBlockTimeFlag,
 		utils.EVMCallTimeOutFlag,
 		utils.MultitenancyFlag,
+		utils.MultitenancyQuotasFlag,
 		utils.RevertReasonFlag,
 		utils.QuorumEnablePrivacyMarker,
+		utils.QuorumEnablePrivateStateChecker,
 		utils.QuorumPTMUnixSocketFlag,
 		utils.QuorumPTMUrlFlag,
+		utils.QuorumPTMEmbeddedFlag,
+		utils.QuorumPTMPersistentCacheFlag,
+		utils.QuorumPTMPersistentCacheSizeFlag,
+		utils.QuorumPTMPersistentCacheWarmBlocksFlag,
 		utils.QuorumPTMTimeoutFlag,
+		utils.QuorumPTMRetriesFlag,
+		utils.QuorumPTMCircuitBreakerThresholdFlag,
+		utils.QuorumPTMCircuitBreakerCooldownFlag,
+		utils.QuorumPTMHealthCheckIntervalFlag,
 		utils.QuorumPTMDialTimeoutFlag,
 		utils.QuorumPTMHttpIdleTimeoutFlag,
 		utils.QuorumPTMHttpWriteBufferSizeFlag,
@@ -283,6 +307,10 @@ func init() {
 		versionCommand,
 		versionCheckCommand,
 		licenseCommand,
+		// See e2ccmd.go:
+		e2cCommand,
+		// See privacycmd.go:
+		privacyCommand,
 		// See config.go
 		dumpConfigCommand,
 		// See cmd/utils/flags_legacy.go
@@ -0,0 +1,323 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/mps"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/private/consistency"
+	"github.com/ethereum/go-ethereum/rpc"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	PrivacyVerifyBlockFlag = cli.StringFlag{
+		Name:  "block",
+		Usage: "Block number to check the private state at",
+		Value: "latest",
+	}
+	PrivacyVerifyContractFlag = cli.StringSliceFlag{
+		Name:  "contract",
+		Usage: "Address of a private contract to check, may be repeated",
+	}
+	PrivacyVerifyParticipantFlag = cli.StringSliceFlag{
+		Name:  "participant",
+		Usage: "Public key of a party to check the contracts with, may be repeated (default: the participants of party protected contracts)",
+	}
+	PrivacyImportSignerFlag = cli.StringFlag{
+		Name:  "signer",
+		Usage: "Address of the node key the dump must be signed with",
+	}
+	PrivacyImportPSIFlag = cli.StringFlag{
+		Name:  "psi",
+		Usage: "Private state to import the dump into (default: the private state it was exported from)",
+	}
+	PrivacyImportBlockFlag = cli.Uint64Flag{
+		Name:  "block",
+		Usage: "Number of the block whose private state the dump is imported into, required unless the private state is empty at the head",
+	}
+	PrivacyPruneRetainFlag = cli.Uint64Flag{
+		Name:  "retain",
+		Usage: "Number of recent blocks to keep the private state of",
+		Value: core.TriesInMemory,
+	}
+
+	privacyCommand = cli.Command{
+		Name:     "privacy",
+		Usage:    "Manage private states",
+		Category: "MISCELLANEOUS COMMANDS",
+		Subcommands: []cli.Command{
+			{
+				Name:      "verify",
+				Usage:     "Check that the other parties to private contracts have the same private state",
+				Action:    utils.MigrateFlags(privacyVerify),
+				ArgsUsage: "[endpoint]",
+				Flags: append([]cli.Flag{
+					utils.DataDirFlag,
+					PrivacyVerifyBlockFlag,
+					PrivacyVerifyContractFlag,
+					PrivacyVerifyParticipantFlag,
+				}, rpcClientFlags...),
+				Description: `
+    geth privacy verify [endpoint] --contract <address> [--participant <key>]
+
+Attaches to a running node and checks the private state of the contracts at the
+block with the nodes of the other parties, through the private transaction
+manager. The nodes of the parties must be peers of the node. Party protected
+contracts are checked with the participants of the transaction that created
+them, other contracts need the participants, without the parties of the node.
+
+Prints, for every contract and party, whether their private states are the
+same and fails if any diverged or couldn't be checked.`,
+			},
+			{
+				Name:      "import",
+				Usage:     "Import a private state dump taken with debug_exportPrivateState",
+				Action:    utils.MigrateFlags(privacyImport),
+				ArgsUsage: "<dumpfile>",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.CacheFlag,
+					utils.SyncModeFlag,
+					PrivacyImportSignerFlag,
+					PrivacyImportPSIFlag,
+					PrivacyImportBlockFlag,
+				},
+				Description: `
+    geth privacy import [--signer <address>] [--psi <psi>] [--block <number>] <dumpfile>
+
+Imports the contracts of a private state dump into the private state of a block.
+Without --block, the private state of the head block has to be empty. The import
+fails rather than replace contracts the private state already has. The dump is
+the JSON returned by debug_exportPrivateState, e.g.
+
+    geth attach --exec 'JSON.stringify(debug.exportPrivateState("private", [], "latest"))' > dump.json
+
+The signature of the dump is checked, --signer requires it to be signed by the
+node key of the exporting node. Run it while the node is stopped, with the
+private transaction manager flags of the node when it has multiple private
+states.`,
+			},
+			{
+				Name:   "prune",
+				Usage:  "Delete the private states of the blocks before the most recent ones",
+				Action: utils.MigrateFlags(privacyPrune),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.AncientFlag,
+					utils.CacheFlag,
+					utils.CacheDatabaseFlag,
+					PrivacyPruneRetainFlag,
+				},
+				Description: `
+    geth privacy prune [--retain <blocks>]
+
+Deletes from the database the trie nodes of the private states of the blocks
+before the most recent ones, which the node never reads again unless it runs
+transactions or calls on older blocks. Run it while the node is stopped.
+
+The private states of the most recent blocks, of the block the node restarts
+from and of the seeds of the private states created at runtime are kept, as well
+as the trie nodes they share with any public state on disk. Contract code is
+kept. The node can run with --private.gc afterwards to stop writing the multiple
+private states of every block to disk.`,
+			},
+		},
+	}
+)
+
+// privacyPrune deletes the private states of the blocks before the most recent ones
+func privacyPrune(ctx *cli.Context) error {
+	retain := ctx.Uint64(PrivacyPruneRetainFlag.Name)
+	if retain < core.TriesInMemory {
+		utils.Fatalf("--%s must be at least %d", PrivacyPruneRetainFlag.Name, core.TriesInMemory)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chainDb := utils.MakeChainDatabase(ctx, stack)
+	defer chainDb.Close()
+
+	stats, err := mps.PrunePrivateStates(chainDb, retain)
+	if err != nil {
+		utils.Fatalf("Failed to prune the private states: %v", err)
+	}
+	if stats.Missing > 0 {
+		log.Warn("Some private states were incomplete already", "blocks", stats.Missing)
+	}
+	fmt.Printf("Deleted %d trie nodes of the private states before block %d, head block %d, restart block %d\n",
+		stats.Nodes, stats.Retained, stats.Head, stats.Restart)
+	return nil
+}
+
+// privacyImport writes a private state dump to the private state of the given block,
+// or of the head block if its private state is empty
+func privacyImport(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+	data, err := ioutil.ReadFile(ctx.Args().First())
+	if err != nil {
+		utils.Fatalf("Failed to read the dump: %v", err)
+	}
+	var dump mps.PrivateStateDump
+	if err := json.Unmarshal(data, &dump); err != nil {
+		utils.Fatalf("Invalid dump: %v", err)
+	}
+	signer, err := dump.Signer()
+	if err != nil {
+		utils.Fatalf("Failed to verify the dump: %v", err)
+	}
+	if expected := ctx.String(PrivacyImportSignerFlag.Name); expected == "" {
+		log.Warn("Importing a private state dump without checking who signed it", "signer", signer)
+	} else if common.HexToAddress(expected) != signer {
+		utils.Fatalf("Dump signed by %s, expected %s", signer.Hex(), expected)
+	}
+	psi := dump.PSI
+	if ctx.IsSet(PrivacyImportPSIFlag.Name) {
+		psi = types.PrivateStateIdentifier(ctx.String(PrivacyImportPSIFlag.Name))
+	}
+
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, chainDb := utils.MakeChain(ctx, stack, false, true)
+	defer chainDb.Close()
+
+	psm := chain.PrivateStateManager()
+	managed := false
+	for _, id := range psm.PSIs() {
+		managed = managed || id == psi
+	}
+	if !managed {
+		utils.Fatalf("Unknown private state %s", psi)
+	}
+	target := chain.CurrentBlock()
+	if ctx.IsSet(PrivacyImportBlockFlag.Name) {
+		number := ctx.Uint64(PrivacyImportBlockFlag.Name)
+		if target = chain.GetBlockByNumber(number); target == nil {
+			utils.Fatalf("Unknown block %d", number)
+		}
+	}
+	repo, err := psm.StateRepository(target.Root())
+	if err != nil {
+		utils.Fatalf("Failed to open the private states: %v", err)
+	}
+	privateState, err := repo.StatePSI(psi)
+	if err != nil {
+		utils.Fatalf("Failed to open private state %s: %v", psi, err)
+	}
+	if !ctx.IsSet(PrivacyImportBlockFlag.Name) && privateState.IntermediateRoot(true) != types.EmptyRootHash {
+		utils.Fatalf("Private state %s isn't empty at the head, pass the block to import into with --%s", psi, PrivacyImportBlockFlag.Name)
+	}
+	for address := range dump.Accounts {
+		if privateState.Exist(address) {
+			utils.Fatalf("Private state %s already has %s at block %d", psi, address.Hex(), target.NumberU64())
+		}
+	}
+	if err := dump.Apply(privateState); err != nil {
+		utils.Fatalf("Failed to import the dump: %v", err)
+	}
+	root, err := repo.CommitAndWrite(chain.Config().IsEIP158(target.Number()), target)
+	if err != nil {
+		utils.Fatalf("Failed to write the private state: %v", err)
+	}
+	if err := psm.TrieDB().Commit(root, false, nil); err != nil {
+		utils.Fatalf("Failed to write the private state: %v", err)
+	}
+	fmt.Printf("Imported %d accounts of private state %s at block %d, signed by %s, into private state %s at block %d\n",
+		len(dump.Accounts), dump.PSI, dump.BlockNumber, signer.Hex(), psi, target.NumberU64())
+	return nil
+}
+
+// privacyVerify runs privacy_verifyPrivateState on a running node and prints the report
+func privacyVerify(ctx *cli.Context) error {
+	endpoint := ctx.Args().First()
+	if endpoint == "" {
+		path := node.DefaultDataDir()
+		if ctx.GlobalIsSet(utils.DataDirFlag.Name) {
+			path = ctx.GlobalString(utils.DataDirFlag.Name)
+		}
+		endpoint = filepath.Join(path, "geth.ipc")
+	}
+	var contracts []common.Address
+	for _, contract := range ctx.StringSlice(PrivacyVerifyContractFlag.Name) {
+		if !common.IsHexAddress(contract) {
+			utils.Fatalf("Invalid contract address %q", contract)
+		}
+		contracts = append(contracts, common.HexToAddress(contract))
+	}
+	if len(contracts) == 0 {
+		utils.Fatalf("No contracts to verify, pass them with --%s", PrivacyVerifyContractFlag.Name)
+	}
+	// the block is sent the way the RPC API takes it: hex or a tag
+	block := ctx.String(PrivacyVerifyBlockFlag.Name)
+	if number, err := strconv.ParseUint(block, 10, 63); err == nil {
+		block = hexutil.EncodeUint64(number)
+	} else if err := new(rpc.BlockNumber).UnmarshalJSON([]byte(block)); err != nil {
+		utils.Fatalf("Invalid block %q: %v", block, err)
+	}
+	participants := ctx.StringSlice(PrivacyVerifyParticipantFlag.Name)
+
+	client, err := dialRPC(endpoint, ctx)
+	if err != nil {
+		utils.Fatalf("Unable to attach to geth: %v", err)
+	}
+	defer client.Close()
+
+	var report consistency.Report
+	if err := client.Call(&report, "privacy_verifyPrivateState", block, contracts, participants); err != nil {
+		utils.Fatalf("Failed to verify the private state: %v", err)
+	}
+
+	fmt.Printf("Private state at block %d (%s)\n\n", report.BlockNumber, report.BlockHash.Hex())
+	fmt.Printf("%-42s %-44s %-10s %s\n", "contract", "party", "status", "node")
+	for _, contract := range report.Contracts {
+		if contract.Error != "" {
+			fmt.Printf("%-42s %-44s %-10s %s\n", contract.Address.Hex(), "", "error", contract.Error)
+			continue
+		}
+		if len(contract.Parties) == 0 {
+			fmt.Printf("%-42s %-44s %-10s\n", contract.Address.Hex(), "", "no parties")
+		}
+		for _, party := range contract.Parties {
+			detail := party.Node
+			if party.Error != "" {
+				detail = party.Error
+			}
+			fmt.Printf("%-42s %-44s %-10s %s\n", contract.Address.Hex(), party.Party, party.Status, detail)
+		}
+	}
+	fmt.Println()
+	if !report.Consistent {
+		utils.Fatalf("The private states diverged or couldn't all be checked")
+	}
+	fmt.Println("The private states are consistent")
+	return nil
+}
@@ -259,9 +259,13 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.PluginPublicKeyFlag,
 			utils.AllowedFutureBlockTimeFlag,
 			utils.MultitenancyFlag,
+			utils.MultitenancyQuotasFlag,
 			utils.RevertReasonFlag,
 			utils.PrivateCacheTrieJournalFlag,
+			utils.PrivateGCFlag,
+			utils.PrivateGCRetainFlag,
 			utils.QuorumEnablePrivacyMarker,
+			utils.QuorumEnablePrivateStateChecker,
 		},
 	},
 	{
@@ -269,7 +273,15 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 		Flags: []cli.Flag{
 			utils.QuorumPTMUnixSocketFlag,
 			utils.QuorumPTMUrlFlag,
+			utils.QuorumPTMEmbeddedFlag,
+			utils.QuorumPTMPersistentCacheFlag,
+			utils.QuorumPTMPersistentCacheSizeFlag,
+			utils.QuorumPTMPersistentCacheWarmBlocksFlag,
 			utils.QuorumPTMTimeoutFlag,
+			utils.QuorumPTMRetriesFlag,
+			utils.QuorumPTMCircuitBreakerThresholdFlag,
+			utils.QuorumPTMCircuitBreakerCooldownFlag,
+			utils.QuorumPTMHealthCheckIntervalFlag,
 			utils.QuorumPTMDialTimeoutFlag,
 			utils.QuorumPTMHttpIdleTimeoutFlag,
 			utils.QuorumPTMHttpWriteBufferSizeFlag,
@@ -295,6 +307,17 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.RaftJoinExistingFlag,
 			utils.RaftPortFlag,
 			utils.RaftDNSEnabledFlag,
+			utils.RaftSnapshotCompressionFlag,
+			utils.RaftStopHandOffFlag,
+			utils.RaftAutoPromoteFlag,
+			utils.RaftPromotionLagFlag,
+			utils.RaftPromotionPeriodFlag,
+			utils.RaftMaxVotersFlag,
+			utils.RaftMaxBlockGasFlag,
+			utils.RaftMaxBlockTxsFlag,
+			utils.RaftMinBlockTxsFlag,
+			utils.RaftMinBlockTxsDeadlineFlag,
+			utils.RaftPrioritySendersFlag,
 		},
 	},
 	{
@@ -304,6 +327,12 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.IstanbulBlockPeriodFlag,
 		},
 	},
+	{
+		Name: "E2C",
+		Flags: []cli.Flag{
+			utils.E2CSafetyMonitorFlag,
+		},
+	},
 	// END QUORUM
 	{
 		Name: "MISC",
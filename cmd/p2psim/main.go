@@ -133,6 +133,11 @@ func main() {
 							Value: "",
 							Usage: "node private key (hex encoded)",
 						},
+						cli.StringFlag{
+							Name:  "properties",
+							Value: "",
+							Usage: "node properties (comma separated)",
+						},
 					},
 				},
 				{
@@ -291,6 +296,9 @@ func createNode(ctx *cli.Context) error {
 	if services := ctx.String("services"); services != "" {
 		config.Lifecycles = strings.Split(services, ",")
 	}
+	if properties := ctx.String("properties"); properties != "" {
+		config.Properties = strings.Split(properties, ",")
+	}
 	node, err := client.CreateNode(config)
 	if err != nil {
 		return err
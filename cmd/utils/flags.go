@@ -73,6 +73,9 @@ import (
 	"github.com/ethereum/go-ethereum/permission/core/types"
 	"github.com/ethereum/go-ethereum/plugin"
 	"github.com/ethereum/go-ethereum/private"
+	"github.com/ethereum/go-ethereum/private/cache"
+	"github.com/ethereum/go-ethereum/private/consistency"
+	"github.com/ethereum/go-ethereum/private/engine/embedded"
 	"github.com/ethereum/go-ethereum/raft"
 	pcsclite "github.com/gballet/go-libpcsclite"
 	"gopkg.in/urfave/cli.v1"
@@ -811,6 +814,53 @@ var (
 		Name:  "raftdnsenable",
 		Usage: "Enable DNS resolution of peers",
 	}
+	RaftSnapshotCompressionFlag = cli.BoolFlag{
+		Name:  "raftsnapshotcompression",
+		Usage: "Snappy compress the raft membership snapshots taken by this node. Nodes without snapshot compression support can't read them, so only enable it once every node in the cluster has been upgraded",
+	}
+	RaftStopHandOffFlag = cli.BoolFlag{
+		Name:  "raftstophandoff",
+		Usage: "Hand off raft leadership to the most up to date peer when this node is stopped while leading",
+	}
+	RaftAutoPromoteFlag = cli.BoolFlag{
+		Name:  "raftautopromote",
+		Usage: "Promote learners to voters automatically once they caught up, while this node is the leader",
+	}
+	RaftPromotionLagFlag = cli.Uint64Flag{
+		Name:  "raftpromotionlag",
+		Usage: "Number of raft entries a learner may be behind the leader to be promoted automatically",
+		Value: 100,
+	}
+	RaftPromotionPeriodFlag = cli.DurationFlag{
+		Name:  "raftpromotionperiod",
+		Usage: "Time a learner must stay caught up and active before it's promoted automatically",
+		Value: time.Minute,
+	}
+	RaftMaxVotersFlag = cli.IntFlag{
+		Name:  "raftmaxvoters",
+		Usage: "Maximum number of voters the automatic promotion grows the cluster to (0 = no limit)",
+		Value: 7,
+	}
+	RaftMaxBlockGasFlag = cli.Uint64Flag{
+		Name:  "raftmaxblockgas",
+		Usage: "Maximum gas of the transactions in a minted block (0 = block gas limit)",
+	}
+	RaftMaxBlockTxsFlag = cli.IntFlag{
+		Name:  "raftmaxblocktxs",
+		Usage: "Maximum number of transactions in a minted block (0 = no limit)",
+	}
+	RaftMinBlockTxsFlag = cli.IntFlag{
+		Name:  "raftminblocktxs",
+		Usage: "Number of pending transactions to wait for before minting a block, requires --raftminblocktxsdeadline",
+	}
+	RaftMinBlockTxsDeadlineFlag = cli.IntFlag{
+		Name:  "raftminblocktxsdeadline",
+		Usage: "Time in milliseconds after which a block is minted without --raftminblocktxs pending transactions",
+	}
+	RaftPrioritySendersFlag = cli.StringFlag{
+		Name:  "raftprioritysenders",
+		Usage: "Comma separated list of senders whose transactions are minted first, e.g. permissioning admins",
+	}
 
 	// Permission
 	EnableNodePermissionFlag = cli.BoolFlag{
@@ -855,11 +905,20 @@ var (
 		Usage: "Default minimum difference between two consecutive block's timestamps in seconds",
 		Value: eth.DefaultConfig.Istanbul.BlockPeriod,
 	}
+	// E2C settings
+	E2CSafetyMonitorFlag = cli.BoolFlag{
+		Name:  "e2c.safetymonitor",
+		Usage: "Compare committed E2C blocks with other nodes, stop mining and write a report if they diverge",
+	}
 	// Multitenancy setting
 	MultitenancyFlag = cli.BoolFlag{
 		Name:  "multitenancy",
 		Usage: "Enable multitenancy support for this node. This requires RPC Security Plugin to also be configured.",
 	}
+	MultitenancyQuotasFlag = cli.StringFlag{
+		Name:  "multitenancy.quotas",
+		Usage: "JSON file with the default quota and the quotas of the tenants (by PSI or client ID) of a multitenant node, unless granted by the security plugin",
+	}
 
 	// Revert Reason
 	RevertReasonFlag = cli.BoolFlag{
@@ -873,11 +932,24 @@ var (
 		Usage: "Disk journal directory for private trie cache to survive node restarts",
 		Value: eth.DefaultConfig.PrivateTrieCleanCacheJournal,
 	}
+	PrivateGCFlag = cli.BoolFlag{
+		Name:  "private.gc",
+		Usage: "Garbage collect multiple private states in memory, like the public state, instead of writing the private states of every block to disk",
+	}
+	PrivateGCRetainFlag = cli.Uint64Flag{
+		Name:  "private.gc.retain",
+		Usage: "Number of recent blocks to keep the private state of in memory when garbage collecting",
+		Value: core.TriesInMemory,
+	}
 
 	QuorumEnablePrivacyMarker = cli.BoolFlag{
 		Name:  "privacymarker.enable",
 		Usage: "Enable use of privacy marker transactions (PMT) for this node.",
 	}
+	QuorumEnablePrivateStateChecker = cli.BoolFlag{
+		Name:  "privatestatecheck.enable",
+		Usage: "Enable the private state checker (privacy API and pscheck protocol), which exchanges commitments to the private states of contracts with the peers that are parties to them",
+	}
 
 	// Quorum Private Transaction Manager connection options
 	QuorumPTMUnixSocketFlag = DirectoryFlag{
@@ -886,13 +958,51 @@ var (
 	}
 	QuorumPTMUrlFlag = cli.StringFlag{
 		Name:  "ptm.url",
-		Usage: "URL when using http connection to private transaction manager",
+		Usage: "URL when using http connection to private transaction manager, or comma separated URLs to fail over between",
+	}
+	QuorumPTMEmbeddedFlag = cli.BoolFlag{
+		Name:  "ptm.embedded",
+		Usage: "Run an embedded private transaction manager for development and test networks, storing payloads in the data directory and exchanging them with peers over devp2p",
+	}
+	QuorumPTMPersistentCacheFlag = cli.BoolFlag{
+		Name:  "ptm.cache.persistent",
+		Usage: "Keep the payloads received from the private transaction manager in a disk backed cache, so restarts and reorgs don't fetch them again. The decrypted payloads are sealed with a key derived from the node key, so anyone with access to the node key can read them",
+	}
+	QuorumPTMPersistentCacheSizeFlag = cli.IntFlag{
+		Name:  "ptm.cache.size",
+		Usage: "Size (megabytes) of the persistent private payload cache, the least recently used payloads are evicted first",
+		Value: 256,
+	}
+	QuorumPTMPersistentCacheWarmBlocksFlag = cli.Uint64Flag{
+		Name:  "ptm.cache.warmblocks",
+		Usage: "Number of latest blocks whose private payloads are cached on startup",
+		Value: 128,
 	}
 	QuorumPTMTimeoutFlag = cli.UintFlag{
 		Name:  "ptm.timeout",
-		Usage: "Timeout (seconds) for the private transaction manager connection. Zero value means timeout disabled.",
+		Usage: "Timeout (seconds) for each attempt of a request to the private transaction manager. Zero value means timeout disabled.",
 		Value: http2.DefaultConfig.Timeout,
 	}
+	QuorumPTMRetriesFlag = cli.UintFlag{
+		Name:  "ptm.retries",
+		Usage: "Number of times a failed read from the private transaction manager is retried, on the next URL if there are several. Writes such as send and storeraw are never retried. Zero value means retries disabled.",
+		Value: http2.DefaultConfig.Retries,
+	}
+	QuorumPTMCircuitBreakerThresholdFlag = cli.UintFlag{
+		Name:  "ptm.circuitbreaker.threshold",
+		Usage: "Consecutive failures after which a private transaction manager URL is considered unavailable. Zero value means circuit breaker disabled.",
+		Value: http2.DefaultConfig.CircuitBreakerThreshold,
+	}
+	QuorumPTMCircuitBreakerCooldownFlag = cli.UintFlag{
+		Name:  "ptm.circuitbreaker.cooldown",
+		Usage: "Time (seconds) an unavailable private transaction manager URL isn't used before trying it again",
+		Value: http2.DefaultConfig.CircuitBreakerCooldown,
+	}
+	QuorumPTMHealthCheckIntervalFlag = cli.UintFlag{
+		Name:  "ptm.healthcheck.interval",
+		Usage: "Time (seconds) between health checks of the private transaction manager URLs. Zero value means health checks disabled.",
+		Value: http2.DefaultConfig.HealthCheckInterval,
+	}
 	QuorumPTMDialTimeoutFlag = cli.UintFlag{
 		Name:  "ptm.dialtimeout",
 		Usage: "Dial timeout (seconds) for the private transaction manager connection. Zero value means timeout disabled.",
@@ -1447,6 +1557,9 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	if ctx.GlobalIsSet(MultitenancyFlag.Name) {
 		cfg.EnableMultitenancy = ctx.GlobalBool(MultitenancyFlag.Name)
 	}
+	if ctx.GlobalIsSet(MultitenancyQuotasFlag.Name) {
+		cfg.MultitenancyQuotas = ctx.GlobalString(MultitenancyQuotasFlag.Name)
+	}
 }
 
 func setSmartCard(ctx *cli.Context, cfg *node.Config) {
@@ -1721,6 +1834,12 @@ func setIstanbul(ctx *cli.Context, cfg *eth.Config) {
 	}
 }
 
+func setE2C(ctx *cli.Context, cfg *eth.Config) {
+	if ctx.GlobalIsSet(E2CSafetyMonitorFlag.Name) {
+		cfg.E2C.SafetyMonitor = ctx.GlobalBool(E2CSafetyMonitorFlag.Name)
+	}
+}
+
 func setRaft(ctx *cli.Context, cfg *eth.Config) {
 	cfg.RaftMode = ctx.GlobalBool(RaftModeFlag.Name)
 }
@@ -1729,10 +1848,18 @@ func setQuorumConfig(ctx *cli.Context, cfg *eth.Config) error {
 	cfg.EVMCallTimeOut = time.Duration(ctx.GlobalInt(EVMCallTimeOutFlag.Name)) * time.Second
 	cfg.QuorumChainConfig = core.NewQuorumChainConfig(ctx.GlobalBool(MultitenancyFlag.Name), ctx.GlobalBool(RevertReasonFlag.Name), ctx.GlobalBool(QuorumEnablePrivacyMarker.Name))
 	setIstanbul(ctx, cfg)
+	setE2C(ctx, cfg)
 	setRaft(ctx, cfg)
 	if ctx.GlobalIsSet(PrivateCacheTrieJournalFlag.Name) {
 		cfg.PrivateTrieCleanCacheJournal = ctx.GlobalString(PrivateCacheTrieJournalFlag.Name)
 	}
+	cfg.PrivateTrieGC = ctx.GlobalBool(PrivateGCFlag.Name)
+	if ctx.GlobalIsSet(PrivateGCRetainFlag.Name) {
+		if retain := ctx.GlobalUint64(PrivateGCRetainFlag.Name); retain < core.TriesInMemory {
+			return fmt.Errorf("--%s must be at least %d", PrivateGCRetainFlag.Name, core.TriesInMemory)
+		}
+		cfg.PrivateTriesInMemory = ctx.GlobalUint64(PrivateGCRetainFlag.Name)
+	}
 	if ctx.GlobalString(CacheTrieJournalFlag.Name) == cfg.PrivateTrieCleanCacheJournal {
 		return fmt.Errorf("configuration collision with '%s' and '%s' that must be different", CacheTrieJournalFlag.Name, PrivateCacheTrieJournalFlag.Name)
 	}
@@ -2079,6 +2206,28 @@ func RegisterRaftService(stack *node.Node, ctx *cli.Context, nodeCfg *node.Confi
 	raftLogDir := nodeCfg.RaftLogDir // default value is set either 'datadir' or 'raftlogdir'
 	joinExistingId := ctx.GlobalInt(RaftJoinExistingFlag.Name)
 	useDns := ctx.GlobalBool(RaftDNSEnabledFlag.Name)
+	compressSnapshots := ctx.GlobalBool(RaftSnapshotCompressionFlag.Name)
+	handOffOnStop := ctx.GlobalBool(RaftStopHandOffFlag.Name)
+	promotion := raft.PromotionPolicy{
+		Enabled:   ctx.GlobalBool(RaftAutoPromoteFlag.Name),
+		MaxLag:    ctx.GlobalUint64(RaftPromotionLagFlag.Name),
+		Period:    ctx.GlobalDuration(RaftPromotionPeriodFlag.Name),
+		MaxVoters: ctx.GlobalInt(RaftMaxVotersFlag.Name),
+	}
+	minting := raft.MintingPolicy{
+		MaxGas:         ctx.GlobalUint64(RaftMaxBlockGasFlag.Name),
+		MaxTxs:         ctx.GlobalInt(RaftMaxBlockTxsFlag.Name),
+		MinTxs:         ctx.GlobalInt(RaftMinBlockTxsFlag.Name),
+		MinTxsDeadline: uint64(ctx.GlobalInt(RaftMinBlockTxsDeadlineFlag.Name)),
+	}
+	if ctx.GlobalIsSet(RaftPrioritySendersFlag.Name) {
+		for _, sender := range SplitAndTrim(ctx.GlobalString(RaftPrioritySendersFlag.Name)) {
+			if !common.IsHexAddress(sender) {
+				Fatalf("Invalid priority sender %q in --%s", sender, RaftPrioritySendersFlag.Name)
+			}
+			minting.PrioritySenders = append(minting.PrioritySenders, common.HexToAddress(sender))
+		}
+	}
 	raftPort := uint16(ctx.GlobalInt(RaftPortFlag.Name))
 
 	privkey := nodeCfg.NodeKey()
@@ -2114,7 +2263,7 @@ func RegisterRaftService(stack *node.Node, ctx *cli.Context, nodeCfg *node.Confi
 		}
 	}
 
-	_, err := raft.New(stack, ethService.BlockChain().Config(), myId, raftPort, joinExisting, blockTimeNanos, ethService, peers, raftLogDir, useDns)
+	_, err := raft.New(stack, ethService.BlockChain().Config(), myId, raftPort, joinExisting, blockTimeNanos, ethService, peers, raftLogDir, useDns, compressSnapshots, handOffOnStop, promotion, minting)
 	if err != nil {
 		Fatalf("raft: Failed to register the Raft service: %v", err)
 	}
@@ -2131,6 +2280,65 @@ func RegisterExtensionService(stack *node.Node, ethService *eth.Ethereum) {
 	log.Info("extension service registered")
 }
 
+// RegisterEmbeddedPTMService adds the ptm protocol of the embedded private transaction
+// manager to the node, and closes the manager with it
+func RegisterEmbeddedPTMService(stack *node.Node, ptm *embedded.PrivateTransactionManager) {
+	stack.RegisterProtocols(ptm.Protocols())
+	stack.RegisterLifecycle(ptm)
+
+	log.Info("embedded private transaction manager registered", "key", ptm.PublicKey())
+}
+
+// RegisterPrivateStateChecker adds the privacy API and the pscheck protocol, so the
+// node checks its private states with the other parties and answers their checks
+func RegisterPrivateStateChecker(stack *node.Node, ethService *eth.Ethereum) {
+	checker := consistency.New(ethService.BlockChain(), private.P, stack.Server().PrivateKey)
+	stack.RegisterAPIs(checker.APIs())
+	stack.RegisterProtocols(checker.Protocols())
+
+	log.Info("private state checker registered")
+}
+
+// RegisterPrivatePayloadCache puts a persistent cache in front of the private transaction
+// manager and warms it with the payloads of the latest blocks in the background
+func RegisterPrivatePayloadCache(stack *node.Node, ethService *eth.Ethereum, size uint64, warmBlocks uint64) {
+	// the payloads are sealed with a key only this node has, derived from its node key
+	var key [32]byte
+	copy(key[:], crypto.Keccak256(crypto.FromECDSA(stack.Config().NodeKey()), []byte("private payload cache")))
+	c, err := cache.NewPersistent(stack.ResolvePath("ptmcache"), size, key)
+	if err != nil {
+		Fatalf("Failed to open the private payload cache: %v", err)
+	}
+	private.P = private.WithPersistentCache(private.P, c)
+	stack.RegisterLifecycle(c)
+
+	go func() {
+		var (
+			bc     = ethService.BlockChain()
+			head   = bc.CurrentBlock().NumberU64()
+			from   = uint64(0)
+			hashes []common.EncryptedPayloadHash
+		)
+		if head > warmBlocks {
+			from = head - warmBlocks + 1
+		}
+		for number := from; number <= head && warmBlocks > 0; number++ {
+			block := bc.GetBlockByNumber(number)
+			if block == nil {
+				break
+			}
+			for _, tx := range block.Transactions() {
+				if tx.IsPrivate() || tx.IsPrivacyMarker() {
+					hashes = append(hashes, common.BytesToEncryptedPayloadHash(tx.Data()))
+				}
+			}
+		}
+		start := time.Now()
+		cached := private.WarmCache(hashes)
+		log.Info("Warmed the private payload cache", "blocks", head-from+1, "transactions", len(hashes), "payloads", cached, "elapsed", common.PrettyDuration(time.Since(start)))
+	}()
+}
+
 func SetupMetrics(ctx *cli.Context) {
 	if metrics.Enabled {
 		log.Info("Enabling metrics collection")
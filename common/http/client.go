@@ -3,27 +3,28 @@ package http
 import (
 	"fmt"
 	"net/http"
-	"time"
+	"net/url"
+	"path/filepath"
 
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/private/engine"
 )
 
 func CreateClient(cfg Config) (*engine.Client, error) {
-	var client *engine.Client
+	var (
+		transport http.RoundTripper
+		endpoints []*endpoint
+	)
 	if IsSocketConfigured(cfg) {
 
 		log.Info("Connecting to private tx manager using IPC socket")
-		client = &engine.Client{
-			HttpClient: &http.Client{
-				Transport: unixTransport(cfg),
-			},
-			BaseURL: "http+unix://c",
-		}
+		transport = unixTransport(cfg)
+		base, _ := url.Parse("http+unix://c")
+		endpoints = []*endpoint{{name: filepath.Join(cfg.WorkDir, cfg.Socket), base: base}}
 
 	} else {
 
-		transport := httpTransport(cfg)
+		httpTransport := httpTransport(cfg)
 		if cfg.TlsMode == TlsOff {
 			log.Info("Connecting to private tx manager using HTTP")
 		} else {
@@ -32,18 +33,32 @@ func CreateClient(cfg Config) (*engine.Client, error) {
 			if err != nil {
 				return nil, fmt.Errorf("unable to create http.client to private tx manager due to: %s", err)
 			}
-			transport.TLSClientConfig = tlsConfig
+			httpTransport.TLSClientConfig = tlsConfig
 		}
+		transport = httpTransport
 
-		client = &engine.Client{
-			HttpClient: &http.Client{
-				Timeout:   time.Duration(cfg.Timeout) * time.Second,
-				Transport: transport,
-			},
-			BaseURL: cfg.HttpUrl,
+		for _, httpUrl := range cfg.HttpUrls() {
+			base, err := url.Parse(httpUrl)
+			if err != nil {
+				return nil, fmt.Errorf("invalid private tx manager url %s: %v", httpUrl, err)
+			}
+			endpoints = append(endpoints, &endpoint{name: base.Redacted(), base: base})
+		}
+		if len(endpoints) > 1 && cfg.Retries == 0 && cfg.CircuitBreakerThreshold == 0 {
+			log.Warn("Failover between private tx manager urls disabled, only the first one is used", "count", len(endpoints))
+		} else if len(endpoints) > 1 {
+			log.Info("Failing over between private tx manager urls", "count", len(endpoints), "retries", cfg.Retries)
 		}
 
 	}
 
-	return client, nil
+	// the failover transport times out each attempt, rather than the whole request
+	failover := newFailoverTransport(cfg, endpoints, transport)
+	return &engine.Client{
+		HttpClient: &http.Client{
+			Transport: failover,
+		},
+		BaseURL: failoverBaseURL,
+		Monitor: failover,
+	}, nil
 }
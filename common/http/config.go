@@ -13,6 +13,7 @@ const (
 	NoConnection               string = "none"
 	UnixDomainSocketConnection string = "unix"
 	HttpConnection             string = "http"
+	EmbeddedConnection         string = "embedded"
 )
 
 const (
@@ -23,9 +24,9 @@ const (
 type Config struct {
 	ConnectionType        string `toml:"-"` // connection type is not loaded from toml
 	Socket                string // filename for unix domain socket
-	WorkDir               string // directory for unix domain socket
-	HttpUrl               string // transaction manager URL for HTTP connection
-	Timeout               uint   // timeout for overall client call (seconds), zero means timeout disabled
+	WorkDir               string // directory for unix domain socket, or the store of the embedded transaction manager
+	HttpUrl               string // transaction manager URL for HTTP connection, or comma separated URLs to fail over between
+	Timeout               uint   // timeout for each attempt of a client call (seconds), zero means timeout disabled
 	DialTimeout           uint   // timeout for connecting to unix socket (seconds)
 	HttpIdleConnTimeout   uint   // timeout for idle http connection (seconds), zero means timeout disabled
 	HttpWriteBufferSize   int    // size of http connection write buffer (bytes), if zero then uses http.Transport default
@@ -35,18 +36,39 @@ type Config struct {
 	TlsClientCert         string // path to file containing client certificate (or chain of certs)
 	TlsClientKey          string // path to file containing client's private key
 	TlsInsecureSkipVerify bool   // if true then does not verify that server certificate is CA signed
+
+	// failover is off unless configured: a single attempt per request, no circuit
+	// breakers nor health checks
+	Retries                 uint // times a failed read is retried, on the next URL if there are several
+	CircuitBreakerThreshold uint // consecutive failures after which a connection is considered unavailable, zero disables it
+	CircuitBreakerCooldown  uint // time (seconds) an unavailable connection isn't used before trying it again
+	HealthCheckInterval     uint // time (seconds) between health checks of the connections, zero disables them
 }
 
 var NoConnectionConfig = Config{
 	ConnectionType: NoConnection,
 	TlsMode:        TlsOff,
+	// failover defaults, for connections configured with flags only
+	CircuitBreakerCooldown: DefaultConfig.CircuitBreakerCooldown,
 }
 
 var DefaultConfig = Config{
-	Timeout:             5,
-	DialTimeout:         1,
-	HttpIdleConnTimeout: 10,
-	TlsMode:             TlsOff,
+	Timeout:                5,
+	DialTimeout:            1,
+	HttpIdleConnTimeout:    10,
+	TlsMode:                TlsOff,
+	CircuitBreakerCooldown: 10,
+}
+
+// HttpUrls returns the URLs of the HTTP connection, in order of preference
+func (cfg *Config) HttpUrls() []string {
+	var urls []string
+	for _, httpUrl := range strings.Split(cfg.HttpUrl, ",") {
+		if httpUrl = strings.TrimSpace(httpUrl); httpUrl != "" {
+			urls = append(urls, httpUrl)
+		}
+	}
+	return urls
 }
 
 func IsSocketConfigured(cfg Config) bool {
@@ -133,8 +155,10 @@ func (cfg *Config) Validate() error {
 		case TlsOff:
 			//no action needed
 		case TlsStrict:
-			if !strings.Contains(strings.ToLower(cfg.HttpUrl), "https") {
-				return fmt.Errorf("connection is configured with TLS but HTTPS url is not specified")
+			for _, httpUrl := range cfg.HttpUrls() {
+				if !strings.Contains(strings.ToLower(httpUrl), "https") {
+					return fmt.Errorf("connection is configured with TLS but HTTPS url is not specified")
+				}
 			}
 			if (len(cfg.TlsClientCert) == 0 && len(cfg.TlsClientKey) != 0) || (len(cfg.TlsClientCert) != 0 && len(cfg.TlsClientKey) == 0) {
 				return fmt.Errorf("invalid details for HTTP connection with TLS, configuration must specify both clientCert and clientKey, or neither one")
@@ -142,6 +166,13 @@ func (cfg *Config) Validate() error {
 		default:
 			return fmt.Errorf("invalid value for TLS mode in config file, must be either OFF or STRICT")
 		}
+	case EmbeddedConnection:
+		if len(cfg.Socket) != 0 || len(cfg.HttpUrl) != 0 {
+			return fmt.Errorf("embedded private transaction manager cannot be used with an ipc file or HTTP URL")
+		}
+		if len(cfg.WorkDir) == 0 { //sanity check - should never occur
+			return fmt.Errorf("directory is missing for the embedded private transaction manager")
+		}
 	}
 
 	return nil
@@ -165,6 +196,11 @@ func (cfg *Config) SetHttpUrl(httpUrl string) {
 	cfg.HttpUrl = httpUrl
 }
 
+func (cfg *Config) SetEmbedded(dir string) {
+	cfg.ConnectionType = EmbeddedConnection
+	cfg.WorkDir = dir
+}
+
 func (cfg *Config) SetTimeout(timeout uint) {
 	cfg.Timeout = timeout
 }
@@ -204,3 +240,19 @@ func (cfg *Config) SetTlsClientKey(tlsClientKey string) {
 func (cfg *Config) SetTlsInsecureSkipVerify(tlsInsecureSkipVerify bool) {
 	cfg.TlsInsecureSkipVerify = tlsInsecureSkipVerify
 }
+
+func (cfg *Config) SetRetries(retries uint) {
+	cfg.Retries = retries
+}
+
+func (cfg *Config) SetCircuitBreakerThreshold(threshold uint) {
+	cfg.CircuitBreakerThreshold = threshold
+}
+
+func (cfg *Config) SetCircuitBreakerCooldown(cooldown uint) {
+	cfg.CircuitBreakerCooldown = cooldown
+}
+
+func (cfg *Config) SetHealthCheckInterval(interval uint) {
+	cfg.HealthCheckInterval = interval
+}
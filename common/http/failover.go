@@ -0,0 +1,327 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/private/engine"
+)
+
+// failoverBaseURL is the base URL of the requests sent through a failoverTransport,
+// which replaces it with the URL of the endpoint it picks
+const failoverBaseURL = "http://ptm"
+
+var (
+	ptmRequestTimer       = metrics.NewRegisteredTimer("ptm/request/latency", nil)
+	ptmFailureMeter       = metrics.NewRegisteredMeter("ptm/request/failures", nil)
+	ptmRetryMeter         = metrics.NewRegisteredMeter("ptm/request/retries", nil)
+	ptmUnavailableMeter   = metrics.NewRegisteredMeter("ptm/request/unavailable", nil)
+	ptmCircuitOpenMeter   = metrics.NewRegisteredMeter("ptm/circuit/open", nil)
+	ptmAvailableEndpoints = metrics.NewRegisteredGauge("ptm/endpoints/available", nil)
+)
+
+// failoverTransport sends requests to the first available endpoint of the private
+// transaction manager, retrying failed ones on the next endpoint. Each endpoint has
+// a circuit breaker: after threshold consecutive failures it's skipped for the
+// cooldown, then a single request or health check decides whether it's back.
+type failoverTransport struct {
+	endpoints []*endpoint
+	transport http.RoundTripper
+	timeout   time.Duration // of each attempt, zero for none
+	retries   int
+	threshold int // zero disables the circuit breakers
+	cooldown  time.Duration
+
+	quit      chan struct{}
+	closeOnce sync.Once
+}
+
+type endpoint struct {
+	name string   // for health reports, without credentials
+	base *url.URL // replaces failoverBaseURL
+
+	lock        sync.Mutex
+	failures    int
+	openUntil   time.Time // circuit is open until then, zero when closed
+	trial       bool      // a request is checking whether the endpoint is back
+	lastError   string
+	lastChecked time.Time
+}
+
+func newFailoverTransport(cfg Config, endpoints []*endpoint, transport http.RoundTripper) *failoverTransport {
+	t := &failoverTransport{
+		endpoints: endpoints,
+		transport: transport,
+		timeout:   time.Duration(cfg.Timeout) * time.Second,
+		retries:   int(cfg.Retries),
+		threshold: int(cfg.CircuitBreakerThreshold),
+		cooldown:  time.Duration(cfg.CircuitBreakerCooldown) * time.Second,
+		quit:      make(chan struct{}),
+	}
+	ptmAvailableEndpoints.Update(int64(len(endpoints)))
+	if cfg.HealthCheckInterval > 0 {
+		go t.healthCheckLoop(time.Duration(cfg.HealthCheckInterval) * time.Second)
+	}
+	return t
+}
+
+// RoundTrip implements http.RoundTripper. Only reads are retried, writes such as
+// send and storeraw are attempted once so they're never duplicated. Once no
+// endpoint can serve the request the error wraps
+// engine.ErrPrivateTxManagerUnavailable.
+func (t *failoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	defer ptmRequestTimer.UpdateSince(time.Now())
+
+	var (
+		lastErr error
+		tried   = make(map[*endpoint]bool)
+	)
+	for attempt := 0; attempt <= t.retries; attempt++ {
+		if attempt > 0 && req.Body != nil && req.GetBody == nil {
+			break // can't send the body again
+		}
+		ep := t.pick(tried)
+		if ep == nil {
+			break
+		}
+		tried[ep] = true
+		if attempt > 0 {
+			ptmRetryMeter.Mark(1)
+			log.Debug("Retrying private transaction manager request", "endpoint", ep.name, "path", req.URL.Path, "attempt", attempt, "err", lastErr)
+		}
+		resp, err := t.send(ep, req, attempt)
+		if err == nil && !isUnavailableStatus(resp.StatusCode) {
+			ep.success()
+			return resp, nil
+		}
+		if err == nil {
+			err = fmt.Errorf("%s returned %s", ep.name, resp.Status)
+		}
+		ptmFailureMeter.Mark(1)
+		t.failure(ep, err)
+		lastErr = err
+
+		if !isIdempotent(req) {
+			if resp != nil {
+				// the endpoint answered, let the caller handle the status
+				return resp, nil
+			}
+			break
+		}
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+	}
+	if lastErr == nil {
+		lastErr = errors.New("all endpoints are failing")
+	}
+	ptmUnavailableMeter.Mark(1)
+	return nil, fmt.Errorf("%w: %v", engine.ErrPrivateTxManagerUnavailable, lastErr)
+}
+
+// pick returns the first available endpoint that wasn't tried yet, or else the
+// first available one
+func (t *failoverTransport) pick(tried map[*endpoint]bool) *endpoint {
+	now := time.Now()
+	for _, ep := range t.endpoints {
+		if !tried[ep] && ep.allow(now) {
+			return ep
+		}
+	}
+	for _, ep := range t.endpoints {
+		if ep.allow(now) {
+			return ep
+		}
+	}
+	return nil
+}
+
+// send sends the request to the endpoint, giving the attempt its own timeout
+func (t *failoverTransport) send(ep *endpoint, req *http.Request, attempt int) (*http.Response, error) {
+	ctx, cancel := req.Context(), context.CancelFunc(func() {})
+	if t.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, t.timeout)
+	}
+	r := req.Clone(ctx)
+	r.Host = ""
+	r.URL = ep.resolve(req.URL)
+	if attempt > 0 && req.Body != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		r.Body = body
+	}
+	resp, err := t.transport.RoundTrip(r)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+func (t *failoverTransport) failure(ep *endpoint, err error) {
+	if ep.failure(err, t.threshold, t.cooldown) {
+		ptmCircuitOpenMeter.Mark(1)
+		log.Warn("Private transaction manager endpoint unavailable", "endpoint", ep.name, "retry in", t.cooldown, "err", err)
+	}
+}
+
+// healthCheckLoop checks the endpoints periodically, closing the circuits of the
+// ones that are back
+func (t *failoverTransport) healthCheckLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			available := 0
+			for _, ep := range t.endpoints {
+				if t.check(ep) {
+					available++
+				}
+			}
+			ptmAvailableEndpoints.Update(int64(available))
+		case <-t.quit:
+			return
+		}
+	}
+}
+
+func (t *failoverTransport) check(ep *endpoint) bool {
+	req, err := http.NewRequest("GET", failoverBaseURL+"/upcheck", nil)
+	if err != nil {
+		return false
+	}
+	resp, err := t.send(ep, req, 0)
+	if err == nil {
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			err = fmt.Errorf("upcheck returned %s", resp.Status)
+		}
+	}
+	ep.lock.Lock()
+	ep.lastChecked = time.Now()
+	ep.lock.Unlock()
+
+	if err != nil {
+		t.failure(ep, err)
+		return false
+	}
+	if ep.success() {
+		log.Info("Private transaction manager endpoint available again", "endpoint", ep.name)
+	}
+	return true
+}
+
+// Health implements engine.EndpointMonitor
+func (t *failoverTransport) Health() []engine.EndpointHealth {
+	health := make([]engine.EndpointHealth, len(t.endpoints))
+	for i, ep := range t.endpoints {
+		ep.lock.Lock()
+		health[i] = engine.EndpointHealth{
+			URL:                 ep.name,
+			Available:           ep.openUntil.IsZero(),
+			ConsecutiveFailures: ep.failures,
+			LastError:           ep.lastError,
+			LastChecked:         ep.lastChecked,
+		}
+		ep.lock.Unlock()
+	}
+	return health
+}
+
+// Close implements engine.EndpointMonitor
+func (t *failoverTransport) Close() {
+	t.closeOnce.Do(func() { close(t.quit) })
+}
+
+// allow reports whether a request may be sent to the endpoint. Once the cooldown
+// of an open circuit passed, one request at a time checks whether it's back.
+func (ep *endpoint) allow(now time.Time) bool {
+	ep.lock.Lock()
+	defer ep.lock.Unlock()
+
+	if ep.openUntil.IsZero() {
+		return true
+	}
+	if now.Before(ep.openUntil) || ep.trial {
+		return false
+	}
+	ep.trial = true
+	return true
+}
+
+// success closes the circuit, reporting whether it was open
+func (ep *endpoint) success() bool {
+	ep.lock.Lock()
+	defer ep.lock.Unlock()
+
+	wasOpen := !ep.openUntil.IsZero()
+	ep.failures, ep.openUntil, ep.trial, ep.lastError = 0, time.Time{}, false, ""
+	return wasOpen
+}
+
+// failure records a failure, reporting whether it opened the circuit
+func (ep *endpoint) failure(err error, threshold int, cooldown time.Duration) bool {
+	ep.lock.Lock()
+	defer ep.lock.Unlock()
+
+	ep.failures++
+	ep.lastError = err.Error()
+	if threshold == 0 || (ep.failures < threshold && !ep.trial) {
+		return false
+	}
+	wasClosed := ep.openUntil.IsZero()
+	ep.openUntil, ep.trial = time.Now().Add(cooldown), false
+	return wasClosed
+}
+
+// resolve replaces failoverBaseURL in u by the URL of the endpoint
+func (ep *endpoint) resolve(u *url.URL) *url.URL {
+	resolved := *ep.base
+	basePath := strings.TrimSuffix(ep.base.Path, "/")
+	resolved.Path = basePath + u.Path
+	if u.RawPath != "" {
+		resolved.RawPath = strings.TrimSuffix(ep.base.EscapedPath(), "/") + u.RawPath
+	}
+	resolved.RawQuery = u.RawQuery
+	return &resolved
+}
+
+// cancelOnClose cancels the context of an attempt once its response is read
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// isUnavailableStatus reports whether the status means the endpoint couldn't serve
+// the request, rather than an answer from the private transaction manager
+func isUnavailableStatus(status int) bool {
+	return status == http.StatusBadGateway || status == http.StatusServiceUnavailable || status == http.StatusGatewayTimeout
+}
+
+func isIdempotent(req *http.Request) bool {
+	return req.Method == "" || req.Method == http.MethodGet || req.Method == http.MethodHead
+}
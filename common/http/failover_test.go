@@ -0,0 +1,145 @@
+package http
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/private/engine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestFailoverClient(t *testing.T, threshold uint, urls ...string) *engine.Client {
+	cfg := DefaultConfig
+	cfg.ConnectionType = HttpConnection
+	cfg.HttpUrl = strings.Join(urls, ",")
+	cfg.Retries = 2
+	cfg.CircuitBreakerThreshold = threshold
+	cfg.CircuitBreakerCooldown = 1
+	cfg.HealthCheckInterval = 0
+
+	client, err := CreateClient(cfg)
+	require.NoError(t, err)
+	t.Cleanup(client.Monitor.Close)
+	return client
+}
+
+// a URL nothing listens on
+func closedServerURL() string {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	return server.URL
+}
+
+func TestFailover_NextEndpoint(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := newTestFailoverClient(t, 5, closedServerURL(), server.URL)
+
+	res, err := client.Get("/transaction/a%2Fb")
+	require.NoError(t, err)
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	assert.Equal(t, "ok", string(body))
+	assert.Equal(t, "/transaction/a%2Fb", path)
+
+	health := client.Monitor.Health()
+	assert.Equal(t, 1, health[0].ConsecutiveFailures)
+	assert.Equal(t, 0, health[1].ConsecutiveFailures)
+}
+
+func TestFailover_OffByDefault(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig
+	cfg.SetHttpUrl(closedServerURL() + "," + server.URL)
+	client, err := CreateClient(cfg)
+	require.NoError(t, err)
+	defer client.Monitor.Close()
+
+	for i := 0; i < 10; i++ {
+		_, err = client.Get("/upcheck")
+		assert.True(t, errors.Is(err, engine.ErrPrivateTxManagerUnavailable), "got %v", err)
+	}
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+	assert.True(t, client.Monitor.Health()[0].Available)
+}
+
+func TestFailover_PostNeverRetried(t *testing.T) {
+	var calls int32
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unavailable.Close()
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer ok.Close()
+
+	client := newTestFailoverClient(t, 5, unavailable.URL, ok.URL)
+
+	res, err := client.HttpClient.Post(client.FullPath("/send"), "application/json", strings.NewReader("{}"))
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// not even when it didn't reach the private transaction manager
+	client = newTestFailoverClient(t, 5, closedServerURL(), ok.URL)
+	for _, path := range []string{"/send", "/storeraw"} {
+		_, err = client.HttpClient.Post(client.FullPath(path), "application/json", strings.NewReader("{}"))
+		assert.True(t, errors.Is(err, engine.ErrPrivateTxManagerUnavailable), "got %v", err)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// reads are retried
+	res, err = client.Get("/upcheck")
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestFailover_CircuitBreaker(t *testing.T) {
+	var down int32 = 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&down) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	client := newTestFailoverClient(t, 3, server.URL)
+
+	// the retries of the first request open the circuit
+	_, err := client.Get("/upcheck")
+	assert.True(t, errors.Is(err, engine.ErrPrivateTxManagerUnavailable), "got %v", err)
+	assert.False(t, client.Monitor.Health()[0].Available)
+
+	// the endpoint isn't called while the circuit is open
+	atomic.StoreInt32(&down, 0)
+	_, err = client.Get("/upcheck")
+	assert.True(t, errors.Is(err, engine.ErrPrivateTxManagerUnavailable), "got %v", err)
+
+	// after the cooldown a request closes it again
+	time.Sleep(1100 * time.Millisecond)
+	res, err := client.Get("/upcheck")
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.True(t, client.Monitor.Health()[0].Available)
+}
@@ -19,10 +19,13 @@ package consensus
 
 import (
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/e2c"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -174,4 +177,46 @@ type E2C interface {
 	ShouldMine() bool
 
 	ClientVerify(*types.Block, common.Address, ChainHeaderReader) bool
+
+	// TxTarget returns the leader transactions should be forwarded to. It returns
+	// false if this node is the leader or the leader isn't known
+	TxTarget() (common.Address, bool)
+
+	// MarkForwarded records that the transactions were handed to the given leader
+	MarkForwarded(hashes []common.Hash, leader common.Address)
+
+	// ReceiveTransactions acks transactions a peer forwarded to the leader and
+	// returns the ones that haven't been seen before
+	ReceiveTransactions(from common.Address, txs []*types.Transaction) []*types.Transaction
+
+	// SubscribeLeaderChange notifies with the new leader whenever it changes
+	SubscribeLeaderChange(ch chan<- common.Address) event.Subscription
+
+	// Checkpoint returns the latest checkpoint signed by f+1 validators, or nil
+	Checkpoint() *e2c.Checkpoint
+
+	// ValidatorsAt returns the validator set the header was sealed under
+	ValidatorsAt(chain ChainHeaderReader, header *types.Header) (e2c.Validators, error)
+
+	// AddCheckpoint verifies a checkpoint handed over by a peer and keeps it if
+	// it's newer than ours
+	AddCheckpoint(cp *e2c.Checkpoint) error
+
+	// RequestCheckpoint asks the peer for the latest checkpoint it knows about
+	RequestCheckpoint(p Peer) error
+
+	// Stats returns the view, leader and consensus progress of the node
+	Stats() *e2c.Stats
+
+	// PeerScores returns the invalid consensus traffic accounted to every peer
+	PeerScores() map[common.Address]*e2c.PeerScore
+
+	// Bans returns the peers that are currently banned
+	Bans() []*e2c.Ban
+
+	// Ban bans the peer for the given duration, Unban lifts it. IsBanned tells
+	// whether a peer should be refused
+	Ban(addr common.Address, d time.Duration, reason string)
+	Unban(addr common.Address) bool
+	IsBanned(addr common.Address) bool
 }
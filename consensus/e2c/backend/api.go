@@ -24,6 +24,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/e2c"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rpc"
 )
@@ -232,3 +233,35 @@ func (api *API) IsValidator(blockNum *rpc.BlockNumber) (bool, error) {
 	}
 	return false, nil
 }
+
+// TxStatus reports whether a transaction submitted to this node reached the leader
+type TxStatus struct {
+	Hash      common.Hash    `json:"hash"`
+	Forwarded *ForwardStatus `json:"forwarded,omitempty"` // set if we forwarded it to the leader
+	Received  *ReceiveStatus `json:"received,omitempty"`  // set if it was forwarded to us while leading
+}
+
+// TxForwardStatus returns the forwarding status of the transaction with the given hash
+func (api *API) TxForwardStatus(hash common.Hash) (*TxStatus, error) {
+	forwarded, received := api.e2c.forwardStatus(hash)
+	if forwarded == nil && received == nil {
+		return nil, errUnknownTransaction
+	}
+	return &TxStatus{
+		Hash:      hash,
+		Forwarded: forwarded,
+		Received:  received,
+	}, nil
+}
+
+// Stats returns the current view, leader and status phase along with the blames in
+// this view, the duration of the last view change and the committed and locked heights
+func (api *API) Stats() *e2c.Stats {
+	return api.e2c.Stats()
+}
+
+// Synchrony returns the delays measured to the other validators and whether they
+// stay within Δ. Violated is set once any of them exceeded it recently
+func (api *API) Synchrony() *Synchrony {
+	return api.e2c.synchrony()
+}
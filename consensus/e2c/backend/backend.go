@@ -44,8 +44,11 @@ const (
 func New(config *e2c.Config, privateKey *ecdsa.PrivateKey, db ethdb.Database) consensus.E2C {
 	// Allocate the snapshot caches and create the engine
 	recents, _ := lru.NewARC(inmemorySnapshots)
+	validatorSets, _ := lru.NewARC(inmemorySnapshots)
 	recentMessages, _ := lru.NewARC(inmemoryPeers)
 	knownMessages, _ := lru.NewARC(inmemoryMessages)
+	forwardedTxs, _ := lru.NewARC(inmemoryForwardedTxs)
+	receivedTxs, _ := lru.NewARC(inmemoryForwardedTxs)
 
 	backend := &backend{
 		config:         config,
@@ -54,14 +57,20 @@ func New(config *e2c.Config, privateKey *ecdsa.PrivateKey, db ethdb.Database) co
 		address:        crypto.PubkeyToAddress(privateKey.PublicKey),
 		db:             db,
 		recents:        recents,
+		validatorSets:  validatorSets,
 		coreStarted:    false,
 		recentMessages: recentMessages,
 		knownMessages:  knownMessages,
+		forwardedTxs:   forwardedTxs,
+		receivedTxs:    receivedTxs,
 		status:         0,
 		view:           0,
 
 		// TODO: blocks should time out after a period
 		clientBlocks: make(map[common.Hash]uint64),
+		delays:       make(map[common.Address]*delayStats),
+		scores:       make(map[common.Address]*peerScore),
+		bans:         make(map[common.Address]*e2c.Ban),
 	}
 	backend.core = e2cCore.New(backend, backend.config)
 	return backend
@@ -88,6 +97,9 @@ type backend struct {
 	// Snapshots for recent block to speed up reorgs
 	recents *lru.ARCCache
 
+	// validator sets of recent blocks, see validatorsAt
+	validatorSets *lru.ARCCache
+
 	// event subscription for ChainHeadEvent event
 	broadcaster consensus.Broadcaster
 
@@ -96,6 +108,27 @@ type backend struct {
 
 	// map for tracking acks of a block
 	clientBlocks map[common.Hash]uint64
+	lastAuthor   common.Address // author of the last block a member node committed
+
+	forwardedTxs *lru.ARCCache // transactions we forwarded to the leader
+	receivedTxs  *lru.ARCCache // transactions forwarded to us while leading
+	leaderFeed   event.Feed    // notifies when the leader changes
+
+	consensusFeed event.Feed // delivers the consensus events to the e2c_subscribe subscribers
+
+	checkpoint   *e2c.Checkpoint // latest checkpoint with f+1 signatures
+	checkpointMu sync.Mutex
+
+	viewCert   *types.E2CViewCertificate // certificate for the first block we propose in the view
+	viewCertMu sync.Mutex
+
+	delays   map[common.Address]*delayStats // delays measured to the other validators
+	delaysMu sync.Mutex
+	pingQuit chan struct{}
+
+	scores   map[common.Address]*peerScore // invalid traffic received from each peer
+	bans     map[common.Address]*e2c.Ban
+	scoresMu sync.Mutex
 }
 
 // miner.Worker will call this to see if it should be creating new blocks
@@ -269,6 +302,23 @@ func (b *backend) ChangeView() {
 	b.SetStatus(e2c.Wait)
 	b.view++
 	log.Info("View change has been triggered", "leader", b.Leader())
+	go b.leaderFeed.Send(b.Leader())
+}
+
+// Stats implements consensus.E2C.Stats
+func (b *backend) Stats() *e2c.Stats {
+	stats := b.core.Stats()
+	stats.View = b.View()
+	stats.Status = e2c.StatusName(b.Status())
+	if len(b.validators) > 0 {
+		stats.Leader = b.Leader()
+	}
+	// members don't run the core, whatever they imported is committed
+	if !b.coreStarted && b.chain != nil {
+		stats.Committed = b.chain.CurrentHeader().Number.Uint64()
+		stats.Locked = stats.Committed
+	}
+	return &stats
 }
 
 // Retrieves the block from the chain for e2c.Core
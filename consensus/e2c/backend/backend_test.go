@@ -0,0 +1,101 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/e2c"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// newTestBackend returns the backend of the first of n validators, with the
+// validator keys in the order of the validator set
+func newTestBackend(t *testing.T, n int) (*backend, []*ecdsa.PrivateKey) {
+	keys := make([]*ecdsa.PrivateKey, n)
+	for i := range keys {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i] = key
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return crypto.PubkeyToAddress(keys[i].PublicKey).Hex() < crypto.PubkeyToAddress(keys[j].PublicKey).Hex()
+	})
+	config := *e2c.DefaultConfig
+	config.ExtraV2Block = big.NewInt(1)
+	b := New(&config, keys[0], rawdb.NewMemoryDatabase()).(*backend)
+	for _, key := range keys {
+		b.validators = append(b.validators, crypto.PubkeyToAddress(key.PublicKey))
+	}
+	return b, keys
+}
+
+// sentMsg is a message a testPeer was asked to send
+type sentMsg struct {
+	to   common.Address
+	code uint64
+	data interface{}
+}
+
+// testPeer records what is sent to it
+type testPeer struct {
+	addr common.Address
+	sent chan sentMsg
+}
+
+func (p *testPeer) Send(code uint64, data interface{}) error {
+	p.sent <- sentMsg{p.addr, code, data}
+	return nil
+}
+
+func (p *testPeer) SendConsensus(code uint64, data interface{}) error {
+	p.sent <- sentMsg{p.addr, code, data}
+	return nil
+}
+
+func (p *testPeer) SendQBFTConsensus(code uint64, payload []byte) error {
+	p.sent <- sentMsg{p.addr, code, payload}
+	return nil
+}
+
+// testBroadcaster connects the backend to a testPeer for every address
+type testBroadcaster struct {
+	sent chan sentMsg
+}
+
+func newTestBroadcaster() *testBroadcaster {
+	return &testBroadcaster{sent: make(chan sentMsg, 64)}
+}
+
+func (tb *testBroadcaster) Enqueue(id string, block *types.Block) {}
+
+func (tb *testBroadcaster) FindPeers(targets map[common.Address]bool) map[common.Address]consensus.Peer {
+	peers := make(map[common.Address]consensus.Peer)
+	for addr := range targets {
+		peers[addr] = &testPeer{addr: addr, sent: tb.sent}
+	}
+	return peers
+}
@@ -0,0 +1,244 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/e2c"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Signed checkpoints are exchanged with e2cCheckpointMsg. The payload is a list of
+// checkpoints, an empty list asks the peer for the latest one it knows about.
+// Checkpoints are checked against the validator set at their height before they are
+// kept, so it doesn't matter which peer sent them
+
+const (
+	dbKeyCheckpoint = "e2c-checkpoint" // latest checkpoint with f+1 signatures
+)
+
+// StoreCheckpoint implements e2c.Backend.StoreCheckpoint
+func (b *backend) StoreCheckpoint(cp *e2c.Checkpoint) {
+	validators, err := b.checkpointValidators(cp)
+	if err != nil {
+		log.Error("Failed to load validators for checkpoint", "number", cp.Number, "err", err)
+		return
+	}
+	signers, err := cp.Signers(validators)
+	if err != nil {
+		return
+	}
+	if !b.setCheckpoint(cp) {
+		return
+	}
+	b.PostEvent(e2c.CheckpointEvent{
+		Number:  cp.Number,
+		Hash:    cp.Hash,
+		Root:    cp.Root,
+		Signers: signers,
+	})
+}
+
+// Checkpoint implements consensus.E2C.Checkpoint
+func (b *backend) Checkpoint() *e2c.Checkpoint {
+	b.checkpointMu.Lock()
+	defer b.checkpointMu.Unlock()
+
+	if b.checkpoint == nil {
+		blob, err := b.db.Get([]byte(dbKeyCheckpoint))
+		if err != nil {
+			return nil
+		}
+		cp := new(e2c.Checkpoint)
+		if err := rlp.DecodeBytes(blob, cp); err != nil {
+			log.Error("Invalid checkpoint in database", "err", err)
+			return nil
+		}
+		b.checkpoint = cp
+	}
+	return b.checkpoint
+}
+
+// RequestCheckpoint implements consensus.E2C.RequestCheckpoint
+func (b *backend) RequestCheckpoint(p consensus.Peer) error {
+	return p.SendConsensus(e2cCheckpointMsg, []*e2c.Checkpoint{})
+}
+
+// setCheckpoint persists the checkpoint if it's newer than the one we have
+func (b *backend) setCheckpoint(cp *e2c.Checkpoint) bool {
+	current := b.Checkpoint()
+
+	b.checkpointMu.Lock()
+	defer b.checkpointMu.Unlock()
+
+	if current != nil && current.Number >= cp.Number {
+		return false
+	}
+	blob, err := rlp.EncodeToBytes(cp)
+	if err != nil {
+		log.Error("Failed to encode checkpoint", "err", err)
+		return false
+	}
+	if err := b.db.Put([]byte(dbKeyCheckpoint), blob); err != nil {
+		log.Error("Failed to store checkpoint", "err", err)
+		return false
+	}
+	b.checkpoint = cp
+	return true
+}
+
+// handles a checkpoint request or response from a peer
+func (b *backend) handleCheckpoint(addr common.Address, msg p2p.Msg) error {
+	var cps []*e2c.Checkpoint
+	if err := msg.Decode(&cps); err != nil {
+		return errDecodeFailed
+	}
+	if len(cps) == 0 {
+		cp := b.Checkpoint()
+		if cp == nil || b.broadcaster == nil {
+			return nil
+		}
+		p, ok := b.broadcaster.FindPeers(map[common.Address]bool{addr: true})[addr]
+		if !ok {
+			return nil
+		}
+		go func() {
+			if err := p.SendConsensus(e2cCheckpointMsg, []*e2c.Checkpoint{cp}); err != nil {
+				log.Debug("Failed to send checkpoint", "to", addr, "err", err)
+			}
+		}()
+		return nil
+	}
+
+	for _, cp := range cps {
+		err := b.AddCheckpoint(cp)
+		if err == errUnknownValidatorSet {
+			log.Debug("Ignoring checkpoint past unknown validator set change", "addr", addr, "number", cp.Number)
+			continue
+		}
+		if err != nil {
+			log.Warn("Peer sent invalid checkpoint", "addr", addr, "number", cp.Number, "err", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// AddCheckpoint implements consensus.E2C.AddCheckpoint
+func (b *backend) AddCheckpoint(cp *e2c.Checkpoint) error {
+	validators, err := b.checkpointValidators(cp)
+	if err != nil {
+		return err
+	}
+	if err := cp.Verify(validators); err != nil {
+		return err
+	}
+	if b.setCheckpoint(cp) {
+		log.Info("Received signed checkpoint", "number", cp.Number, "hash", cp.Hash)
+	}
+	return nil
+}
+
+// checkpointValidators returns the validator set that has to sign a checkpoint. If we
+// have the block, that's the set at its height. Otherwise it's the newest set we know
+// of, from our head or the checkpoint we keep, and the checkpoint has to list it: one
+// past a validator change we haven't seen can't be verified until we synced to it
+func (b *backend) checkpointValidators(cp *e2c.Checkpoint) (e2c.Validators, error) {
+	chain := &dbHeaderChain{db: b.db}
+	if hash := rawdb.ReadCanonicalHash(b.db, cp.Number); hash != (common.Hash{}) {
+		if hash != cp.Hash {
+			return nil, errCheckpointMismatch
+		}
+		return b.validatorsAt(chain, chain.GetHeader(hash, cp.Number), nil)
+	}
+	validators, err := b.genesisValidators()
+	if err != nil {
+		return nil, err
+	}
+	if head := chain.CurrentHeader(); head != nil && head.Number.Uint64() < cp.Number {
+		if validators, err = b.validatorsAt(chain, head, nil); err != nil {
+			return nil, err
+		}
+	}
+	if current := b.Checkpoint(); current != nil && current.Number < cp.Number && len(current.Validators) > 0 {
+		if head := chain.CurrentHeader(); head == nil || head.Number.Uint64() < current.Number {
+			validators = current.Validators
+		}
+	}
+	if types.E2CValidatorsHash(validators) != types.E2CValidatorsHash(cp.Validators) {
+		return nil, errUnknownValidatorSet
+	}
+	return validators, nil
+}
+
+// dbHeaderChain reads canonical headers straight from the database, so checkpoints
+// can be checked before the backend is started with a chain
+type dbHeaderChain struct {
+	db ethdb.Database
+}
+
+func (hc *dbHeaderChain) Config() *params.ChainConfig {
+	return rawdb.ReadChainConfig(hc.db, rawdb.ReadCanonicalHash(hc.db, 0))
+}
+
+func (hc *dbHeaderChain) CurrentHeader() *types.Header {
+	hash := rawdb.ReadHeadHeaderHash(hc.db)
+	if number := rawdb.ReadHeaderNumber(hc.db, hash); number != nil {
+		return rawdb.ReadHeader(hc.db, hash, *number)
+	}
+	return nil
+}
+
+func (hc *dbHeaderChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	return rawdb.ReadHeader(hc.db, hash, number)
+}
+
+func (hc *dbHeaderChain) GetHeaderByNumber(number uint64) *types.Header {
+	return rawdb.ReadHeader(hc.db, rawdb.ReadCanonicalHash(hc.db, number), number)
+}
+
+func (hc *dbHeaderChain) GetHeaderByHash(hash common.Hash) *types.Header {
+	if number := rawdb.ReadHeaderNumber(hc.db, hash); number != nil {
+		return rawdb.ReadHeader(hc.db, hash, *number)
+	}
+	return nil
+}
+
+// genesisValidators returns the validator set from the genesis block. It's the one
+// set every node agrees on without trusting anybody
+func (b *backend) genesisValidators() (e2c.Validators, error) {
+	hash := rawdb.ReadCanonicalHash(b.db, 0)
+	genesis := rawdb.ReadHeader(b.db, hash, 0)
+	if genesis == nil {
+		return nil, errUnknownBlock
+	}
+	e2cExtra, err := types.ExtractE2CExtra(genesis)
+	if err != nil {
+		return nil, errInvalidExtraDataFormat
+	}
+	if len(e2cExtra.Validators) == 0 {
+		return nil, errEmptyValidatorSet
+	}
+	return e2cExtra.Validators, nil
+}
@@ -12,16 +12,14 @@ func (b *backend) ClientVerify(block *types.Block, addr common.Address, chain co
 	b.clientMu.Lock()
 	defer b.clientMu.Unlock()
 
-	if b.Validators() == nil {
-		header := chain.CurrentHeader()
-		e2cExtra, err := types.ExtractE2CExtra(header)
-		if err != nil {
-			return false
-		}
-		b.validators = e2cExtra.Validators
+	// acks count from the validators the block was sealed under
+	validators, err := b.validatorsAt(chain, block.Header(), nil)
+	if err != nil {
+		return false
 	}
+	b.validators = validators
 
-	if i, _ := b.Validators().GetByAddress(addr); i == -1 {
+	if i, _ := validators.GetByAddress(addr); i == -1 {
 		return false
 	}
 
@@ -32,7 +30,7 @@ func (b *backend) ClientVerify(block *types.Block, addr common.Address, chain co
 	}
 
 	log.Info("Block acknowledgement received", "number", block.Number(), "hash", block.Hash(), "acks", b.clientBlocks[block.Hash()])
-	if b.clientBlocks[block.Hash()] == b.F()+1 {
+	if b.clientBlocks[block.Hash()] == validators.F()+1 {
 		// Delete the parent block. If we delete the one we just committed
 		// then we will probably see it again since we commit at F+1 acks
 		// This means it doesn't actually get delete and we will run into
@@ -41,6 +39,12 @@ func (b *backend) ClientVerify(block *types.Block, addr common.Address, chain co
 		// after a set interval
 		log.Info("Successfully committed block", "number", block.Number().Uint64(), "txs", len(block.Transactions()), "hash", block.Hash())
 		delete(b.clientBlocks, block.ParentHash())
+
+		// members don't follow views, the author of the latest block is our best guess at the leader
+		if author, err := ecrecover(block.Header()); err == nil && author != b.lastAuthor {
+			b.lastAuthor = author
+			go b.leaderFeed.Send(author)
+		}
 		return true
 	}
 
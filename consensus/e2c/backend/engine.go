@@ -75,11 +75,24 @@ func (b *backend) VerifyHeader(chain consensus.ChainHeaderReader, header *types.
 // a batch of new headers.
 func (b *backend) verifyHeader(chain consensus.ChainHeaderReader, header *types.Header, parents []*types.Header) error {
 
+	// light clients never run the core or see the acks, so the header is all they can check
+	if b.config.LightClient {
+		return b.verifyLightHeader(chain, header, parents)
+	}
+
 	// if node is a client node, we don't have to verify
 	if !b.coreStarted {
 		return nil
 	}
 
+	if err := b.verifyHeaderFields(header); err != nil {
+		return err
+	}
+	return b.verifyCascadingFields(chain, header, parents)
+}
+
+// verifyHeaderFields checks the fields of a header that don't depend on any other header
+func (b *backend) verifyHeaderFields(header *types.Header) error {
 	if header.Number == nil {
 		return errUnknownBlock
 	}
@@ -93,6 +106,9 @@ func (b *backend) verifyHeader(chain consensus.ChainHeaderReader, header *types.
 	if _, err := types.ExtractE2CExtra(header); err != nil {
 		return errInvalidExtraDataFormat
 	}
+	if err := b.verifyExtraVersion(header); err != nil {
+		return err
+	}
 
 	// Ensure that the coinbase is valid
 	if header.Nonce != (emptyNonce) && !bytes.Equal(header.Nonce[:], nonceAuthVote) && !bytes.Equal(header.Nonce[:], nonceDropVote) {
@@ -110,8 +126,7 @@ func (b *backend) verifyHeader(chain consensus.ChainHeaderReader, header *types.
 	if header.Difficulty == nil || header.Difficulty.Cmp(defaultDifficulty) != 0 {
 		return errInvalidDifficulty
 	}
-
-	return b.verifyCascadingFields(chain, header, parents)
+	return nil
 }
 
 // verifyCascadingFields verifies all the header fields that are not standalone,
@@ -148,6 +163,10 @@ func (b *backend) verifyCascadingFields(chain consensus.ChainHeaderReader, heade
 		return errInvalidTimestamp
 	}
 
+	if err := verifyViewChange(header, parent, b.validators); err != nil {
+		return err
+	}
+
 	if err := b.verifySigner(chain, header, parents); err != nil {
 		return err
 	}
@@ -192,11 +211,26 @@ func (b *backend) VerifyUncles(chain consensus.ChainReader, block *types.Block)
 	if len(block.Uncles()) > 0 {
 		return errInvalidUncleHash
 	}
+	// the header was verified without the block its view certificate voted for
+	if b.coreStarted {
+		return verifyViewVotes(chain, block.Header(), b.validators)
+	}
 	return nil
 }
 
 // verifySigner checks whether the signer is the leader
 func (b *backend) verifySigner(chain consensus.ChainHeaderReader, header *types.Header, parents []*types.Header) error {
+	// Verifying the genesis block is not supported
+	number := header.Number.Uint64()
+	if number == 0 {
+		return errUnknownBlock
+	}
+
+	// newer headers say which view they were sealed in, so we don't need our own view
+	if ok, err := verifyViewLeader(header, b.validators); ok {
+		return err
+	}
+
 	// this is here because block signer was of previous view,
 	// block has already been verified so we can skip this step
 	// If fact, not skipping this step will break system since the leader
@@ -205,12 +239,6 @@ func (b *backend) verifySigner(chain consensus.ChainHeaderReader, header *types.
 		return nil
 	}
 
-	// Verifying the genesis block is not supported
-	number := header.Number.Uint64()
-	if number == 0 {
-		return errUnknownBlock
-	}
-
 	// resolve the authorization key and check against signers
 	signer, err := ecrecover(header)
 	if err != nil {
@@ -237,6 +265,9 @@ func (b *backend) VerifySeal(chain consensus.ChainHeaderReader, header *types.He
 	if header.Difficulty.Cmp(defaultDifficulty) != 0 {
 		return errInvalidDifficulty
 	}
+	if b.config.LightClient {
+		return b.verifyLightSeal(chain, header)
+	}
 	return b.verifySigner(chain, header, nil)
 }
 
@@ -257,12 +288,21 @@ func (b *backend) Prepare(chain consensus.ChainHeaderReader, header *types.Heade
 	// use the same difficulty for all blocks
 	header.Difficulty = defaultDifficulty
 
-	// add address to the extra field
-	extra, err := prepareExtra(header, []common.Address{b.address})
+	// add address to the extra field, or the whole validator set if it changed
+	vals, err := b.extraValidators(chain, parent)
+	if err != nil {
+		return err
+	}
+	extra, err := prepareExtra(header, vals)
 	if err != nil {
 		return err
 	}
 	header.Extra = extra
+	if b.isExtraV2(header.Number) {
+		if err := writeView(header, b.View(), types.E2CValidatorsHash(b.validators), nil); err != nil {
+			return err
+		}
+	}
 
 	// set header's timestamp
 	header.Time = parent.Time
@@ -344,6 +384,14 @@ func (b *backend) Seal(chain consensus.ChainHeaderReader, block *types.Block, re
 // update timestamp and signature of the block based on its number of transactions
 func (b *backend) updateBlock(parent *types.Header, block *types.Block) (*types.Block, error) {
 	header := block.Header()
+
+	// the view may have changed since the block was prepared
+	if b.isExtraV2(header.Number) {
+		if err := writeView(header, b.View(), types.E2CValidatorsHash(b.validators), b.viewCertificate()); err != nil {
+			return nil, err
+		}
+	}
+
 	// sign the hash
 	seal, err := b.Sign(sigHash(header).Bytes())
 	if err != nil {
@@ -382,18 +430,23 @@ func (b *backend) Start(chain consensus.Chain) error {
 		return err
 	}
 
-	// get the validators from last valid block
-	e2cExtra, err := types.ExtractE2CExtra(header)
+	// get the validators of the last valid block
+	validators, err := b.validatorsAt(chain, header, nil)
 	if err != nil {
 		return err
 	}
-	b.validators = e2cExtra.Validators
+	b.validators = validators
 
 	// Start the core
 	if err := b.core.Start(chain.GetBlock(header.Hash(), header.Number.Uint64())); err != nil {
 		return err
 	}
 
+	if b.config.PingInterval > 0 {
+		b.pingQuit = make(chan struct{})
+		go b.pingLoop(b.pingQuit)
+	}
+
 	b.coreStarted = true
 	return nil
 }
@@ -408,6 +461,10 @@ func (b *backend) Stop() error {
 	if err := b.core.Stop(); err != nil {
 		return err
 	}
+	if b.pingQuit != nil {
+		close(b.pingQuit)
+		b.pingQuit = nil
+	}
 	b.coreStarted = false
 	return nil
 }
@@ -15,6 +15,9 @@ var (
 	// errUnknownBlock is returned when the list of validators is requested for a block
 	// that is not part of the local blockchain.
 	errUnknownBlock = errors.New("unknown block")
+	// errUnknownTransaction is returned when the forwarding status of a transaction
+	// this node never forwarded or received is requested.
+	errUnknownTransaction = errors.New("unknown transaction")
 	// errUnauthorized is returned if a header is signed by a non authorized entity.
 	errUnauthorized = errors.New("unauthorized")
 	// errInvalidDifficulty is returned if the difficulty of a block is not 1
@@ -29,6 +32,31 @@ var (
 	errInvalidUncleHash = errors.New("non empty uncle hash")
 	// errInvalidTimestamp is returned if the timestamp of a block is lower than the previous block's timestamp + the minimum block period.
 	errInvalidTimestamp = errors.New("invalid timestamp")
+	// errEmptyValidatorSet is returned if the genesis block doesn't list any validators
+	errEmptyValidatorSet = errors.New("empty validator set")
+	// errInvalidValidatorsHash is returned if a header records a different validator set than ours
+	errInvalidValidatorsHash = errors.New("invalid validators hash")
+	// errInvalidView is returned if a header's view is lower than its parent's
+	errInvalidView = errors.New("invalid view")
+	// errInvalidViewCertificate is returned if the first block of a view doesn't carry a
+	// valid certificate for the view change, or a later block carries one
+	errInvalidViewCertificate = errors.New("invalid view certificate")
+	// errCheckpointMismatch is returned if a header is at the height of the signed
+	// checkpoint but isn't the block the validators signed
+	errCheckpointMismatch = errors.New("header conflicts with signed checkpoint")
+	// errUnknownValidatorSet is returned if a checkpoint is past our chain and lists a
+	// validator set we don't know yet
+	errUnknownValidatorSet = errors.New("unknown checkpoint validator set")
+	// errInvalidExtraVersion is returned if a header records the view before the
+	// ExtraV2Block fork, or doesn't from the fork on
+	errInvalidExtraVersion = errors.New("invalid extra-data version")
+	// errUnknownCertifiedBlock is returned if the block a view certificate votes for
+	// isn't in the chain
+	errUnknownCertifiedBlock = errors.New("unknown certified block")
+	// errPeerBanned is returned if a banned peer sends us anything, so it gets disconnected
+	errPeerBanned = errors.New("peer banned")
+	// errRateLimited is the penalty reason for peers sending E2C messages too fast
+	errRateLimited = errors.New("rate limit exceeded")
 	// errInvalidVotingChain is returned if an authorization list is attempted to
 	// be modified via out-of-range or non-contiguous headers.
 	errInvalidVotingChain = errors.New("invalid voting chain")
@@ -0,0 +1,170 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p"
+)
+
+// Only the leader builds blocks, so rather than gossiping transactions through every
+// pool, non-leaders hand them straight to the leader. The leader acks every forwarded
+// transaction back to the sender so clients can find out whether it reached the proposer
+
+const (
+	inmemoryForwardedTxs = 4096 // Number of forwarded transactions we keep the status of
+)
+
+// ForwardStatus tracks a transaction this node handed to the leader
+type ForwardStatus struct {
+	Leader      common.Address `json:"leader"`
+	ForwardedAt time.Time      `json:"forwardedAt"`
+	Acked       bool           `json:"acked"`
+	AckedAt     *time.Time     `json:"ackedAt,omitempty"`
+}
+
+// ReceiveStatus tracks a transaction the leader received from another node
+type ReceiveStatus struct {
+	From       common.Address `json:"from"`
+	ReceivedAt time.Time      `json:"receivedAt"`
+}
+
+// TxTarget implements consensus.E2C.TxTarget. Validators follow the view, member
+// nodes assume the author of the last block they committed is still the leader
+func (b *backend) TxTarget() (common.Address, bool) {
+	var leader common.Address
+	if b.coreStarted {
+		if len(b.validators) == 0 {
+			return common.Address{}, false
+		}
+		leader = b.Leader()
+	} else {
+		b.clientMu.RLock()
+		leader = b.lastAuthor
+		b.clientMu.RUnlock()
+	}
+	if leader == (common.Address{}) || leader == b.address {
+		return common.Address{}, false
+	}
+	return leader, true
+}
+
+// MarkForwarded implements consensus.E2C.MarkForwarded
+func (b *backend) MarkForwarded(hashes []common.Hash, leader common.Address) {
+	now := time.Now()
+	for _, hash := range hashes {
+		b.forwardedTxs.Add(hash, &ForwardStatus{
+			Leader:      leader,
+			ForwardedAt: now,
+		})
+	}
+}
+
+// ReceiveTransactions implements consensus.E2C.ReceiveTransactions. The leader acks
+// everything it gets from a peer and drops transactions it has already been given,
+// the rest is returned for the pool. Nodes that aren't leading pass everything through
+func (b *backend) ReceiveTransactions(from common.Address, txs []*types.Transaction) []*types.Transaction {
+	if !b.coreStarted || len(b.validators) == 0 || b.Leader() != b.address {
+		return txs
+	}
+
+	var (
+		fresh  = make([]*types.Transaction, 0, len(txs))
+		hashes = make([]common.Hash, 0, len(txs))
+		now    = time.Now()
+	)
+	for _, tx := range txs {
+		hashes = append(hashes, tx.Hash())
+		if b.receivedTxs.Contains(tx.Hash()) {
+			continue
+		}
+		b.receivedTxs.Add(tx.Hash(), &ReceiveStatus{From: from, ReceivedAt: now})
+		fresh = append(fresh, tx)
+	}
+	if len(hashes) > 0 {
+		b.ackTransactions(from, hashes)
+	}
+	return fresh
+}
+
+// SubscribeLeaderChange implements consensus.E2C.SubscribeLeaderChange
+func (b *backend) SubscribeLeaderChange(ch chan<- common.Address) event.Subscription {
+	return b.leaderFeed.Subscribe(ch)
+}
+
+// sends the ack for the given transactions back to the node that forwarded them
+func (b *backend) ackTransactions(to common.Address, hashes []common.Hash) {
+	if b.broadcaster == nil {
+		return
+	}
+	p, ok := b.broadcaster.FindPeers(map[common.Address]bool{to: true})[to]
+	if !ok {
+		return
+	}
+	go func() {
+		if err := p.SendConsensus(e2cTxAckMsg, hashes); err != nil {
+			log.Debug("Failed to ack forwarded transactions", "to", to, "err", err)
+		}
+	}()
+}
+
+// handles an ack from the leader for transactions we forwarded to it
+func (b *backend) handleTxAck(addr common.Address, msg p2p.Msg) error {
+	var hashes []common.Hash
+	if err := msg.Decode(&hashes); err != nil {
+		return errDecodeFailed
+	}
+	now := time.Now()
+	for _, hash := range hashes {
+		s, ok := b.forwardedTxs.Get(hash)
+		if !ok {
+			continue
+		}
+		// only the node we forwarded to can ack the transaction
+		status := s.(*ForwardStatus)
+		if status.Leader != addr || status.Acked {
+			continue
+		}
+		b.forwardedTxs.Add(hash, &ForwardStatus{
+			Leader:      status.Leader,
+			ForwardedAt: status.ForwardedAt,
+			Acked:       true,
+			AckedAt:     &now,
+		})
+	}
+	return nil
+}
+
+// forwardStatus returns what we know about a transaction we forwarded or received as leader
+func (b *backend) forwardStatus(hash common.Hash) (*ForwardStatus, *ReceiveStatus) {
+	var (
+		forwarded *ForwardStatus
+		received  *ReceiveStatus
+	)
+	if s, ok := b.forwardedTxs.Get(hash); ok {
+		forwarded = s.(*ForwardStatus)
+	}
+	if s, ok := b.receivedTxs.Get(hash); ok {
+		received = s.(*ReceiveStatus)
+	}
+	return forwarded, received
+}
@@ -0,0 +1,148 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func TestTxTarget(t *testing.T) {
+	b, keys := newTestBackend(t, 4)
+	other := crypto.PubkeyToAddress(keys[1].PublicKey)
+
+	// member nodes follow the author of the last block they committed
+	if _, ok := b.TxTarget(); ok {
+		t.Fatal("member node without a committed block has a target")
+	}
+	b.lastAuthor = other
+	if target, ok := b.TxTarget(); !ok || target != other {
+		t.Fatalf("got target %x %v, want %x", target, ok, other)
+	}
+
+	// validators follow the view, and keep what they get while leading
+	b.coreStarted = true
+	if _, ok := b.TxTarget(); ok {
+		t.Fatal("leader forwards to itself")
+	}
+	b.view = 1
+	if target, ok := b.TxTarget(); !ok || target != other {
+		t.Fatalf("got target %x %v, want the leader of view 1 %x", target, ok, other)
+	}
+}
+
+func TestReceiveTransactionsAcks(t *testing.T) {
+	b, keys := newTestBackend(t, 4)
+	broadcaster := newTestBroadcaster()
+	b.SetBroadcaster(broadcaster)
+	from := crypto.PubkeyToAddress(keys[1].PublicKey)
+	txs := []*types.Transaction{
+		types.NewTransaction(0, common.Address{1}, big.NewInt(1), 21000, big.NewInt(1), nil),
+		types.NewTransaction(1, common.Address{1}, big.NewInt(1), 21000, big.NewInt(1), nil),
+	}
+
+	// not leading, everything goes to the pool and nothing is acked
+	if fresh := b.ReceiveTransactions(from, txs); len(fresh) != 2 {
+		t.Fatalf("got %d transactions for the pool, want 2", len(fresh))
+	}
+	b.coreStarted = true
+	b.view = 1
+	if fresh := b.ReceiveTransactions(from, txs); len(fresh) != 2 {
+		t.Fatalf("got %d transactions for the pool, want 2", len(fresh))
+	}
+	expectNoMsg(t, broadcaster)
+
+	// leading, transactions seen before are acked but not returned
+	b.view = 0
+	if fresh := b.ReceiveTransactions(from, txs[:1]); len(fresh) != 1 {
+		t.Fatalf("got %d transactions for the pool, want 1", len(fresh))
+	}
+	expectAck(t, broadcaster, from, txs[:1])
+	if fresh := b.ReceiveTransactions(from, txs); len(fresh) != 1 || fresh[0].Hash() != txs[1].Hash() {
+		t.Fatalf("got %d transactions for the pool, want the second one only", len(fresh))
+	}
+	expectAck(t, broadcaster, from, txs)
+	if _, received := b.forwardStatus(txs[1].Hash()); received == nil || received.From != from {
+		t.Fatalf("received status %v, want received from %x", received, from)
+	}
+}
+
+func TestHandleTxAck(t *testing.T) {
+	b, keys := newTestBackend(t, 4)
+	var (
+		leader = crypto.PubkeyToAddress(keys[1].PublicKey)
+		other  = crypto.PubkeyToAddress(keys[2].PublicKey)
+		hash   = common.HexToHash("0x01")
+	)
+	b.MarkForwarded([]common.Hash{hash}, leader)
+
+	// only the node the transaction was forwarded to can ack it
+	if handled, err := b.HandleMsg(other, ackMsg(t, hash)); !handled || err != nil {
+		t.Fatalf("ack not handled: %v", err)
+	}
+	if forwarded, _ := b.forwardStatus(hash); forwarded == nil || forwarded.Acked {
+		t.Fatalf("forwarded status %v, want not acked", forwarded)
+	}
+	if handled, err := b.HandleMsg(leader, ackMsg(t, hash)); !handled || err != nil {
+		t.Fatalf("ack not handled: %v", err)
+	}
+	if forwarded, _ := b.forwardStatus(hash); forwarded == nil || !forwarded.Acked || forwarded.AckedAt == nil {
+		t.Fatalf("forwarded status %v, want acked", forwarded)
+	}
+}
+
+func ackMsg(t *testing.T, hashes ...common.Hash) p2p.Msg {
+	size, r, err := rlp.EncodeToReader(hashes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p2p.Msg{Code: e2cTxAckMsg, Size: uint32(size), Payload: r}
+}
+
+func expectAck(t *testing.T, broadcaster *testBroadcaster, to common.Address, txs []*types.Transaction) {
+	t.Helper()
+	select {
+	case msg := <-broadcaster.sent:
+		hashes := msg.data.([]common.Hash)
+		if msg.to != to || msg.code != e2cTxAckMsg || len(hashes) != len(txs) {
+			t.Fatalf("got message %v, want an ack of %d transactions to %x", msg, len(txs), to)
+		}
+		for i, tx := range txs {
+			if hashes[i] != tx.Hash() {
+				t.Fatalf("ack %d is %x, want %x", i, hashes[i], tx.Hash())
+			}
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no ack sent")
+	}
+}
+
+func expectNoMsg(t *testing.T, broadcaster *testBroadcaster) {
+	t.Helper()
+	select {
+	case msg := <-broadcaster.sent:
+		t.Fatalf("unexpected message %v", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -26,6 +26,8 @@ import (
 
 const (
 	e2cMsg            = 0x11
+	e2cTxAckMsg       = 0x0b // leader acks transactions forwarded to it, unused by eth so safe on the legacy protocols
+	e2cCheckpointMsg  = 0x0c // signed checkpoints, also unused by eth
 	NewBlockMsg       = 0x07
 	NewBlockHashesMsg = 0x01
 )
@@ -48,12 +50,24 @@ func (b *backend) decode(msg p2p.Msg) ([]byte, common.Hash, error) {
 func (b *backend) HandleMsg(addr common.Address, msg p2p.Msg) (bool, error) {
 	b.coreMu.Lock()
 	defer b.coreMu.Unlock()
+	if drop, err := b.checkPeer(addr, msg.Code); drop {
+		msg.Discard()
+		return true, err
+	}
 	if msg.Code == e2cMsg && b.coreStarted {
 
 		data, hash, err := b.decode(msg)
 		if err != nil {
+			b.Penalize(addr, err)
 			return true, errDecodeFailed
 		}
+		// pings are answered right away, waiting behind the core would skew the delays
+		if ok, err := b.handleDelayMsg(addr, data, msg.ReceivedAt); ok {
+			if err != nil {
+				b.Penalize(addr, err)
+			}
+			return true, err
+		}
 		// Mark peer's message
 		ms, ok := b.recentMessages.Get(addr)
 		var m *lru.ARCCache
@@ -72,7 +86,15 @@ func (b *backend) HandleMsg(addr common.Address, msg p2p.Msg) (bool, error) {
 		b.knownMessages.Add(hash, true)
 
 		// Send the message to the e2c.Core for handling
-		go b.eventMux.Post(e2c.MessageEvent{Payload: data})
+		go b.eventMux.Post(e2c.MessageEvent{Payload: data, Peer: addr})
+	}
+	// acks are handled by member nodes as well, so don't check if the core has started
+	if msg.Code == e2cTxAckMsg {
+		return true, b.handleTxAck(addr, msg)
+	}
+	// joining nodes ask for checkpoints before they start the core, so don't check either
+	if msg.Code == e2cCheckpointMsg {
+		return true, b.handleCheckpoint(addr, msg)
 	}
 	// We commit our own blocks, and thus, don't want this to run on the protocol manager
 	if msg.Code == NewBlockMsg {
@@ -0,0 +1,334 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/e2c"
+	e2cCore "github.com/ethereum/go-ethereum/consensus/e2c/core"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Validators ping each other every PingInterval with signed, timestamped messages on
+// the consensus channel. The round trip doesn't depend on the clocks, the one-way
+// delays in each direction do. Links can be asymmetric, so we take the larger of
+// half the round trip and the one-way delays whenever the clocks look close enough
+// for them to make sense
+
+const (
+	delaySamples      = 64  // recent samples kept per validator
+	delayWarningRatio = 0.8 // fraction of Δ at which delays are reported as approaching it
+)
+
+var (
+	rttTimer       = metrics.NewRegisteredTimer("consensus/e2c/delay/rtt", nil)
+	oneWayTimer    = metrics.NewRegisteredTimer("consensus/e2c/delay/oneway", nil)
+	maxDelayGauge  = metrics.NewRegisteredGauge("consensus/e2c/delay/max", nil) // milliseconds, over all validators
+	warningMeter   = metrics.NewRegisteredMeter("consensus/e2c/synchrony/warning", nil)
+	violationMeter = metrics.NewRegisteredMeter("consensus/e2c/synchrony/violation", nil)
+)
+
+// PeerDelay holds the delays observed to a validator, in milliseconds
+type PeerDelay struct {
+	RTT      float64   `json:"rtt"`      // last round trip
+	Delay    float64   `json:"delay"`    // last one-way delay
+	MaxDelay float64   `json:"maxDelay"` // highest one-way delay over the recent samples
+	Samples  uint64    `json:"samples"`
+	LastSeen time.Time `json:"lastSeen"`
+	Status   string    `json:"status"`
+}
+
+// Synchrony tells whether the delays observed to the other validators stay within Δ
+type Synchrony struct {
+	Delta    uint64                        `json:"delta"`    // milliseconds
+	MaxDelay float64                       `json:"maxDelay"` // highest one-way delay to any validator
+	Status   string                        `json:"status"`
+	Violated bool                          `json:"violated"`
+	Peers    map[common.Address]*PeerDelay `json:"peers"`
+}
+
+// delays observed to a single validator
+type delayStats struct {
+	rtts     [delaySamples]time.Duration
+	delays   [delaySamples]time.Duration
+	samples  uint64
+	lastSent uint64 // ping time echoed by the last pong, anything older is a replay
+	lastSeen time.Time
+	status   string
+}
+
+// adds a sample and returns the highest delay over the recent ones
+func (s *delayStats) add(rtt, delay time.Duration) time.Duration {
+	s.rtts[s.samples%delaySamples] = rtt
+	s.delays[s.samples%delaySamples] = delay
+	s.samples++
+	return s.maxDelay()
+}
+
+func (s *delayStats) maxDelay() time.Duration {
+	var max time.Duration
+	for i := uint64(0); i < s.samples && i < delaySamples; i++ {
+		if s.delays[i] > max {
+			max = s.delays[i]
+		}
+	}
+	return max
+}
+
+func (s *delayStats) last() (time.Duration, time.Duration) {
+	if s.samples == 0 {
+		return 0, 0
+	}
+	i := (s.samples - 1) % delaySamples
+	return s.rtts[i], s.delays[i]
+}
+
+// pings the other validators until quit is closed
+func (b *backend) pingLoop(quit chan struct{}) {
+	ticker := time.NewTicker(b.config.PingInterval * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			b.sendPings()
+		case <-quit:
+			return
+		}
+	}
+}
+
+func (b *backend) sendPings() {
+	if b.broadcaster == nil {
+		return
+	}
+	payload, err := b.delayMessage(e2cCore.PingMsg, &e2c.Ping{Sent: uint64(time.Now().UnixNano())})
+	if err != nil {
+		log.Error("Failed to create ping", "err", err)
+		return
+	}
+	targets := make(map[common.Address]bool)
+	for _, val := range b.Validators() {
+		if val != b.Address() {
+			targets[val] = true
+		}
+	}
+	for _, p := range b.broadcaster.FindPeers(targets) {
+		go p.SendConsensus(e2cMsg, payload)
+	}
+}
+
+// signs a ping or pong so nobody can fake the delays to a validator
+func (b *backend) delayMessage(code uint64, val interface{}) ([]byte, error) {
+	data, err := rlp.EncodeToBytes(val)
+	if err != nil {
+		return nil, err
+	}
+	msg := &e2cCore.Message{
+		Code:    code,
+		Msg:     data,
+		View:    b.View(),
+		Address: b.Address(),
+	}
+	return msg.PayloadWithSig(b.Sign)
+}
+
+// handleDelayMsg answers pings and records the delay measured by pongs. It returns
+// false if the message is neither, so it can go to the core
+func (b *backend) handleDelayMsg(addr common.Address, data []byte, received time.Time) (bool, error) {
+	msg := new(e2cCore.Message)
+	if err := rlp.DecodeBytes(data, msg); err != nil {
+		return false, nil
+	}
+	if msg.Code != e2cCore.PingMsg && msg.Code != e2cCore.PongMsg {
+		return false, nil
+	}
+	payload, err := msg.PayloadNoSig()
+	if err != nil {
+		return true, errDecodeFailed
+	}
+	signer, err := e2c.CheckValidatorSignature(b.Validators(), payload, msg.Signature)
+	if err != nil || signer != addr {
+		return true, errInvalidSignature
+	}
+	if received.IsZero() {
+		received = time.Now()
+	}
+
+	switch msg.Code {
+	case e2cCore.PingMsg:
+		var ping e2c.Ping
+		if err := msg.Decode(&ping); err != nil {
+			return true, errDecodeFailed
+		}
+		b.sendPong(addr, &e2c.Pong{
+			Sent:     ping.Sent,
+			Received: uint64(received.UnixNano()),
+		})
+
+	case e2cCore.PongMsg:
+		var pong e2c.Pong
+		if err := msg.Decode(&pong); err != nil {
+			return true, errDecodeFailed
+		}
+		b.recordDelay(addr, &pong, received)
+	}
+	return true, nil
+}
+
+func (b *backend) sendPong(addr common.Address, pong *e2c.Pong) {
+	if b.broadcaster == nil {
+		return
+	}
+	p, ok := b.broadcaster.FindPeers(map[common.Address]bool{addr: true})[addr]
+	if !ok {
+		return
+	}
+	pong.Replied = uint64(time.Now().UnixNano())
+	payload, err := b.delayMessage(e2cCore.PongMsg, pong)
+	if err != nil {
+		log.Error("Failed to create pong", "err", err)
+		return
+	}
+	go p.SendConsensus(e2cMsg, payload)
+}
+
+// records the delay measured by a pong and reports when it gets close to Δ
+func (b *backend) recordDelay(addr common.Address, pong *e2c.Pong, received time.Time) {
+	now := uint64(received.UnixNano())
+	if pong.Sent >= now || pong.Replied < pong.Received || now-pong.Sent < pong.Replied-pong.Received {
+		return
+	}
+	rtt := time.Duration(now - pong.Sent - (pong.Replied - pong.Received))
+	delay := rtt / 2
+	for _, oneWay := range []time.Duration{
+		time.Duration(int64(pong.Received) - int64(pong.Sent)), // to the validator
+		time.Duration(int64(now) - int64(pong.Replied)),        // and back
+	} {
+		if oneWay > delay && oneWay <= rtt {
+			delay = oneWay
+		}
+	}
+
+	b.delaysMu.Lock()
+	stats, ok := b.delays[addr]
+	if !ok {
+		stats = &delayStats{status: e2c.SynchronyOK}
+		b.delays[addr] = stats
+	}
+	if pong.Sent <= stats.lastSent {
+		b.delaysMu.Unlock()
+		return
+	}
+	stats.lastSent = pong.Sent
+	stats.lastSeen = received
+	windowMax := stats.add(rtt, delay)
+
+	previous := stats.status
+	stats.status = b.synchronyStatus(windowMax)
+	var highest time.Duration
+	for _, s := range b.delays {
+		if max := s.maxDelay(); max > highest {
+			highest = max
+		}
+	}
+	b.delaysMu.Unlock()
+
+	rttTimer.Update(rtt)
+	oneWayTimer.Update(delay)
+	maxDelayGauge.Update(highest.Milliseconds())
+
+	delta := b.config.Delta * time.Millisecond
+	switch {
+	case delay > delta:
+		violationMeter.Mark(1)
+	case float64(delay) > delayWarningRatio*float64(delta):
+		warningMeter.Mark(1)
+	}
+	if stats.status == previous {
+		return
+	}
+	switch stats.status {
+	case e2c.SynchronyViolated:
+		log.Error("Delay to validator exceeds Δ, E2C safety no longer guaranteed", "addr", addr, "delay", windowMax, "delta", delta)
+	case e2c.SynchronyWarning:
+		log.Warn("Delay to validator is approaching Δ", "addr", addr, "delay", windowMax, "delta", delta)
+	default:
+		log.Info("Delay to validator back within Δ", "addr", addr, "delay", windowMax, "delta", delta)
+	}
+	b.PostEvent(e2c.SynchronyEvent{
+		Address:  addr,
+		Status:   stats.status,
+		Previous: previous,
+		Delay:    milliseconds(windowMax),
+		Delta:    uint64(b.config.Delta),
+	})
+}
+
+// tells how the delay compares to Δ
+func (b *backend) synchronyStatus(delay time.Duration) string {
+	delta := b.config.Delta * time.Millisecond
+	switch {
+	case delay > delta:
+		return e2c.SynchronyViolated
+	case float64(delay) > delayWarningRatio*float64(delta):
+		return e2c.SynchronyWarning
+	}
+	return e2c.SynchronyOK
+}
+
+// synchrony returns the delays observed to every validator that answered our pings
+func (b *backend) synchrony() *Synchrony {
+	b.delaysMu.Lock()
+	defer b.delaysMu.Unlock()
+
+	var (
+		highest time.Duration
+		peers   = make(map[common.Address]*PeerDelay, len(b.delays))
+	)
+	for addr, stats := range b.delays {
+		rtt, delay := stats.last()
+		max := stats.maxDelay()
+		if max > highest {
+			highest = max
+		}
+		peers[addr] = &PeerDelay{
+			RTT:      milliseconds(rtt),
+			Delay:    milliseconds(delay),
+			MaxDelay: milliseconds(max),
+			Samples:  stats.samples,
+			LastSeen: stats.lastSeen,
+			Status:   stats.status,
+		}
+	}
+	status := b.synchronyStatus(highest)
+	return &Synchrony{
+		Delta:    uint64(b.config.Delta),
+		MaxDelay: milliseconds(highest),
+		Status:   status,
+		Violated: status == e2c.SynchronyViolated,
+		Peers:    peers,
+	}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,128 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/e2c"
+)
+
+func TestSynchronyStatus(t *testing.T) {
+	b, _ := newTestBackend(t, 1)
+	b.config.Delta = 200
+
+	tests := []struct {
+		delay time.Duration
+		want  string
+	}{
+		{0, e2c.SynchronyOK},
+		{100 * time.Millisecond, e2c.SynchronyOK},
+		{160 * time.Millisecond, e2c.SynchronyOK},
+		{161 * time.Millisecond, e2c.SynchronyWarning},
+		{200 * time.Millisecond, e2c.SynchronyWarning},
+		{201 * time.Millisecond, e2c.SynchronyViolated},
+		{time.Second, e2c.SynchronyViolated},
+	}
+	for _, tt := range tests {
+		if have := b.synchronyStatus(tt.delay); have != tt.want {
+			t.Errorf("delay %v: status mismatch: have %s, want %s", tt.delay, have, tt.want)
+		}
+	}
+}
+
+func TestRecordDelay(t *testing.T) {
+	b, _ := newTestBackend(t, 1)
+	b.config.Delta = 200
+	events, sub := b.subscribeEvents(e2c.SynchronyEvent{})
+	defer sub.Unsubscribe()
+
+	var (
+		addr  = common.Address{0x01}
+		start = time.Now()
+		sent  = start
+	)
+	// pong returns a pong for a ping sent now, which took out to reach the
+	// validator and back to come back, and when it was received
+	pong := func(out, back time.Duration) (*e2c.Pong, time.Time) {
+		sent = sent.Add(time.Second)
+		received := sent.Add(out)
+		replied := received.Add(time.Millisecond)
+		return &e2c.Pong{
+			Sent:     uint64(sent.UnixNano()),
+			Received: uint64(received.UnixNano()),
+			Replied:  uint64(replied.UnixNano()),
+		}, replied.Add(back)
+	}
+
+	tests := []struct {
+		name     string
+		out      time.Duration
+		back     time.Duration
+		samples  int    // pongs recorded
+		want     string // status afterwards
+		previous string // status left, empty if there is no event
+	}{
+		{"within Δ", 50 * time.Millisecond, 50 * time.Millisecond, 1, e2c.SynchronyOK, ""},
+		{"one-way delay near Δ", 170 * time.Millisecond, 10 * time.Millisecond, 1, e2c.SynchronyWarning, e2c.SynchronyOK},
+		{"beyond Δ", 250 * time.Millisecond, 250 * time.Millisecond, 1, e2c.SynchronyViolated, e2c.SynchronyWarning},
+		{"violation still in the window", 10 * time.Millisecond, 10 * time.Millisecond, delaySamples - 1, e2c.SynchronyViolated, ""},
+		{"violation out of the window", 10 * time.Millisecond, 10 * time.Millisecond, 1, e2c.SynchronyOK, e2c.SynchronyViolated},
+	}
+	for _, tt := range tests {
+		for i := 0; i < tt.samples; i++ {
+			p, received := pong(tt.out, tt.back)
+			b.recordDelay(addr, p, received)
+		}
+		if have := b.delays[addr].status; have != tt.want {
+			t.Fatalf("%s: status mismatch: have %s, want %s", tt.name, have, tt.want)
+		}
+		select {
+		case ev := <-events:
+			event := ev.(e2c.SynchronyEvent)
+			if tt.previous == "" {
+				t.Fatalf("%s: unexpected event %+v", tt.name, event)
+			}
+			if event.Status != tt.want || event.Previous != tt.previous {
+				t.Fatalf("%s: event mismatch: have %s -> %s, want %s -> %s", tt.name, event.Previous, event.Status, tt.previous, tt.want)
+			}
+		case <-time.After(50 * time.Millisecond):
+			if tt.previous != "" {
+				t.Fatalf("%s: no event for %s -> %s", tt.name, tt.previous, tt.want)
+			}
+		}
+	}
+
+	// replayed and inconsistent pongs are ignored
+	samples := b.delays[addr].samples
+	replayed, received := pong(time.Second, time.Second)
+	replayed.Sent = uint64(start.UnixNano())
+	b.recordDelay(addr, replayed, received)
+
+	backwards, received := pong(time.Second, time.Second)
+	backwards.Replied = backwards.Received - 1
+	b.recordDelay(addr, backwards, received)
+
+	if have := b.delays[addr].samples; have != samples {
+		t.Fatalf("ignored pongs recorded: have %d samples, want %d", have, samples)
+	}
+	if have := b.delays[addr].status; have != e2c.SynchronyOK {
+		t.Fatalf("status changed by ignored pongs: have %s, want %s", have, e2c.SynchronyOK)
+	}
+}
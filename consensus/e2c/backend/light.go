@@ -0,0 +1,121 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/e2c"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Light clients only get headers from a LES server. A full node only stores blocks
+// once they are committed, so the evidence a server has to offer is the leader's
+// seal and the view certificates in each header, plus the latest checkpoint signed
+// by f+1 validators, which it hands over in the LES handshake. Light clients check
+// the seals against the validator set they follow through the headers, and reject
+// a chain that doesn't go through the signed checkpoint, since they can't run the
+// core or count acks like members do
+
+// verifyLightHeader checks a header without executing the block or following the view
+func (b *backend) verifyLightHeader(chain consensus.ChainHeaderReader, header *types.Header, parents []*types.Header) error {
+	if err := b.verifyHeaderFields(header); err != nil {
+		return err
+	}
+	// The genesis block is the always valid dead-end
+	number := header.Number.Uint64()
+	if number == 0 {
+		return nil
+	}
+	if cp := b.Checkpoint(); cp != nil && cp.Number == number && cp.Hash != header.Hash() {
+		return errCheckpointMismatch
+	}
+
+	var parent *types.Header
+	if len(parents) > 0 {
+		parent = parents[len(parents)-1]
+	} else {
+		parent = chain.GetHeader(header.ParentHash, number-1)
+	}
+	if parent == nil || parent.Number.Uint64() != number-1 || parent.Hash() != header.ParentHash {
+		return consensus.ErrUnknownAncestor
+	}
+	if parent.Time > header.Time {
+		return errInvalidTimestamp
+	}
+	validators, err := b.validatorsAt(chain, header, parents)
+	if err != nil {
+		return err
+	}
+	if err := verifyViewChange(header, parent, validators); err != nil {
+		return err
+	}
+	if err := verifyLightCertified(chain, header, parents); err != nil {
+		return err
+	}
+	return verifyLightSigner(header, validators)
+}
+
+// verifyLightCertified checks the block a view certificate votes for is one of ours.
+// Light clients don't have the block, so they can't check the votes themselves
+func verifyLightCertified(chain consensus.ChainHeaderReader, header *types.Header, parents []*types.Header) error {
+	e2cExtra, err := types.ExtractE2CExtra(header)
+	if err != nil {
+		return errInvalidExtraDataFormat
+	}
+	cert := e2cExtra.Certificate
+	if e2cExtra.Version < types.E2CExtraV2 || cert == nil {
+		return nil
+	}
+	for _, parent := range parents {
+		if parent.Hash() == cert.Hash && parent.Number.Uint64() == cert.Number {
+			return nil
+		}
+	}
+	if chain.GetHeader(cert.Hash, cert.Number) == nil {
+		return errUnknownCertifiedBlock
+	}
+	return nil
+}
+
+// verifyLightSeal checks the seal of a header whose parent is known
+func (b *backend) verifyLightSeal(chain consensus.ChainHeaderReader, header *types.Header) error {
+	if header.Number.Uint64() == 0 {
+		return errUnknownBlock
+	}
+	validators, err := b.validatorsAt(chain, header, nil)
+	if err != nil {
+		return err
+	}
+	return verifyLightSigner(header, validators)
+}
+
+// verifyLightSigner checks the header was sealed by the leader of the view recorded
+// in it. Older headers don't record the view, so for those light clients can only
+// check the signer is one of the validators
+func verifyLightSigner(header *types.Header, validators e2c.Validators) error {
+	if ok, err := verifyViewLeader(header, validators); ok {
+		return err
+	}
+	signer, err := ecrecover(header)
+	if err != nil {
+		return err
+	}
+	if i, _ := validators.GetByAddress(signer); i == -1 {
+		return errUnauthorized
+	}
+	return nil
+}
@@ -0,0 +1,319 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/e2c"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// testHeaderChain is a header chain light clients verify against
+type testHeaderChain struct {
+	headers map[common.Hash]*types.Header
+	numbers map[uint64]*types.Header
+}
+
+func newTestHeaderChain(genesis *types.Header) *testHeaderChain {
+	hc := &testHeaderChain{headers: make(map[common.Hash]*types.Header), numbers: make(map[uint64]*types.Header)}
+	hc.insert(genesis)
+	return hc
+}
+
+func (hc *testHeaderChain) insert(header *types.Header) {
+	hc.headers[header.Hash()] = header
+	hc.numbers[header.Number.Uint64()] = header
+}
+
+func (hc *testHeaderChain) Config() *params.ChainConfig { return params.TestChainConfig }
+
+func (hc *testHeaderChain) CurrentHeader() *types.Header {
+	return hc.numbers[uint64(len(hc.numbers)-1)]
+}
+
+func (hc *testHeaderChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header := hc.headers[hash]; header != nil && header.Number.Uint64() == number {
+		return header
+	}
+	return nil
+}
+
+func (hc *testHeaderChain) GetHeaderByNumber(number uint64) *types.Header {
+	return hc.numbers[number]
+}
+
+func (hc *testHeaderChain) GetHeaderByHash(hash common.Hash) *types.Header {
+	return hc.headers[hash]
+}
+
+func testAddresses(keys []*ecdsa.PrivateKey) []common.Address {
+	addrs := make([]common.Address, len(keys))
+	for i, key := range keys {
+		addrs[i] = crypto.PubkeyToAddress(key.PublicKey)
+	}
+	return addrs
+}
+
+// testGenesis returns a genesis header listing the validators
+func testGenesis(t *testing.T, validators []common.Address) *types.Header {
+	header := &types.Header{
+		Number:     big.NewInt(0),
+		Difficulty: defaultDifficulty,
+		MixDigest:  types.E2CDigest,
+		UncleHash:  nilUncleHash,
+	}
+	extra, err := prepareExtra(header, validators)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header.Extra = extra
+	return header
+}
+
+// sealTestHeader returns a v2 child of parent sealed by key in view 0, listing
+// vals in the extra-data and recording the hash of the validator set
+func sealTestHeader(t *testing.T, parent *types.Header, key *ecdsa.PrivateKey, vals []common.Address, validators []common.Address) *types.Header {
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		Difficulty: defaultDifficulty,
+		MixDigest:  types.E2CDigest,
+		UncleHash:  nilUncleHash,
+		Time:       parent.Time + 1,
+	}
+	extra, err := prepareExtra(header, vals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header.Extra = extra
+	if err := writeView(header, 0, types.E2CValidatorsHash(validators), nil); err != nil {
+		t.Fatal(err)
+	}
+	seal, err := crypto.Sign(crypto.Keccak256(sigHash(header).Bytes()), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeSeal(header, seal); err != nil {
+		t.Fatal(err)
+	}
+	return header
+}
+
+func TestValidatorsAtFollowsChanges(t *testing.T) {
+	b, keys := newTestBackend(t, 3)
+	outsider, _ := crypto.GenerateKey()
+	oldSet := testAddresses(keys)
+	newSet := append(oldSet[:2:2], crypto.PubkeyToAddress(outsider.PublicKey))
+
+	genesis := testGenesis(t, oldSet)
+	chain := newTestHeaderChain(genesis)
+	block1 := sealTestHeader(t, genesis, keys[0], []common.Address{oldSet[0]}, oldSet)
+	block2 := sealTestHeader(t, block1, keys[0], newSet, newSet)
+	block3 := sealTestHeader(t, block2, keys[0], []common.Address{newSet[0]}, newSet)
+	for _, header := range []*types.Header{block1, block2, block3} {
+		chain.insert(header)
+	}
+
+	tests := []struct {
+		header *types.Header
+		want   []common.Address
+	}{
+		{genesis, oldSet},
+		{block1, oldSet},
+		{block2, newSet},
+		{block3, newSet},
+	}
+	for i, tt := range tests {
+		validators, err := b.validatorsAt(chain, tt.header, nil)
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		if types.E2CValidatorsHash(validators) != types.E2CValidatorsHash(tt.want) {
+			t.Errorf("test %d: validators mismatch: have %v, want %v", i, validators, tt.want)
+		}
+	}
+}
+
+func TestValidatorsAtRejectsInvalidChanges(t *testing.T) {
+	b, keys := newTestBackend(t, 3)
+	outsider, _ := crypto.GenerateKey()
+	oldSet := testAddresses(keys)
+	newSet := []common.Address{crypto.PubkeyToAddress(outsider.PublicKey)}
+
+	genesis := testGenesis(t, oldSet)
+	chain := newTestHeaderChain(genesis)
+
+	tests := []struct {
+		name   string
+		header *types.Header
+		want   error
+	}{
+		{"sealed by a new validator", sealTestHeader(t, genesis, outsider, newSet, newSet), errUnauthorized},
+		{"new set not listed", sealTestHeader(t, genesis, keys[0], []common.Address{oldSet[0]}, newSet), errInvalidValidatorsHash},
+	}
+	for _, tt := range tests {
+		if _, err := b.validatorsAt(chain, tt.header, nil); err != tt.want {
+			t.Errorf("%s: error mismatch: have %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestVerifyLightHeaders(t *testing.T) {
+	b, keys := newTestBackend(t, 3)
+	b.config.LightClient = true
+	b.validators = nil
+	outsider, _ := crypto.GenerateKey()
+	oldSet := testAddresses(keys)
+	newSet := append([]common.Address{crypto.PubkeyToAddress(outsider.PublicKey)}, oldSet[1:]...)
+
+	genesis := testGenesis(t, oldSet)
+	chain := newTestHeaderChain(genesis)
+	block1 := sealTestHeader(t, genesis, keys[0], []common.Address{oldSet[0]}, oldSet)
+	// the leader of view 0 in the new set is the outsider, who isn't in the old set
+	invalid := sealTestHeader(t, block1, outsider, newSet, newSet)
+	if err := b.verifyLightHeader(chain, invalid, []*types.Header{block1}); err != errUnauthorized {
+		t.Fatalf("handover to an outsider: error mismatch: have %v, want %v", err, errUnauthorized)
+	}
+	// the old set hands over by listing the new one, led by one of its own
+	newSet = append(oldSet[1:2:2], crypto.PubkeyToAddress(outsider.PublicKey))
+	block2 := sealTestHeader(t, block1, keys[1], newSet, newSet)
+	block3 := sealTestHeader(t, block2, keys[1], []common.Address{newSet[0]}, newSet)
+
+	headers := []*types.Header{block1, block2, block3}
+	for i, header := range headers {
+		if err := b.verifyLightHeader(chain, header, headers[:i]); err != nil {
+			t.Fatalf("block %d: %v", header.Number, err)
+		}
+	}
+	// block 3 sealed by a validator of the old set that isn't in the new one
+	stale := sealTestHeader(t, block2, keys[2], []common.Address{oldSet[2]}, newSet)
+	if err := b.verifyLightHeader(chain, stale, headers[:2]); err != errUnauthorized {
+		t.Fatalf("stale validator: error mismatch: have %v, want %v", err, errUnauthorized)
+	}
+
+	// a signed checkpoint at block 3 rules out any other block 3
+	b.checkpoint = &e2c.Checkpoint{Number: 3, Hash: stale.Hash()}
+	if err := b.verifyLightHeader(chain, block3, headers[:2]); err != errCheckpointMismatch {
+		t.Fatalf("checkpoint: error mismatch: have %v, want %v", err, errCheckpointMismatch)
+	}
+}
+
+func TestAddCheckpoint(t *testing.T) {
+	b, keys := newTestBackend(t, 4)
+	validators := testAddresses(keys)
+	genesis := testGenesis(t, validators)
+	rawdb.WriteHeader(b.db, genesis)
+	rawdb.WriteCanonicalHash(b.db, genesis.Hash(), 0)
+
+	signed := func(number uint64, signers ...*ecdsa.PrivateKey) *e2c.Checkpoint {
+		cp := &e2c.Checkpoint{Number: number, Hash: common.Hash{byte(number)}, Validators: validators}
+		for _, key := range signers {
+			sig, err := crypto.Sign(crypto.Keccak256(cp.SigHash().Bytes()), key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cp.Signatures = append(cp.Signatures, sig)
+		}
+		return cp
+	}
+	tests := []struct {
+		cp   *e2c.Checkpoint
+		want error
+		kept uint64
+	}{
+		{signed(10, keys[0]), e2c.ErrInsufficientSignatures, 0},
+		{signed(10, keys[0], keys[1]), nil, 10},
+		{signed(5, keys[2], keys[3]), nil, 10},
+		{signed(20, keys[1], keys[1]), e2c.ErrInvalidCheckpoint, 10},
+	}
+	for i, tt := range tests {
+		if err := b.AddCheckpoint(tt.cp); err != tt.want {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.want)
+		}
+		var kept uint64
+		if cp := b.Checkpoint(); cp != nil {
+			kept = cp.Number
+		}
+		if kept != tt.kept {
+			t.Errorf("test %d: kept checkpoint mismatch: have %d, want %d", i, kept, tt.kept)
+		}
+	}
+}
+
+func TestAddCheckpointAfterValidatorChange(t *testing.T) {
+	b, keys := newTestBackend(t, 4)
+	outsider, _ := crypto.GenerateKey()
+	oldSet := testAddresses(keys)
+	newSet := append(oldSet[:3:3], crypto.PubkeyToAddress(outsider.PublicKey))
+
+	genesis := testGenesis(t, oldSet)
+	block1 := sealTestHeader(t, genesis, keys[0], []common.Address{oldSet[0]}, oldSet)
+	block2 := sealTestHeader(t, block1, keys[0], newSet, newSet)
+	block3 := sealTestHeader(t, block2, keys[0], []common.Address{newSet[0]}, newSet)
+	for _, header := range []*types.Header{genesis, block1, block2, block3} {
+		rawdb.WriteHeader(b.db, header)
+		rawdb.WriteCanonicalHash(b.db, header.Hash(), header.Number.Uint64())
+	}
+	rawdb.WriteHeadHeaderHash(b.db, block3.Hash())
+
+	signed := func(number uint64, hash common.Hash, validators []common.Address, signers ...*ecdsa.PrivateKey) *e2c.Checkpoint {
+		cp := &e2c.Checkpoint{Number: number, Hash: hash, Validators: validators}
+		for _, key := range signers {
+			sig, err := crypto.Sign(crypto.Keccak256(cp.SigHash().Bytes()), key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cp.Signatures = append(cp.Signatures, sig)
+		}
+		return cp
+	}
+	tests := []struct {
+		cp   *e2c.Checkpoint
+		want error
+		kept uint64
+	}{
+		// the removed validator can't sign for blocks after the change
+		{signed(3, block3.Hash(), oldSet, keys[3], keys[2]), e2c.ErrInvalidCheckpoint, 0},
+		{signed(3, block3.Hash(), newSet, keys[3], keys[2]), e2c.ErrUnauthorizedAddress, 0},
+		{signed(3, block3.Hash(), newSet, outsider, keys[2]), nil, 3},
+		// but it could before
+		{signed(1, block1.Hash(), oldSet, keys[3], keys[2]), nil, 3},
+		{signed(2, common.Hash{2}, newSet, outsider, keys[2]), errCheckpointMismatch, 3},
+		// past our head, only the newest known set is accepted
+		{signed(10, common.Hash{10}, oldSet, keys[3], keys[2]), errUnknownValidatorSet, 3},
+		{signed(10, common.Hash{10}, newSet, outsider, keys[1]), nil, 10},
+	}
+	for i, tt := range tests {
+		if err := b.AddCheckpoint(tt.cp); err != tt.want {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.want)
+		}
+		var kept uint64
+		if cp := b.Checkpoint(); cp != nil {
+			kept = cp.Number
+		}
+		if kept != tt.kept {
+			t.Errorf("test %d: kept checkpoint mismatch: have %d, want %d", i, kept, tt.kept)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/e2c"
+	"github.com/ethereum/go-ethereum/log"
+	"golang.org/x/time/rate"
+)
+
+// Every peer gets a score for the invalid E2C traffic it sends. Messages from another
+// view cost little since honest validators send them around view changes, forged or
+// bogus ones cost a lot. The score decays over time, so only a peer that keeps at it
+// gets throttled and, eventually, disconnected and banned. Banned peers are dropped
+// the next time they send anything and refused when they reconnect
+
+const (
+	penaltyInvalid     = 20
+	penaltyStale       = 1
+	penaltyRateLimited = 2
+
+	scoreDecay    = 1   // points forgiven every second
+	throttleScore = 50  // consensus messages from the peer are dropped above this
+	banScore      = 100 // the peer is banned above this
+
+	maxScoredPeers = 1024 // peers with a clean score are forgotten above this
+)
+
+// limits on the E2C messages a single peer may send per second
+var msgLimits = map[uint64]struct {
+	rate  rate.Limit
+	burst int
+}{
+	e2cMsg:           {500, 1000},
+	e2cTxAckMsg:      {100, 200},
+	e2cCheckpointMsg: {2, 10},
+}
+
+// scoring state of a single peer
+type peerScore struct {
+	e2c.PeerScore
+	updated  time.Time
+	limiters map[uint64]*rate.Limiter
+}
+
+// decays the score up to now
+func (s *peerScore) decay(now time.Time) {
+	s.Score -= now.Sub(s.updated).Seconds() * scoreDecay
+	if s.Score < 0 {
+		s.Score = 0
+	}
+	s.updated = now
+	s.Throttled = s.Score >= throttleScore
+}
+
+// returns the scoring state of the peer, scoresMu must be held
+func (b *backend) peerScore(addr common.Address) *peerScore {
+	s, ok := b.scores[addr]
+	if !ok {
+		if len(b.scores) >= maxScoredPeers {
+			now := time.Now()
+			for a, old := range b.scores {
+				if old.decay(now); old.Score == 0 {
+					delete(b.scores, a)
+				}
+			}
+		}
+		s = &peerScore{updated: time.Now(), limiters: make(map[uint64]*rate.Limiter)}
+		b.scores[addr] = s
+	}
+	return s
+}
+
+// Penalize implements e2c.Backend.Penalize
+func (b *backend) Penalize(addr common.Address, reason error) {
+	b.scoresMu.Lock()
+	defer b.scoresMu.Unlock()
+
+	b.penalize(addr, reason)
+}
+
+// adds the penalty for reason to the peer's score and bans it if it's too high,
+// scoresMu must be held
+func (b *backend) penalize(addr common.Address, reason error) {
+	s := b.peerScore(addr)
+	s.decay(time.Now())
+
+	switch {
+	case errors.Is(reason, e2c.ErrStaleMessage):
+		s.Stale++
+		s.Score += penaltyStale
+	case errors.Is(reason, errRateLimited):
+		s.RateLimited++
+		s.Score += penaltyRateLimited
+	default:
+		s.Invalid++
+		s.Score += penaltyInvalid
+	}
+	log.Debug("Penalized E2C peer", "addr", addr, "reason", reason, "score", s.Score)
+
+	if s.Score >= banScore {
+		if _, banned := b.bans[addr]; !banned {
+			b.ban(addr, e2c.DefaultBanDuration, reason.Error())
+		}
+	}
+	s.Throttled = s.Score >= throttleScore
+}
+
+// checkPeer applies the ban list, the throttling and the rate limits to a message
+// from the peer. It returns true if the message should be dropped, and an error if
+// the peer should be disconnected
+func (b *backend) checkPeer(addr common.Address, code uint64) (bool, error) {
+	b.scoresMu.Lock()
+	defer b.scoresMu.Unlock()
+
+	if b.isBanned(addr) {
+		return true, errPeerBanned
+	}
+	limit, ok := msgLimits[code]
+	if !ok {
+		return false, nil
+	}
+
+	s := b.peerScore(addr)
+	s.decay(time.Now())
+	if s.Throttled && code == e2cMsg {
+		return true, nil
+	}
+	limiter, ok := s.limiters[code]
+	if !ok {
+		limiter = rate.NewLimiter(limit.rate, limit.burst)
+		s.limiters[code] = limiter
+	}
+	if !limiter.Allow() {
+		b.penalize(addr, errRateLimited)
+		if b.isBanned(addr) {
+			return true, errPeerBanned
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// PeerScores implements consensus.E2C.PeerScores
+func (b *backend) PeerScores() map[common.Address]*e2c.PeerScore {
+	b.scoresMu.Lock()
+	defer b.scoresMu.Unlock()
+
+	now := time.Now()
+	scores := make(map[common.Address]*e2c.PeerScore, len(b.scores))
+	for addr, s := range b.scores {
+		s.decay(now)
+		score := s.PeerScore
+		scores[addr] = &score
+	}
+	return scores
+}
+
+// Bans implements consensus.E2C.Bans
+func (b *backend) Bans() []*e2c.Ban {
+	b.scoresMu.Lock()
+	defer b.scoresMu.Unlock()
+
+	bans := make([]*e2c.Ban, 0, len(b.bans))
+	for addr, ban := range b.bans {
+		if b.isBanned(addr) {
+			cpy := *ban
+			bans = append(bans, &cpy)
+		}
+	}
+	sort.Slice(bans, func(i, j int) bool { return bans[i].Until.Before(bans[j].Until) })
+	return bans
+}
+
+// Ban implements consensus.E2C.Ban
+func (b *backend) Ban(addr common.Address, d time.Duration, reason string) {
+	b.scoresMu.Lock()
+	defer b.scoresMu.Unlock()
+
+	b.ban(addr, d, reason)
+}
+
+// scoresMu must be held
+func (b *backend) ban(addr common.Address, d time.Duration, reason string) {
+	b.bans[addr] = &e2c.Ban{
+		Address: addr,
+		Until:   time.Now().Add(d),
+		Reason:  reason,
+	}
+	log.Warn("Banned E2C peer", "addr", addr, "duration", d, "reason", reason)
+}
+
+// Unban implements consensus.E2C.Unban. The peer starts over with a clean score
+func (b *backend) Unban(addr common.Address) bool {
+	b.scoresMu.Lock()
+	defer b.scoresMu.Unlock()
+
+	if _, ok := b.bans[addr]; !ok {
+		return false
+	}
+	delete(b.bans, addr)
+	delete(b.scores, addr)
+	log.Info("Unbanned E2C peer", "addr", addr)
+	return true
+}
+
+// IsBanned implements consensus.E2C.IsBanned
+func (b *backend) IsBanned(addr common.Address) bool {
+	b.scoresMu.Lock()
+	defer b.scoresMu.Unlock()
+
+	return b.isBanned(addr)
+}
+
+// scoresMu must be held
+func (b *backend) isBanned(addr common.Address) bool {
+	ban, ok := b.bans[addr]
+	if !ok {
+		return false
+	}
+	if time.Now().After(ban.Until) {
+		delete(b.bans, addr)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,142 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/e2c"
+)
+
+func TestPenalize(t *testing.T) {
+	b, _ := newTestBackend(t, 1)
+	addr := common.Address{0x01}
+	invalid := errors.New("invalid message")
+
+	tests := []struct {
+		name      string
+		reason    error
+		times     int
+		score     float64 // score afterwards, less what decayed meanwhile
+		throttled bool
+		banned    bool
+	}{
+		{"invalid", invalid, 2, 40, false, false},
+		{"stale", e2c.ErrStaleMessage, 5, 45, false, false},
+		{"rate limited", errRateLimited, 3, 51, true, false},
+		{"invalid above the ban score", invalid, 3, 111, true, true},
+	}
+	for _, tt := range tests {
+		for i := 0; i < tt.times; i++ {
+			b.Penalize(addr, tt.reason)
+		}
+		s := b.PeerScores()[addr]
+		if s.Score > tt.score || s.Score < tt.score-1 {
+			t.Errorf("%s: score mismatch: have %v, want %v", tt.name, s.Score, tt.score)
+		}
+		if s.Throttled != tt.throttled {
+			t.Errorf("%s: throttled mismatch: have %v, want %v", tt.name, s.Throttled, tt.throttled)
+		}
+		if banned := b.IsBanned(addr); banned != tt.banned {
+			t.Errorf("%s: banned mismatch: have %v, want %v", tt.name, banned, tt.banned)
+		}
+	}
+	s := b.PeerScores()[addr]
+	if s.Invalid != 5 || s.Stale != 5 || s.RateLimited != 3 {
+		t.Errorf("penalty counts mismatch: have %d invalid, %d stale, %d rate limited", s.Invalid, s.Stale, s.RateLimited)
+	}
+	if !b.Unban(addr) || b.IsBanned(addr) {
+		t.Fatal("peer still banned")
+	}
+	if _, ok := b.PeerScores()[addr]; ok {
+		t.Fatal("score kept after the ban was lifted")
+	}
+}
+
+func TestScoreDecay(t *testing.T) {
+	b, _ := newTestBackend(t, 1)
+	addr := common.Address{0x01}
+	for i := 0; i < 3; i++ {
+		b.Penalize(addr, errors.New("invalid message"))
+	}
+	tests := []struct {
+		elapsed   time.Duration
+		score     float64
+		throttled bool
+	}{
+		{0, 60, true},
+		{5 * time.Second, 55, true},
+		{10 * time.Second, 45, false},
+		{time.Minute, 0, false},
+	}
+	for _, tt := range tests {
+		b.scores[addr].updated = b.scores[addr].updated.Add(-tt.elapsed)
+		s := b.PeerScores()[addr]
+		if s.Score > tt.score || s.Score < tt.score-1 {
+			t.Errorf("after %v: score mismatch: have %v, want %v", tt.elapsed, s.Score, tt.score)
+		}
+		if s.Throttled != tt.throttled {
+			t.Errorf("after %v: throttled mismatch: have %v, want %v", tt.elapsed, s.Throttled, tt.throttled)
+		}
+	}
+}
+
+func TestCheckPeer(t *testing.T) {
+	b, _ := newTestBackend(t, 1)
+	addr := common.Address{0x01}
+
+	// the checkpoint messages of a peer are limited to a burst of 10
+	burst := msgLimits[e2cCheckpointMsg].burst
+	for i := 0; i < burst; i++ {
+		if drop, err := b.checkPeer(addr, e2cCheckpointMsg); drop || err != nil {
+			t.Fatalf("message %d within the burst: have drop %v, err %v", i, drop, err)
+		}
+	}
+	if drop, err := b.checkPeer(addr, e2cCheckpointMsg); !drop || err != nil {
+		t.Fatalf("message above the burst: have drop %v, err %v, want dropped", drop, err)
+	}
+	if s := b.PeerScores()[addr]; s.RateLimited != 1 {
+		t.Fatalf("rate limit penalty mismatch: have %d, want 1", s.RateLimited)
+	}
+
+	// a throttled peer loses its consensus messages, nothing else
+	for i := 0; i < 3; i++ {
+		b.Penalize(addr, errors.New("invalid message"))
+	}
+	if drop, err := b.checkPeer(addr, e2cMsg); !drop || err != nil {
+		t.Fatalf("consensus message of throttled peer: have drop %v, err %v, want dropped", drop, err)
+	}
+	if drop, err := b.checkPeer(addr, e2cTxAckMsg); drop || err != nil {
+		t.Fatalf("ack of throttled peer: have drop %v, err %v", drop, err)
+	}
+
+	// a banned peer is disconnected whatever it sends
+	b.Ban(addr, time.Minute, "test")
+	for _, code := range []uint64{e2cMsg, e2cTxAckMsg, e2cCheckpointMsg} {
+		if drop, err := b.checkPeer(addr, code); !drop || err != errPeerBanned {
+			t.Fatalf("message %#x of banned peer: have drop %v, err %v, want %v", code, drop, err, errPeerBanned)
+		}
+	}
+	// until the ban runs out
+	b.bans[addr].Until = time.Now().Add(-time.Second)
+	if b.IsBanned(addr) || len(b.Bans()) != 0 {
+		t.Fatal("expired ban still in force")
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/consensus/e2c"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// These are exposed as e2c_subscribe("<name>") over websocket and IPC.
+// Every notification is the event posted by the core, see consensus/e2c/events.go
+
+// ViewChange notifies when the node quits a view and who leads the next one
+func (api *API) ViewChange(ctx context.Context) (*rpc.Subscription, error) {
+	return api.subscribe(ctx, e2c.ViewChangeEvent{})
+}
+
+// Blame notifies on every blame sent or received in the current view
+func (api *API) Blame(ctx context.Context) (*rpc.Subscription, error) {
+	return api.subscribe(ctx, e2c.BlameEvent{})
+}
+
+// BlockCertified notifies when a new highest block certificate is formed
+func (api *API) BlockCertified(ctx context.Context) (*rpc.Subscription, error) {
+	return api.subscribe(ctx, e2c.BlockCertifiedEvent{})
+}
+
+// LeaderChanged notifies when leadership moves to another validator
+func (api *API) LeaderChanged(ctx context.Context) (*rpc.Subscription, error) {
+	return api.subscribe(ctx, e2c.LeaderChangedEvent{})
+}
+
+// ProposalReceived notifies when a proposal from the leader is accepted
+func (api *API) ProposalReceived(ctx context.Context) (*rpc.Subscription, error) {
+	return api.subscribe(ctx, e2c.ProposalReceivedEvent{})
+}
+
+// Committed notifies when the core commits a block
+func (api *API) Committed(ctx context.Context) (*rpc.Subscription, error) {
+	return api.subscribe(ctx, e2c.CommittedEvent{})
+}
+
+// Checkpoint notifies when a checkpoint has been signed by f+1 validators
+func (api *API) Checkpoint(ctx context.Context) (*rpc.Subscription, error) {
+	return api.subscribe(ctx, e2c.CheckpointEvent{})
+}
+
+// SynchronyChanged notifies when the delays to a validator approach, exceed or
+// drop back within Δ
+func (api *API) SynchronyChanged(ctx context.Context) (*rpc.Subscription, error) {
+	return api.subscribe(ctx, e2c.SynchronyEvent{})
+}
+
+// eventBuffer is how many events a subscriber can fall behind by before the
+// events it has no room for are dropped
+const eventBuffer = 256
+
+// consensusEvent carries any consensus event, so they all go through one feed
+type consensusEvent struct{ data interface{} }
+
+// PostEvent implements e2c.Backend.PostEvent. Every subscriber takes the events
+// into a buffer of its own, so the sender is never held up by a slow client.
+func (b *backend) PostEvent(ev interface{}) {
+	b.consensusFeed.Send(consensusEvent{ev})
+}
+
+// subscribeEvents returns the events of the same type as ev. The events the
+// subscriber doesn't take in time are dropped once its buffer is full.
+func (b *backend) subscribeEvents(ev interface{}) (<-chan interface{}, event.Subscription) {
+	var (
+		kind   = reflect.TypeOf(ev)
+		events = make(chan consensusEvent, eventBuffer)
+		out    = make(chan interface{}, eventBuffer)
+	)
+	feedSub := b.consensusFeed.Subscribe(events)
+	return out, event.NewSubscription(func(quit <-chan struct{}) error {
+		defer feedSub.Unsubscribe()
+		dropped := 0
+		for {
+			select {
+			case ev := <-events:
+				if reflect.TypeOf(ev.data) != kind {
+					continue
+				}
+				select {
+				case out <- ev.data:
+					if dropped > 0 {
+						log.Warn("Dropped consensus events for a slow subscriber", "type", kind, "dropped", dropped)
+						dropped = 0
+					}
+				default:
+					dropped++
+				}
+			case <-quit:
+				return nil
+			case err := <-feedSub.Err():
+				return err
+			}
+		}
+	})
+}
+
+// subscribe forwards every event of the given type to the rpc subscription
+// until the client unsubscribes or disconnects
+func (api *API) subscribe(ctx context.Context, ev interface{}) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	events, sub := api.e2c.subscribeEvents(ev)
+
+	go func() {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case event := <-events:
+				notifier.Notify(rpcSub.ID, event)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/consensus/e2c"
+)
+
+func TestPostEventDeliversByType(t *testing.T) {
+	b := &backend{}
+	committed, sub := b.subscribeEvents(e2c.CommittedEvent{})
+	defer sub.Unsubscribe()
+
+	b.PostEvent(e2c.BlameEvent{View: 1})
+	b.PostEvent(e2c.CommittedEvent{Number: 7})
+
+	select {
+	case ev := <-committed:
+		if ev.(e2c.CommittedEvent).Number != 7 {
+			t.Fatalf("got %v, want the committed event of block 7", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("committed event not delivered")
+	}
+	select {
+	case ev := <-committed:
+		t.Fatalf("unexpected event %v", ev)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestPostEventDoesNotBlockOnSlowSubscriber(t *testing.T) {
+	b := &backend{}
+	// never read
+	_, slow := b.subscribeEvents(e2c.CommittedEvent{})
+	defer slow.Unsubscribe()
+	views, sub := b.subscribeEvents(e2c.ViewChangeEvent{})
+	defer sub.Unsubscribe()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 10*eventBuffer; i++ {
+			b.PostEvent(e2c.CommittedEvent{Number: uint64(i)})
+		}
+		b.PostEvent(e2c.ViewChangeEvent{View: 2})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("posting blocked on a slow subscriber")
+	}
+	select {
+	case ev := <-views:
+		if ev.(e2c.ViewChangeEvent).View != 2 {
+			t.Fatalf("got %v, want the view change to view 2", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("view change not delivered")
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/e2c"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// The validator set of a block is the one of its parent, starting from the set in
+// the genesis block. A v2 header records the hash of its set, so a header recording
+// a different hash than its parent's set changes the set. It has to list the new
+// set in its extra-data and be sealed by a member of the old one, which is all a
+// node needs to follow the set from the headers alone
+
+// validatorsAt returns the validator set the header was sealed under. The caller
+// may pass in a batch of parents (ascending order) that aren't in the chain yet
+func (b *backend) validatorsAt(chain consensus.ChainHeaderReader, header *types.Header, parents []*types.Header) (e2c.Validators, error) {
+	var (
+		headers    []*types.Header
+		validators e2c.Validators
+		cp         = b.Checkpoint()
+	)
+	for validators == nil {
+		if v, ok := b.validatorSets.Get(header.Hash()); ok {
+			validators = v.(e2c.Validators)
+			break
+		}
+		// a signed checkpoint vouches for the set, so syncing from one needs no older headers
+		if cp != nil && cp.Hash == header.Hash() && len(cp.Validators) > 0 {
+			validators = cp.Validators
+			b.validatorSets.Add(header.Hash(), validators)
+			break
+		}
+		number := header.Number.Uint64()
+		if number == 0 {
+			e2cExtra, err := types.ExtractE2CExtra(header)
+			if err != nil {
+				return nil, errInvalidExtraDataFormat
+			}
+			if len(e2cExtra.Validators) == 0 {
+				return nil, errEmptyValidatorSet
+			}
+			validators = e2cExtra.Validators
+			b.validatorSets.Add(header.Hash(), validators)
+			break
+		}
+		headers = append(headers, header)
+
+		var parent *types.Header
+		if len(parents) > 0 {
+			parent, parents = parents[len(parents)-1], parents[:len(parents)-1]
+		} else {
+			parent = chain.GetHeader(header.ParentHash, number-1)
+		}
+		if parent == nil || parent.Number.Uint64() != number-1 || parent.Hash() != header.ParentHash {
+			return nil, consensus.ErrUnknownAncestor
+		}
+		header = parent
+	}
+	for i := len(headers) - 1; i >= 0; i-- {
+		next, err := nextValidators(headers[i], validators)
+		if err != nil {
+			return nil, err
+		}
+		validators = next
+		b.validatorSets.Add(headers[i].Hash(), validators)
+	}
+	return validators, nil
+}
+
+// ValidatorsAt implements consensus.E2C.ValidatorsAt
+func (b *backend) ValidatorsAt(chain consensus.ChainHeaderReader, header *types.Header) (e2c.Validators, error) {
+	return b.validatorsAt(chain, header, nil)
+}
+
+// nextValidators returns the validator set of a header, given the set of its parent
+func nextValidators(header *types.Header, validators e2c.Validators) (e2c.Validators, error) {
+	e2cExtra, err := types.ExtractE2CExtra(header)
+	if err != nil {
+		return nil, errInvalidExtraDataFormat
+	}
+	if e2cExtra.Version < types.E2CExtraV2 || e2cExtra.ValidatorsHash == types.E2CValidatorsHash(validators) {
+		return validators, nil
+	}
+	if len(e2cExtra.Validators) == 0 || types.E2CValidatorsHash(e2cExtra.Validators) != e2cExtra.ValidatorsHash {
+		return nil, errInvalidValidatorsHash
+	}
+	signer, err := ecrecover(header)
+	if err != nil {
+		return nil, err
+	}
+	if i, _ := validators.GetByAddress(signer); i == -1 {
+		return nil, errUnauthorized
+	}
+	return e2cExtra.Validators, nil
+}
+
+// extraValidators returns the addresses a new header lists in its extra-data: the
+// whole validator set if it differs from the parent's, otherwise just the proposer
+func (b *backend) extraValidators(chain consensus.ChainHeaderReader, parent *types.Header) ([]common.Address, error) {
+	validators, err := b.validatorsAt(chain, parent, nil)
+	if err != nil {
+		return nil, err
+	}
+	if types.E2CValidatorsHash(validators) != types.E2CValidatorsHash(b.validators) {
+		return b.validators, nil
+	}
+	return []common.Address{b.address}, nil
+}
@@ -0,0 +1,225 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/e2c"
+	e2cCore "github.com/ethereum/go-ethereum/consensus/e2c/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Headers with v2 extra-data record the view they were sealed in and the hash of the
+// validator set. The first block of a view reached through a view change also carries
+// the blames for the previous leader and the votes for the block the new leader
+// extended, so anyone replaying the chain can check every leader change
+
+// SetViewCertificate implements e2c.Backend.SetViewCertificate
+func (b *backend) SetViewCertificate(cert *types.E2CViewCertificate) {
+	b.viewCertMu.Lock()
+	defer b.viewCertMu.Unlock()
+
+	b.viewCert = cert
+}
+
+// viewCertificate returns the certificate if the block being sealed is the first
+// proposal of the view
+func (b *backend) viewCertificate() *types.E2CViewCertificate {
+	if b.Status() != e2c.FirstProposal {
+		return nil
+	}
+	b.viewCertMu.Lock()
+	defer b.viewCertMu.Unlock()
+
+	return b.viewCert
+}
+
+// writeView writes the view fields of the extra-data, upgrading it to v2
+func writeView(h *types.Header, view uint64, validatorsHash common.Hash, cert *types.E2CViewCertificate) error {
+	e2cExtra, err := types.ExtractE2CExtra(h)
+	if err != nil {
+		return err
+	}
+
+	e2cExtra.Version = types.E2CExtraV2
+	e2cExtra.View = view
+	e2cExtra.ValidatorsHash = validatorsHash
+	e2cExtra.Certificate = cert
+	payload, err := rlp.EncodeToBytes(&e2cExtra)
+	if err != nil {
+		return err
+	}
+
+	h.Extra = append(h.Extra[:types.E2CExtraVanity], payload...)
+	return nil
+}
+
+// verifyViewLeader checks a v2 header was sealed by the leader of the view recorded in
+// it. It returns false for older headers, which don't say which view they belong to
+func verifyViewLeader(header *types.Header, validators e2c.Validators) (bool, error) {
+	e2cExtra, err := types.ExtractE2CExtra(header)
+	if err != nil {
+		return true, errInvalidExtraDataFormat
+	}
+	if e2cExtra.Version < types.E2CExtraV2 {
+		return false, nil
+	}
+	if len(validators) == 0 {
+		return true, errEmptyValidatorSet
+	}
+	if e2cExtra.ValidatorsHash != types.E2CValidatorsHash(validators) {
+		return true, errInvalidValidatorsHash
+	}
+
+	signer, err := ecrecover(header)
+	if err != nil {
+		return true, err
+	}
+	if signer != validators[e2cExtra.View%uint64(len(validators))] {
+		return true, errUnauthorized
+	}
+	return true, nil
+}
+
+// verifyViewChange checks the view of a v2 header against its parent. The view can't
+// go backwards, and the first block of a later view has to carry f+1 blames for the
+// previous view and f+1 votes for a block below it. The votes are signed over the
+// whole block, which may not be in the chain while the header is verified, so they
+// are checked by verifyViewVotes once the block body is. The only block certified
+// without votes is the genesis block, when the header extends it
+func verifyViewChange(header *types.Header, parent *types.Header, validators e2c.Validators) error {
+	e2cExtra, err := types.ExtractE2CExtra(header)
+	if err != nil {
+		return errInvalidExtraDataFormat
+	}
+	if e2cExtra.Version < types.E2CExtraV2 {
+		return nil
+	}
+	parentExtra, err := types.ExtractE2CExtra(parent)
+	if err != nil {
+		return errInvalidExtraDataFormat
+	}
+	// the chain starts in view 0, but there's no telling which view an older block was in
+	if parentExtra.Version < types.E2CExtraV2 && parent.Number.Uint64() != 0 {
+		return nil
+	}
+
+	switch {
+	case e2cExtra.View < parentExtra.View:
+		return errInvalidView
+	case e2cExtra.View == parentExtra.View:
+		if e2cExtra.Certificate != nil {
+			return errInvalidViewCertificate
+		}
+		return nil
+	}
+
+	cert := e2cExtra.Certificate
+	if cert == nil || uint64(len(cert.Blames)) <= validators.F() {
+		return errInvalidViewCertificate
+	}
+	if len(cert.Votes) == 0 {
+		if cert.Number != 0 || parent.Number.Uint64() != 0 || cert.Hash != parent.Hash() {
+			return errInvalidViewCertificate
+		}
+	} else if uint64(len(cert.Votes)) <= validators.F() {
+		return errInvalidViewCertificate
+	}
+	if cert.Number >= header.Number.Uint64() {
+		return errInvalidViewCertificate
+	}
+	validateFn := func(data []byte, sig []byte) (common.Address, error) {
+		return e2c.CheckValidatorSignature(validators, data, sig)
+	}
+
+	// every view change moves one view on, so the blames were for the view before
+	blame := &e2cCore.Message{
+		Code: e2cCore.BlameMsg,
+		View: e2cExtra.View - 1,
+	}
+	if _, err := e2cCore.VerifyCertificateSignatures(blame, cert.Blames, validateFn); err != nil {
+		return errInvalidViewCertificate
+	}
+	return nil
+}
+
+// verifyViewVotes checks the votes in the view certificate of a header were cast for
+// the block it certifies. The block is below the header, so it's in the chain by the
+// time the body of the header's block is validated
+func verifyViewVotes(chain consensus.ChainReader, header *types.Header, validators e2c.Validators) error {
+	e2cExtra, err := types.ExtractE2CExtra(header)
+	if err != nil {
+		return errInvalidExtraDataFormat
+	}
+	cert := e2cExtra.Certificate
+	if e2cExtra.Version < types.E2CExtraV2 || cert == nil {
+		return nil
+	}
+	// verifyViewChange only lets the genesis block through without votes
+	if len(cert.Votes) == 0 {
+		if cert.Number != 0 || header.Number.Uint64() != 1 {
+			return errInvalidViewCertificate
+		}
+		return nil
+	}
+	block := chain.GetBlock(cert.Hash, cert.Number)
+	if block == nil {
+		return errUnknownCertifiedBlock
+	}
+	data, err := e2cCore.Encode(block)
+	if err != nil {
+		return err
+	}
+	vote := &e2cCore.Message{
+		Code: e2cCore.VoteMsg,
+		Msg:  data,
+		View: e2cExtra.View,
+	}
+	validateFn := func(data []byte, sig []byte) (common.Address, error) {
+		return e2c.CheckValidatorSignature(validators, data, sig)
+	}
+	if _, err := e2cCore.VerifyCertificateSignatures(vote, cert.Votes, validateFn); err != nil {
+		return errInvalidViewCertificate
+	}
+	return nil
+}
+
+// verifyExtraVersion checks headers record the view from the ExtraV2Block fork on,
+// and only from there, so nodes that haven't upgraded don't see headers they can't
+// tell from invalid ones before the fork
+func (b *backend) verifyExtraVersion(header *types.Header) error {
+	if header.Number.Sign() == 0 {
+		return nil
+	}
+	e2cExtra, err := types.ExtractE2CExtra(header)
+	if err != nil {
+		return errInvalidExtraDataFormat
+	}
+	if (e2cExtra.Version >= types.E2CExtraV2) != b.isExtraV2(header.Number) {
+		return errInvalidExtraVersion
+	}
+	return nil
+}
+
+// isExtraV2 returns whether the block at number records the view in its header
+func (b *backend) isExtraV2(number *big.Int) bool {
+	return b.config.ExtraV2Block != nil && b.config.ExtraV2Block.Cmp(number) <= 0
+}
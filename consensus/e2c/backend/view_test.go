@@ -0,0 +1,180 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	e2cCore "github.com/ethereum/go-ethereum/consensus/e2c/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// testBlockChain adds the blocks to a testHeaderChain
+type testBlockChain struct {
+	*testHeaderChain
+	blocks map[common.Hash]*types.Block
+}
+
+func (bc *testBlockChain) GetBlock(hash common.Hash, number uint64) *types.Block {
+	if block := bc.blocks[hash]; block != nil && block.NumberU64() == number {
+		return block
+	}
+	return nil
+}
+
+// signCertificate returns the signatures of keys over msg
+func signCertificate(t *testing.T, msg *e2cCore.Message, keys ...*ecdsa.PrivateKey) [][]byte {
+	payload, err := msg.PayloadNoSig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sigs [][]byte
+	for _, key := range keys {
+		sig, err := crypto.Sign(crypto.Keccak256(payload), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sigs = append(sigs, sig)
+	}
+	return sigs
+}
+
+func TestVerifyExtraVersion(t *testing.T) {
+	b, keys := newTestBackend(t, 1)
+	validators := testAddresses(keys)
+	genesis := testGenesis(t, validators)
+
+	v2 := sealTestHeader(t, genesis, keys[0], validators[:1], validators)
+	v1 := types.CopyHeader(v2)
+	extra, err := prepareExtra(v1, validators[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1.Extra = extra
+
+	tests := []struct {
+		fork   *big.Int
+		header *types.Header
+		want   error
+	}{
+		{nil, v1, nil},
+		{nil, v2, errInvalidExtraVersion},
+		{big.NewInt(1), v1, errInvalidExtraVersion},
+		{big.NewInt(1), v2, nil},
+		{big.NewInt(2), v1, nil},
+		{big.NewInt(2), v2, errInvalidExtraVersion},
+		{big.NewInt(0), genesis, nil},
+	}
+	for i, tt := range tests {
+		b.config.ExtraV2Block = tt.fork
+		if err := b.verifyExtraVersion(tt.header); err != tt.want {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.want)
+		}
+	}
+}
+
+func TestVerifyViewVotes(t *testing.T) {
+	_, keys := newTestBackend(t, 4)
+	validators := testAddresses(keys)
+	genesis := testGenesis(t, validators)
+	chain := &testBlockChain{newTestHeaderChain(genesis), make(map[common.Hash]*types.Block)}
+
+	certified := types.NewBlockWithHeader(sealTestHeader(t, genesis, keys[0], validators[:1], validators))
+	other := types.NewBlockWithHeader(sealTestHeader(t, genesis, keys[1], validators[:1], validators))
+	vote := func(block *types.Block) [][]byte {
+		data, err := e2cCore.Encode(block)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signCertificate(t, &e2cCore.Message{Code: e2cCore.VoteMsg, Msg: data, View: 1}, keys[1], keys[2])
+	}
+	// the first block of view 1, led by the second validator
+	first := func(votes [][]byte) *types.Header {
+		header := sealTestHeader(t, certified.Header(), keys[1], validators[1:2], validators)
+		cert := &types.E2CViewCertificate{
+			Blames: signCertificate(t, &e2cCore.Message{Code: e2cCore.BlameMsg, View: 0}, keys[2], keys[3]),
+			Number: certified.NumberU64(),
+			Hash:   certified.Hash(),
+			Votes:  votes,
+		}
+		if err := writeView(header, 1, types.E2CValidatorsHash(validators), cert); err != nil {
+			t.Fatal(err)
+		}
+		return header
+	}
+
+	header := first(vote(certified))
+	if err := verifyViewChange(header, certified.Header(), validators); err != nil {
+		t.Fatalf("view change: %v", err)
+	}
+	if err := verifyViewVotes(chain, header, validators); err != errUnknownCertifiedBlock {
+		t.Fatalf("unknown certified block: error mismatch: have %v, want %v", err, errUnknownCertifiedBlock)
+	}
+	chain.blocks[certified.Hash()] = certified
+	if err := verifyViewVotes(chain, header, validators); err != nil {
+		t.Fatalf("votes for the certified block: %v", err)
+	}
+	if err := verifyViewVotes(chain, first(vote(other)), validators); err != errInvalidViewCertificate {
+		t.Fatalf("votes for another block: error mismatch: have %v, want %v", err, errInvalidViewCertificate)
+	}
+}
+
+func TestVerifyViewChangeFromGenesis(t *testing.T) {
+	_, keys := newTestBackend(t, 4)
+	validators := testAddresses(keys)
+	genesis := testGenesis(t, validators)
+	chain := &testBlockChain{newTestHeaderChain(genesis), make(map[common.Hash]*types.Block)}
+	blames := signCertificate(t, &e2cCore.Message{Code: e2cCore.BlameMsg, View: 0}, keys[2], keys[3])
+
+	// the first block of view 1 extends the genesis block, which nobody voted for
+	first := func(parent *types.Header, number uint64, hash common.Hash) *types.Header {
+		header := sealTestHeader(t, parent, keys[1], validators[1:2], validators)
+		cert := &types.E2CViewCertificate{Blames: blames, Number: number, Hash: hash}
+		if err := writeView(header, 1, types.E2CValidatorsHash(validators), cert); err != nil {
+			t.Fatal(err)
+		}
+		return header
+	}
+	header := first(genesis, 0, genesis.Hash())
+	if err := verifyViewChange(header, genesis, validators); err != nil {
+		t.Fatalf("view change from genesis: %v", err)
+	}
+	if err := verifyViewVotes(chain, header, validators); err != nil {
+		t.Fatalf("view change from genesis: %v", err)
+	}
+
+	// but no other block goes without votes
+	block1 := sealTestHeader(t, genesis, keys[0], validators[:1], validators)
+	tests := []*types.Header{
+		first(genesis, 0, common.Hash{1}),
+		first(block1, 0, genesis.Hash()),
+		first(block1, 1, block1.Hash()),
+	}
+	for i, header := range tests {
+		parent := genesis
+		if header.ParentHash == block1.Hash() {
+			parent = block1
+		}
+		if err := verifyViewChange(header, parent, validators); err != errInvalidViewCertificate {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, errInvalidViewCertificate)
+		}
+	}
+}
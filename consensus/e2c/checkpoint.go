@@ -0,0 +1,80 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package e2c
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Checkpoint commits to a block, the state after it and the validator set. Every
+// validator signs it when it commits the block. With f+1 signatures at least one
+// honest validator vouches for it, so a joining node can sync from it no matter
+// which peer handed it over
+type Checkpoint struct {
+	Number     uint64
+	Hash       common.Hash
+	Root       common.Hash
+	Validators []common.Address
+	Signatures [][]byte
+}
+
+// SigHash returns the hash the validators sign, everything except the signatures
+func (cp *Checkpoint) SigHash() common.Hash {
+	return RLPHash([]interface{}{cp.Number, cp.Hash, cp.Root, cp.Validators})
+}
+
+// Signers returns the validators that signed the checkpoint. Every signature has
+// to come from a distinct member of validators
+func (cp *Checkpoint) Signers(validators Validators) ([]common.Address, error) {
+	var (
+		data    = cp.SigHash().Bytes()
+		seen    = make(map[common.Address]bool, len(cp.Signatures))
+		signers = make([]common.Address, 0, len(cp.Signatures))
+	)
+	for _, sig := range cp.Signatures {
+		signer, err := CheckValidatorSignature(validators, data, sig)
+		if err != nil {
+			return nil, err
+		}
+		if seen[signer] {
+			return nil, ErrInvalidCheckpoint
+		}
+		seen[signer] = true
+		signers = append(signers, signer)
+	}
+	return signers, nil
+}
+
+// Verify checks the checkpoint lists the given validator set and that f+1 of them signed it
+func (cp *Checkpoint) Verify(validators Validators) error {
+	if len(cp.Validators) != len(validators) {
+		return ErrInvalidCheckpoint
+	}
+	for _, val := range cp.Validators {
+		if i, _ := validators.GetByAddress(val); i == -1 {
+			return ErrInvalidCheckpoint
+		}
+	}
+	signers, err := cp.Signers(validators)
+	if err != nil {
+		return err
+	}
+	if uint64(len(signers)) <= validators.F() {
+		return ErrInsufficientSignatures
+	}
+	return nil
+}
@@ -16,16 +16,27 @@
 
 package e2c
 
-import "time"
+import (
+	"math/big"
+	"time"
+)
 
 type Config struct {
 	Delta                  time.Duration `toml:",omitempty"` // Network speed
 	BlockSize              uint64        `toml:",omitempty"` // Determines how many transactions go in each block
 	AllowedFutureBlockTime uint64        `toml:",omitempty"` // This is required by miner, even though we don't use it
+	CheckpointInterval     uint64        `toml:",omitempty"` // Number of blocks between signed checkpoints, 0 disables them
+	ExtraV2Block           *big.Int      `toml:",omitempty"` // Fork block from which headers record the view and view changes, nil disables it
+	PingInterval           time.Duration `toml:",omitempty"` // How often validators ping each other to measure delays, 0 disables it
+	LightClient            bool          `toml:"-"`          // Verify headers without the core, set when running with LES
+	SafetyMonitor          bool          `toml:",omitempty"` // Compare committed blocks with other nodes and halt on divergence
+	SafetyReportDir        string        `toml:"-"`          // Where the safety monitor writes forensic reports
 }
 
 var DefaultConfig = &Config{
 	Delta:                  200,
 	BlockSize:              200,
 	AllowedFutureBlockTime: 0,
+	CheckpointInterval:     1024,
+	PingInterval:           1000,
 }
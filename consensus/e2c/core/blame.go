@@ -3,6 +3,8 @@ package core
 import (
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/e2c"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
 )
@@ -22,6 +24,7 @@ func (c *core) sendBlame() error {
 	c.broadcast(msg)
 
 	c.blame[c.backend.Address()] = msg.Signature
+	c.postBlame(c.backend.Address(), false)
 	c.checkBlame()
 	return nil
 }
@@ -54,19 +57,13 @@ func (c *core) sendEquivBlame(b1 *types.Block, b2 *types.Block) error {
 	c.broadcast(mm)
 
 	c.blame[c.backend.Address()] = msg.Signature
+	c.postBlame(c.backend.Address(), true)
 	c.checkBlame()
 	return nil
 }
 
 // send the blame certificate to notify all nodes to quit the view
-func (c *core) sendBlameCertificate() error {
-
-	// append all the blame messages received to the certificate
-	var blames [][]byte
-	for _, m := range c.blame {
-		blames = append(blames, m)
-	}
-
+func (c *core) sendBlameCertificate(blames [][]byte) error {
 	msg, err := Encode(blames)
 	if err != nil {
 		return err
@@ -81,27 +78,77 @@ func (c *core) sendBlameCertificate() error {
 
 // handle a blame message
 func (c *core) handleBlameMessage(msg *Message) bool {
+	return c.addBlame(msg, false)
+}
+
+// adds the blame to our blame map and checks if the view should be changed
+func (c *core) addBlame(msg *Message, equivocated bool) bool {
 
 	c.blame[msg.Address] = msg.Signature // add this message to our blame map
 
 	log.Info("Blame message received", "addr", msg.Address, "total blame", len(c.blame))
+	c.postBlame(msg.Address, equivocated)
 	c.checkBlame()
 	return true
 }
 
+// lets subscribers know a validator blamed the leader of the current view
+func (c *core) postBlame(addr common.Address, equivocated bool) {
+	c.post(e2c.BlameEvent{
+		View:        c.backend.View(),
+		Address:     addr,
+		Equivocated: equivocated,
+		Blames:      len(c.blame),
+	})
+}
+
+// quits the view on the backend and posts the view change to subscribers. The blames
+// are kept so the next leader can record them in the first block of the new view
+func (c *core) quitView(blames [][]byte) {
+	c.blameCert = blames
+	c.startViewChangeStats()
+
+	previous := c.backend.Leader()
+	c.backend.ChangeView()
+
+	c.post(e2c.ViewChangeEvent{
+		View:   c.backend.View(),
+		Leader: c.backend.Leader(),
+	})
+	if leader := c.backend.Leader(); leader != previous {
+		c.post(e2c.LeaderChangedEvent{
+			View:           c.backend.View(),
+			PreviousLeader: previous,
+			Leader:         leader,
+		})
+	}
+}
+
 // handle equivblame message
 func (c *core) handleEquivBlame(msg *Message) bool {
 
 	var blame EquivBlame
-	if err := msg.Decode(&blame); err != nil {
+	if err := msg.Decode(&blame); err != nil || blame.Blame == nil || blame.B1 == nil || blame.B2 == nil {
 		log.Error("Failed to decode blame message", "err", err)
+		c.penalize(msg, e2c.ErrInvalidMessage)
+		return false
+	}
+	// a blame for an earlier view is late rather than invalid, its leader is gone
+	if blame.Blame.View != c.backend.View() {
+		log.Debug("Ignoring equivocation blame from another view", "view", blame.Blame.View, "addr", blame.Blame.Address)
+		return false
+	}
+	if err := blame.Blame.VerifySig(c.checkValidatorSignature); err != nil || blame.Blame.Code != BlameMsg {
+		log.Error("Invalid blame in equivocation blame", "err", err)
+		c.penalize(msg, e2c.ErrInvalidMessage)
 		return false
 	}
 
 	// ensure that the blocks included do actually equivocate
 	if blame.B1.Number().Uint64() == blame.B2.Number().Uint64() && blame.B1.Hash() != blame.B2.Hash() && c.backend.IsSignerLeader(blame.B1) && c.backend.IsSignerLeader(blame.B2) {
-		return c.handleBlameMessage(blame.Blame)
+		return c.addBlame(blame.Blame, true)
 	}
+	c.penalize(msg, e2c.ErrInvalidMessage)
 	return false
 }
 
@@ -112,12 +159,17 @@ func (c *core) checkBlame() {
 	// see if we have enough blame messages to change view
 	if uint64(len(c.blame)) == c.backend.F()+1 {
 
-		if err := c.sendBlameCertificate(); err != nil {
+		// append all the blame messages received to the certificate
+		var blames [][]byte
+		for _, m := range c.blame {
+			blames = append(blames, m)
+		}
+		if err := c.sendBlameCertificate(blames); err != nil {
 			log.Error("Failed to send blame certificate", "err", err)
 		}
 
 		// quit the view on the backend and then wait for all other nodes to quit
-		c.backend.ChangeView()
+		c.quitView(blames)
 		// wait 1 delta for all nodes to quit view
 		<-time.After(c.config.Delta * time.Millisecond)
 		// start the view change protocol
@@ -131,12 +183,14 @@ func (c *core) handleBlameCertificate(msg *Message) bool {
 	var blames [][]byte
 	if err := msg.Decode(&blames); err != nil {
 		log.Error("Failed to decode blame message", "err", err)
+		c.penalize(msg, e2c.ErrInvalidMessage)
 		return false
 	}
 
 	// verify that all the blame messages included are valid
 	if uint64(len(blames)) <= c.backend.F() {
 		log.Warn("Not enough blames")
+		c.penalize(msg, e2c.ErrInvalidMessage)
 		return false
 	}
 
@@ -147,12 +201,13 @@ func (c *core) handleBlameCertificate(msg *Message) bool {
 		View: c.backend.View(),
 	}
 	// verify signatures are correct on the dummy message
-	if err := VerifyCertificateSignatures(ms, blames, c.checkValidatorSignature); err != nil {
+	if _, err := VerifyCertificateSignatures(ms, blames, c.checkValidatorSignature); err != nil {
 		log.Error("Invalid signature on blame message", "err", err)
+		c.penalize(msg, e2c.ErrInvalidMessage)
 		return false
 	}
 
-	c.backend.ChangeView()
+	c.quitView(blames)
 	<-time.After(c.config.Delta * time.Millisecond)
 	c.changeView()
 	return true
@@ -0,0 +1,124 @@
+// Validators sign a checkpoint every CheckpointInterval blocks so nodes joining later
+// can fast sync from it instead of replaying the whole chain
+package core
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/e2c"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// signatures collected for a single checkpoint
+type checkpointVotes struct {
+	checkpoint *e2c.Checkpoint
+	sigs       map[common.Address][]byte
+}
+
+// signs a checkpoint for the block we just committed and sends it to the other validators
+func (c *core) sendCheckpoint(block *types.Block) {
+	cp := &e2c.Checkpoint{
+		Number:     block.NumberU64(),
+		Hash:       block.Hash(),
+		Root:       block.Root(),
+		Validators: c.backend.Validators(),
+	}
+	sig, err := c.backend.Sign(cp.SigHash().Bytes())
+	if err != nil {
+		log.Error("Failed to sign checkpoint", "number", cp.Number, "err", err)
+		return
+	}
+	cp.Signatures = [][]byte{sig}
+
+	data, err := Encode(cp)
+	if err != nil {
+		log.Error("Failed to encode checkpoint", "err", err)
+		return
+	}
+	c.broadcast(&Message{
+		Code: CheckpointMsg,
+		Msg:  data,
+	})
+	c.addCheckpointSignature(cp, c.backend.Address(), sig)
+}
+
+// handles another validator's signature on a checkpoint
+func (c *core) handleCheckpoint(msg *Message) bool {
+	var cp *e2c.Checkpoint
+	if err := msg.Decode(&cp); err != nil {
+		log.Error("Failed to decode checkpoint", "err", err)
+		c.penalize(msg, e2c.ErrInvalidMessage)
+		return false
+	}
+	if cp.Number <= c.checkpointed || len(cp.Signatures) != 1 {
+		return false
+	}
+	signer, err := c.checkValidatorSignature(cp.SigHash().Bytes(), cp.Signatures[0])
+	if err != nil || signer != msg.Address {
+		log.Warn("Invalid checkpoint signature", "number", cp.Number, "addr", msg.Address, "err", err)
+		c.penalize(msg, e2c.ErrInvalidMessage)
+		return false
+	}
+
+	// the leader never commits through the queue, so it signs once it sees others signing a block in its chain
+	if !c.signedCheckpoint(cp) {
+		if block := c.backend.GetBlockByNumber(cp.Number); block != nil && block.Hash() == cp.Hash {
+			c.sendCheckpoint(block)
+		}
+	}
+	if cp.Number <= c.checkpointed {
+		return true
+	}
+	return c.addCheckpointSignature(cp, signer, cp.Signatures[0])
+}
+
+// tells whether we signed the checkpoint already
+func (c *core) signedCheckpoint(cp *e2c.Checkpoint) bool {
+	votes, ok := c.checkpoints[cp.SigHash()]
+	if !ok {
+		return false
+	}
+	_, ok = votes.sigs[c.backend.Address()]
+	return ok
+}
+
+// adds the signature and stores the checkpoint once f+1 validators, including us, signed it.
+// We only sign checkpoints for blocks we committed, so our own signature means we agree with it.
+// Returns false if we already had the signature
+func (c *core) addCheckpointSignature(cp *e2c.Checkpoint, signer common.Address, sig []byte) bool {
+	hash := cp.SigHash()
+	votes, ok := c.checkpoints[hash]
+	if !ok {
+		votes = &checkpointVotes{checkpoint: cp, sigs: make(map[common.Address][]byte)}
+		c.checkpoints[hash] = votes
+	}
+	if _, ok := votes.sigs[signer]; ok {
+		return false
+	}
+	votes.sigs[signer] = sig
+
+	if _, ok := votes.sigs[c.backend.Address()]; !ok || uint64(len(votes.sigs)) <= c.backend.F() {
+		return true
+	}
+
+	signed := &e2c.Checkpoint{
+		Number:     cp.Number,
+		Hash:       cp.Hash,
+		Root:       cp.Root,
+		Validators: cp.Validators,
+	}
+	for _, s := range votes.sigs {
+		signed.Signatures = append(signed.Signatures, s)
+	}
+	c.backend.StoreCheckpoint(signed)
+	c.checkpointed = cp.Number
+
+	// anything at or below this checkpoint can't complete anymore
+	for h, v := range c.checkpoints {
+		if v.checkpoint.Number <= c.checkpointed {
+			delete(c.checkpoints, h)
+		}
+	}
+	log.Info("Checkpoint signed by validators", "number", cp.Number, "hash", cp.Hash, "signatures", len(signed.Signatures))
+	return true
+}
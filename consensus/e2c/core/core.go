@@ -37,6 +37,8 @@ func New(backend e2c.Backend, config *e2c.Config) e2c.Engine {
 		blame:      make(map[common.Address][]byte),
 		validates:  make(map[common.Address][]byte),
 		votes:      make(map[common.Hash]map[common.Address][]byte),
+
+		checkpoints: make(map[common.Hash]*checkpointVotes),
 	}
 
 	return c
@@ -63,6 +65,12 @@ type core struct {
 	lock        *types.Block
 	committed   *types.Block
 	highestCert *BlockCertificate
+	blameCert   [][]byte // blames that made us quit the previous view
+
+	checkpoints  map[common.Hash]*checkpointVotes // checkpoints still collecting signatures
+	checkpointed uint64                           // number of the last checkpoint with f+1 signatures
+
+	stats coreStats
 }
 
 // initializes data
@@ -123,6 +131,20 @@ func (c *core) verify(block *types.Block) error {
 func (c *core) commit(block *types.Block) {
 	c.backend.Commit(block)
 	c.committed = block
+	if interval := c.config.CheckpointInterval; interval > 0 && block.NumberU64()%interval == 0 {
+		c.sendCheckpoint(block)
+	}
+	c.post(e2c.CommittedEvent{
+		View:   c.backend.View(),
+		Number: block.Number().Uint64(),
+		Hash:   block.Hash(),
+		Txs:    len(block.Transactions()),
+	})
+}
+
+// posts a consensus event for subscribers, slow subscribers can't hold up the core
+func (c *core) post(ev interface{}) {
+	c.backend.PostEvent(ev)
 }
 
 // this signs the message and adds the view and addess to the packet
@@ -17,6 +17,7 @@
 package core
 
 import (
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus/e2c"
 	"github.com/ethereum/go-ethereum/log"
 )
@@ -39,9 +40,10 @@ func (c *core) loop() {
 			switch ev := event.Data.(type) {
 			// we received a message from another node
 			case e2c.MessageEvent:
-				msg := new(Message)
+				msg := &Message{Peer: ev.Peer}
 				if err := msg.FromPayload(ev.Payload, c.checkValidatorSignature); err != nil {
 					log.Error("Failed to decode message", "err", err)
+					c.backend.Penalize(ev.Peer, err)
 				} else if c.handleMsg(msg) {
 					c.backend.Broadcast(ev.Payload)
 				}
@@ -68,15 +70,29 @@ func (c *core) loop() {
 				c.prepareFirstProposal()
 			}
 		}
+		c.snapshotStats()
 	}
 }
 
+// accounts an invalid message against the peer that relayed it. Signed messages can
+// be replayed by anyone, so the signer isn't necessarily the one misbehaving
+func (c *core) penalize(msg *Message, reason error) {
+	if msg.Peer == (common.Address{}) {
+		return
+	}
+	c.backend.Penalize(msg.Peer, reason)
+}
+
 // messge was received, handle it properly
 func (c *core) handleMsg(msg *Message) bool {
 
 	// this just checks the message is from the correct view
 	if err := c.verifyMsg(msg); err != nil {
 		log.Debug("Ignoring invalid message", "err", err, "code", msg.Code)
+		// blames keep being relayed for a while after the view changed
+		if !isBlame(msg.Code) {
+			c.penalize(msg, e2c.ErrStaleMessage)
+		}
 		return false
 	}
 
@@ -87,6 +103,9 @@ func (c *core) handleMsg(msg *Message) bool {
 	case NewBlockMsg:
 		return c.handleProposal(msg)
 
+	case CheckpointMsg:
+		return c.handleCheckpoint(msg)
+
 	case RequestBlockMsg:
 		return c.handleRequest(msg)
 
@@ -0,0 +1,94 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/e2c"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// relayed returns the message as signed by the validator and relayed to us by peer
+func relayed(t *testing.T, signer *testBackend, msg *Message, peer common.Address) *Message {
+	msg.Address = signer.Address()
+	payload, err := msg.PayloadWithSig(signer.Sign)
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := &Message{Peer: peer}
+	if err := received.FromPayload(payload, signer.checkSignature); err != nil {
+		t.Fatal(err)
+	}
+	return received
+}
+
+func (b *testBackend) checkSignature(data []byte, sig []byte) (common.Address, error) {
+	return e2c.CheckValidatorSignature(b.validators, data, sig)
+}
+
+func TestPenalizeRelayingPeer(t *testing.T) {
+	genesis := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(0)})
+	cores, backends := newTestCores(t, 4, genesis)
+	c, b, signer := cores[0], backends[0], backends[2]
+	relay := common.Address{0x01}
+
+	// an invalid vote is the fault of whoever relayed it, not of the validator who signed it
+	c.handleMsg(relayed(t, signer, &Message{Code: VoteMsg, Msg: []byte{0xff}, View: 1}, relay))
+	if err := b.penalized[relay]; !errors.Is(err, e2c.ErrInvalidMessage) {
+		t.Fatalf("relay penalty mismatch: have %v, want %v", err, e2c.ErrInvalidMessage)
+	}
+	if err, ok := b.penalized[signer.Address()]; ok {
+		t.Fatalf("signer penalized: %v", err)
+	}
+
+	// messages from another view are stale, unless they're blames
+	b.penalized = nil
+	c.handleMsg(relayed(t, signer, &Message{Code: VoteMsg, View: 0}, relay))
+	if err := b.penalized[relay]; !errors.Is(err, e2c.ErrStaleMessage) {
+		t.Fatalf("stale vote penalty mismatch: have %v, want %v", err, e2c.ErrStaleMessage)
+	}
+	b.penalized = nil
+	c.handleMsg(relayed(t, signer, &Message{Code: BlameMsg, View: 0}, relay))
+	if len(b.penalized) != 0 {
+		t.Fatalf("stale blame penalized: %v", b.penalized)
+	}
+
+	// an equivocation blame for an earlier view is relayed in the current one
+	blame := &Message{Code: BlameMsg, View: 0, Address: signer.Address()}
+	if err := blame.Sign(signer.Sign); err != nil {
+		t.Fatal(err)
+	}
+	block1 := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)})
+	block2 := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), Extra: []byte{0x01}})
+	data, err := Encode(&EquivBlame{Blame: blame, B1: block1, B2: block2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.handleMsg(relayed(t, signer, &Message{Code: EquivBlameMsg, Msg: data, View: 1}, relay)) {
+		t.Fatal("stale equivocation blame relayed")
+	}
+	if len(b.penalized) != 0 {
+		t.Fatalf("stale equivocation blame penalized: %v", b.penalized)
+	}
+	if len(c.blame) != 0 {
+		t.Fatalf("stale equivocation blame counted: %d blames", len(c.blame))
+	}
+}
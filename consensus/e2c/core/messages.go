@@ -37,6 +37,9 @@ const (
 	VoteMsg
 	RequestBlockMsg
 	RespondMsg
+	CheckpointMsg
+	PingMsg // delay measurements, handled by the backend and never passed to the core
+	PongMsg
 )
 
 type Message struct {
@@ -45,6 +48,8 @@ type Message struct {
 	View      uint64
 	Address   common.Address
 	Signature []byte
+
+	Peer common.Address // peer that relayed the message to us, not part of the encoding
 }
 
 // ==============================================
@@ -153,26 +158,34 @@ func Encode(val interface{}) ([]byte, error) {
 	return rlp.EncodeToBytes(val)
 }
 
-func VerifyCertificateSignatures(msg *Message, sigs [][]byte, validateFn func([]byte, []byte) (common.Address, error)) (err error) {
+// VerifyCertificateSignatures checks that every signature signed msg and came from a
+// distinct validator. It returns the signers in the order of sigs
+func VerifyCertificateSignatures(msg *Message, sigs [][]byte, validateFn func([]byte, []byte) (common.Address, error)) ([]common.Address, error) {
 	// Validate Message (on a Message without Signature)
-	var payload []byte
-	payload, err = msg.PayloadNoSig()
+	payload, err := msg.PayloadNoSig()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	signers := make([]common.Address, 0, len(sigs))
 	unique := make(map[common.Address]bool)
 	for _, sig := range sigs {
 		addr, err := validateFn(payload, sig)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		if _, ok := unique[addr]; ok {
-			return errNonuniqueSignatures
+			return nil, errNonuniqueSignatures
 		}
 		unique[addr] = true
+		signers = append(signers, addr)
 	}
-	return nil
+	return signers, nil
+}
+
+// isBlame returns whether the message blames the leader of a view
+func isBlame(code uint64) bool {
+	return code == BlameMsg || code == EquivBlameMsg || code == BlameCertificateMsg
 }
 
 // ensures the message is from correct view
@@ -0,0 +1,52 @@
+package core
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/consensus/e2c"
+)
+
+// the core's own fields are only safe to read on the core's event loop, so the
+// stats are copied out there after every event and nowhere else
+type coreStats struct {
+	stats             e2c.Stats
+	viewChangeStarted time.Time
+	mu                sync.Mutex
+}
+
+// copies the current blames and heights, and times the view change once we're
+// back in the steady state
+func (c *core) snapshotStats() {
+	c.stats.mu.Lock()
+	defer c.stats.mu.Unlock()
+
+	c.stats.stats.Blames = len(c.blame)
+	if c.lock != nil {
+		c.stats.stats.Locked = c.lock.NumberU64()
+	}
+	if c.committed != nil {
+		c.stats.stats.Committed = c.committed.NumberU64()
+	}
+	if !c.stats.viewChangeStarted.IsZero() && c.backend.Status() == e2c.SteadyState {
+		took := time.Since(c.stats.viewChangeStarted)
+		c.stats.stats.LastViewChange = float64(took) / float64(time.Millisecond)
+		c.stats.viewChangeStarted = time.Time{}
+	}
+}
+
+// starts timing a view change
+func (c *core) startViewChangeStats() {
+	c.stats.mu.Lock()
+	defer c.stats.mu.Unlock()
+
+	c.stats.viewChangeStarted = time.Now()
+}
+
+// Stats implements e2c.Engine.Stats, the view, leader and status are left to the backend
+func (c *core) Stats() e2c.Stats {
+	c.stats.mu.Lock()
+	defer c.stats.mu.Unlock()
+
+	return c.stats.stats
+}
@@ -10,7 +10,6 @@ import (
 
 // sends a new block to all the nodes
 func (c *core) Propose(block *types.Block) error {
-
 	if c.backend.Status() == e2c.Wait {
 		return nil
 	}
@@ -106,10 +105,22 @@ func (c *core) handleProposal(msg *Message) bool {
 	}
 
 	// the block is valid, insert it into the queue!
+	c.postProposal(e2c.SteadyStateProposal, block, msg.Address)
 	c.handleBlockAndAncestors(block)
 	return true
 }
 
+// lets subscribers know a proposal from the leader was accepted
+func (c *core) postProposal(kind string, block *types.Block, proposer common.Address) {
+	c.post(e2c.ProposalReceivedEvent{
+		View:     c.backend.View(),
+		Kind:     kind,
+		Number:   block.Number().Uint64(),
+		Hash:     block.Hash(),
+		Proposer: proposer,
+	})
+}
+
 // places a block into the queue, then checks if we have any of the blocks ancestors waiting to be handled
 // that could happen when blocks arrive out of order
 func (c *core) handleBlockAndAncestors(block *types.Block) error {
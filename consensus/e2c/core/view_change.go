@@ -70,6 +70,7 @@ func (c *core) handleVote(msg *Message) bool {
 	var votes []*Message
 	if err := msg.Decode(&votes); err != nil {
 		log.Error("Failed to decode vote message", "err", err)
+		c.penalize(msg, e2c.ErrInvalidMessage)
 		return false
 	}
 
@@ -80,6 +81,7 @@ func (c *core) handleVote(msg *Message) bool {
 		var block *types.Block
 		if err := vote.Decode(&block); err != nil {
 			log.Error("Invalid vote message", "err", err)
+			c.penalize(msg, e2c.ErrInvalidMessage)
 			return false
 		}
 
@@ -116,10 +118,13 @@ func (c *core) sendBlockCertificate(block *types.Block) error {
 	// check that this block is the highested certificate locally. otherwise don't send it
 	if c.highestCert == nil || c.highestCert.Block.Number().Uint64() < block.Number().Uint64() {
 		// attach all the votes it received
-		var votes [][]byte
-
-		for _, val := range c.votes[block.Hash()] {
+		var (
+			votes   [][]byte
+			signers []common.Address
+		)
+		for addr, val := range c.votes[block.Hash()] {
 			votes = append(votes, val)
+			signers = append(signers, addr)
 		}
 
 		// save it as the new highestCert
@@ -129,6 +134,7 @@ func (c *core) sendBlockCertificate(block *types.Block) error {
 		}
 
 		log.Info("New Highest Block is certified!", "number", block.Number(), "hash", block.Hash())
+		c.postBlockCertified(block, signers)
 
 		// send the certificate to all nodes
 		m, err := Encode(c.highestCert)
@@ -144,15 +150,33 @@ func (c *core) sendBlockCertificate(block *types.Block) error {
 	return nil
 }
 
-func (c *core) verifyBlockCertificate(bc *BlockCertificate) error {
+// lets subscribers know about a new highest block certificate
+func (c *core) postBlockCertified(block *types.Block, signers []common.Address) {
+	c.post(e2c.BlockCertifiedEvent{
+		View:    c.backend.View(),
+		Number:  block.Number().Uint64(),
+		Hash:    block.Hash(),
+		Signers: signers,
+	})
+}
+
+// verifies the certificate and returns the validators that voted for it
+func (c *core) verifyBlockCertificate(bc *BlockCertificate) ([]common.Address, error) {
+	if bc == nil || bc.Block == nil {
+		return nil, errInvalidBlockCertificate
+	}
+	// the genesis block is certified without votes, as long as nothing was committed on top
+	if len(bc.Votes) == 0 && bc.Block.NumberU64() == 0 && c.committed.NumberU64() == 0 && bc.Block.Hash() == c.committed.Hash() {
+		return nil, nil
+	}
 	// check it has enough votes
 	if uint64(len(bc.Votes)) <= c.backend.F() {
-		return errNotEnoughSignatures
+		return nil, errNotEnoughSignatures
 	}
 
 	m, err := Encode(&bc.Block)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	msg := &Message{
 		Code: VoteMsg,
@@ -168,12 +192,15 @@ func (c *core) handleBlockCertificate(msg *Message) bool {
 	var bc *BlockCertificate
 	if err := msg.Decode(&bc); err != nil {
 		log.Error("Failed to decode block certificate", "err", err)
+		c.penalize(msg, e2c.ErrInvalidMessage)
 		return false
 	}
 
 	// verify the cert is valid
-	if err := c.verifyBlockCertificate(bc); err != nil {
+	signers, err := c.verifyBlockCertificate(bc)
+	if err != nil {
 		log.Error("Block certificate invalid", "err", err)
+		c.penalize(msg, e2c.ErrInvalidMessage)
 		return false
 	}
 
@@ -183,6 +210,7 @@ func (c *core) handleBlockCertificate(msg *Message) bool {
 	if c.highestCert == nil || c.highestCert.Block.Number().Uint64() < bc.Block.Number().Uint64() {
 		c.highestCert = bc
 		log.Info("New Highest Block is certified!", "number", c.highestCert.Block.Number(), "hash", c.highestCert.Block.Hash())
+		c.postBlockCertified(bc.Block, signers)
 	}
 
 	return true
@@ -190,10 +218,29 @@ func (c *core) handleBlockCertificate(msg *Message) bool {
 
 func (c *core) prepareFirstProposal() {
 
+	// nobody reported a certified block. On a fresh chain the new view extends the
+	// genesis block, which needs no votes, otherwise we can't justify a proposal and
+	// leave it to the next view change
+	if c.highestCert == nil {
+		if c.committed.NumberU64() != 0 {
+			log.Warn("No certified block to start the view from")
+			return
+		}
+		c.highestCert = &BlockCertificate{Block: c.committed}
+	}
+
 	// commit all the blocks needed to get to the highest cert
 	// for example last committed was block 5, highest cert is 10, we commit blocks 5-10 here
 	c.commitToHighest()
 	c.blockQueue = NewBlockQueue(c.config.Delta)
+
+	// the first block of the view carries the certificates that justified it
+	c.backend.SetViewCertificate(&types.E2CViewCertificate{
+		Blames: c.blameCert,
+		Number: c.highestCert.Block.NumberU64(),
+		Hash:   c.highestCert.Block.Hash(),
+		Votes:  c.highestCert.Votes,
+	})
 	c.backend.SetStatus(e2c.FirstProposal)
 }
 
@@ -232,13 +279,13 @@ func (c *core) handleFirstProposal(msg *Message) bool {
 	log.Info("Proposal for first block in view received", "number", b.Block.Number(), "hash", b.Block.Hash())
 
 	// ensure the block cert is valid
-	if err := c.verifyBlockCertificate(b.Cert); err != nil {
+	if _, err := c.verifyBlockCertificate(b.Cert); err != nil {
 		c.sendBlame()
 		log.Warn("Blame sent", "err", err)
 		return false
 	}
 	// ensure block cert is extending our highest cert
-	if b.Cert.Block.Number().Uint64() < c.highestCert.Block.Number().Uint64() {
+	if c.highestCert != nil && b.Cert.Block.Number().Uint64() < c.highestCert.Block.Number().Uint64() {
 		c.sendBlame()
 		log.Warn("Blame sent", "err", errInvalidBlockCertificate)
 		return false
@@ -257,6 +304,7 @@ func (c *core) handleFirstProposal(msg *Message) bool {
 
 	// commit new block in the proposal
 	c.handleBlock(b.Block)
+	c.postProposal(e2c.FirstViewProposal, b.Block, msg.Address)
 
 	c.broadcast(&Message{
 		Code: ValidateMsg,
@@ -325,7 +373,7 @@ func (c *core) handleSecondProposal(msg *Message) bool {
 		Code: ValidateMsg,
 		View: c.backend.View(),
 	}
-	if err := VerifyCertificateSignatures(m, b.Validates, c.checkValidatorSignature); err != nil {
+	if _, err := VerifyCertificateSignatures(m, b.Validates, c.checkValidatorSignature); err != nil {
 		c.sendBlame()
 		log.Warn("Blame sent", "err", errInvalidValidates)
 		return false
@@ -338,6 +386,7 @@ func (c *core) handleSecondProposal(msg *Message) bool {
 	}
 
 	c.handleBlock(b.Block)
+	c.postProposal(e2c.SecondViewProposal, b.Block, msg.Address)
 	c.backend.SetStatus(e2c.SteadyState)
 	log.Info("View Change completed! Resuming normal operations")
 	return true
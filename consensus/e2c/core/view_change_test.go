@@ -0,0 +1,168 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/e2c"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// testBackend is the backend of one validator, recording what the core broadcasts
+type testBackend struct {
+	key        *ecdsa.PrivateKey
+	validators e2c.Validators
+	view       uint64
+	status     uint32
+	viewCert   *types.E2CViewCertificate
+	sent       [][]byte
+	penalized  map[common.Address]error
+}
+
+func (b *testBackend) Address() common.Address    { return crypto.PubkeyToAddress(b.key.PublicKey) }
+func (b *testBackend) Leader() common.Address     { return b.validators[b.view%uint64(len(b.validators))] }
+func (b *testBackend) Validators() e2c.Validators { return b.validators }
+func (b *testBackend) F() uint64                  { return b.validators.F() }
+func (b *testBackend) Status() uint32             { return b.status }
+func (b *testBackend) SetStatus(status uint32)    { b.status = status }
+func (b *testBackend) View() uint64               { return b.view }
+func (b *testBackend) EventMux() *event.TypeMux   { return nil }
+func (b *testBackend) PostEvent(interface{})      {}
+func (b *testBackend) Send([]byte, common.Address) error {
+	return nil
+}
+func (b *testBackend) Broadcast(payload []byte) error {
+	b.sent = append(b.sent, payload)
+	return nil
+}
+func (b *testBackend) Commit(*types.Block)                                 {}
+func (b *testBackend) Verify(*types.Block) error                           { return nil }
+func (b *testBackend) GetBlockFromChain(common.Hash) (*types.Block, error) { return nil, nil }
+func (b *testBackend) GetBlockByNumber(uint64) *types.Block                { return nil }
+func (b *testBackend) IsSignerLeader(*types.Block) bool                    { return true }
+func (b *testBackend) ChangeView()                                         {}
+func (b *testBackend) SetViewCertificate(cert *types.E2CViewCertificate)   { b.viewCert = cert }
+func (b *testBackend) StoreCheckpoint(*e2c.Checkpoint)                     {}
+func (b *testBackend) Penalize(addr common.Address, reason error) {
+	if b.penalized == nil {
+		b.penalized = make(map[common.Address]error)
+	}
+	b.penalized[addr] = reason
+}
+func (b *testBackend) Sign(data []byte) ([]byte, error) {
+	return crypto.Sign(crypto.Keccak256(data), b.key)
+}
+
+// newTestCores returns the cores of n validators in view 1, all at the committed block
+func newTestCores(t *testing.T, n int, committed *types.Block) ([]*core, []*testBackend) {
+	keys := make([]*ecdsa.PrivateKey, n)
+	for i := range keys {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i] = key
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return crypto.PubkeyToAddress(keys[i].PublicKey).Hex() < crypto.PubkeyToAddress(keys[j].PublicKey).Hex()
+	})
+	var validators e2c.Validators
+	for _, key := range keys {
+		validators = append(validators, crypto.PubkeyToAddress(key.PublicKey))
+	}
+	config := *e2c.DefaultConfig
+	cores := make([]*core, n)
+	backends := make([]*testBackend, n)
+	for i, key := range keys {
+		backends[i] = &testBackend{key: key, validators: validators, view: 1, status: e2c.Wait}
+		cores[i] = New(backends[i], &config).(*core)
+		cores[i].committed, cores[i].lock = committed, committed
+		cores[i].progressTimer = NewProgressTimer(time.Hour)
+		cores[i].votingTimer = time.NewTimer(time.Hour)
+	}
+	return cores, backends
+}
+
+// lastSent decodes the last message the backend broadcast
+func lastSent(t *testing.T, b *testBackend) *Message {
+	if len(b.sent) == 0 {
+		t.Fatal("nothing broadcast")
+	}
+	msg := new(Message)
+	validateFn := func(data []byte, sig []byte) (common.Address, error) {
+		return e2c.CheckValidatorSignature(b.validators, data, sig)
+	}
+	if err := msg.FromPayload(b.sent[len(b.sent)-1], validateFn); err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func TestViewChangeFromGenesis(t *testing.T) {
+	genesis := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(0)})
+	cores, backends := newTestCores(t, 4, genesis)
+	leader, follower := cores[1], cores[2]
+
+	// nobody certified a block, so the leader of view 1 extends the genesis block
+	leader.prepareFirstProposal()
+	if status := backends[1].Status(); status != e2c.FirstProposal {
+		t.Fatalf("leader status mismatch: have %d, want %d", status, e2c.FirstProposal)
+	}
+	cert := backends[1].viewCert
+	if cert == nil || cert.Number != 0 || cert.Hash != genesis.Hash() || len(cert.Votes) != 0 {
+		t.Fatalf("view certificate mismatch: have %+v, want the genesis block without votes", cert)
+	}
+
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), ParentHash: genesis.Hash()})
+	if err := leader.sendFirstProposal(block); err != nil {
+		t.Fatal(err)
+	}
+	proposal := lastSent(t, backends[1])
+	if proposal.Code != FirstProposalMsg {
+		t.Fatalf("leader sent message %d, want first proposal", proposal.Code)
+	}
+	if !follower.handleFirstProposal(proposal) {
+		t.Fatal("follower rejected the first proposal extending the genesis block")
+	}
+	if msg := lastSent(t, backends[2]); msg.Code != ValidateMsg {
+		t.Fatalf("follower sent message %d, want validate", msg.Code)
+	}
+	if follower.lock.Hash() != block.Hash() {
+		t.Fatalf("follower lock mismatch: have %x, want %x", follower.lock.Hash(), block.Hash())
+	}
+
+	// once a block is committed, the genesis block needs votes like any other
+	follower.committed, follower.lock = block, block
+	if follower.handleFirstProposal(proposal) {
+		t.Fatal("first proposal without votes accepted past the genesis block")
+	}
+	leader.committed, leader.highestCert = block, nil
+	backends[1].SetViewCertificate(nil)
+	backends[1].SetStatus(e2c.Wait)
+	leader.prepareFirstProposal()
+	if status := backends[1].Status(); status != e2c.Wait || backends[1].viewCert != nil {
+		t.Fatalf("leader proposed without a certified block: status %d, certificate %+v", status, backends[1].viewCert)
+	}
+}
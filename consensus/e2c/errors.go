@@ -26,4 +26,14 @@ var (
 	ErrStoppedEngine = errors.New("stopped engine")
 	// ErrStartedEngine is returned if the engine is already started
 	ErrStartedEngine = errors.New("started engine")
+	// ErrInvalidCheckpoint is returned if a checkpoint has a different validator set
+	// or a validator signed it twice
+	ErrInvalidCheckpoint = errors.New("invalid checkpoint")
+	// ErrInsufficientSignatures is returned if fewer than f+1 validators signed a checkpoint
+	ErrInsufficientSignatures = errors.New("insufficient checkpoint signatures")
+	// ErrStaleMessage is reported when a peer sends a consensus message from another view
+	ErrStaleMessage = errors.New("message from another view")
+	// ErrInvalidMessage is reported when a peer sends a consensus message that is signed
+	// correctly but carries invalid content, e.g. a bogus certificate
+	ErrInvalidMessage = errors.New("invalid consensus message")
 )
@@ -0,0 +1,93 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package e2c
+
+import "github.com/ethereum/go-ethereum/common"
+
+// The events below are posted through Backend.PostEvent so they can be
+// streamed to operators through e2c_subscribe
+
+// ViewChangeEvent is posted when the node quits a view
+type ViewChangeEvent struct {
+	View   uint64         `json:"view"`   // the view being entered
+	Leader common.Address `json:"leader"` // leader of the new view
+}
+
+// LeaderChangedEvent is posted when a view change hands leadership to another validator
+type LeaderChangedEvent struct {
+	View           uint64         `json:"view"`
+	PreviousLeader common.Address `json:"previousLeader"`
+	Leader         common.Address `json:"leader"`
+}
+
+// BlameEvent is posted for every blame sent or received in the current view
+type BlameEvent struct {
+	View        uint64         `json:"view"`
+	Address     common.Address `json:"address"`     // validator that sent the blame
+	Equivocated bool           `json:"equivocated"` // true if the blame carries equivocating blocks
+	Blames      int            `json:"blames"`      // total blames collected in this view
+}
+
+// BlockCertifiedEvent is posted when a new highest block certificate is formed or received
+type BlockCertifiedEvent struct {
+	View    uint64           `json:"view"`
+	Number  uint64           `json:"number"`
+	Hash    common.Hash      `json:"hash"`
+	Signers []common.Address `json:"signers"`
+}
+
+// Kinds of proposals carried by ProposalReceivedEvent
+const (
+	SteadyStateProposal = "steady"
+	FirstViewProposal   = "first"
+	SecondViewProposal  = "second"
+)
+
+// ProposalReceivedEvent is posted when a valid proposal from the leader is accepted
+type ProposalReceivedEvent struct {
+	View     uint64         `json:"view"`
+	Kind     string         `json:"kind"`
+	Number   uint64         `json:"number"`
+	Hash     common.Hash    `json:"hash"`
+	Proposer common.Address `json:"proposer"`
+}
+
+// CheckpointEvent is posted when a checkpoint has collected f+1 signatures
+type CheckpointEvent struct {
+	Number  uint64           `json:"number"`
+	Hash    common.Hash      `json:"hash"`
+	Root    common.Hash      `json:"root"`
+	Signers []common.Address `json:"signers"`
+}
+
+// SynchronyEvent is posted when the delays observed to a validator move between
+// the synchrony states, e.g. from ok to warning
+type SynchronyEvent struct {
+	Address  common.Address `json:"address"`
+	Status   string         `json:"status"`
+	Previous string         `json:"previous"`
+	Delay    float64        `json:"delay"` // observed one-way delay in milliseconds
+	Delta    uint64         `json:"delta"` // configured Δ in milliseconds
+}
+
+// CommittedEvent is posted when the core commits a block to the chain
+type CommittedEvent struct {
+	View   uint64      `json:"view"`
+	Number uint64      `json:"number"`
+	Hash   common.Hash `json:"hash"`
+	Txs    int         `json:"txs"`
+}
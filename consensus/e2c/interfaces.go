@@ -48,6 +48,10 @@ type Backend interface {
 	// EventMux returns the event mux in backend
 	EventMux() *event.TypeMux
 
+	// PostEvent delivers a consensus event to the e2c_subscribe subscribers
+	// without blocking on them
+	PostEvent(ev interface{})
+
 	// Broadcast sends a message to all peers
 	Broadcast([]byte) error
 
@@ -70,6 +74,15 @@ type Backend interface {
 
 	// Triggers a view change
 	ChangeView()
+
+	// Sets the certificate recorded in the first block the leader proposes in the view
+	SetViewCertificate(*types.E2CViewCertificate)
+
+	// Stores a checkpoint that f+1 validators signed
+	StoreCheckpoint(*Checkpoint)
+
+	// Penalize accounts an invalid message against the peer that sent it
+	Penalize(common.Address, error)
 }
 
 type Engine interface {
@@ -82,4 +95,7 @@ type Engine interface {
 	// Returns blocks that are currently in the queue
 	GetQueuedBlock(common.Hash) (*types.Header, error)
 	Propose(*types.Block) error
+
+	// Returns the blames, heights and last view change duration for monitoring
+	Stats() Stats
 }
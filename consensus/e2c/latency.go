@@ -0,0 +1,40 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package e2c
+
+// E2C is only safe while every message arrives within Δ. Validators ping each other
+// to measure how close the network gets to that bound. All times are unix nanoseconds
+
+// Ping carries the time it was sent
+type Ping struct {
+	Sent uint64
+}
+
+// Pong answers a ping. Received and Replied let the pinging validator take the time
+// spent handling the ping out of the round trip
+type Pong struct {
+	Sent     uint64 // copied from the ping
+	Received uint64
+	Replied  uint64
+}
+
+// Synchrony states reported for the delays observed to a validator
+const (
+	SynchronyOK       = "ok"       // delays are well within Δ
+	SynchronyWarning  = "warning"  // delays are approaching Δ
+	SynchronyViolated = "violated" // delays exceeded Δ, the protocol's guarantees don't hold
+)
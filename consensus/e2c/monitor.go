@@ -0,0 +1,78 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package e2c
+
+import (
+	"crypto/ecdsa"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// CommitAnnouncement is gossiped by nodes running the safety monitor for every block
+// they commit. It carries the header so a divergence can be reported with both
+// blocks, and is signed with the node key so relaying nodes can't forge it
+type CommitAnnouncement struct {
+	Header    *types.Header
+	Signature []byte
+}
+
+// sigData returns the data the announcing node signs, the prefix keeps the
+// signature from being mistaken for anything else signed with the node key
+func (a *CommitAnnouncement) sigData() []byte {
+	data, _ := rlp.EncodeToBytes([]interface{}{"e2c commit", a.Header.Number.Uint64(), a.Header.Hash()})
+	return data
+}
+
+// Sign signs the announcement with the node key
+func (a *CommitAnnouncement) Sign(key *ecdsa.PrivateKey) error {
+	sig, err := crypto.Sign(crypto.Keccak256(a.sigData()), key)
+	if err != nil {
+		return err
+	}
+	a.Signature = sig
+	return nil
+}
+
+// Signer returns the address of the node that announced the commit
+func (a *CommitAnnouncement) Signer() (common.Address, error) {
+	if a.Header == nil || a.Header.Number == nil {
+		return common.Address{}, ErrInvalidMessage
+	}
+	return GetSignatureAddress(a.sigData(), a.Signature)
+}
+
+// ConflictingBlock is one side of a divergence found by the safety monitor
+type ConflictingBlock struct {
+	Hash     common.Hash      `json:"hash"`
+	Header   *types.Header    `json:"header"`
+	Proposer common.Address   `json:"proposer"` // validator that sealed the block
+	Signers  []common.Address `json:"signers"`  // nodes that announced committing it
+	Local    bool             `json:"local"`    // whether this node committed it
+	Txs      []common.Hash    `json:"txs"`      // only known for the local block
+}
+
+// SafetyReport is written by the safety monitor when nodes committed different
+// blocks at the same height
+type SafetyReport struct {
+	Number   uint64              `json:"number"`
+	Detected time.Time           `json:"detected"`
+	Blocks   []*ConflictingBlock `json:"blocks"`
+}
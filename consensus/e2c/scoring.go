@@ -0,0 +1,43 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package e2c
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DefaultBanDuration is how long a peer stays banned unless told otherwise
+const DefaultBanDuration = 10 * time.Minute
+
+// PeerScore sums up the invalid consensus traffic received from a peer. The score
+// goes up with every invalid message and decays over time
+type PeerScore struct {
+	Score       float64 `json:"score"`
+	Invalid     uint64  `json:"invalid"`     // undecodable, badly signed or bogus messages
+	Stale       uint64  `json:"stale"`       // messages from another view
+	RateLimited uint64  `json:"rateLimited"` // messages dropped by the rate limits
+	Throttled   bool    `json:"throttled"`   // consensus messages from the peer are dropped
+}
+
+// Ban is an entry of the ban list. Banned peers are disconnected and refused until it expires
+type Ban struct {
+	Address common.Address `json:"address"`
+	Until   time.Time      `json:"until"`
+	Reason  string         `json:"reason"`
+}
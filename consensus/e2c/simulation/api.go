@@ -0,0 +1,67 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulation
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// API lets a simulation script inspect a node and shape its outgoing links
+type API struct {
+	s *Service
+}
+
+// NodeInfo returns the current view, leader and head of the node
+func (api *API) NodeInfo() *NodeInfo {
+	return api.s.info()
+}
+
+// SetLinkDelay delays every message this node sends to addr by the given number of
+// milliseconds. A delay of zero restores the link
+func (api *API) SetLinkDelay(addr common.Address, ms int64) error {
+	if ms < 0 {
+		return errNegativeDelay
+	}
+	api.s.setLinkDelay(addr, time.Duration(ms)*time.Millisecond)
+	return nil
+}
+
+// LinkDelays returns the configured delays in milliseconds, keyed by peer address
+func (api *API) LinkDelays() map[common.Address]int64 {
+	api.s.peerMu.RLock()
+	defer api.s.peerMu.RUnlock()
+
+	delays := make(map[common.Address]int64, len(api.s.delays))
+	for addr, d := range api.s.delays {
+		delays[addr] = int64(d / time.Millisecond)
+	}
+	return delays
+}
+
+// Peers returns the addresses of the currently connected peers
+func (api *API) Peers() []common.Address {
+	api.s.peerMu.RLock()
+	defer api.s.peerMu.RUnlock()
+
+	peers := make([]common.Address, 0, len(api.s.peers))
+	for addr := range api.s.peers {
+		peers = append(peers, addr)
+	}
+	return peers
+}
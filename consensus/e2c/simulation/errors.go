@@ -0,0 +1,10 @@
+package simulation
+
+import "errors"
+
+var (
+	// errNoValidators is returned when a node is started without a validator set
+	errNoValidators = errors.New("no e2c validators in node properties")
+	// errNegativeDelay is returned when a link delay below zero is requested
+	errNegativeDelay = errors.New("link delay must not be negative")
+)
@@ -0,0 +1,69 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulation
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p"
+)
+
+// peer implements consensus.Peer on top of the simulation protocol. Every send
+// is held back by the link delay configured for the remote address
+type peer struct {
+	service *Service
+	address common.Address
+	rw      p2p.MsgReadWriter
+	log     log.Logger
+}
+
+func newPeer(s *Service, p *p2p.Peer, rw p2p.MsgReadWriter) *peer {
+	addr := crypto.PubkeyToAddress(*p.Node().Pubkey())
+	return &peer{
+		service: s,
+		address: addr,
+		rw:      rw,
+		log:     log.New("peer", addr),
+	}
+}
+
+// Send implements consensus.Peer.Send
+func (p *peer) Send(msgcode uint64, data interface{}) error {
+	if d := p.service.linkDelay(p.address); d > 0 {
+		time.AfterFunc(d, func() {
+			if err := p2p.Send(p.rw, msgcode, data); err != nil {
+				p.log.Debug("Failed to send delayed message", "code", msgcode, "err", err)
+			}
+		})
+		return nil
+	}
+	return p2p.Send(p.rw, msgcode, data)
+}
+
+// SendConsensus implements consensus.Peer.SendConsensus
+func (p *peer) SendConsensus(msgcode uint64, data interface{}) error {
+	return p.Send(msgcode, data)
+}
+
+// SendQBFTConsensus implements consensus.Peer.SendQBFTConsensus. E2C never
+// sends raw payloads, so this just wraps them like any other message
+func (p *peer) SendQBFTConsensus(msgcode uint64, payload []byte) error {
+	return p.Send(msgcode, payload)
+}
@@ -0,0 +1,468 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package simulation runs an E2C validator inside a p2p/simulations node.
+//
+// Each simulation node gets its own in-memory chain, an E2C backend keyed with
+// the node's devp2p key and a tiny block producer that seals empty blocks
+// whenever the engine says it should mine. Partitions and crashes are driven
+// through the simulation HTTP API (connect, disconnect, stop), while link delays
+// are set per peer through the "e2csim" RPC namespace of each node.
+package simulation
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/e2c"
+	e2cBackend "github.com/ethereum/go-ethereum/consensus/e2c/backend"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/simulations/adapters"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// ServiceName is the lifecycle name the service is registered under
+	ServiceName = "e2c"
+
+	// ValidatorProperty is the node property prefix used to list the validator set,
+	// e.g. "e2c.validator=0x...". Every node of a simulation must carry the same list
+	// since it is baked into the genesis block.
+	ValidatorProperty = "e2c.validator="
+
+	protocolName    = "e2csim"
+	protocolVersion = 1
+	protocolLength  = 18
+
+	// consensus messages use the same code as on the istanbul protocol (0x11), so
+	// they can be passed to the backend untouched. Blocks for member nodes mirror
+	// the eth NewBlockMsg
+	newBlockMsg = 0x07
+)
+
+// Config holds the E2C parameters used by every simulated node
+type Config struct {
+	Delta     time.Duration // Network speed, in milliseconds like e2c.Config
+	BlockSize uint64        // Determines how many transactions go in each block
+	ChainID   *big.Int      // Chain id of the simulated genesis
+	GasLimit  uint64        // Gas limit of the simulated genesis
+
+	CheckpointInterval uint64        // Number of blocks between signed checkpoints
+	PingInterval       time.Duration // How often validators measure delays, in milliseconds
+}
+
+// DefaultConfig contains the default settings for a simulated E2C network
+var DefaultConfig = &Config{
+	Delta:     e2c.DefaultConfig.Delta,
+	BlockSize: e2c.DefaultConfig.BlockSize,
+	ChainID:   big.NewInt(1337),
+	GasLimit:  params.GenesisGasLimit,
+
+	CheckpointInterval: e2c.DefaultConfig.CheckpointInterval,
+	PingInterval:       e2c.DefaultConfig.PingInterval,
+}
+
+// Lifecycles returns the constructors to register with a simulation adapter
+func Lifecycles(config *Config) adapters.LifecycleConstructors {
+	return adapters.LifecycleConstructors{
+		ServiceName: func(ctx *adapters.ServiceContext, stack *node.Node) (node.Lifecycle, error) {
+			validators, err := ParseValidators(ctx.Config.Properties)
+			if err != nil {
+				return nil, err
+			}
+			s, err := New(config, ctx.Config.PrivateKey, validators)
+			if err != nil {
+				return nil, err
+			}
+			stack.RegisterProtocols(s.Protocols())
+			stack.RegisterAPIs(s.APIs())
+			stack.RegisterLifecycle(s)
+			return s, nil
+		},
+	}
+}
+
+// ParseValidators extracts the validator set from the node properties
+func ParseValidators(properties []string) (e2c.Validators, error) {
+	var validators e2c.Validators
+	for _, p := range properties {
+		if !strings.HasPrefix(p, ValidatorProperty) {
+			continue
+		}
+		addr := strings.TrimPrefix(p, ValidatorProperty)
+		if !common.IsHexAddress(addr) {
+			return nil, fmt.Errorf("invalid validator address %q", addr)
+		}
+		validators = append(validators, common.HexToAddress(addr))
+	}
+	if len(validators) == 0 {
+		return nil, errNoValidators
+	}
+	return validators, nil
+}
+
+// Genesis returns the genesis block shared by all nodes of a simulation
+func Genesis(config *Config, validators e2c.Validators) (*core.Genesis, error) {
+	extra, err := genesisExtra(validators)
+	if err != nil {
+		return nil, err
+	}
+	return &core.Genesis{
+		Config: &params.ChainConfig{
+			ChainID:             config.ChainID,
+			HomesteadBlock:      big.NewInt(0),
+			EIP150Block:         big.NewInt(0),
+			EIP155Block:         big.NewInt(0),
+			EIP158Block:         big.NewInt(0),
+			ByzantiumBlock:      big.NewInt(0),
+			ConstantinopleBlock: big.NewInt(0),
+			PetersburgBlock:     big.NewInt(0),
+			IstanbulBlock:       big.NewInt(0),
+			E2C: &params.E2CConfig{
+				Delta:        config.Delta,
+				BlockSize:    config.BlockSize,
+				ExtraV2Block: big.NewInt(0),
+			},
+			IsQuorum:             true,
+			TransactionSizeLimit: 64,
+			MaxCodeSize:          24,
+		},
+		ExtraData:  extra,
+		GasLimit:   config.GasLimit,
+		Difficulty: big.NewInt(1),
+		Mixhash:    types.E2CDigest,
+		Alloc:      core.GenesisAlloc{},
+	}, nil
+}
+
+// genesisExtra encodes the validator set the same way the backend expects to find it
+func genesisExtra(validators e2c.Validators) ([]byte, error) {
+	payload, err := rlp.EncodeToBytes(&types.E2CExtra{
+		Validators: validators,
+		Seal:       []byte{},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(make([]byte, types.E2CExtraVanity), payload...), nil
+}
+
+// Service runs an E2C validator, or a member node if its key is not in
+// the validator set, on top of an in-memory chain
+type Service struct {
+	config     *Config
+	address    common.Address
+	validators e2c.Validators
+	engine     consensus.E2C
+	chain      *core.BlockChain
+
+	peers  map[common.Address]*peer
+	delays map[common.Address]time.Duration
+	peerMu sync.RWMutex
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a simulated E2C node with the given devp2p key
+func New(config *Config, key *ecdsa.PrivateKey, validators e2c.Validators) (*Service, error) {
+	genesis, err := Genesis(config, validators)
+	if err != nil {
+		return nil, err
+	}
+	db := rawdb.NewMemoryDatabase()
+	genesis.MustCommit(db)
+
+	engine := e2cBackend.New(&e2c.Config{
+		Delta:              config.Delta,
+		BlockSize:          config.BlockSize,
+		CheckpointInterval: config.CheckpointInterval,
+		PingInterval:       config.PingInterval,
+		ExtraV2Block:       genesis.Config.E2C.ExtraV2Block,
+	}, key, db)
+
+	chain, err := core.NewBlockChain(db, nil, genesis.Config, engine, vm.Config{}, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Service{
+		config:     config,
+		address:    crypto.PubkeyToAddress(key.PublicKey),
+		validators: validators,
+		engine:     engine,
+		chain:      chain,
+		peers:      make(map[common.Address]*peer),
+		delays:     make(map[common.Address]time.Duration),
+		quit:       make(chan struct{}),
+	}
+	if handler, ok := engine.(consensus.Handler); ok {
+		handler.SetBroadcaster(s)
+	}
+	return s, nil
+}
+
+// Protocols returns the devp2p protocol used to carry consensus messages
+func (s *Service) Protocols() []p2p.Protocol {
+	return []p2p.Protocol{{
+		Name:     protocolName,
+		Version:  protocolVersion,
+		Length:   protocolLength,
+		Run:      s.run,
+		NodeInfo: func() interface{} { return s.info() },
+	}}
+}
+
+// APIs returns the RPC APIs of the simulation service and the engine
+func (s *Service) APIs() []rpc.API {
+	return append(s.engine.APIs(s.chain), rpc.API{
+		Namespace: "e2csim",
+		Version:   "1.0",
+		Service:   &API{s: s},
+		Public:    true,
+	})
+}
+
+// Start implements node.Lifecycle, starting the engine if we are a validator
+func (s *Service) Start() error {
+	if s.isValidator() {
+		if err := s.engine.Start(s.chain); err != nil {
+			return err
+		}
+		s.wg.Add(1)
+		go s.produce()
+	}
+	log.Info("E2C simulation node started", "address", s.address, "validator", s.isValidator())
+	return nil
+}
+
+// Stop implements node.Lifecycle
+func (s *Service) Stop() error {
+	close(s.quit)
+	s.wg.Wait()
+	if s.isValidator() {
+		if err := s.engine.Stop(); err != nil {
+			return err
+		}
+	}
+	s.chain.Stop()
+	return nil
+}
+
+func (s *Service) isValidator() bool {
+	i, _ := s.validators.GetByAddress(s.address)
+	return i != -1
+}
+
+// produce seals a new empty block every delta while we are the leader. This stands
+// in for the miner.worker which isn't available inside a simulation node
+func (s *Service) produce() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.config.Delta * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if !s.engine.ShouldMine() {
+				continue
+			}
+			if err := s.sealNext(); err != nil {
+				log.Debug("Failed to seal simulated block", "err", err)
+			}
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// sealNext builds an empty block on top of the current head and hands it to the engine
+func (s *Service) sealNext() error {
+	parent := s.chain.CurrentBlock()
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number(), common.Big1),
+		GasLimit:   parent.GasLimit(),
+		Time:       uint64(time.Now().Unix()),
+	}
+	if err := s.engine.Prepare(s.chain, header); err != nil {
+		return err
+	}
+	statedb, _, err := s.chain.StateAt(parent.Root())
+	if err != nil {
+		return err
+	}
+	block, err := s.engine.FinalizeAndAssemble(s.chain, header, statedb, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	results := make(chan *types.Block, 1)
+	if err := s.engine.Seal(s.chain, block, results, s.quit); err != nil {
+		return err
+	}
+	select {
+	case sealed := <-results:
+		_, err := s.chain.InsertChain(types.Blocks{sealed})
+		return err
+	default:
+		// the core refused the proposal, e.g. we are in the middle of a view change
+		return nil
+	}
+}
+
+// Enqueue implements consensus.Broadcaster. Committed blocks are written to our
+// chain and forwarded to member nodes, which count them as acknowledgements
+func (s *Service) Enqueue(id string, block *types.Block) {
+	if _, err := s.chain.InsertChain(types.Blocks{block}); err != nil {
+		log.Error("Failed to insert committed block", "number", block.Number(), "hash", block.Hash(), "err", err)
+		return
+	}
+	s.peerMu.RLock()
+	defer s.peerMu.RUnlock()
+	for addr, p := range s.peers {
+		if i, _ := s.validators.GetByAddress(addr); i == -1 {
+			go p.Send(newBlockMsg, block)
+		}
+	}
+}
+
+// FindPeers implements consensus.Broadcaster
+func (s *Service) FindPeers(targets map[common.Address]bool) map[common.Address]consensus.Peer {
+	s.peerMu.RLock()
+	defer s.peerMu.RUnlock()
+
+	m := make(map[common.Address]consensus.Peer)
+	for addr, p := range s.peers {
+		if targets[addr] {
+			m[addr] = p
+		}
+	}
+	return m
+}
+
+// run handles a single peer connection for the lifetime of the link
+func (s *Service) run(p2pPeer *p2p.Peer, rw p2p.MsgReadWriter) error {
+	p := newPeer(s, p2pPeer, rw)
+
+	s.peerMu.Lock()
+	s.peers[p.address] = p
+	s.peerMu.Unlock()
+
+	defer func() {
+		s.peerMu.Lock()
+		delete(s.peers, p.address)
+		s.peerMu.Unlock()
+	}()
+
+	if err := s.engine.RequestCheckpoint(p); err != nil {
+		p.log.Debug("Failed to request checkpoint", "err", err)
+	}
+	for {
+		msg, err := rw.ReadMsg()
+		if err != nil {
+			return err
+		}
+		if err := s.handleMsg(p, msg); err != nil {
+			p.log.Debug("Failed to handle simulated message", "code", msg.Code, "err", err)
+		}
+		msg.Discard()
+	}
+}
+
+func (s *Service) handleMsg(p *peer, msg p2p.Msg) error {
+	if handler, ok := s.engine.(consensus.Handler); ok {
+		handled, err := handler.HandleMsg(p.address, msg)
+		if handled || err != nil {
+			return err
+		}
+	}
+	if msg.Code != newBlockMsg {
+		return nil
+	}
+	// member nodes only accept a block once f+1 validators have acknowledged it
+	var block *types.Block
+	if err := msg.Decode(&block); err != nil {
+		return err
+	}
+	if s.engine.ClientVerify(block, p.address, s.chain) {
+		_, err := s.chain.InsertChain(types.Blocks{block})
+		return err
+	}
+	return nil
+}
+
+// linkDelay returns the artificial delay applied to messages sent to addr
+func (s *Service) linkDelay(addr common.Address) time.Duration {
+	s.peerMu.RLock()
+	defer s.peerMu.RUnlock()
+	return s.delays[addr]
+}
+
+// setLinkDelay sets the artificial delay applied to messages sent to addr
+func (s *Service) setLinkDelay(addr common.Address, d time.Duration) {
+	s.peerMu.Lock()
+	defer s.peerMu.Unlock()
+	if d <= 0 {
+		delete(s.delays, addr)
+		return
+	}
+	s.delays[addr] = d
+}
+
+// NodeInfo is reported through the simulation API for every node
+type NodeInfo struct {
+	Address   common.Address `json:"address"`
+	Validator bool           `json:"validator"`
+	View      uint64         `json:"view"`
+	Leader    common.Address `json:"leader"`
+	Status    string         `json:"status"`
+	Number    uint64         `json:"number"`
+	Hash      common.Hash    `json:"hash"`
+}
+
+func (s *Service) info() *NodeInfo {
+	head := s.chain.CurrentHeader()
+	info := &NodeInfo{
+		Address:   s.address,
+		Validator: s.isValidator(),
+		Number:    head.Number.Uint64(),
+		Hash:      head.Hash(),
+	}
+	// the backend only learns the validator set once the engine has been started
+	if b, ok := s.engine.(e2c.Backend); ok && len(b.Validators()) > 0 {
+		info.View = b.View()
+		info.Leader = b.Leader()
+		info.Status = e2c.StatusName(b.Status())
+	}
+	return info
+}
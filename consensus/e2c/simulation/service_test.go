@@ -0,0 +1,194 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulation
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/e2c"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// newTestService returns a simulated node for the key, with the rpc client of
+// its APIs
+func newTestService(t *testing.T, config *Config, key *ecdsa.PrivateKey, validators e2c.Validators) (*Service, *rpc.Client) {
+	s, err := New(config, key, validators)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := rpc.NewServer()
+	for _, api := range s.APIs() {
+		if err := server.RegisterName(api.Namespace, api.Service); err != nil {
+			t.Fatal(err)
+		}
+	}
+	client := rpc.DialInProc(server)
+	t.Cleanup(func() {
+		client.Close()
+		server.Stop()
+	})
+	return s, client
+}
+
+func newKey(t *testing.T) (*ecdsa.PrivateKey, common.Address) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, crypto.PubkeyToAddress(key.PublicKey)
+}
+
+// newBlockMessage wraps the payload in a block message
+func newBlockMessage(t *testing.T, payload interface{}) p2p.Msg {
+	data, err := rlp.EncodeToBytes(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p2p.Msg{Code: newBlockMsg, Size: uint32(len(data)), Payload: bytes.NewReader(data)}
+}
+
+func TestParseValidators(t *testing.T) {
+	addr := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	validators, err := ParseValidators([]string{"other=1", ValidatorProperty + addr.Hex()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(validators) != 1 || validators[0] != addr {
+		t.Fatalf("validators mismatch: have %v, want [%v]", validators, addr)
+	}
+	if _, err := ParseValidators([]string{ValidatorProperty + "0x1234"}); err == nil {
+		t.Fatal("expected an invalid address to be rejected")
+	}
+	if _, err := ParseValidators(nil); err != errNoValidators {
+		t.Fatalf("error mismatch: have %v, want %v", err, errNoValidators)
+	}
+}
+
+func TestAPILinkDelays(t *testing.T) {
+	key, addr := newKey(t)
+	_, peerAddr := newKey(t)
+	s, client := newTestService(t, DefaultConfig, key, e2c.Validators{addr})
+
+	var info NodeInfo
+	if err := client.Call(&info, "e2csim_nodeInfo"); err != nil {
+		t.Fatal(err)
+	}
+	genesis := s.chain.Genesis()
+	if info.Address != addr || !info.Validator || info.Number != 0 || info.Hash != genesis.Hash() {
+		t.Fatalf("node info mismatch: have %+v", info)
+	}
+
+	// a delay is applied to the link, then removed again by a zero delay
+	if err := client.Call(nil, "e2csim_setLinkDelay", peerAddr, 300); err != nil {
+		t.Fatal(err)
+	}
+	var delays map[common.Address]int64
+	if err := client.Call(&delays, "e2csim_linkDelays"); err != nil {
+		t.Fatal(err)
+	}
+	if len(delays) != 1 || delays[peerAddr] != 300 {
+		t.Fatalf("delays mismatch: have %v, want %v: 300", delays, peerAddr)
+	}
+	if d := s.linkDelay(peerAddr); d != 300*time.Millisecond {
+		t.Fatalf("link delay mismatch: have %v, want %v", d, 300*time.Millisecond)
+	}
+	if err := client.Call(nil, "e2csim_setLinkDelay", peerAddr, 0); err != nil {
+		t.Fatal(err)
+	}
+	delays = nil
+	if err := client.Call(&delays, "e2csim_linkDelays"); err != nil {
+		t.Fatal(err)
+	}
+	if len(delays) != 0 {
+		t.Fatalf("expected the delay to be removed, have %v", delays)
+	}
+
+	// negative delays are refused and leave the links untouched
+	err := client.Call(nil, "e2csim_setLinkDelay", peerAddr, -1)
+	if err == nil || err.Error() != errNegativeDelay.Error() {
+		t.Fatalf("error mismatch: have %v, want %v", err, errNegativeDelay)
+	}
+	if d := s.linkDelay(peerAddr); d != 0 {
+		t.Fatalf("link delay mismatch: have %v, want 0", d)
+	}
+}
+
+func TestMemberImportsAcknowledgedBlocks(t *testing.T) {
+	config := *DefaultConfig
+	config.Delta = 10
+
+	validatorKey, validatorAddr := newKey(t)
+	memberKey, _ := newKey(t)
+	validators := e2c.Validators{validatorAddr}
+
+	validator, _ := newTestService(t, &config, validatorKey, validators)
+	if err := validator.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer validator.Stop()
+	member, client := newTestService(t, &config, memberKey, validators)
+	defer member.chain.Stop()
+
+	// a lone validator seals blocks by itself
+	deadline := time.Now().Add(10 * time.Second)
+	for validator.chain.CurrentBlock().NumberU64() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("validator didn't seal a block")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	block := validator.chain.GetBlockByNumber(1)
+	from := &peer{service: member, address: validatorAddr}
+
+	// blocks that don't decode are reported and not imported
+	if err := member.handleMsg(from, newBlockMessage(t, []byte{0x01})); err == nil {
+		t.Fatal("expected an undecodable block to be refused")
+	}
+
+	// blocks that don't extend the chain and acknowledgements of other nodes
+	// than validators are ignored
+	header := block.Header()
+	header.ParentHash = common.Hash{0x01}
+	if err := member.handleMsg(from, newBlockMessage(t, block.WithSeal(header))); err != nil {
+		t.Fatal(err)
+	}
+	if err := member.handleMsg(&peer{service: member, address: common.Address{0x01}}, newBlockMessage(t, block)); err != nil {
+		t.Fatal(err)
+	}
+	if n := member.chain.CurrentBlock().NumberU64(); n != 0 {
+		t.Fatalf("head mismatch: have %d, want 0", n)
+	}
+
+	// f+1 acknowledgements of the validators import the block
+	if err := member.handleMsg(from, newBlockMessage(t, block)); err != nil {
+		t.Fatal(err)
+	}
+	var info NodeInfo
+	if err := client.Call(&info, "e2csim_nodeInfo"); err != nil {
+		t.Fatal(err)
+	}
+	if info.Validator || info.Number != 1 || info.Hash != block.Hash() {
+		t.Fatalf("node info mismatch: have %+v, want block %d %x", info, 1, block.Hash())
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package e2c
+
+import "github.com/ethereum/go-ethereum/common"
+
+// Stats sums up the consensus health of a node, e.g. for ethstats
+type Stats struct {
+	View           uint64         `json:"view"`
+	Leader         common.Address `json:"leader"`
+	Status         string         `json:"status"`
+	Blames         int            `json:"blames"`         // blames collected in the current view
+	LastViewChange float64        `json:"lastViewChange"` // milliseconds the last view change took
+	Committed      uint64         `json:"committed"`      // height of the last committed block
+	Locked         uint64         `json:"locked"`         // height of the highest block we locked on
+}
+
+// StatusName returns a readable name for the status phase
+func StatusName(status uint32) string {
+	switch status {
+	case SteadyState:
+		return "steady"
+	case FirstProposal:
+		return "firstProposal"
+	case SecondProposal:
+		return "secondProposal"
+	case Wait:
+		return "wait"
+	}
+	return "unknown"
+}
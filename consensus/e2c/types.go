@@ -16,6 +16,8 @@
 
 package e2c
 
+import "github.com/ethereum/go-ethereum/common"
+
 // These are the states for status
 const (
 	SteadyState uint32 = iota
@@ -27,4 +29,5 @@ const (
 // MessageEvent is posted for E2C engine communication
 type MessageEvent struct {
 	Payload []byte
+	Peer    common.Address // peer that sent it, blamed if the message turns out invalid
 }
@@ -137,6 +137,8 @@ type CacheConfig struct {
 	SnapshotWait bool // Wait for snapshot construction on startup. TODO(karalabe): This is a dirty hack for testing, nuke it
 
 	PrivateTrieCleanJournal string // Quorum: Disk journal for saving clean private cache entries.
+	PrivateTrieGC           bool   // Quorum: Whether to garbage collect multiple private states in memory instead of writing every block's to disk
+	PrivateTriesInMemory    uint64 // Quorum: Number of recent blocks to keep the private state of in memory, TriesInMemory if lower
 }
 
 // defaultCacheConfig are the default caching values if none are specified by the
@@ -285,6 +287,9 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 	}, chainConfig.IsMPS); err != nil {
 		return nil, err
 	}
+	if psm, ok := bc.privateStateManager.(*MultiplePrivateStateManager); ok {
+		psm.trieGC = cacheConfig.PrivateTrieGC && !cacheConfig.TrieDirtyDisabled
+	}
 	bc.hc, err = NewHeaderChain(db, chainConfig, engine, bc.insertStopped)
 	if err != nil {
 		return nil, err
@@ -1187,10 +1192,11 @@ func (bc *BlockChain) Stop() {
 					log.Error("Failed to commit recent state trie", "err", err)
 				}
 				// Quorum
-				privateRoot := rawdb.GetPrivateStateRoot(bc.db, recent.Root())
-				log.Info("Writing private cached state to disk", "block", recent.Number(), "hash", recent.Hash(), "privateRoot", privateRoot)
-				if err := privateTrieDb.Commit(privateRoot, true, nil); err != nil {
-					log.Error("Failed to commit recent private state trie", "err", err)
+				if privateRoot := bc.privateStateRoot(recent.Root()); privateRoot != (common.Hash{}) {
+					log.Info("Writing private cached state to disk", "block", recent.Number(), "hash", recent.Hash(), "privateRoot", privateRoot)
+					if err := privateTrieDb.Commit(privateRoot, true, nil); err != nil {
+						log.Error("Failed to commit recent private state trie", "err", err)
+					}
 				}
 				// End Quorum
 			}
@@ -1699,6 +1705,26 @@ func (bc *BlockChain) CommitBlockWithState(deleteEmptyObjects bool, state, priva
 	return nil
 }
 
+// privateStateRoot returns the root of the private state of the block with the
+// given state root, or of its trie of private states with multiple private states
+func (bc *BlockChain) privateStateRoot(root common.Hash) common.Hash {
+	// the chain config changes when the database is upgraded to multiple private
+	// states, the private state manager doesn't
+	if _, ok := bc.privateStateManager.(*MultiplePrivateStateManager); ok {
+		return rawdb.GetPrivateStatesTrieRoot(bc.db, root)
+	}
+	return rawdb.GetPrivateStateRoot(bc.db, root)
+}
+
+// privateTriesInMemory returns the number of recent blocks to keep the private
+// state of in memory
+func (bc *BlockChain) privateTriesInMemory() uint64 {
+	if bc.cacheConfig.PrivateTriesInMemory > TriesInMemory {
+		return bc.cacheConfig.PrivateTriesInMemory
+	}
+	return TriesInMemory
+}
+
 // END QUORUM
 
 // writeBlockWithState writes the block and all associated state to the database,
@@ -1807,8 +1833,9 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 					triedb.Commit(header.Root, true, nil)
 
 					// Quorum
-					privateroot := rawdb.GetPrivateStateRoot(bc.db, header.Root)
-					privateTrieDB.Commit(privateroot, true, nil)
+					if privateRoot := bc.privateStateRoot(header.Root); privateRoot != (common.Hash{}) {
+						privateTrieDB.Commit(privateRoot, true, nil)
+					}
 					// End Quorum
 
 					lastWrite = chosen
@@ -1825,13 +1852,16 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 				triedb.Dereference(root.(common.Hash))
 			}
 			// Quorum
-			for !bc.privateTrieGC.Empty() {
-				root, number := bc.privateTrieGC.Pop()
-				if uint64(-number) > chosen {
-					bc.privateTrieGC.Push(root, number)
-					break
+			if retain := bc.privateTriesInMemory(); current > retain {
+				chosen := current - retain
+				for !bc.privateTrieGC.Empty() {
+					root, number := bc.privateTrieGC.Pop()
+					if uint64(-number) > chosen {
+						bc.privateTrieGC.Push(root, number)
+						break
+					}
+					privateTrieDB.Dereference(root.(common.Hash))
 				}
-				privateTrieDB.Dereference(root.(common.Hash))
 			}
 			// End Quorum
 		}
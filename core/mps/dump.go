@@ -0,0 +1,200 @@
+package mps
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/private/engine"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// PrivateStateDumpVersion is the version of the private state dump format
+const PrivateStateDumpVersion = 1
+
+var (
+	errUnsignedDump        = errors.New("private state dump isn't signed")
+	errMissingPreimage     = errors.New("private state has accounts without address preimages, export the contracts by address")
+	errMissingSlotPreimage = errors.New("private state has storage slots without key preimages, it was written without --cache.preimages")
+	errUnsupportedVersion  = fmt.Errorf("unsupported private state dump version, expected %d", PrivateStateDumpVersion)
+)
+
+// PrivateStateDump is a portable snapshot of contracts of a private state, or of
+// the whole private state, signed by the node that took it. It includes the extra
+// data of the accounts, so it can be imported into the private state of another
+// node.
+type PrivateStateDump struct {
+	Version     int                                   `json:"version"`
+	PSI         types.PrivateStateIdentifier          `json:"psi"`
+	BlockNumber uint64                                `json:"blockNumber"`
+	BlockHash   common.Hash                           `json:"blockHash"`
+	Root        common.Hash                           `json:"root"` // of the private state at the block
+	Accounts    map[common.Address]PrivateAccountDump `json:"accounts"`
+	Signature   hexutil.Bytes                         `json:"signature,omitempty"`
+}
+
+// PrivateAccountDump is an account of a private state dump
+type PrivateAccountDump struct {
+	state.DumpAccount
+	PrivacyFlag    *engine.PrivacyFlagType `json:"privacyFlag,omitempty"`    // only for party protection and state validation contracts
+	CreationTxHash string                  `json:"creationTxHash,omitempty"` // base64, with the privacy flag
+	ManagedParties []string                `json:"managedParties,omitempty"`
+}
+
+// DumpPrivateState dumps the contracts of the private state at the block, or all
+// its accounts without contracts
+func DumpPrivateState(psi types.PrivateStateIdentifier, block *types.Block, privateState *state.StateDB, contracts []common.Address) (*PrivateStateDump, error) {
+	dump := &PrivateStateDump{
+		Version:     PrivateStateDumpVersion,
+		PSI:         psi,
+		BlockNumber: block.NumberU64(),
+		BlockHash:   block.Hash(),
+		Root:        privateState.IntermediateRoot(true),
+		Accounts:    make(map[common.Address]PrivateAccountDump),
+	}
+	if len(contracts) == 0 {
+		for address, account := range privateState.RawDump(true, true, false).Accounts {
+			if account.SecureKey != nil {
+				return nil, errMissingPreimage
+			}
+			contracts = append(contracts, address)
+		}
+	}
+	accounts := make(map[common.Address]state.DumpAccount)
+	for _, address := range contracts {
+		account, found := privateState.DumpAddress(address)
+		if !found {
+			return nil, fmt.Errorf("contract %s not found in private state %s", address.Hex(), psi)
+		}
+		storage, err := dumpStorage(privateState, address)
+		if err != nil {
+			return nil, fmt.Errorf("can't dump storage of %s: %w", address.Hex(), err)
+		}
+		account.Storage = storage
+		accounts[address] = account
+	}
+
+	for address, account := range accounts {
+		accountDump := PrivateAccountDump{DumpAccount: account}
+		if metadata, err := privateState.GetPrivacyMetadata(address); err == nil && metadata != nil {
+			flag := metadata.PrivacyFlag
+			accountDump.PrivacyFlag = &flag
+			accountDump.CreationTxHash = metadata.CreationTxHash.ToBase64()
+		}
+		managedParties, err := privateState.GetManagedParties(address)
+		if err != nil {
+			return nil, err
+		}
+		if len(managedParties) > 0 {
+			accountDump.ManagedParties = managedParties
+		}
+		dump.Accounts[address] = accountDump
+	}
+	return dump, nil
+}
+
+// dumpStorage returns the storage of the account by slot. The storage trie is keyed
+// by the hashes of the slots, so it fails if the database has no preimage of one
+func dumpStorage(privateState *state.StateDB, address common.Address) (map[common.Hash]string, error) {
+	storage := make(map[common.Hash]string)
+	storageTrie := privateState.StorageTrie(address)
+	if storageTrie == nil {
+		return storage, nil
+	}
+	it := trie.NewIterator(storageTrie.NodeIterator(nil))
+	for it.Next() {
+		slot := storageTrie.GetKey(it.Key)
+		if slot == nil {
+			return nil, errMissingSlotPreimage
+		}
+		_, content, _, err := rlp.Split(it.Value)
+		if err != nil {
+			return nil, err
+		}
+		storage[common.BytesToHash(slot)] = common.Bytes2Hex(content)
+	}
+	return storage, it.Err
+}
+
+// SigHash is the hash signed by the node that took the dump
+func (d *PrivateStateDump) SigHash() (common.Hash, error) {
+	unsigned := *d
+	unsigned.Signature = nil
+	enc, err := json.Marshal(&unsigned)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(enc), nil
+}
+
+// Sign signs the dump with the key
+func (d *PrivateStateDump) Sign(key *ecdsa.PrivateKey) error {
+	hash, err := d.SigHash()
+	if err != nil {
+		return err
+	}
+	d.Signature, err = crypto.Sign(hash.Bytes(), key)
+	return err
+}
+
+// Signer returns the address of the key that signed the dump, failing if the dump
+// was changed since
+func (d *PrivateStateDump) Signer() (common.Address, error) {
+	if d.Version != PrivateStateDumpVersion {
+		return common.Address{}, errUnsupportedVersion
+	}
+	if len(d.Signature) == 0 {
+		return common.Address{}, errUnsignedDump
+	}
+	hash, err := d.SigHash()
+	if err != nil {
+		return common.Address{}, err
+	}
+	pub, err := crypto.SigToPub(hash.Bytes(), d.Signature)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid private state dump signature: %v", err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// Apply writes the accounts of the dump to the private state, replacing the
+// accounts it already has at the same addresses
+func (d *PrivateStateDump) Apply(privateState *state.StateDB) error {
+	for address, account := range d.Accounts {
+		balance, ok := new(big.Int).SetString(account.Balance, 10)
+		if !ok {
+			return fmt.Errorf("invalid balance %q of %s", account.Balance, address.Hex())
+		}
+		var creationTxHash common.EncryptedPayloadHash
+		if account.PrivacyFlag != nil {
+			hash, err := common.Base64ToEncryptedPayloadHash(account.CreationTxHash)
+			if err != nil {
+				return fmt.Errorf("invalid creation transaction hash of %s: %v", address.Hex(), err)
+			}
+			creationTxHash = hash
+		}
+
+		privateState.CreateAccount(address)
+		privateState.SetBalance(address, balance)
+		privateState.SetNonce(address, account.Nonce)
+		privateState.SetCode(address, common.Hex2Bytes(account.Code))
+		for key, value := range account.Storage {
+			privateState.SetState(address, key, common.HexToHash(value))
+		}
+		if account.PrivacyFlag != nil {
+			privateState.SetPrivacyMetadata(address, state.NewStatePrivacyMetadata(creationTxHash, *account.PrivacyFlag))
+		}
+		if len(account.ManagedParties) > 0 {
+			privateState.SetManagedParties(address, account.ManagedParties)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package mps
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/private/engine"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDumpTestState(t *testing.T) *state.StateDB {
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(t, err)
+	return statedb
+}
+
+func TestPrivateStateDump_ExportImport(t *testing.T) {
+	var (
+		contract  = common.HexToAddress("0x1")
+		other     = common.HexToAddress("0x2")
+		creation  = common.BytesToEncryptedPayloadHash([]byte("creation"))
+		block     = types.NewBlockWithHeader(&types.Header{Number: big.NewInt(5)})
+		source    = newDumpTestState(t)
+		slot      = common.HexToHash("0xaa")
+		value     = common.HexToHash("0xbb")
+		parties   = []string{"party1", "party2"}
+		key, _    = crypto.GenerateKey()
+		psi       = types.PrivateStateIdentifier("tenant")
+		destState = newDumpTestState(t)
+	)
+	source.SetCode(contract, []byte{0x60, 0x00})
+	source.SetState(contract, slot, value)
+	source.SetPrivacyMetadata(contract, state.NewStatePrivacyMetadata(creation, engine.PrivacyFlagStateValidation))
+	source.SetManagedParties(contract, parties)
+	source.SetCode(other, []byte{0x60, 0x01})
+	root, err := source.Commit(true)
+	require.NoError(t, err)
+	source, err = state.New(root, source.Database(), nil)
+	require.NoError(t, err)
+
+	dump, err := DumpPrivateState(psi, block, source, []common.Address{contract})
+	require.NoError(t, err)
+	require.Len(t, dump.Accounts, 1)
+	require.NoError(t, dump.Sign(key))
+
+	// the dump is portable
+	enc, err := json.Marshal(dump)
+	require.NoError(t, err)
+	var imported PrivateStateDump
+	require.NoError(t, json.Unmarshal(enc, &imported))
+	signer, err := imported.Signer()
+	require.NoError(t, err)
+	assert.Equal(t, crypto.PubkeyToAddress(key.PublicKey), signer)
+	assert.Equal(t, psi, imported.PSI)
+	assert.Equal(t, block.Hash(), imported.BlockHash)
+
+	require.NoError(t, imported.Apply(destState))
+	assert.Equal(t, value, destState.GetState(contract, slot))
+	assert.Equal(t, []byte{0x60, 0x00}, destState.GetCode(contract))
+	assert.False(t, destState.Exist(other))
+	metadata, err := destState.GetPrivacyMetadata(contract)
+	require.NoError(t, err)
+	assert.Equal(t, engine.PrivacyFlagStateValidation, metadata.PrivacyFlag)
+	assert.Equal(t, creation, metadata.CreationTxHash)
+	managedParties, err := destState.GetManagedParties(contract)
+	require.NoError(t, err)
+	assert.Equal(t, parties, managedParties)
+	destState.IntermediateRoot(true)
+	srcRoot, _ := source.GetStorageRoot(contract)
+	destRoot, _ := destState.GetStorageRoot(contract)
+	assert.Equal(t, srcRoot, destRoot)
+
+	// a changed dump isn't signed by the node anymore
+	account := imported.Accounts[contract]
+	account.Storage[slot] = common.Bytes2Hex(common.HexToHash("0xcc").Bytes())
+	signer, err = imported.Signer()
+	require.NoError(t, err)
+	assert.NotEqual(t, crypto.PubkeyToAddress(key.PublicKey), signer)
+}
+
+func TestPrivateStateDump_WholeState(t *testing.T) {
+	source := newDumpTestState(t)
+	source.SetCode(common.HexToAddress("0x1"), []byte{0x60, 0x00})
+	source.SetCode(common.HexToAddress("0x2"), []byte{0x60, 0x01})
+	root, err := source.Commit(true)
+	require.NoError(t, err)
+	source, err = state.New(root, source.Database(), nil)
+	require.NoError(t, err)
+
+	dump, err := DumpPrivateState(types.DefaultPrivateStateIdentifier, types.NewBlockWithHeader(&types.Header{Number: common.Big1}), source, nil)
+	require.NoError(t, err)
+	assert.Len(t, dump.Accounts, 2)
+	assert.Equal(t, root, dump.Root)
+
+	_, err = dump.Signer()
+	assert.Error(t, err, "unsigned")
+}
+
+func TestPrivateStateDump_MissingPreimages(t *testing.T) {
+	contract := common.HexToAddress("0x1")
+	source, err := state.New(common.Hash{}, state.NewDatabaseWithConfig(rawdb.NewMemoryDatabase(), &trie.Config{Preimages: false}), nil)
+	require.NoError(t, err)
+	source.SetCode(contract, []byte{0x60, 0x00})
+	source.SetState(contract, common.HexToHash("0xaa"), common.HexToHash("0xbb"))
+	root, err := source.Commit(true)
+	require.NoError(t, err)
+	source, err = state.New(root, source.Database(), nil)
+	require.NoError(t, err)
+
+	block := types.NewBlockWithHeader(&types.Header{Number: common.Big1})
+	_, err = DumpPrivateState(types.DefaultPrivateStateIdentifier, block, source, []common.Address{contract})
+	assert.True(t, errors.Is(err, errMissingSlotPreimage), "have %v", err)
+
+	_, err = DumpPrivateState(types.DefaultPrivateStateIdentifier, block, source, nil)
+	assert.True(t, errors.Is(err, errMissingPreimage), "have %v", err)
+}
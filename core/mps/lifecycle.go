@@ -0,0 +1,278 @@
+package mps
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// PrivateStateArchiveVersion is the version of the retired private state archive format
+const PrivateStateArchiveVersion = 1
+
+var (
+	emptyCodeHash = crypto.Keccak256Hash(nil)
+
+	// ErrRetiredPrivateState is returned for private states that were retired and
+	// the parties that were residents of one
+	ErrRetiredPrivateState = errors.New("private state was retired")
+)
+
+// RetiredPrivateState records the retirement of a private state
+type RetiredPrivateState struct {
+	PSI         types.PrivateStateIdentifier `json:"psi"`
+	Name        string                       `json:"name"`
+	Addresses   []string                     `json:"addresses"` // residents of the private state
+	BlockNumber uint64                       `json:"blockNumber"`
+	BlockHash   common.Hash                  `json:"blockHash"`
+	Root        common.Hash                  `json:"root"` // of the private state at the block
+	Archive     string                       `json:"archive"`
+	PrunedNodes uint64                       `json:"prunedNodes"`
+}
+
+// RuntimePrivateStates keeps track of the private states created and retired
+// while the node runs, on top of the privacy groups of the transaction manager.
+// A created private state starts from its seed the first time it's used, a
+// retired one can't be used nor created again.
+type RuntimePrivateStates struct {
+	db ethdb.Database
+
+	mux     sync.RWMutex
+	seeds   map[types.PrivateStateIdentifier]common.Hash
+	retired map[types.PrivateStateIdentifier]*RetiredPrivateState
+}
+
+// LoadRuntimePrivateStates reads the private states created and retired at
+// runtime from the database
+func LoadRuntimePrivateStates(db ethdb.Database) (*RuntimePrivateStates, error) {
+	r := &RuntimePrivateStates{
+		db:      db,
+		seeds:   rawdb.ReadPrivateStateSeeds(db),
+		retired: make(map[types.PrivateStateIdentifier]*RetiredPrivateState),
+	}
+	for psi, data := range rawdb.ReadRetiredPrivateStates(db) {
+		record := new(RetiredPrivateState)
+		if err := rlp.DecodeBytes(data, record); err != nil {
+			return nil, fmt.Errorf("invalid retirement record of private state %s: %v", psi, err)
+		}
+		r.retired[psi] = record
+	}
+	return r, nil
+}
+
+// Seed returns the root the private state created at runtime starts from
+func (r *RuntimePrivateStates) Seed(psi types.PrivateStateIdentifier) (common.Hash, bool) {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	root, found := r.seeds[psi]
+	return root, found
+}
+
+// Retired returns the retirement record of the private state, nil if it's not retired
+func (r *RuntimePrivateStates) Retired(psi types.PrivateStateIdentifier) *RetiredPrivateState {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	return r.retired[psi]
+}
+
+// RetiredParty returns whether the party was a resident of a retired private state
+func (r *RuntimePrivateStates) RetiredParty(party string) bool {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	for _, record := range r.retired {
+		for _, address := range record.Addresses {
+			if address == party {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Create persists the root the private state starts from
+func (r *RuntimePrivateStates) Create(psi types.PrivateStateIdentifier, seed common.Hash) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if _, retired := r.retired[psi]; retired {
+		return fmt.Errorf("private state %s was retired, it can't be created again", psi)
+	}
+	if err := rawdb.WritePrivateStateSeed(r.db, psi, seed); err != nil {
+		return err
+	}
+	r.seeds[psi] = seed
+	return nil
+}
+
+// Retire persists the retirement record of the private state
+func (r *RuntimePrivateStates) Retire(record *RetiredPrivateState) error {
+	data, err := rlp.EncodeToBytes(record)
+	if err != nil {
+		return err
+	}
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if err := rawdb.WriteRetiredPrivateState(r.db, record.PSI, data); err != nil {
+		return err
+	}
+	r.retired[record.PSI] = record
+	return nil
+}
+
+// privateStateArchiveHeader starts an archive of a retired private state, it's
+// followed by an archiveEntry for every trie node and contract code of the
+// private state
+type privateStateArchiveHeader struct {
+	Version     uint
+	PSI         types.PrivateStateIdentifier
+	Name        string
+	Description string
+	Type        PrivateStateType
+	Addresses   []string
+	BlockNumber uint64
+	BlockHash   common.Hash
+	Root        common.Hash
+}
+
+type archiveEntry struct {
+	Code bool
+	Hash common.Hash
+	Blob []byte
+}
+
+// ArchivePrivateState writes the private state with the given root to the
+// archive as an RLP stream. The trie nodes are read through the trie database of
+// the private state, as with trie garbage collection the recent ones are only in
+// memory.
+func ArchivePrivateState(w io.Writer, cache state.Database, metadata *PrivateStateMetadata, block *types.Block, root common.Hash) error {
+	header := &privateStateArchiveHeader{
+		Version:     PrivateStateArchiveVersion,
+		PSI:         metadata.ID,
+		Name:        metadata.Name,
+		Description: metadata.Description,
+		Type:        metadata.Type,
+		Addresses:   metadata.Addresses,
+		BlockNumber: block.NumberU64(),
+		BlockHash:   block.Hash(),
+		Root:        root,
+	}
+	if err := rlp.Encode(w, header); err != nil {
+		return err
+	}
+	var (
+		nodes = make(map[common.Hash]struct{})
+		codes = make(map[common.Hash]struct{})
+		err   error
+	)
+	write := func(hash common.Hash, code bool) error {
+		entry := &archiveEntry{Code: code, Hash: hash}
+		if code {
+			entry.Blob = rawdb.ReadCode(cache.TrieDB().DiskDB(), hash)
+		} else {
+			entry.Blob, _ = cache.TrieDB().Node(hash)
+		}
+		if len(entry.Blob) == 0 {
+			return fmt.Errorf("missing node %x of private state %s", hash, metadata.ID)
+		}
+		return rlp.Encode(w, entry)
+	}
+	visit := func(hash common.Hash) bool {
+		if _, found := nodes[hash]; found || err != nil {
+			return false
+		}
+		nodes[hash] = struct{}{}
+		err = write(hash, false)
+		return err == nil
+	}
+	walkErr := walkState(cache, root, visit, func(hash common.Hash) error {
+		if _, found := codes[hash]; found {
+			return nil
+		}
+		codes[hash] = struct{}{}
+		return write(hash, true)
+	})
+	if err != nil {
+		return err
+	}
+	return walkErr
+}
+
+// PrunePrivateState deletes the trie nodes of the retired private state with the
+// given root that no other state has. The states of every canonical block up to
+// head are marked first, whether in memory or on disk: the public states, read
+// from public, and the private states that aren't retired and the trie of private
+// states, read from private. The seeds of the private states created at runtime
+// are marked too. Contract code is kept.
+func PrunePrivateState(db ethdb.Database, public, private state.Database, root common.Hash, head uint64, runtime *RuntimePrivateStates) (uint64, error) {
+	marked := make(map[common.Hash]struct{})
+	mark := func(hash common.Hash) bool {
+		if _, found := marked[hash]; found {
+			return false
+		}
+		marked[hash] = struct{}{}
+		return true
+	}
+	for n := uint64(0); n <= head; n++ {
+		header := rawdb.ReadHeader(db, rawdb.ReadCanonicalHash(db, n), n)
+		if header == nil {
+			return 0, errors.New("missing canonical header")
+		}
+		// a block whose states are gone has nothing left to keep
+		if err := walkState(public, header.Root, mark, nil); err != nil {
+			log.Trace("Public state of block unavailable", "number", n, "err", err)
+		}
+		if err := walkPrivateStates(db, private, header.Root, runtime, mark); err != nil {
+			log.Trace("Private states of block unavailable", "number", n, "err", err)
+		}
+	}
+	runtime.mux.RLock()
+	seeds := make([]common.Hash, 0, len(runtime.seeds))
+	for psi, seed := range runtime.seeds {
+		if runtime.retired[psi] == nil {
+			seeds = append(seeds, seed)
+		}
+	}
+	runtime.mux.RUnlock()
+	for _, seed := range seeds {
+		if err := walkState(private, seed, mark, nil); err != nil {
+			return 0, err
+		}
+	}
+
+	candidates := make(map[common.Hash]struct{})
+	collect := func(hash common.Hash) bool {
+		if _, found := marked[hash]; found {
+			return false
+		}
+		if _, found := candidates[hash]; found {
+			return false
+		}
+		candidates[hash] = struct{}{}
+		return true
+	}
+	if err := walkState(private, root, collect, nil); err != nil {
+		return 0, err
+	}
+	batch := db.NewBatch()
+	for hash := range candidates {
+		rawdb.DeleteTrieNode(batch, hash)
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return 0, err
+			}
+			batch.Reset()
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return 0, err
+	}
+	return uint64(len(candidates)), nil
+}
@@ -0,0 +1,109 @@
+package mps
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchivePrivateStateInMemory(t *testing.T) {
+	var (
+		db       = rawdb.NewMemoryDatabase()
+		cache    = state.NewDatabase(db)
+		contract = common.HexToAddress("0x1932c48b2bf8102ba33b4a6b545c32236e342f34")
+	)
+	privateState, _ := state.New(common.Hash{}, cache, nil)
+	privateState.SetCode(contract, []byte{0x60, 0x00})
+	privateState.SetState(contract, common.Hash{}, common.HexToHash("0x01"))
+	root, err := privateState.Commit(true)
+	require.NoError(t, err)
+	// the trie nodes are only in memory, as with trie garbage collection
+	require.Nil(t, rawdb.ReadTrieNode(db, root))
+
+	var (
+		archive  bytes.Buffer
+		metadata = &PrivateStateMetadata{ID: "psi1", Name: "psi1", Type: Resident}
+		block    = types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)})
+	)
+	require.NoError(t, ArchivePrivateState(&archive, cache, metadata, block, root))
+
+	stream := rlp.NewStream(&archive, 0)
+	header := new(privateStateArchiveHeader)
+	require.NoError(t, stream.Decode(header))
+	assert.Equal(t, root, header.Root)
+	var nodes, codes int
+	for {
+		entry := new(archiveEntry)
+		if err := stream.Decode(entry); err != nil {
+			break
+		}
+		if entry.Code {
+			codes++
+		} else {
+			nodes++
+		}
+	}
+	assert.Equal(t, 1, codes)
+	assert.NotZero(t, nodes)
+}
+
+func TestPrunePrivateStateKeepsSharedNodes(t *testing.T) {
+	var (
+		db       = rawdb.NewMemoryDatabase()
+		cache    = state.NewDatabase(db)
+		retired  = types.PrivateStateIdentifier("retired")
+		kept     = types.PrivateStateIdentifier("kept")
+		contract = common.HexToAddress("0x1932c48b2bf8102ba33b4a6b545c32236e342f34")
+		parent   common.Hash
+		roots    = make(map[uint64]common.Hash)
+	)
+	// the storage of kept at block 0 is the one of retired at block 2
+	for n := uint64(0); n <= 2; n++ {
+		header := &types.Header{Number: new(big.Int).SetUint64(n), ParentHash: parent}
+		statesRoot := common.Hash{}
+		if n > 0 {
+			statesRoot = rawdb.GetPrivateStatesTrieRoot(db, rawdb.ReadHeader(db, parent, n-1).Root)
+		}
+		repo, err := NewMultiplePrivateStateRepository(db, cache, statesRoot)
+		require.NoError(t, err)
+		psi, value := kept, common.BigToHash(new(big.Int).SetUint64(n+1))
+		if n == 2 {
+			psi, value = retired, common.HexToHash("0x01")
+		}
+		privateState, err := repo.StatePSI(psi)
+		require.NoError(t, err)
+		privateState.SetNonce(contract, 1)
+		privateState.SetState(contract, common.Hash{}, value)
+		roots[n] = privateState.IntermediateRoot(true)
+		header.Root = common.BigToHash(new(big.Int).SetUint64(n + 1))
+		statesRoot, err = repo.CommitAndWrite(true, types.NewBlockWithHeader(header))
+		require.NoError(t, err)
+		require.NoError(t, cache.TrieDB().Commit(statesRoot, false, nil))
+
+		rawdb.WriteHeader(db, header)
+		rawdb.WriteCanonicalHash(db, header.Hash(), n)
+		parent = header.Hash()
+	}
+	require.Equal(t, roots[0], roots[2])
+
+	runtime, err := LoadRuntimePrivateStates(db)
+	require.NoError(t, err)
+	require.NoError(t, runtime.Retire(&RetiredPrivateState{PSI: retired, BlockNumber: 2, Root: roots[2]}))
+	_, err = PrunePrivateState(db, cache, cache, roots[2], 2, runtime)
+	require.NoError(t, err)
+
+	// kept at block 0 still has every node it shared with retired
+	cache = state.NewDatabase(db)
+	for n := uint64(0); n <= 1; n++ {
+		blockRoot := rawdb.ReadHeader(db, rawdb.ReadCanonicalHash(db, n), n).Root
+		assert.NoError(t, walkPrivateStates(db, cache, blockRoot, runtime, func(common.Hash) bool { return true }), "private states of block %d", n)
+	}
+}
@@ -1,6 +1,7 @@
 package mps
 
 import (
+	"fmt"
 	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -8,6 +9,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/trie"
 )
 
 type StateRootProviderFunc func(isEIP158 bool) (common.Hash, error)
@@ -29,6 +31,12 @@ type MultiplePrivateStateRepository struct {
 	mux sync.Mutex
 	// managed states map
 	managedStates map[types.PrivateStateIdentifier]*managedState
+
+	// private states created and retired at runtime, may be nil
+	runtime *RuntimePrivateStates
+	// whether the private states are garbage collected with the trie of private
+	// states instead of being written to disk
+	trieGC bool
 }
 
 var _ PrivateStateRepository = (*MultiplePrivateStateRepository)(nil) // MultiplePrivateStateRepository must implement PrivateStateRepository
@@ -47,18 +55,35 @@ func NewMultiplePrivateStateRepository(db ethdb.Database, cache state.Database,
 	return repo, nil
 }
 
+// SetRuntimePrivateStates makes the repository start the private states created
+// at runtime from their seed and refuse to open the retired ones
+func (mpsr *MultiplePrivateStateRepository) SetRuntimePrivateStates(runtime *RuntimePrivateStates) {
+	mpsr.runtime = runtime
+}
+
+// EnableTrieGC makes the repository commit the private states to the trie
+// database of the trie of private states, which references them, instead of
+// writing them to disk, so they're garbage collected with the trie of private
+// states
+func (mpsr *MultiplePrivateStateRepository) EnableTrieGC() {
+	mpsr.trieGC = true
+}
+
 // A managed state is a pair of stateDb and it's corresponding stateCache objects
 // Although right now we may not need a separate stateCache it may be useful if we'll do multiple managed state commits in parallel
 type managedState struct {
 	stateDb               *state.StateDB
 	stateCache            state.Database
 	stateRootProviderFunc StateRootProviderFunc
+	// inMemory leaves the committed trie nodes in the trie database of stateCache
+	inMemory bool
 }
 
 func (ms *managedState) Copy() *managedState {
 	copy := &managedState{
 		stateDb:    ms.stateDb.Copy(),
 		stateCache: ms.stateCache,
+		inMemory:   ms.inMemory,
 	}
 	copy.stateRootProviderFunc = copy.calPrivateStateRoot
 	return copy
@@ -70,6 +95,9 @@ func (ms *managedState) calPrivateStateRoot(isEIP158 bool) (common.Hash, error)
 	if err != nil {
 		return common.Hash{}, err
 	}
+	if ms.inMemory {
+		return privateRoot, nil
+	}
 	err = ms.stateCache.TrieDB().Commit(privateRoot, false, nil)
 	if err != nil {
 		return common.Hash{}, err
@@ -96,12 +124,21 @@ func (mpsr *MultiplePrivateStateRepository) StatePSI(psi types.PrivateStateIdent
 	if found {
 		return ms.stateDb, nil
 	}
+	if mpsr.runtime != nil && mpsr.runtime.Retired(psi) != nil {
+		return nil, fmt.Errorf("%w: %s", ErrRetiredPrivateState, psi)
+	}
 	privateStateRoot, err := mpsr.trie.TryGet([]byte(psi))
 	if err != nil {
 		return nil, err
 	}
 	var stateCache state.Database
 	var stateDB *state.StateDB
+	if privateStateRoot == nil && mpsr.runtime != nil {
+		// a private state created at runtime starts from its seed
+		if seed, found := mpsr.runtime.Seed(psi); found {
+			privateStateRoot = seed.Bytes()
+		}
+	}
 	if privateStateRoot == nil && psi != EmptyPrivateStateMetadata.ID {
 		// this is the first time we are trying to use this private state so branch from the empty state
 		emptyState, err := mpsr.DefaultState()
@@ -116,6 +153,10 @@ func (mpsr *MultiplePrivateStateRepository) StatePSI(psi types.PrivateStateIdent
 		stateCache = ms.stateCache
 	} else {
 		stateCache = state.NewDatabase(mpsr.db)
+		if mpsr.trieGC {
+			// the trie nodes of the private state may not be on disk yet
+			stateCache = mpsr.repoCache
+		}
 		stateDB, err = state.New(common.BytesToHash(privateStateRoot), stateCache, nil)
 		if err != nil {
 			return nil, err
@@ -126,6 +167,7 @@ func (mpsr *MultiplePrivateStateRepository) StatePSI(psi types.PrivateStateIdent
 	managedState := &managedState{
 		stateCache: stateCache,
 		stateDb:    stateDB,
+		inMemory:   mpsr.trieGC,
 	}
 	managedState.stateRootProviderFunc = managedState.calPrivateStateRoot
 	mpsr.managedStates[psi] = managedState
@@ -170,7 +212,17 @@ func (mpsr *MultiplePrivateStateRepository) CommitAndWrite(isEIP158 bool, block
 		}
 	}
 	// commit the trie of states
-	mtRoot, err := mpsr.trie.Commit(nil)
+	var onleaf trie.LeafCallback
+	if mpsr.trieGC {
+		// keep the private states alive as long as the trie of states
+		onleaf = func(path []byte, leaf []byte, parent common.Hash) error {
+			if privateRoot := common.BytesToHash(leaf); privateRoot != types.EmptyRootHash {
+				mpsr.repoCache.TrieDB().Reference(privateRoot, parent)
+			}
+			return nil
+		}
+	}
+	mtRoot, err := mpsr.trie.Commit(onleaf)
 	if err != nil {
 		return mtRoot, err
 	}
@@ -214,6 +266,8 @@ func (mpsr *MultiplePrivateStateRepository) Copy() PrivateStateRepository {
 		repoCache:     mpsr.repoCache,
 		trie:          mpsr.repoCache.CopyTrie(mpsr.trie),
 		managedStates: managedStatesCopy,
+		runtime:       mpsr.runtime,
+		trieGC:        mpsr.trieGC,
 	}
 }
 
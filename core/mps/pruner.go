@@ -0,0 +1,237 @@
+package mps
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// PruneStats reports what PrunePrivateStates did
+type PruneStats struct {
+	Head     uint64 // number of the head block
+	Retained uint64 // number of the oldest block whose private state is kept
+	Restart  uint64 // number of the block the node restarts from
+	Nodes    uint64 // trie nodes deleted
+	Missing  uint64 // private states that were already incomplete, or never written to disk
+}
+
+// PrunePrivateStates deletes, from a database no node runs on, the trie nodes of
+// the private states of the blocks before the most recent retain ones.
+//
+// The private states of the most recent blocks are kept, as well as those of
+// the block the node restarts from, which is the most recent block whose public
+// state is on disk, and the seeds of the private states created at runtime.
+// Trie nodes the kept private states or any public state on disk has are never
+// deleted. Contract code and the mappings from the block roots to the private
+// state roots are kept. Retained blocks whose private states aren't on disk, as
+// trie garbage collection only writes some of them, count as missing.
+func PrunePrivateStates(db ethdb.Database, retain uint64) (*PruneStats, error) {
+	headHash := rawdb.ReadHeadBlockHash(db)
+	number := rawdb.ReadHeaderNumber(db, headHash)
+	if number == nil {
+		return nil, errors.New("head block not found")
+	}
+	runtime, err := LoadRuntimePrivateStates(db)
+	if err != nil {
+		return nil, err
+	}
+	stats := &PruneStats{Head: *number, Restart: *number}
+	if stats.Head >= retain {
+		stats.Retained = stats.Head - retain + 1
+	}
+	if stats.Retained == 0 {
+		return stats, nil
+	}
+	var (
+		cache  = state.NewDatabase(db)
+		marked = make(map[common.Hash]struct{})
+		roots  = make([]common.Hash, stats.Head+1)
+		start  = time.Now()
+	)
+	for n := uint64(0); n <= stats.Head; n++ {
+		header := rawdb.ReadHeader(db, rawdb.ReadCanonicalHash(db, n), n)
+		if header == nil {
+			return nil, errors.New("missing canonical header")
+		}
+		roots[n] = header.Root
+	}
+	for stats.Restart > 0 && len(rawdb.ReadTrieNode(db, roots[stats.Restart])) == 0 {
+		stats.Restart--
+	}
+	mark := func(hash common.Hash) bool {
+		if _, found := marked[hash]; found {
+			return false
+		}
+		marked[hash] = struct{}{}
+		return true
+	}
+	for n := stats.Retained; n <= stats.Head; n++ {
+		// with trie garbage collection, the private states of most recent blocks
+		// were only in memory
+		if err := walkPrivateStates(db, cache, roots[n], runtime, mark); err != nil {
+			log.Debug("Retained private state incomplete", "number", n, "err", err)
+			stats.Missing++
+		}
+	}
+	if stats.Restart < stats.Retained {
+		if err := walkPrivateStates(db, cache, roots[stats.Restart], runtime, mark); err != nil {
+			return nil, err
+		}
+	}
+	for psi, seed := range runtime.seeds {
+		if runtime.retired[psi] == nil {
+			if err := walkState(cache, seed, mark, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+	// the private states may share trie nodes with the public states
+	for n, root := range roots {
+		if len(rawdb.ReadTrieNode(db, root)) == 0 {
+			continue
+		}
+		if err := walkState(cache, root, mark, nil); err != nil {
+			log.Warn("Public state incomplete, not pruning the private state nodes it's missing", "number", n, "err", err)
+		}
+	}
+	log.Info("Marked the private states to keep", "nodes", len(marked), "elapsed", common.PrettyDuration(time.Since(start)))
+
+	candidates := make(map[common.Hash]struct{})
+	collect := func(hash common.Hash) bool {
+		if _, found := marked[hash]; found {
+			return false
+		}
+		if _, found := candidates[hash]; found {
+			return false
+		}
+		candidates[hash] = struct{}{}
+		return true
+	}
+	for n := uint64(0); n < stats.Retained; n++ {
+		if n == stats.Restart {
+			continue
+		}
+		if err := walkPrivateStates(db, cache, roots[n], nil, collect); err != nil {
+			log.Debug("Private state incomplete", "number", n, "err", err)
+			stats.Missing++
+		}
+	}
+	batch := db.NewBatch()
+	for hash := range candidates {
+		rawdb.DeleteTrieNode(batch, hash)
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return nil, err
+			}
+			batch.Reset()
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return nil, err
+	}
+	stats.Nodes = uint64(len(candidates))
+	log.Info("Pruned the private states", "nodes", stats.Nodes, "elapsed", common.PrettyDuration(time.Since(start)))
+	return stats, nil
+}
+
+// walkPrivateStates calls visit with the trie nodes of the private states of the
+// block with the given root and, with multiple private states, of its trie of
+// private states, skipping the retired private states if runtime is set
+func walkPrivateStates(db ethdb.Database, cache state.Database, blockRoot common.Hash, runtime *RuntimePrivateStates, visit func(common.Hash) bool) error {
+	statesRoot := rawdb.GetPrivateStatesTrieRoot(db, blockRoot)
+	if statesRoot == (common.Hash{}) {
+		return walkState(cache, rawdb.GetPrivateStateRoot(db, blockRoot), visit, nil)
+	}
+	retired := make(map[common.Hash]bool)
+	if runtime != nil {
+		for psi := range runtime.retired {
+			retired[crypto.Keccak256Hash([]byte(psi))] = true
+		}
+	}
+	var privateRoots []common.Hash
+	err := walkTrie(cache, statesRoot, visit, func(key, blob []byte) error {
+		if !retired[common.BytesToHash(key)] {
+			privateRoots = append(privateRoots, common.BytesToHash(blob))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, root := range privateRoots {
+		if err := walkState(cache, root, visit, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walkState calls visit with the trie nodes of the state with the given root, of
+// its storage tries and of its account extra data trie, not descending into the
+// nodes visit returns false for, and onCode, if set, with the code hash of the
+// contracts it reaches
+func walkState(db state.Database, root common.Hash, visit func(common.Hash) bool, onCode func(common.Hash) error) error {
+	err := walkTrie(db, root, visit, func(key, blob []byte) error {
+		var account state.Account
+		if err := rlp.DecodeBytes(blob, &account); err != nil {
+			return err
+		}
+		if account.Root != types.EmptyRootHash {
+			storage, err := db.OpenStorageTrie(common.BytesToHash(key), account.Root)
+			if err != nil {
+				return err
+			}
+			if err := walkNodes(storage.NodeIterator(nil), visit, nil); err != nil {
+				return err
+			}
+		}
+		if onCode != nil && !bytes.Equal(account.CodeHash, emptyCodeHash.Bytes()) {
+			return onCode(common.BytesToHash(account.CodeHash))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return walkTrie(db, db.AccountExtraDataLinker().GetAccountExtraDataRoot(root), visit, nil)
+}
+
+// walkTrie opens the trie with the given root and walks its nodes
+func walkTrie(db state.Database, root common.Hash, visit func(common.Hash) bool, onLeaf func(key, blob []byte) error) error {
+	if root == (common.Hash{}) || root == types.EmptyRootHash {
+		return nil
+	}
+	tr, err := db.OpenTrie(root)
+	if err != nil {
+		return err
+	}
+	return walkNodes(tr.NodeIterator(nil), visit, onLeaf)
+}
+
+// walkNodes calls visit with every trie node of the iterator, not descending into
+// the nodes it returns false for, and onLeaf, if set, with the leaves it reaches
+func walkNodes(it trie.NodeIterator, visit func(common.Hash) bool, onLeaf func(key, blob []byte) error) error {
+	for descend := true; it.Next(descend); {
+		descend = true
+		// nodes embedded in their parent have no hash of their own
+		if hash := it.Hash(); hash != (common.Hash{}) {
+			descend = visit(hash)
+		}
+		if onLeaf != nil && it.Leaf() {
+			if err := onLeaf(it.LeafKey(), it.LeafBlob()); err != nil {
+				return err
+			}
+		}
+	}
+	return it.Error()
+}
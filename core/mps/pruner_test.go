@@ -0,0 +1,83 @@
+package mps
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrunePrivateStates(t *testing.T) {
+	var (
+		db       = rawdb.NewMemoryDatabase()
+		cache    = state.NewDatabase(db)
+		psi      = types.PrivateStateIdentifier("psi1")
+		contract = common.HexToAddress("0x1932c48b2bf8102ba33b4a6b545c32236e342f34")
+		parent   common.Hash
+	)
+	// the public states of blocks 0 and 3 only are on disk
+	for n := uint64(0); n <= 5; n++ {
+		header := &types.Header{Number: new(big.Int).SetUint64(n), ParentHash: parent, Root: crypto.Keccak256Hash(new(big.Int).SetUint64(n).Bytes())}
+		if n == 0 || n == 3 {
+			public, _ := state.New(common.Hash{}, cache, nil)
+			public.AddBalance(contract, new(big.Int).SetUint64(n+1))
+			root, err := public.Commit(true)
+			require.NoError(t, err)
+			require.NoError(t, cache.TrieDB().Commit(root, false, nil))
+			header.Root = root
+		}
+		statesRoot := common.Hash{}
+		if n > 0 {
+			statesRoot = rawdb.GetPrivateStatesTrieRoot(db, rawdb.ReadHeader(db, parent, n-1).Root)
+		}
+		repo, err := NewMultiplePrivateStateRepository(db, cache, statesRoot)
+		require.NoError(t, err)
+		privateState, err := repo.StatePSI(psi)
+		require.NoError(t, err)
+		privateState.SetNonce(contract, 1)
+		privateState.SetState(contract, common.BigToHash(new(big.Int).SetUint64(n)), common.HexToHash("0x01"))
+		privateState.SetState(contract, common.Hash{}, common.BigToHash(new(big.Int).SetUint64(n)))
+		statesRoot, err = repo.CommitAndWrite(true, types.NewBlockWithHeader(header))
+		require.NoError(t, err)
+		require.NoError(t, cache.TrieDB().Commit(statesRoot, false, nil))
+
+		rawdb.WriteHeader(db, header)
+		rawdb.WriteCanonicalHash(db, header.Hash(), n)
+		rawdb.WriteHeadBlockHash(db, header.Hash())
+		parent = header.Hash()
+	}
+
+	stats, err := PrunePrivateStates(db, 2)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), stats.Head)
+	assert.Equal(t, uint64(4), stats.Retained)
+	assert.Equal(t, uint64(3), stats.Restart)
+	assert.NotZero(t, stats.Nodes)
+	assert.Zero(t, stats.Missing)
+
+	cache = state.NewDatabase(db)
+	for n := uint64(0); n <= 5; n++ {
+		blockRoot := rawdb.ReadHeader(db, rawdb.ReadCanonicalHash(db, n), n).Root
+		err := walkPrivateStates(db, cache, blockRoot, nil, func(common.Hash) bool { return true })
+		if n < 3 {
+			assert.Error(t, err, "private state of block %d", n)
+			continue
+		}
+		require.NoError(t, err, "private state of block %d", n)
+		repo, err := NewMultiplePrivateStateRepository(db, cache, rawdb.GetPrivateStatesTrieRoot(db, blockRoot))
+		require.NoError(t, err)
+		privateState, err := repo.StatePSI(psi)
+		require.NoError(t, err)
+		assert.Equal(t, common.BigToHash(new(big.Int).SetUint64(n)), privateState.GetState(contract, common.Hash{}))
+	}
+	// the public states on disk are untouched
+	public, err := state.New(rawdb.ReadHeader(db, rawdb.ReadCanonicalHash(db, 0), 0).Root, cache, nil)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(1), public.GetBalance(contract))
+}
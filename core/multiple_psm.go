@@ -3,6 +3,7 @@ package core
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/mps"
@@ -19,27 +20,100 @@ type MultiplePrivateStateManager struct {
 	db                     ethdb.Database
 	privateStatesTrieCache state.Database
 
+	// mux protects the maps of private states, which change when private states
+	// are created and retired at runtime
+	mux                sync.RWMutex
 	residentGroupByKey map[string]*mps.PrivateStateMetadata
 	privacyGroupById   map[types.PrivateStateIdentifier]*mps.PrivateStateMetadata
+	runtime            *mps.RuntimePrivateStates
+	// trieGC garbage collects the private states with the trie of private states
+	trieGC bool
 }
 
 func newMultiplePrivateStateManager(db ethdb.Database, config *trie.Config, residentGroupByKey map[string]*mps.PrivateStateMetadata, privacyGroupById map[types.PrivateStateIdentifier]*mps.PrivateStateMetadata) (*MultiplePrivateStateManager, error) {
-	return &MultiplePrivateStateManager{
+	runtime, err := mps.LoadRuntimePrivateStates(db)
+	if err != nil {
+		return nil, err
+	}
+	m := &MultiplePrivateStateManager{
 		db:                     db,
 		privateStatesTrieCache: state.NewDatabaseWithConfig(db, config),
 		residentGroupByKey:     residentGroupByKey,
 		privacyGroupById:       privacyGroupById,
-	}, nil
+		runtime:                runtime,
+	}
+	// the transaction manager may still have the privacy groups of retired private states
+	for psi := range privacyGroupById {
+		if runtime.Retired(psi) != nil {
+			m.remove(psi)
+		}
+	}
+	return m, nil
 }
 
 func (m *MultiplePrivateStateManager) StateRepository(blockHash common.Hash) (mps.PrivateStateRepository, error) {
 	privateStatesTrieRoot := rawdb.GetPrivateStatesTrieRoot(m.db, blockHash)
-	return mps.NewMultiplePrivateStateRepository(m.db, m.privateStatesTrieCache, privateStatesTrieRoot)
+	repo, err := mps.NewMultiplePrivateStateRepository(m.db, m.privateStatesTrieCache, privateStatesTrieRoot)
+	if err != nil {
+		return nil, err
+	}
+	repo.SetRuntimePrivateStates(m.runtime)
+	if m.trieGC {
+		repo.EnableTrieGC()
+	}
+	return repo, nil
+}
+
+// add starts managing the private state, failing if it's managed already or
+// any of its residents is resident of another private state
+func (m *MultiplePrivateStateManager) add(metadata *mps.PrivateStateMetadata) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if existing, found := m.privacyGroupById[metadata.ID]; found {
+		return fmt.Errorf("private state %s already exists with name %s", metadata.ID, existing.Name)
+	}
+	if metadata.Type == mps.Resident {
+		for _, address := range metadata.Addresses {
+			if existing, found := m.residentGroupByKey[address]; found {
+				return fmt.Errorf("same address is part of two different groups: address=%s existing.Name=%s duplicate.Name=%s", address, existing.Name, metadata.Name)
+			}
+		}
+		for _, address := range metadata.Addresses {
+			m.residentGroupByKey[address] = metadata
+		}
+	}
+	m.privacyGroupById[metadata.ID] = metadata
+	return nil
+}
+
+// remove stops managing the private state
+func (m *MultiplePrivateStateManager) remove(psi types.PrivateStateIdentifier) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	delete(m.privacyGroupById, psi)
+	for address, metadata := range m.residentGroupByKey {
+		if metadata.ID == psi {
+			delete(m.residentGroupByKey, address)
+		}
+	}
+}
+
+// metadata returns the metadata of a managed private state
+func (m *MultiplePrivateStateManager) metadata(psi types.PrivateStateIdentifier) (*mps.PrivateStateMetadata, bool) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	metadata, found := m.privacyGroupById[psi]
+	return metadata, found
 }
 
 func (m *MultiplePrivateStateManager) ResolveForManagedParty(managedParty string) (*mps.PrivateStateMetadata, error) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
 	psm, found := m.residentGroupByKey[managedParty]
 	if !found {
+		if m.runtime.RetiredParty(managedParty) {
+			return nil, fmt.Errorf("%w: managed party %s", mps.ErrRetiredPrivateState, managedParty)
+		}
 		return nil, fmt.Errorf("unable to find private state metadata for managed party %s", managedParty)
 	}
 	return psm, nil
@@ -50,7 +124,7 @@ func (m *MultiplePrivateStateManager) ResolveForUserContext(ctx context.Context)
 	if !ok {
 		psi = types.DefaultPrivateStateIdentifier
 	}
-	psm, found := m.privacyGroupById[psi]
+	psm, found := m.metadata(psi)
 	if !found {
 		return nil, fmt.Errorf("unable to find private state for context psi %s", psi)
 	}
@@ -58,6 +132,8 @@ func (m *MultiplePrivateStateManager) ResolveForUserContext(ctx context.Context)
 }
 
 func (m *MultiplePrivateStateManager) PSIs() []types.PrivateStateIdentifier {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
 	psis := make([]types.PrivateStateIdentifier, 0, len(m.privacyGroupById))
 	for psi := range m.privacyGroupById {
 		psis = append(psis, psi)
@@ -1,8 +1,11 @@
 package core
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
+	"errors"
+	"fmt"
 	"math/big"
 	"testing"
 
@@ -20,6 +23,7 @@ import (
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -162,6 +166,49 @@ func TestMultiplePSMRStateCreated(t *testing.T) {
 	}
 }
 
+func TestMultiplePSMRSkipsRetiredParties(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockptm := private.NewMockPrivateTransactionManager(mockCtrl)
+
+	saved := private.P
+	defer func() {
+		private.P = saved
+	}()
+	private.P = mockptm
+
+	mockpsm := mps.NewMockPrivateStateManager(mockCtrl)
+
+	mockptm.EXPECT().Receive(gomock.Not(common.EncryptedPayloadHash{})).Return("", []string{"psi1", "psi2"}, common.FromHex(testCode), nil, nil).AnyTimes()
+	mockptm.EXPECT().Receive(common.EncryptedPayloadHash{}).Return("", []string{}, common.EncryptedPayloadHash{}.Bytes(), nil, nil).AnyTimes()
+	mockptm.EXPECT().HasFeature(engine.MultiplePrivateStates).Return(true)
+	mockptm.EXPECT().Groups().Return(PrivacyGroups, nil).AnyTimes()
+
+	// psi2 was retired, the transaction manager still lists its party
+	mockpsm.EXPECT().ResolveForManagedParty("psi1").Return(&PSI1PSM, nil).AnyTimes()
+	mockpsm.EXPECT().ResolveForManagedParty("psi2").Return(nil, fmt.Errorf("%w: managed party psi2", mps.ErrRetiredPrivateState)).AnyTimes()
+	mockpsm.EXPECT().PSIs().Return([]types.PrivateStateIdentifier{PSI1PSM.ID, types.DefaultPrivateStateIdentifier}).AnyTimes()
+
+	blocks, blockmap, blockchain := buildTestChain(1, params.QuorumMPSTestChainConfig)
+	cache := state.NewDatabase(blockchain.db)
+	blockchain.privateStateManager = mockpsm
+	mockpsm.EXPECT().StateRepository(gomock.Any()).Return(mps.NewMultiplePrivateStateRepository(blockchain.db, cache, common.Hash{})).AnyTimes()
+
+	parent := blockmap[blocks[0].ParentHash()]
+	statedb, _ := state.New(parent.Root(), blockchain.StateCache(), nil)
+	privateStateRepo, err := blockchain.PrivateStateManager().StateRepository(parent.Root())
+	assert.NoError(t, err)
+
+	_, privateReceipts, _, _, err := blockchain.Processor().Process(blocks[0], statedb, privateStateRepo, vm.Config{})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, privateReceipts)
+	for _, privReceipt := range privateReceipts {
+		assert.Equal(t, 1, len(privReceipt.PSReceipts))
+		assert.NotNil(t, privReceipt.PSReceipts["psi1"])
+	}
+}
+
 func TestMPSReset(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -329,3 +376,190 @@ var PrivacyGroups = []engine.PrivacyGroup{
 		Members:        []string{"LEG1", "LEG2"},
 	},
 }
+
+func TestCreateAndRetirePrivateState(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockptm := private.NewMockPrivateTransactionManager(mockCtrl)
+
+	saved := private.P
+	defer func() {
+		private.P = saved
+	}()
+	private.P = mockptm
+
+	addedGroups := append(PrivacyGroups, engine.PrivacyGroup{
+		Type:           "RESIDENT",
+		Name:           "RG3",
+		PrivacyGroupId: base64.StdEncoding.EncodeToString([]byte("RG3")),
+		Description:    "Resident Group 3",
+		Members:        []string{"EEE"},
+	})
+	mockptm.EXPECT().Receive(gomock.Any()).Return("", []string{}, common.EncryptedPayloadHash{}.Bytes(), nil, nil).AnyTimes()
+	mockptm.EXPECT().HasFeature(engine.MultiplePrivateStates).Return(true).AnyTimes()
+	mockptm.EXPECT().Groups().Return(PrivacyGroups, nil).Times(1)
+	mockptm.EXPECT().Groups().Return(addedGroups, nil).AnyTimes()
+
+	_, _, blockchain := buildTestChain(1, params.QuorumMPSTestChainConfig)
+	head := blockchain.CurrentBlock()
+	contract := common.HexToAddress("0x1")
+
+	repo, err := blockchain.PrivateStateManager().StateRepository(head.Root())
+	assert.NoError(t, err)
+	rg1, err := repo.StatePSI("RG1")
+	assert.NoError(t, err)
+	rg1.SetCode(contract, common.FromHex(testCode))
+	rg1.SetState(contract, common.Hash{}, common.HexToHash("0x1"))
+	_, err = repo.CommitAndWrite(false, head)
+	assert.NoError(t, err)
+
+	// the transaction manager added RG3
+	_, err = blockchain.CreatePrivateState("UNKNOWN", nil)
+	assert.Error(t, err)
+	_, err = blockchain.CreatePrivateState("RG1", nil)
+	assert.Error(t, err)
+	metadata, err := blockchain.CreatePrivateState("RG3", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, privacyGroupToPrivateStateMetadata(PG1).Type, metadata.Type)
+	resolved, err := blockchain.PrivateStateManager().ResolveForManagedParty("EEE")
+	assert.NoError(t, err)
+	assert.Equal(t, types.PrivateStateIdentifier("RG3"), resolved.ID)
+	_, rg3, err := blockchain.StateAtPSI(head.Root(), "RG3")
+	assert.NoError(t, err)
+	assert.Equal(t, types.EmptyRootHash, rg3.IntermediateRoot(false))
+
+	var archive bytes.Buffer
+	record, err := blockchain.RetirePrivateState("RG1", &archive, "archive", true)
+	assert.NoError(t, err)
+	assert.NotZero(t, record.PrunedNodes)
+	assert.Nil(t, rawdb.ReadTrieNode(blockchain.db, record.Root))
+	assert.NotContains(t, blockchain.PrivateStateManager().PSIs(), types.PrivateStateIdentifier("RG1"))
+	_, err = blockchain.PrivateStateManager().ResolveForManagedParty("AAA")
+	assert.True(t, errors.Is(err, mps.ErrRetiredPrivateState))
+	_, _, err = blockchain.StateAtPSI(head.Root(), "RG1")
+	assert.Error(t, err)
+	assert.NotZero(t, archive.Len())
+	_, _, err = blockchain.StateAtPSI(head.Root(), "RG2")
+	assert.NoError(t, err)
+
+	// the private states survive a restart, though the transaction manager still has RG1
+	restarted, err := newPrivateStateManager(blockchain.db, nil, true)
+	assert.NoError(t, err)
+	assert.NotContains(t, restarted.PSIs(), types.PrivateStateIdentifier("RG1"))
+	assert.Contains(t, restarted.PSIs(), types.PrivateStateIdentifier("RG3"))
+	blockchain.privateStateManager = restarted
+	_, err = blockchain.CreatePrivateState("RG1", nil)
+	assert.Error(t, err)
+}
+
+func TestMPSPrivateTrieGC(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockptm := private.NewMockPrivateTransactionManager(mockCtrl)
+
+	saved := private.P
+	defer func() {
+		private.P = saved
+	}()
+	private.P = mockptm
+
+	mockptm.EXPECT().Receive(gomock.Not(common.EncryptedPayloadHash{})).Return("", []string{"AAA"}, common.FromHex(testCode), nil, nil).AnyTimes()
+	mockptm.EXPECT().Receive(common.EncryptedPayloadHash{}).Return("", []string{}, common.EncryptedPayloadHash{}.Bytes(), nil, nil).AnyTimes()
+	mockptm.EXPECT().HasFeature(engine.MultiplePrivateStates).Return(true).AnyTimes()
+	mockptm.EXPECT().Groups().Return(PrivacyGroups, nil).AnyTimes()
+
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		genesis = GenesisBlockForTesting(db, testAddress, big.NewInt(1000000000))
+		config  = *defaultCacheConfig
+	)
+	config.PrivateTrieGC = true
+	blocks, _ := GenerateChain(params.QuorumMPSTestChainConfig, genesis, ethash.NewFaker(), db, 3, func(i int, block *BlockGen) {
+		block.SetCoinbase(common.Address{0})
+		tx, err := types.SignTx(types.NewContractCreation(block.TxNonce(testAddress), big.NewInt(0), testGas, nil, common.FromHex(testCode)), types.QuorumPrivateTxSigner{}, testKey)
+		if err != nil {
+			panic(err)
+		}
+		block.AddTx(tx)
+	})
+	blockchain, err := NewBlockChain(db, &config, params.QuorumMPSTestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil, nil)
+	assert.NoError(t, err)
+	_, err = blockchain.InsertChain(blocks)
+	assert.NoError(t, err)
+
+	head := blockchain.CurrentBlock()
+	_, rg1, err := blockchain.StateAtPSI(head.Root(), "RG1")
+	assert.NoError(t, err)
+	assert.NotEqual(t, 0, rg1.GetCodeSize(crypto.CreateAddress(testAddress, 2)))
+	// the private states of the recent blocks stay in memory
+	assert.Nil(t, rawdb.ReadTrieNode(db, rg1.IntermediateRoot(true)))
+	assert.Nil(t, rawdb.ReadTrieNode(db, rawdb.GetPrivateStatesTrieRoot(db, head.Root())))
+
+	// the head private states are written to disk on shutdown
+	blockchain.Stop()
+	restarted, err := NewBlockChain(db, &config, params.QuorumMPSTestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil, nil)
+	assert.NoError(t, err)
+	defer restarted.Stop()
+	assert.Equal(t, head.Hash(), restarted.CurrentBlock().Hash())
+	_, rg1, err = restarted.StateAtPSI(head.Root(), "RG1")
+	assert.NoError(t, err)
+	assert.NotEqual(t, 0, rg1.GetCodeSize(crypto.CreateAddress(testAddress, 2)))
+}
+
+func TestPrunePrivateStatesWithTrieGC(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockptm := private.NewMockPrivateTransactionManager(mockCtrl)
+
+	saved := private.P
+	defer func() {
+		private.P = saved
+	}()
+	private.P = mockptm
+
+	mockptm.EXPECT().Receive(gomock.Not(common.EncryptedPayloadHash{})).Return("", []string{"AAA"}, common.FromHex(testCode), nil, nil).AnyTimes()
+	mockptm.EXPECT().Receive(common.EncryptedPayloadHash{}).Return("", []string{}, common.EncryptedPayloadHash{}.Bytes(), nil, nil).AnyTimes()
+	mockptm.EXPECT().HasFeature(engine.MultiplePrivateStates).Return(true).AnyTimes()
+	mockptm.EXPECT().Groups().Return(PrivacyGroups, nil).AnyTimes()
+
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		genesis = GenesisBlockForTesting(db, testAddress, big.NewInt(1000000000))
+		config  = *defaultCacheConfig
+	)
+	config.PrivateTrieGC = true
+	blocks, _ := GenerateChain(params.QuorumMPSTestChainConfig, genesis, ethash.NewFaker(), db, 6, func(i int, block *BlockGen) {
+		block.SetCoinbase(common.Address{0})
+		tx, err := types.SignTx(types.NewContractCreation(block.TxNonce(testAddress), big.NewInt(0), testGas, nil, common.FromHex(testCode)), types.QuorumPrivateTxSigner{}, testKey)
+		if err != nil {
+			panic(err)
+		}
+		block.AddTx(tx)
+	})
+	blockchain, err := NewBlockChain(db, &config, params.QuorumMPSTestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil, nil)
+	assert.NoError(t, err)
+	_, err = blockchain.InsertChain(blocks)
+	assert.NoError(t, err)
+	head := blockchain.CurrentBlock()
+	// only the private states of the head and its parent are written on shutdown
+	blockchain.Stop()
+
+	stats, err := mps.PrunePrivateStates(db, 4)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), stats.Retained)
+	assert.Equal(t, head.NumberU64(), stats.Restart)
+	// the private states of blocks 3 and 4 never made it to disk
+	assert.True(t, stats.Missing >= 2)
+
+	restarted, err := NewBlockChain(db, &config, params.QuorumMPSTestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil, nil)
+	assert.NoError(t, err)
+	defer restarted.Stop()
+	for _, block := range []*types.Block{head, blocks[len(blocks)-2]} {
+		_, rg1, err := restarted.StateAtPSI(block.Root(), "RG1")
+		assert.NoError(t, err)
+		assert.NotEqual(t, 0, rg1.GetCodeSize(crypto.CreateAddress(testAddress, block.NumberU64()-1)))
+	}
+}
@@ -0,0 +1,147 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/core/mps"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/private"
+)
+
+var (
+	// errNotMPS is returned when private states are created or retired on a node
+	// without multiple private states
+	errNotMPS = errors.New("the node doesn't run multiple private states")
+	// errPruneArchive is returned when a private state is pruned on an archive node
+	errPruneArchive = errors.New("private states aren't pruned on archive nodes")
+)
+
+// CreatePrivateState starts managing the private state of a privacy group the
+// transaction manager added, without restarting the node.
+//
+// The private state starts empty, or from the empty state at the seed block if
+// it's set, unless the trie of private states has it already.
+func (bc *BlockChain) CreatePrivateState(psi types.PrivateStateIdentifier, seed *types.Block) (*mps.PrivateStateMetadata, error) {
+	psm, ok := bc.privateStateManager.(*MultiplePrivateStateManager)
+	if !ok {
+		return nil, errNotMPS
+	}
+	bc.chainmu.Lock()
+	defer bc.chainmu.Unlock()
+
+	if _, found := psm.metadata(psi); found || psi == types.EmptyPrivateStateIdentifier {
+		return nil, fmt.Errorf("private state %s already exists", psi)
+	}
+	groups, err := private.P.Groups()
+	if err != nil {
+		return nil, err
+	}
+	var metadata *mps.PrivateStateMetadata
+	for _, group := range groups {
+		if group, err = decodePrivacyGroup(group); err != nil {
+			return nil, err
+		}
+		if types.ToPrivateStateIdentifier(group.PrivacyGroupId) == psi {
+			metadata = privacyGroupToPrivateStateMetadata(group)
+			break
+		}
+	}
+	if metadata == nil {
+		return nil, fmt.Errorf("the transaction manager has no privacy group for private state %s", psi)
+	}
+
+	root := types.EmptyRootHash
+	if seed != nil {
+		repo, err := psm.StateRepository(seed.Root())
+		if err != nil {
+			return nil, err
+		}
+		emptyState, err := repo.DefaultState()
+		if err != nil {
+			return nil, fmt.Errorf("empty state at block %d unavailable: %v", seed.NumberU64(), err)
+		}
+		root = emptyState.IntermediateRoot(bc.chainConfig.IsEIP158(seed.Number()))
+		// the seed must outlive the garbage collection of the private states
+		if err := psm.TrieDB().Commit(root, false, nil); err != nil {
+			return nil, err
+		}
+	}
+	if err := psm.add(metadata); err != nil {
+		return nil, err
+	}
+	if err := psm.runtime.Create(psi, root); err != nil {
+		psm.remove(psi)
+		return nil, err
+	}
+	log.Info("Created private state", "psi", psi, "name", metadata.Name, "seed", root)
+	return metadata, nil
+}
+
+// RetirePrivateState stops managing a private state, writing it to the archive
+// if set and pruning the trie nodes that no other state of the canonical blocks
+// has. Pruning walks the states of the whole chain, blocks can't be inserted
+// meanwhile.
+func (bc *BlockChain) RetirePrivateState(psi types.PrivateStateIdentifier, archive io.Writer, archiveName string, prune bool) (*mps.RetiredPrivateState, error) {
+	psm, ok := bc.privateStateManager.(*MultiplePrivateStateManager)
+	if !ok {
+		return nil, errNotMPS
+	}
+	if prune && bc.cacheConfig.TrieDirtyDisabled {
+		return nil, errPruneArchive
+	}
+	if psi == types.EmptyPrivateStateIdentifier || psi == types.DefaultPrivateStateIdentifier {
+		return nil, fmt.Errorf("private state %s can't be retired", psi)
+	}
+	bc.chainmu.Lock()
+	defer bc.chainmu.Unlock()
+
+	metadata, found := psm.metadata(psi)
+	if !found {
+		return nil, fmt.Errorf("unknown private state %s", psi)
+	}
+	head := bc.CurrentBlock()
+	isEIP158 := bc.chainConfig.IsEIP158(head.Number())
+	repo, err := psm.StateRepository(head.Root())
+	if err != nil {
+		return nil, err
+	}
+	privateState, err := repo.StatePSI(psi)
+	if err != nil {
+		return nil, err
+	}
+	record := &mps.RetiredPrivateState{
+		PSI:         psi,
+		Name:        metadata.Name,
+		Addresses:   metadata.Addresses,
+		BlockNumber: head.NumberU64(),
+		BlockHash:   head.Hash(),
+		Root:        privateState.IntermediateRoot(isEIP158),
+		Archive:     archiveName,
+	}
+	if archive != nil {
+		if err := mps.ArchivePrivateState(archive, privateState.Database(), metadata, head, record.Root); err != nil {
+			return nil, fmt.Errorf("failed to archive private state %s: %v", psi, err)
+		}
+	}
+	if err := psm.runtime.Retire(record); err != nil {
+		return nil, err
+	}
+	psm.remove(psi)
+	log.Info("Retired private state", "psi", psi, "name", metadata.Name, "number", record.BlockNumber, "root", record.Root)
+	if !prune {
+		return record, nil
+	}
+
+	// the private state is retired already, the record goes back with the errors
+	if record.PrunedNodes, err = mps.PrunePrivateState(bc.db, bc.stateCache, psm.privateStatesTrieCache, record.Root, head.NumberU64(), psm.runtime); err != nil {
+		return record, fmt.Errorf("failed to prune private state %s: %v", psi, err)
+	}
+	if err := psm.runtime.Retire(record); err != nil {
+		return record, err
+	}
+	log.Info("Pruned retired private state", "psi", psi, "nodes", record.PrunedNodes)
+	return record, nil
+}
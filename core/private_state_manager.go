@@ -30,13 +30,8 @@ func newPrivateStateManager(db ethdb.Database, config *trie.Config, isMPS bool)
 		residentGroupByKey := make(map[string]*mps.PrivateStateMetadata)
 		privacyGroupById := make(map[types.PrivateStateIdentifier]*mps.PrivateStateMetadata)
 		for _, group := range groups {
-			if group.Type == engine.PrivacyGroupResident {
-				// Resident group IDs come in base64 encoded, so revert to original ID
-				decoded, err := base64.StdEncoding.DecodeString(group.PrivacyGroupId)
-				if err != nil {
-					return nil, err
-				}
-				group.PrivacyGroupId = string(decoded)
+			if group, err = decodePrivacyGroup(group); err != nil {
+				return nil, err
 			}
 			psi := types.ToPrivateStateIdentifier(group.PrivacyGroupId)
 			existing, found := privacyGroupById[psi]
@@ -60,6 +55,19 @@ func newPrivateStateManager(db ethdb.Database, config *trie.Config, isMPS bool)
 	}
 }
 
+// decodePrivacyGroup reverts the ID of a resident group to the original ID, as
+// they come in base64 encoded
+func decodePrivacyGroup(group engine.PrivacyGroup) (engine.PrivacyGroup, error) {
+	if group.Type == engine.PrivacyGroupResident {
+		decoded, err := base64.StdEncoding.DecodeString(group.PrivacyGroupId)
+		if err != nil {
+			return group, err
+		}
+		group.PrivacyGroupId = string(decoded)
+	}
+	return group, nil
+}
+
 func privacyGroupToPrivateStateMetadata(group engine.PrivacyGroup) *mps.PrivateStateMetadata {
 	return mps.NewPrivateStateMetadata(
 		types.ToPrivateStateIdentifier(group.PrivacyGroupId),
@@ -35,6 +35,9 @@ var (
 	// we introduce a generic approach to store extra data for an account. PrivacyMetadata is wrapped.
 	// However, this value is kept as-is to support backward compatibility
 	stateRootToExtraDataRootPrefix = []byte("PSR2PMDR")
+	// private states created and retired at runtime on a node with multiple private states
+	privateStateSeedPrefix    = []byte("mps-seed-")    // privateStateSeedPrefix + psi -> root the private state starts from
+	retiredPrivateStatePrefix = []byte("mps-retired-") // retiredPrivateStatePrefix + psi -> RLP encoded retirement record
 	// emptyRoot is the known root hash of an empty trie. Duplicate from `trie/trie.go#emptyRoot`
 	emptyRoot = common.HexToHash("56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
 )
@@ -80,6 +83,38 @@ func WriteRootHashMapping(db ethdb.KeyValueWriter, stateRoot, extraDataRoot comm
 	return db.Put(append(stateRootToExtraDataRootPrefix, stateRoot[:]...), extraDataRoot[:])
 }
 
+// WritePrivateStateSeed stores the root a private state created at runtime starts from
+func WritePrivateStateSeed(db ethdb.KeyValueWriter, psi types.PrivateStateIdentifier, root common.Hash) error {
+	return db.Put(append(privateStateSeedPrefix, psi...), root[:])
+}
+
+// ReadPrivateStateSeeds retrieves the roots the private states created at runtime start from
+func ReadPrivateStateSeeds(db ethdb.Iteratee) map[types.PrivateStateIdentifier]common.Hash {
+	seeds := make(map[types.PrivateStateIdentifier]common.Hash)
+	it := db.NewIterator(privateStateSeedPrefix, nil)
+	defer it.Release()
+	for it.Next() {
+		seeds[types.PrivateStateIdentifier(it.Key()[len(privateStateSeedPrefix):])] = common.BytesToHash(it.Value())
+	}
+	return seeds
+}
+
+// WriteRetiredPrivateState stores the retirement record of a private state
+func WriteRetiredPrivateState(db ethdb.KeyValueWriter, psi types.PrivateStateIdentifier, record []byte) error {
+	return db.Put(append(retiredPrivateStatePrefix, psi...), record)
+}
+
+// ReadRetiredPrivateStates retrieves the retirement records of the private states
+func ReadRetiredPrivateStates(db ethdb.Iteratee) map[types.PrivateStateIdentifier][]byte {
+	records := make(map[types.PrivateStateIdentifier][]byte)
+	it := db.NewIterator(retiredPrivateStatePrefix, nil)
+	defer it.Release()
+	for it.Next() {
+		records[types.PrivateStateIdentifier(it.Key()[len(retiredPrivateStatePrefix):])] = common.CopyBytes(it.Value())
+	}
+	return records
+}
+
 // WritePrivateBlockBloom creates a bloom filter for the given receipts and saves it to the database
 // with the number given as identifier (i.e. block number).
 func WritePrivateBlockBloom(db ethdb.Database, number uint64, receipts types.Receipts) error {
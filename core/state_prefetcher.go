@@ -138,6 +138,7 @@ func (p *statePrefetcher) prefetchMpsTransaction(block *types.Block, tx *types.T
 		if interrupt != nil && atomic.LoadUint32(interrupt) == 1 {
 			return
 		}
+		// parties of retired private states are skipped along with the unknown ones
 		psMetadata, err := p.bc.PrivateStateManager().ResolveForManagedParty(managedParty)
 		if err != nil {
 			continue
@@ -254,6 +254,10 @@ func ApplyTransactionOnMPS(config *params.ChainConfig, bc ChainContext, author *
 	targetPsi := make(map[types.PrivateStateIdentifier]struct{})
 	for _, managedParty := range managedParties {
 		psMetadata, err := bc.PrivateStateManager().ResolveForManagedParty(managedParty)
+		if errors.Is(err, mps.ErrRetiredPrivateState) {
+			// the parties of retired private states don't apply transactions anymore
+			continue
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -30,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum/multitenancy"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/private"
+	"github.com/ethereum/go-ethereum/private/engine"
 )
 
 /*
@@ -273,6 +274,10 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 		pmh.snapshot = snapshot
 		pmh.eph = common.BytesToEncryptedPayloadHash(st.data)
 		_, _, data, pmh.receivedPrivacyMetadata, err = private.P.Receive(pmh.eph)
+		if errors.Is(err, engine.ErrPrivateTxManagerUnavailable) {
+			// we can't tell whether we are a party, so don't process the transaction as if we weren't
+			return nil, err
+		}
 		// Increment the public account nonce if:
 		// 1. Tx is private and *not* a participant of the group and either call or create
 		// 2. Tx is private we are part of the group and is a call
@@ -38,16 +38,51 @@ var (
 	ErrInvalidE2CHeaderExtra = errors.New("invalid e2c header extra-data")
 )
 
+const (
+	E2CExtraV1 = 1 // validators and seal only
+	E2CExtraV2 = 2 // adds the view, the validator set hash and the view change certificate
+)
+
 type E2CExtra struct {
 	Validators []common.Address
 	Seal       []byte
+
+	// Only set on v2 extra-data. Older headers decode as v1 and encode back to
+	// exactly the same bytes, so their seal hash doesn't change
+	Version        uint
+	View           uint64
+	ValidatorsHash common.Hash
+	Certificate    *E2CViewCertificate // only in the first block of a view reached through a view change
+}
+
+// E2CViewCertificate is the evidence that justified a view change. Blames are the
+// f+1 signatures blaming the leader of the previous view and Votes the f+1 votes cast
+// in the new view for the highest certified block, which the new leader extended
+type E2CViewCertificate struct {
+	Blames [][]byte
+	Number uint64
+	Hash   common.Hash
+	Votes  [][]byte
 }
 
 // EncodeRLP serializes ist into the Ethereum RLP format.
 func (ist *E2CExtra) EncodeRLP(w io.Writer) error {
+	if ist.Version < E2CExtraV2 {
+		return rlp.Encode(w, []interface{}{
+			ist.Validators,
+			ist.Seal,
+		})
+	}
+	var cert []interface{}
+	if c := ist.Certificate; c != nil {
+		cert = []interface{}{c.Blames, c.Number, c.Hash, c.Votes}
+	}
 	return rlp.Encode(w, []interface{}{
 		ist.Validators,
 		ist.Seal,
+		ist.View,
+		ist.ValidatorsHash,
+		cert,
 	})
 }
 
@@ -56,14 +91,38 @@ func (ist *E2CExtra) DecodeRLP(s *rlp.Stream) error {
 	var E2CExtra struct {
 		Validators []common.Address
 		Seal       []byte
+		Rest       []rlp.RawValue `rlp:"tail"`
 	}
 	if err := s.Decode(&E2CExtra); err != nil {
 		return err
 	}
 	ist.Validators, ist.Seal = E2CExtra.Validators, E2CExtra.Seal
+	ist.Version, ist.View, ist.ValidatorsHash, ist.Certificate = E2CExtraV1, 0, common.Hash{}, nil
+	if len(E2CExtra.Rest) == 0 {
+		return nil
+	}
+
+	var v2 struct {
+		View           uint64
+		ValidatorsHash common.Hash
+		Certificate    *E2CViewCertificate `rlp:"nil"`
+	}
+	rest, err := rlp.EncodeToBytes(E2CExtra.Rest)
+	if err != nil {
+		return err
+	}
+	if err := rlp.DecodeBytes(rest, &v2); err != nil {
+		return err
+	}
+	ist.Version, ist.View, ist.ValidatorsHash, ist.Certificate = E2CExtraV2, v2.View, v2.ValidatorsHash, v2.Certificate
 	return nil
 }
 
+// E2CValidatorsHash returns the hash of the validator set recorded in v2 extra-data
+func E2CValidatorsHash(validators []common.Address) common.Hash {
+	return rlpHash(validators)
+}
+
 // ExtractE2CExtra extracts all values of the E2CExtra from the header. It returns an
 // error if the length of the given extra-data is less than 32 bytes or the extra-data can not
 // be decoded.
@@ -0,0 +1,94 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func TestE2CExtraV1RoundTrip(t *testing.T) {
+	v1, err := rlp.EncodeToBytes([]interface{}{
+		[]common.Address{common.HexToAddress("0x44add0ec310f115a0e603b2d7db9f067778eaf8a")},
+		[]byte{1, 2, 3},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &Header{Extra: append(make([]byte, E2CExtraVanity), v1...)}
+	extra, err := ExtractE2CExtra(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if extra.Version != E2CExtraV1 || extra.View != 0 || extra.Certificate != nil {
+		t.Errorf("unexpected v2 fields in v1 extra: %+v", extra)
+	}
+	// old headers have to encode back to the same bytes or their seal hash changes
+	enc, err := rlp.EncodeToBytes(extra)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(enc, v1) {
+		t.Errorf("v1 extra re-encoded differently: have %x, want %x", enc, v1)
+	}
+}
+
+func TestE2CExtraV2RoundTrip(t *testing.T) {
+	validators := []common.Address{
+		common.HexToAddress("0x44add0ec310f115a0e603b2d7db9f067778eaf8a"),
+		common.HexToAddress("0x294fc7e8f22b3bcdcf955dd7ff3ba2ed833f8212"),
+	}
+	tests := []*E2CExtra{
+		{
+			Validators:     validators[:1],
+			Seal:           []byte{},
+			Version:        E2CExtraV2,
+			View:           7,
+			ValidatorsHash: E2CValidatorsHash(validators),
+		},
+		{
+			Validators:     validators[:1],
+			Seal:           []byte{4, 5, 6},
+			Version:        E2CExtraV2,
+			View:           8,
+			ValidatorsHash: E2CValidatorsHash(validators),
+			Certificate: &E2CViewCertificate{
+				Blames: [][]byte{{1}, {2}},
+				Number: 42,
+				Hash:   common.HexToHash("0x01"),
+				Votes:  [][]byte{{3}, {4}},
+			},
+		},
+	}
+	for i, want := range tests {
+		enc, err := rlp.EncodeToBytes(want)
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		have, err := ExtractE2CExtra(&Header{Extra: append(make([]byte, E2CExtraVanity), enc...)})
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		if !reflect.DeepEqual(have, want) {
+			t.Errorf("test %d: have %+v, want %+v", i, have, want)
+		}
+	}
+}
@@ -30,11 +30,15 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/e2c"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/mps"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/multitenancy"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/trie"
@@ -297,6 +301,113 @@ func (api *PrivateAdminAPI) ImportChain(file string) (bool, error) {
 	return true, nil
 }
 
+// CreatePrivateState starts managing the private state of a privacy group the
+// transaction manager added, without restarting the node. The private state
+// starts empty, or from the empty state at the seed block if it's set.
+func (api *PrivateAdminAPI) CreatePrivateState(psi string, seed *rpc.BlockNumber) (*mps.PrivateStateMetadata, error) {
+	var block *types.Block
+	if seed != nil {
+		switch *seed {
+		case rpc.PendingBlockNumber:
+			return nil, errors.New("the pending block can't seed a private state")
+		case rpc.LatestBlockNumber:
+			block = api.eth.blockchain.CurrentBlock()
+		default:
+			block = api.eth.blockchain.GetBlockByNumber(uint64(*seed))
+		}
+		if block == nil {
+			return nil, fmt.Errorf("block #%d not found", *seed)
+		}
+	}
+	return api.eth.blockchain.CreatePrivateState(types.PrivateStateIdentifier(psi), block)
+}
+
+// RetirePrivateState stops managing a private state, archiving it into a local
+// file and pruning its trie unless prune is false. Archive nodes don't prune.
+func (api *PrivateAdminAPI) RetirePrivateState(psi string, file string, prune *bool) (*mps.RetiredPrivateState, error) {
+	shouldPrune := !api.eth.config.NoPruning
+	if prune != nil {
+		shouldPrune = *prune
+	}
+	if _, err := os.Stat(file); err == nil {
+		return nil, errors.New("location would overwrite an existing file")
+	}
+	out, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return nil, err
+	}
+	var writer io.Writer = out
+	if strings.HasSuffix(file, ".gz") {
+		writer = gzip.NewWriter(writer)
+	}
+	record, err := api.eth.blockchain.RetirePrivateState(types.PrivateStateIdentifier(psi), writer, file, shouldPrune)
+	if gz, ok := writer.(*gzip.Writer); ok {
+		gz.Close()
+	}
+	out.Close()
+	if record == nil {
+		// nothing was retired, don't leave a partial archive behind
+		os.Remove(file)
+	}
+	return record, err
+}
+
+// errNotE2C is returned by the E2C admin methods if the node doesn't run E2C
+var errNotE2C = errors.New("consensus engine is not E2C")
+
+func (api *PrivateAdminAPI) e2c() (consensus.E2C, error) {
+	e2c, ok := api.eth.Engine().(consensus.E2C)
+	if !ok {
+		return nil, errNotE2C
+	}
+	return e2c, nil
+}
+
+// E2CPeerScores returns the score every peer earned with invalid E2C traffic.
+func (api *PrivateAdminAPI) E2CPeerScores() (map[common.Address]*e2c.PeerScore, error) {
+	e2c, err := api.e2c()
+	if err != nil {
+		return nil, err
+	}
+	return e2c.PeerScores(), nil
+}
+
+// E2CBans returns the E2C ban list.
+func (api *PrivateAdminAPI) E2CBans() ([]*e2c.Ban, error) {
+	e2c, err := api.e2c()
+	if err != nil {
+		return nil, err
+	}
+	return e2c.Bans(), nil
+}
+
+// E2CBan bans a peer for the given number of seconds, ten minutes by default, and
+// disconnects it.
+func (api *PrivateAdminAPI) E2CBan(addr common.Address, seconds *uint64) (bool, error) {
+	e2cEngine, err := api.e2c()
+	if err != nil {
+		return false, err
+	}
+	d := e2c.DefaultBanDuration
+	if seconds != nil {
+		d = time.Duration(*seconds) * time.Second
+	}
+	e2cEngine.Ban(addr, d, "banned by admin")
+	if p := api.eth.protocolManager.peerByAddress(addr); p != nil {
+		api.eth.protocolManager.removePeer(p.id)
+	}
+	return true, nil
+}
+
+// E2CUnban lifts a ban, returning false if the peer wasn't banned.
+func (api *PrivateAdminAPI) E2CUnban(addr common.Address) (bool, error) {
+	e2c, err := api.e2c()
+	if err != nil {
+		return false, err
+	}
+	return e2c.Unban(addr), nil
+}
+
 // PublicDebugAPI is the collection of Ethereum full node APIs exposed
 // over the public debugging endpoint.
 type PublicDebugAPI struct {
@@ -430,6 +541,53 @@ func (api *PrivateDebugAPI) Preimage(ctx context.Context, hash common.Hash) (hex
 	return nil, errors.New("unknown preimage")
 }
 
+// ExportPrivateState returns a signed dump of the contracts of the private state at
+// the block, or of the whole private state without contracts. The dump includes
+// the privacy metadata and the managed parties of the contracts, so it can be
+// imported on another node with geth privacy import.
+func (api *PrivateDebugAPI) ExportPrivateState(ctx context.Context, psi types.PrivateStateIdentifier, contracts []common.Address, blockNr rpc.BlockNumber) (*mps.PrivateStateDump, error) {
+	psm, err := api.eth.blockchain.PrivateStateManager().ResolveForUserContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if psi == "" {
+		psi = psm.ID
+	}
+	if _, ok := api.eth.APIBackend.SupportsMultitenancy(ctx); ok && psi != psm.ID {
+		return nil, multitenancy.ErrNotAuthorized
+	}
+	managed := false
+	for _, id := range api.eth.blockchain.PrivateStateManager().PSIs() {
+		managed = managed || id == psi
+	}
+	if !managed {
+		return nil, fmt.Errorf("unknown private state %s", psi)
+	}
+
+	var block *types.Block
+	switch blockNr {
+	case rpc.LatestBlockNumber, rpc.PendingBlockNumber:
+		block = api.eth.blockchain.CurrentBlock()
+	default:
+		block = api.eth.blockchain.GetBlockByNumber(uint64(blockNr))
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block #%d not found", blockNr)
+	}
+	_, privateState, err := api.eth.blockchain.StateAtPSI(block.Root(), psi)
+	if err != nil {
+		return nil, err
+	}
+	dump, err := mps.DumpPrivateState(psi, block, privateState, contracts)
+	if err != nil {
+		return nil, err
+	}
+	if err := dump.Sign(api.eth.p2pServer.PrivateKey); err != nil {
+		return nil, err
+	}
+	return dump, nil
+}
+
 // BadBlockArgs represents the entries in the list returned when bad blocks are queried.
 type BadBlockArgs struct {
 	Hash  common.Hash            `json:"hash"`
@@ -220,6 +220,8 @@ func New(stack *node.Node, config *Config) (*Ethereum, error) {
 			Preimages:           config.Preimages,
 			// Quorum
 			PrivateTrieCleanJournal: stack.ResolvePath(config.PrivateTrieCleanCacheJournal),
+			PrivateTrieGC:           config.PrivateTrieGC,
+			PrivateTriesInMemory:    config.PrivateTriesInMemory,
 		}
 	)
 	newBlockChainFunc := core.NewBlockChain
@@ -255,6 +257,9 @@ func New(stack *node.Node, config *Config) (*Ethereum, error) {
 	}
 	eth.miner = miner.New(eth, &config.Miner, chainConfig, eth.EventMux(), eth.engine, eth.isLocalBlock)
 	eth.miner.SetExtra(makeExtraData(config.Miner.ExtraData, eth.blockchain.Config().IsQuorum))
+	if engine, ok := eth.engine.(consensus.E2C); ok && config.E2C.SafetyMonitor {
+		eth.protocolManager.e2cMonitor = newE2CMonitor(&config.E2C, stack.GetNodeKey(), eth.blockchain, engine, eth.protocolManager.peers, eth.StopMining)
+	}
 
 	hexNodeId := fmt.Sprintf("%x", crypto.FromECDSAPub(&stack.GetNodeKey().PublicKey)[1:]) // Quorum
 	eth.APIBackend = &EthAPIBackend{stack.Config().ExtRPCEnabled(), eth, nil, hexNodeId, config.EVMCallTimeOut}
@@ -319,6 +324,12 @@ func CreateConsensusEngine(stack *node.Node, chainConfig *params.ChainConfig, co
 	if chainConfig.E2C != nil {
 		config.E2C.Delta = chainConfig.E2C.Delta
 		config.E2C.BlockSize = chainConfig.E2C.BlockSize
+		config.E2C.LightClient = config.SyncMode == downloader.LightSync
+		config.E2C.ExtraV2Block = chainConfig.E2C.ExtraV2Block
+		config.E2C.SafetyReportDir = stack.ResolvePath("e2c-safety")
+		if chainConfig.E2C.CheckpointInterval != 0 {
+			config.E2C.CheckpointInterval = chainConfig.E2C.CheckpointInterval
+		}
 		config.Istanbul.AllowedFutureBlockTime = config.Miner.AllowedFutureBlockTime //Quorum
 
 		return e2cBackend.New(&config.E2C, stack.GetNodeKey(), db)
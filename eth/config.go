@@ -212,4 +212,6 @@ type Config struct {
 
 	// Quorum
 	PrivateTrieCleanCacheJournal string `toml:",omitempty"` // Disk journal directory for private trie cache to survive node restarts
+	PrivateTrieGC                bool   `toml:",omitempty"` // Whether to garbage collect multiple private states in memory
+	PrivateTriesInMemory         uint64 `toml:",omitempty"` // Number of recent blocks to keep the private state of in memory
 }
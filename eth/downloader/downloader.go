@@ -145,6 +145,10 @@ type Downloader struct {
 	pivotHeader *types.Header // Pivot block header to dynamically push the syncing state root
 	pivotLock   sync.RWMutex  // Lock protecting pivot header reads from updates
 
+	// Quorum
+	trustedPivot *trustedPivot // Signed checkpoint fast sync starts from instead of the peer's pivot
+	// End Quorum
+
 	stateSyncStart chan *stateSync
 	trackStateReq  chan *stateReq
 	stateCh        chan dataPack // [eth/63] Channel receiving inbound node state data
@@ -303,6 +307,88 @@ func (d *Downloader) SyncBloomContains(hash []byte) bool {
 	return d.stateBloom == nil || d.stateBloom.Contains(hash)
 }
 
+// Quorum
+
+// trustedPivot is a block the consensus engine vouches for, e.g. an E2C checkpoint
+// signed by f+1 validators
+type trustedPivot struct {
+	number uint64
+	hash   common.Hash
+	root   common.Hash
+}
+
+// SetTrustedPivot makes fast sync download the state of the given block instead of
+// the pivot advertised by the remote peer. The pivot isn't moved while syncing, so
+// the synced state is the one the engine vouched for rather than one a single peer
+// picked.
+func (d *Downloader) SetTrustedPivot(number uint64, hash common.Hash, root common.Hash) {
+	d.pivotLock.Lock()
+	defer d.pivotLock.Unlock()
+
+	d.trustedPivot = &trustedPivot{number: number, hash: hash, root: root}
+}
+
+// clearTrustedPivot drops the trusted pivot once it's no longer ahead of us
+func (d *Downloader) clearTrustedPivot() {
+	d.pivotLock.Lock()
+	defer d.pivotLock.Unlock()
+
+	d.trustedPivot = nil
+}
+
+// getTrustedPivot returns the trusted pivot, or nil if none was set
+func (d *Downloader) getTrustedPivot() *trustedPivot {
+	d.pivotLock.RLock()
+	defer d.pivotLock.RUnlock()
+
+	return d.trustedPivot
+}
+
+// fetchTrustedPivot retrieves the header of the trusted pivot from the remote peer
+// and checks it is the block the engine vouched for.
+func (d *Downloader) fetchTrustedPivot(p *peerConnection, trusted *trustedPivot, head *types.Header) (*types.Header, error) {
+	if head.Number.Uint64() < trusted.number {
+		return nil, fmt.Errorf("%w: remote head %d below trusted pivot %d", errUnsyncedPeer, head.Number, trusted.number)
+	}
+	p.log.Debug("Retrieving trusted pivot header", "number", trusted.number, "hash", trusted.hash)
+	go p.peer.RequestHeadersByNumber(trusted.number, 1, 0, false)
+
+	ttl := d.requestTTL()
+	timeout := time.After(ttl)
+	for {
+		select {
+		case <-d.cancelCh:
+			return nil, errCanceled
+
+		case packet := <-d.headerCh:
+			// Discard anything not from the origin peer
+			if packet.PeerId() != p.id {
+				log.Debug("Received headers from incorrect peer", "peer", packet.PeerId())
+				break
+			}
+			headers := packet.(*headerPack).headers
+			if len(headers) != 1 {
+				return nil, fmt.Errorf("%w: returned headers %d != requested 1", errBadPeer, len(headers))
+			}
+			pivot := headers[0]
+			if pivot.Number.Uint64() != trusted.number || pivot.Hash() != trusted.hash || pivot.Root != trusted.root {
+				return nil, fmt.Errorf("%w: remote pivot %d [%x] doesn't match trusted pivot %d [%x]", errInvalidChain, pivot.Number, pivot.Hash().Bytes()[:4], trusted.number, trusted.hash[:4])
+			}
+			return pivot, nil
+
+		case <-timeout:
+			p.log.Debug("Waiting for trusted pivot header timed out", "elapsed", ttl)
+			return nil, errTimeout
+
+		case <-d.bodyCh:
+		case <-d.receiptCh:
+			// Out of bounds delivery, ignore
+		}
+	}
+}
+
+// End Quorum
+
 // RegisterPeer injects a new download peer into the set of block source to be
 // used for fetching hashes and blocks from.
 func (d *Downloader) RegisterPeer(id string, version int, peer Peer) error {
@@ -455,6 +541,9 @@ func (d *Downloader) syncWithPeer(p *peerConnection, hash common.Hash, td *big.I
 		if err != nil {
 			d.mux.Post(FailedEvent{err})
 		} else {
+			// Quorum: the trusted pivot is behind us now, later syncs move the pivot as usual
+			d.clearTrustedPivot()
+
 			latest := d.lightchain.CurrentHeader()
 			d.mux.Post(DoneEvent{latest})
 		}
@@ -481,6 +570,24 @@ func (d *Downloader) syncWithPeer(p *peerConnection, hash common.Hash, td *big.I
 		// nil panics on an access.
 		pivot = d.blockchain.CurrentBlock().Header()
 	}
+	// Quorum: start from the engine's checkpoint if it gave us one that we haven't passed
+	// yet, otherwise forget about it so the pivot moves as usual. Peers only keep the
+	// state of the recent blocks, so an older checkpoint can't be synced either
+	trusted := d.getTrustedPivot()
+	if trusted != nil {
+		switch {
+		case mode != FastSync || trusted.number <= d.blockchain.CurrentBlock().NumberU64():
+			trusted = nil
+		case trusted.number+2*uint64(fsMinFullBlocks)-uint64(reorgProtHeaderDelay) <= latest.Number.Uint64():
+			log.Warn("Trusted pivot too old to sync, using remote pivot", "trusted", trusted.number, "head", latest.Number)
+			trusted = nil
+		}
+		if trusted == nil {
+			d.clearTrustedPivot()
+		} else if pivot, err = d.fetchTrustedPivot(p, trusted, latest); err != nil {
+			return err
+		}
+	}
 	height := latest.Number.Uint64()
 
 	origin, err := d.findAncestor(p, latest)
@@ -567,7 +674,7 @@ func (d *Downloader) syncWithPeer(p *peerConnection, hash common.Hash, td *big.I
 		d.pivotHeader = pivot
 		d.pivotLock.Unlock()
 
-		fetchers = append(fetchers, func() error { return d.processFastSyncContent() })
+		fetchers = append(fetchers, func() error { return d.processFastSyncContent(trusted) })
 	} else if mode == FullSync {
 		fetchers = append(fetchers, d.processFullSyncContent)
 	}
@@ -1162,7 +1269,7 @@ func (d *Downloader) fetchHeaders(p *peerConnection, from uint64) error {
 
 				// If we're still skeleton filling fast sync, check pivot staleness
 				// before continuing to the next skeleton filling
-				if skeleton && pivot > 0 {
+				if skeleton && pivot > 0 && d.getTrustedPivot() == nil {
 					getNextPivot()
 				} else {
 					getHeaders(from)
@@ -1722,7 +1829,10 @@ func (d *Downloader) importBlockResults(results []*fetchResult) error {
 
 // processFastSyncContent takes fetch results from the queue and writes them to the
 // database. It also controls the synchronisation of state nodes of the pivot block.
-func (d *Downloader) processFastSyncContent() error {
+//
+// Quorum: if the sync started from a trusted pivot, the pivot block has to be the one
+// the engine vouched for and isn't moved unless it became stale.
+func (d *Downloader) processFastSyncContent(trusted *trustedPivot) error {
 	// Start syncing state of the reported head block. This should get us most of
 	// the state of the pivot block.
 	d.pivotLock.RLock()
@@ -1796,6 +1906,12 @@ func (d *Downloader) processFastSyncContent() error {
 			// need to be taken into account, otherwise we're detecting the pivot move
 			// late and will drop peers due to unavailable state!!!
 			if height := latest.Number.Uint64(); height >= pivot.Number.Uint64()+2*uint64(fsMinFullBlocks)-uint64(reorgProtHeaderDelay) {
+				// Quorum: a trusted pivot only moves once nobody can serve its state anymore
+				if trusted != nil {
+					log.Warn("Trusted pivot became stale, using remote pivot", "trusted", trusted.number)
+					trusted = nil
+					d.clearTrustedPivot()
+				}
 				log.Warn("Pivot became stale, moving", "old", pivot.Number.Uint64(), "new", height-uint64(fsMinFullBlocks)+uint64(reorgProtHeaderDelay))
 				pivot = results[len(results)-1-fsMinFullBlocks+reorgProtHeaderDelay].Header // must exist as lower old pivot is uncommitted
 
@@ -1813,6 +1929,10 @@ func (d *Downloader) processFastSyncContent() error {
 			return err
 		}
 		if P != nil {
+			// Quorum: don't sync or commit a pivot the engine didn't vouch for
+			if trusted != nil && (P.Header.Hash() != trusted.hash || P.Header.Root != trusted.root) {
+				return fmt.Errorf("%w: pivot %d [%x] doesn't match trusted pivot [%x]", errInvalidChain, P.Header.Number, P.Header.Hash().Bytes()[:4], trusted.hash[:4])
+			}
 			// If new pivot block found, cancel old state retrieval and restart
 			if oldPivot != P {
 				sync.Cancel()
@@ -1702,3 +1702,74 @@ func testCheckpointEnforcement(t *testing.T, protocol int, mode SyncMode) {
 		assertOwnChain(t, tester, chain.len())
 	}
 }
+
+// Tests that fast sync downloads the state of a trusted pivot handed over by the
+// consensus engine and rejects peers whose chain doesn't contain it.
+func TestTrustedPivot64Fast(t *testing.T) { testTrustedPivot(t, 64) }
+func TestTrustedPivot65Fast(t *testing.T) { testTrustedPivot(t, 65) }
+
+func testTrustedPivot(t *testing.T, protocol int) {
+	t.Parallel()
+
+	chain := testChainBase.shorten(blockCacheMaxItems - 15)
+	trusted := chain.headerm[chain.chain[chain.len()-100]]
+
+	// A peer serving the checkpointed chain is synced from the trusted pivot
+	tester := newTester()
+	defer tester.terminate()
+
+	tester.downloader.SetTrustedPivot(trusted.Number.Uint64(), trusted.Hash(), trusted.Root)
+	tester.newPeer("peer", protocol, chain)
+	if err := tester.sync("peer", nil, FastSync); err != nil {
+		t.Fatalf("failed to synchronise blocks: %v", err)
+	}
+	assertOwnChain(t, tester, chain.len())
+	if pivot := tester.downloader.pivotHeader; pivot.Hash() != trusted.Hash() {
+		t.Fatalf("pivot mismatch: have %d [%x], want %d [%x]", pivot.Number, pivot.Hash(), trusted.Number, trusted.Hash())
+	}
+	if tester.downloader.getTrustedPivot() != nil {
+		t.Fatalf("trusted pivot kept after a successful sync")
+	}
+
+	// A trusted pivot at or below the head is ignored
+	stale := newTester()
+	defer stale.terminate()
+
+	genesis := chain.headerm[chain.chain[0]]
+	stale.downloader.SetTrustedPivot(0, genesis.Hash(), genesis.Root)
+	stale.newPeer("peer", protocol, chain)
+	if err := stale.sync("peer", nil, FastSync); err != nil {
+		t.Fatalf("failed to synchronise blocks past the trusted pivot: %v", err)
+	}
+	assertOwnChain(t, stale, chain.len())
+	if pivot := stale.downloader.pivotHeader; pivot.Hash() == genesis.Hash() {
+		t.Fatalf("pivot stuck at the stale trusted pivot")
+	}
+
+	// A peer whose chain doesn't contain the checkpoint is rejected
+	forked := newTester()
+	defer forked.terminate()
+
+	forked.downloader.SetTrustedPivot(trusted.Number.Uint64(), common.Hash{0x01}, trusted.Root)
+	forked.newPeer("peer", protocol, chain)
+	if err := forked.sync("peer", nil, FastSync); !errors.Is(err, errInvalidChain) {
+		t.Fatalf("block sync error mismatch: have %v, want %v", err, errInvalidChain)
+	}
+	assertOwnChain(t, forked, 1)
+
+	// A trusted pivot whose state the peer already pruned is dropped for the remote pivot
+	pruned := newTester()
+	defer pruned.terminate()
+
+	old := chain.headerm[chain.chain[100]]
+	pruned.downloader.SetTrustedPivot(old.Number.Uint64(), old.Hash(), old.Root)
+	pruned.newPeer("peer", protocol, chain)
+	pruned.peers["peer"].missingStates = map[common.Hash]bool{old.Root: true}
+	if err := pruned.sync("peer", nil, FastSync); err != nil {
+		t.Fatalf("failed to synchronise blocks past the pruned trusted pivot: %v", err)
+	}
+	assertOwnChain(t, pruned, chain.len())
+	if pivot := pruned.downloader.pivotHeader; pivot.Number.Uint64() <= old.Number.Uint64() {
+		t.Fatalf("pivot stuck at the pruned trusted pivot: have %d", pivot.Number)
+	}
+}
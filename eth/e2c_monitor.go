@@ -0,0 +1,348 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/e2c"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/p2p"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// e2cCommitMsg carries commit announcements. It's beyond the length of the
+	// legacy istanbul protocols, so only istanbul/100 peers get it
+	e2cCommitMsg = 0x12
+
+	e2cMonitorWindow = 1024 // heights around our head we keep announcements for
+	e2cHeadChanSize  = 10   // size of the channel listening to chain head events
+
+	e2cAnnounceRate  = 100 // announcements a peer may send us per second
+	e2cAnnounceBurst = 400 // announcements a peer may send us at once
+)
+
+var e2cDivergenceMeter = metrics.NewRegisteredMeter("eth/e2c/safety/divergence", nil)
+
+var errE2CNotValidator = errors.New("signer is not a validator")
+
+// e2cMonitor is the opt-in E2C safety monitor. Every node running it signs and
+// gossips the hash of each block it commits. If nodes turn out to have committed
+// different blocks at the same height, E2C's safety was broken, e.g. by a Δ that
+// is too low. The monitor then raises an alarm, stops the miner so we don't build
+// on a fork, and writes a report with both blocks and who committed them.
+type e2cMonitor struct {
+	key       *ecdsa.PrivateKey
+	address   common.Address
+	chain     *core.BlockChain
+	engine    e2cMonitorEngine
+	peers     *peerSet
+	reportDir string
+	halt      func() // stops the miner
+
+	commits   map[uint64]map[common.Hash]*e2cCommits // announcements by height and block hash
+	reported  map[uint64]bool                        // heights we already raised the alarm for
+	announced uint64                                 // last height we announced
+	limiters  map[string]*rate.Limiter               // announcements each peer may send us
+	lock      sync.Mutex
+
+	headCh  chan core.ChainHeadEvent
+	headSub event.Subscription
+	wg      sync.WaitGroup
+}
+
+// e2cMonitorEngine is what the monitor needs from the E2C engine to tell whether an
+// announcement came from a validator and is for a block it could have committed
+type e2cMonitorEngine interface {
+	consensus.Engine
+	ValidatorsAt(chain consensus.ChainHeaderReader, header *types.Header) (e2c.Validators, error)
+}
+
+// e2cCommits collects the nodes that announced committing a block
+type e2cCommits struct {
+	header  *types.Header
+	signers map[common.Address]bool
+}
+
+func newE2CMonitor(config *e2c.Config, key *ecdsa.PrivateKey, chain *core.BlockChain, engine e2cMonitorEngine, peers *peerSet, halt func()) *e2cMonitor {
+	return &e2cMonitor{
+		key:       key,
+		address:   crypto.PubkeyToAddress(key.PublicKey),
+		chain:     chain,
+		engine:    engine,
+		peers:     peers,
+		reportDir: config.SafetyReportDir,
+		halt:      halt,
+		commits:   make(map[uint64]map[common.Hash]*e2cCommits),
+		reported:  make(map[uint64]bool),
+		limiters:  make(map[string]*rate.Limiter),
+		announced: chain.CurrentHeader().Number.Uint64(),
+	}
+}
+
+func (m *e2cMonitor) start() {
+	m.headCh = make(chan core.ChainHeadEvent, e2cHeadChanSize)
+	m.headSub = m.chain.SubscribeChainHeadEvent(m.headCh)
+
+	m.wg.Add(1)
+	go m.loop()
+	log.Info("E2C safety monitor started", "reports", m.reportDir)
+}
+
+func (m *e2cMonitor) stop() {
+	m.headSub.Unsubscribe()
+	m.wg.Wait()
+}
+
+// announces every block we commit until the subscription ends
+func (m *e2cMonitor) loop() {
+	defer m.wg.Done()
+
+	for {
+		select {
+		case ev := <-m.headCh:
+			m.commit(ev.Block.NumberU64())
+		case <-m.headSub.Err():
+			return
+		}
+	}
+}
+
+// announces the blocks committed since the last announcement up to head. Heads
+// can skip blocks when they are inserted in batches
+func (m *e2cMonitor) commit(head uint64) {
+	m.lock.Lock()
+	from := m.announced + 1
+	if head >= e2cMonitorWindow && from < head-e2cMonitorWindow {
+		from = head - e2cMonitorWindow
+	}
+	m.announced = head
+	m.prune(head)
+	m.lock.Unlock()
+
+	for number := from; number <= head; number++ {
+		header := m.chain.GetHeaderByNumber(number)
+		if header == nil {
+			continue
+		}
+		ann := &e2c.CommitAnnouncement{Header: header}
+		if err := ann.Sign(m.key); err != nil {
+			log.Error("Failed to sign commit announcement", "number", number, "err", err)
+			return
+		}
+		m.record(ann, m.address, "")
+	}
+}
+
+// handle processes a commit announcement from a peer. Only announcements signed by
+// a validator for a block the engine accepts are recorded and relayed, the others
+// are ignored, so nobody outside the validator set can raise the alarm
+func (m *e2cMonitor) handle(from string, msg p2p.Msg) error {
+	if !m.allow(from) {
+		log.Trace("Dropped E2C commit announcement over the rate limit", "peer", from)
+		return nil
+	}
+	var ann e2c.CommitAnnouncement
+	if err := msg.Decode(&ann); err != nil {
+		return errResp(ErrDecode, "msg %v: %v", msg, err)
+	}
+	signer, err := ann.Signer()
+	if err != nil {
+		return errResp(ErrDecode, "commit announcement: %v", err)
+	}
+	if err := m.verify(&ann, signer); err != nil {
+		log.Debug("Ignored E2C commit announcement", "peer", from, "signer", signer, "number", ann.Header.Number, "err", err)
+		return nil
+	}
+	m.record(&ann, signer, from)
+	return nil
+}
+
+// allow takes an announcement from the rate the peer may send them at
+func (m *e2cMonitor) allow(from string) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	limiter, ok := m.limiters[from]
+	if !ok {
+		limiter = rate.NewLimiter(e2cAnnounceRate, e2cAnnounceBurst)
+		m.limiters[from] = limiter
+	}
+	return limiter.Allow()
+}
+
+// verify checks the announcement was signed by a validator at its height and is
+// for a header the engine accepts
+func (m *e2cMonitor) verify(ann *e2c.CommitAnnouncement, signer common.Address) error {
+	validators, err := m.engine.ValidatorsAt(m.chain, ann.Header)
+	if err != nil {
+		return err
+	}
+	if i, _ := validators.GetByAddress(signer); i == -1 {
+		return errE2CNotValidator
+	}
+	if err := m.engine.VerifyHeader(m.chain, ann.Header, true); err != nil {
+		return err
+	}
+	return m.engine.VerifySeal(m.chain, ann.Header)
+}
+
+// records the announcement, relays it if it's new and checks its height for a
+// divergence. from is the peer it came from, empty for our own
+func (m *e2cMonitor) record(ann *e2c.CommitAnnouncement, signer common.Address, from string) {
+	var (
+		number = ann.Header.Number.Uint64()
+		hash   = ann.Header.Hash()
+	)
+	m.lock.Lock()
+	if head := m.chain.CurrentHeader().Number.Uint64(); number+e2cMonitorWindow < head || number > head+e2cMonitorWindow {
+		m.lock.Unlock()
+		return
+	}
+	blocks, ok := m.commits[number]
+	if !ok {
+		blocks = make(map[common.Hash]*e2cCommits)
+		m.commits[number] = blocks
+	}
+	commits, ok := blocks[hash]
+	if !ok {
+		commits = &e2cCommits{header: ann.Header, signers: make(map[common.Address]bool)}
+		blocks[hash] = commits
+	}
+	if commits.signers[signer] {
+		m.lock.Unlock()
+		return
+	}
+	commits.signers[signer] = true
+	m.lock.Unlock()
+
+	for _, p := range m.peers.Peers() {
+		if p.id != from {
+			go p.SendConsensus(e2cCommitMsg, ann)
+		}
+	}
+	m.check(number)
+}
+
+// compares the blocks announced at the height with the one we committed
+func (m *e2cMonitor) check(number uint64) {
+	local := m.chain.GetHeaderByNumber(number)
+
+	m.lock.Lock()
+	blocks, ok := m.commits[number]
+	if !ok {
+		m.lock.Unlock()
+		return
+	}
+	if local != nil {
+		if _, ok := blocks[local.Hash()]; !ok {
+			blocks[local.Hash()] = &e2cCommits{header: local, signers: make(map[common.Address]bool)}
+		}
+	}
+	if len(blocks) < 2 || m.reported[number] {
+		m.lock.Unlock()
+		return
+	}
+	m.reported[number] = true
+	report := &e2c.SafetyReport{Number: number, Detected: time.Now()}
+	for hash, commits := range blocks {
+		conflict := &e2c.ConflictingBlock{
+			Hash:   hash,
+			Header: commits.header,
+			Local:  local != nil && local.Hash() == hash,
+		}
+		for signer := range commits.signers {
+			conflict.Signers = append(conflict.Signers, signer)
+		}
+		sort.Slice(conflict.Signers, func(i, j int) bool {
+			return conflict.Signers[i].Hex() < conflict.Signers[j].Hex()
+		})
+		report.Blocks = append(report.Blocks, conflict)
+	}
+	m.lock.Unlock()
+
+	hashes := make([]common.Hash, 0, len(report.Blocks))
+	for _, conflict := range report.Blocks {
+		conflict.Proposer, _ = m.engine.Author(conflict.Header)
+		if conflict.Local {
+			if block := m.chain.GetBlock(conflict.Hash, number); block != nil {
+				for _, tx := range block.Transactions() {
+					conflict.Txs = append(conflict.Txs, tx.Hash())
+				}
+			}
+		}
+		hashes = append(hashes, conflict.Hash)
+	}
+	e2cDivergenceMeter.Mark(1)
+	log.Error("E2C SAFETY VIOLATION: nodes committed conflicting blocks, stopping the miner", "number", number, "hashes", hashes)
+	m.halt()
+
+	path, err := m.writeReport(report)
+	if err != nil {
+		log.Error("Failed to write E2C safety report", "number", number, "err", err)
+		return
+	}
+	log.Error("Wrote E2C safety report", "number", number, "path", path)
+}
+
+// writes the report into the report directory and returns its path
+func (m *e2cMonitor) writeReport(report *e2c.SafetyReport) (string, error) {
+	if err := os.MkdirAll(m.reportDir, 0700); err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(m.reportDir, fmt.Sprintf("divergence-%d-%d.json", report.Number, report.Detected.Unix()))
+	return path, ioutil.WriteFile(path, data, 0600)
+}
+
+// drops the announcements that fell out of the window, lock must be held
+func (m *e2cMonitor) prune(head uint64) {
+	if head < e2cMonitorWindow {
+		return
+	}
+	for number := range m.commits {
+		if number < head-e2cMonitorWindow {
+			delete(m.commits, number)
+			delete(m.reported, number)
+		}
+	}
+	for id := range m.limiters {
+		if m.peers.Peer(id) == nil {
+			delete(m.limiters, id)
+		}
+	}
+}
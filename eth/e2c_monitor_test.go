@@ -0,0 +1,204 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/e2c"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/downloader"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// testE2CMonitorEngine gives the test chain's engine a fixed validator set
+type testE2CMonitorEngine struct {
+	consensus.Engine
+	validators e2c.Validators
+}
+
+func (e *testE2CMonitorEngine) ValidatorsAt(chain consensus.ChainHeaderReader, header *types.Header) (e2c.Validators, error) {
+	return e.validators, nil
+}
+
+// newTestE2CMonitor returns a monitor on the chain of pm that writes its reports to
+// dir, with validators made from keys, and a counter of the times it halted
+func newTestE2CMonitor(pm *ProtocolManager, dir string, keys []*ecdsa.PrivateKey) (*e2cMonitor, *int) {
+	engine := &testE2CMonitorEngine{Engine: pm.engine}
+	for _, key := range keys {
+		engine.validators = append(engine.validators, crypto.PubkeyToAddress(key.PublicKey))
+	}
+	key, _ := crypto.GenerateKey()
+	halted := new(int)
+	m := newE2CMonitor(&e2c.Config{SafetyReportDir: dir}, key, pm.blockchain, engine, pm.peers, func() { *halted++ })
+	return m, halted
+}
+
+// announcement returns the commit announcement message of header signed by key
+func announcement(t *testing.T, header *types.Header, key *ecdsa.PrivateKey) p2p.Msg {
+	ann := &e2c.CommitAnnouncement{Header: header}
+	if err := ann.Sign(key); err != nil {
+		t.Fatal(err)
+	}
+	size, r, err := rlp.EncodeToReader(ann)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p2p.Msg{Code: e2cCommitMsg, Size: uint32(size), Payload: r}
+}
+
+// Tests that the safety monitor halts and writes a report when a validator
+// announces a different block at a height we committed, and stays quiet when it
+// announces the same one.
+func TestE2CMonitorDivergence(t *testing.T) {
+	pm, _ := newTestProtocolManagerMust(t, downloader.FullSync, 4, nil, nil)
+	defer pm.Stop()
+
+	dir, err := ioutil.TempDir("", "e2c-safety")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	keys := make([]*ecdsa.PrivateKey, 4)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	m, halted := newTestE2CMonitor(pm, dir, keys)
+
+	local := pm.blockchain.GetHeaderByNumber(3)
+	for _, key := range keys[:2] {
+		if err := m.handle("peer", announcement(t, local, key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if *halted != 0 {
+		t.Fatalf("halted on a matching announcement")
+	}
+
+	forked := types.CopyHeader(local)
+	forked.Extra = []byte("fork")
+	if err := m.handle("peer", announcement(t, forked, keys[2])); err != nil {
+		t.Fatal(err)
+	}
+	if *halted != 1 {
+		t.Fatalf("halted %d times, want 1", *halted)
+	}
+	if err := m.handle("peer", announcement(t, forked, keys[3])); err != nil {
+		t.Fatal(err)
+	}
+	if *halted != 1 {
+		t.Fatalf("halted again on an already reported height")
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "divergence-3-*.json"))
+	if len(files) != 1 {
+		t.Fatalf("found %d reports, want 1", len(files))
+	}
+	data, _ := ioutil.ReadFile(files[0])
+	var report e2c.SafetyReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Blocks) != 2 {
+		t.Fatalf("report has %d blocks, want 2", len(report.Blocks))
+	}
+	signer := crypto.PubkeyToAddress(keys[2].PublicKey)
+	for _, block := range report.Blocks {
+		switch block.Hash {
+		case local.Hash():
+			if !block.Local || len(block.Signers) != 2 {
+				t.Errorf("local block: local %v, signers %v, want local with 2 signers", block.Local, block.Signers)
+			}
+		case forked.Hash():
+			if block.Local || len(block.Signers) != 1 || block.Signers[0] != signer {
+				t.Errorf("forked block signers = %v, want %v", block.Signers, signer)
+			}
+		default:
+			t.Errorf("unexpected block %x in report", block.Hash)
+		}
+	}
+}
+
+// Tests that announcements from nodes outside the validator set, for headers the
+// engine rejects or over a peer's rate limit are ignored.
+func TestE2CMonitorIgnoresInvalidAnnouncements(t *testing.T) {
+	pm, _ := newTestProtocolManagerMust(t, downloader.FullSync, 4, nil, nil)
+	defer pm.Stop()
+
+	dir, err := ioutil.TempDir("", "e2c-safety")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	validator, _ := crypto.GenerateKey()
+	outsider, _ := crypto.GenerateKey()
+	m, halted := newTestE2CMonitor(pm, dir, []*ecdsa.PrivateKey{validator})
+
+	local := pm.blockchain.GetHeaderByNumber(3)
+	forked := types.CopyHeader(local)
+	forked.Extra = []byte("fork")
+	orphan := types.CopyHeader(forked)
+	orphan.ParentHash = common.Hash{0x01}
+
+	tests := []struct {
+		name   string
+		header *types.Header
+		key    *ecdsa.PrivateKey
+	}{
+		{"non-validator", forked, outsider},
+		{"unknown parent", orphan, validator},
+	}
+	for _, tt := range tests {
+		if err := m.handle("peer", announcement(t, tt.header, tt.key)); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if *halted != 0 {
+			t.Fatalf("%s: halted on an invalid announcement", tt.name)
+		}
+		if _, ok := m.commits[tt.header.Number.Uint64()][tt.header.Hash()]; ok {
+			t.Fatalf("%s: announcement recorded", tt.name)
+		}
+	}
+
+	// a peer flooding us with announcements is cut off at its burst
+	for i := 0; i < e2cAnnounceBurst; i++ {
+		m.allow("flooder")
+	}
+	if err := m.handle("flooder", announcement(t, forked, validator)); err != nil {
+		t.Fatal(err)
+	}
+	if *halted != 0 {
+		t.Fatalf("halted on an announcement over the rate limit")
+	}
+	if err := m.handle("peer", announcement(t, forked, validator)); err != nil {
+		t.Fatal(err)
+	}
+	if *halted != 1 {
+		t.Fatalf("halted %d times on a valid announcement from another peer, want 1", *halted)
+	}
+}
@@ -352,6 +352,10 @@ func (api *PublicFilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([
 		if crit.ToBlock != nil {
 			end = crit.ToBlock.Int64()
 		}
+		// Quorum: the range is limited by the quota of the tenant
+		if err := api.checkLogsRange(ctx, begin, end); err != nil {
+			return nil, err
+		}
 		// Construct the range filter
 		filter = NewRangeFilter(api.backend, begin, end, crit.Addresses, crit.Topics, psm.ID)
 	}
@@ -363,6 +367,33 @@ func (api *PublicFilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([
 	return returnLogs(logs), err
 }
 
+// Quorum
+// checkLogsRange returns an error if the blocks from begin to end are more than
+// the tenant making the call can query the logs of
+func (api *PublicFilterAPI) checkLogsRange(ctx context.Context, begin, end int64) error {
+	quota := rpc.TenantQuotaFromContext(ctx)
+	if quota == nil || quota.MaxLogsBlockRange == 0 {
+		return nil
+	}
+	if begin < 0 || end < 0 {
+		header, err := api.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+		if err != nil || header == nil {
+			return fmt.Errorf("unable to resolve the latest block: %v", err)
+		}
+		head := header.Number.Int64()
+		if begin < 0 {
+			begin = head
+		}
+		if end < 0 {
+			end = head
+		}
+	}
+	if end >= begin && uint64(end-begin) >= quota.MaxLogsBlockRange {
+		return fmt.Errorf("block range %d to %d exceeds the limit of %d blocks", begin, end, quota.MaxLogsBlockRange)
+	}
+	return nil
+}
+
 // UninstallFilter removes the filter with the given filter id.
 //
 // https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_uninstallfilter
@@ -418,6 +449,10 @@ func (api *PublicFilterAPI) GetFilterLogs(ctx context.Context, id rpc.ID) ([]*ty
 		if f.crit.ToBlock != nil {
 			end = f.crit.ToBlock.Int64()
 		}
+		// Quorum: the range is limited by the quota of the tenant
+		if err := api.checkLogsRange(ctx, begin, end); err != nil {
+			return nil, err
+		}
 		// Construct the range filter
 		filter = NewRangeFilter(api.backend, begin, end, f.crit.Addresses, f.crit.Topics, psm.ID)
 	}
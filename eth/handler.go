@@ -102,6 +102,12 @@ type ProtocolManager struct {
 	raftMode bool
 	engine   consensus.Engine
 
+	// E2C forwards transactions to the leader, retargeting them when it changes
+	e2cLeaderCh  chan common.Address
+	e2cLeaderSub event.Subscription
+	e2cMonitor   *e2cMonitor // compares committed blocks with other nodes, nil unless enabled
+	e2cWaited    uint32      // set once the first fast sync waited for a signed checkpoint
+
 	// Test fields or hooks
 	broadcastTxAnnouncesOnly bool // Testing field, disable transaction propagation
 }
@@ -273,7 +279,14 @@ func (pm *ProtocolManager) Start(maxPeers int) {
 	pm.wg.Add(1)
 	pm.txsCh = make(chan core.NewTxsEvent, txChanSize)
 	pm.txsSub = pm.txpool.SubscribeNewTxsEvent(pm.txsCh)
+	if e2c, ok := pm.engine.(consensus.E2C); ok {
+		pm.e2cLeaderCh = make(chan common.Address, 1)
+		pm.e2cLeaderSub = e2c.SubscribeLeaderChange(pm.e2cLeaderCh)
+	}
 	go pm.txBroadcastLoop()
+	if pm.e2cMonitor != nil {
+		pm.e2cMonitor.start()
+	}
 
 	// Quorum
 	if !pm.raftMode {
@@ -297,6 +310,12 @@ func (pm *ProtocolManager) Start(maxPeers int) {
 
 func (pm *ProtocolManager) Stop() {
 	pm.txsSub.Unsubscribe() // quits txBroadcastLoop
+	if pm.e2cLeaderSub != nil {
+		pm.e2cLeaderSub.Unsubscribe()
+	}
+	if pm.e2cMonitor != nil {
+		pm.e2cMonitor.stop()
+	}
 	if !pm.raftMode {
 		pm.minedBlockSub.Unsubscribe() // quits blockBroadcastLoop
 	}
@@ -339,6 +358,12 @@ func (pm *ProtocolManager) handle(p *peer, protoName string) error {
 		return p2p.DiscTooManyPeers
 	}
 	p.Log().Debug("Ethereum peer connected", "name", p.Name())
+	// Quorum: refuse E2C peers banned for invalid consensus traffic
+	if pm.e2cBanned(p) {
+		p.Log().Debug("Refusing banned E2C peer")
+		p.EthPeerDisconnected <- struct{}{}
+		return p2p.DiscUselessPeer
+	}
 
 	// Execute the Ethereum handshake
 	var (
@@ -376,6 +401,9 @@ func (pm *ProtocolManager) handle(p *peer, protoName string) error {
 	if err := pm.downloader.RegisterPeer(p.id, p.version, p); err != nil {
 		return err
 	}
+	// Quorum: fetch the latest signed checkpoint before syncing from this peer
+	pm.requestE2CCheckpoint(p)
+
 	pm.chainSync.handlePeerEvent(p)
 
 	// Propagate existing transactions. new transactions appearing
@@ -887,6 +915,10 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 			}
 			p.MarkTransaction(tx.Hash())
 		}
+		// Quorum - the E2C leader acks forwarded transactions and drops the ones it has already seen
+		if e2c, ok := pm.engine.(consensus.E2C); ok {
+			txs = e2c.ReceiveTransactions(crypto.PubkeyToAddress(*p.Node().Pubkey()), txs)
+		}
 		pm.txFetcher.Enqueue(p.id, txs, msg.Code == PooledTransactionsMsg)
 
 	default:
@@ -956,6 +988,11 @@ func (pm *ProtocolManager) BroadcastTransactions(txs types.Transactions, propaga
 	// subset of peers. If this change occurs upstream, a merge conflict should
 	// arise here, and we should add logic to send to *all* peers in raft mode.
 
+	// Quorum - only the E2C leader builds blocks, so non-leaders hand their transactions straight to it
+	if pm.forwardE2CTransactions(txs) {
+		return
+	}
+
 	if propagate {
 		for _, tx := range txs {
 			peers := pm.peers.PeersWithoutTx(tx.Hash())
@@ -1016,6 +1053,9 @@ func (pm *ProtocolManager) txBroadcastLoop() {
 			pm.BroadcastTransactions(event.Txs, true)  // First propagate transactions to peers
 			pm.BroadcastTransactions(event.Txs, false) // Only then announce to the rest
 
+		case <-pm.e2cLeaderCh:
+			pm.retargetE2CTransactions()
+
 		case <-pm.txsSub.Err():
 			return
 		}
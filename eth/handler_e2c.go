@@ -0,0 +1,157 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	e2cTypes "github.com/ethereum/go-ethereum/consensus/e2c"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// e2cCheckpointWait is how long the first fast sync waits for a signed checkpoint
+const e2cCheckpointWait = 10 * time.Second
+
+// forwardE2CTransactions sends the transactions directly to the current E2C leader
+// instead of gossiping them to every peer. It returns false if the transactions
+// should be broadcast as usual, i.e. we aren't running E2C, we are the leader or
+// we aren't connected to it.
+func (pm *ProtocolManager) forwardE2CTransactions(txs types.Transactions) bool {
+	e2c, ok := pm.engine.(consensus.E2C)
+	if !ok {
+		return false
+	}
+	leader, ok := e2c.TxTarget()
+	if !ok {
+		return false
+	}
+	p := pm.peerByAddress(leader)
+	if p == nil {
+		log.Debug("Not connected to E2C leader, gossiping transactions", "leader", leader, "count", len(txs))
+		return false
+	}
+
+	hashes := make([]common.Hash, 0, len(txs))
+	for _, tx := range txs {
+		if !p.knownTxs.Contains(tx.Hash()) {
+			hashes = append(hashes, tx.Hash())
+		}
+	}
+	if len(hashes) > 0 {
+		p.AsyncSendTransactions(hashes)
+		e2c.MarkForwarded(hashes, leader)
+		log.Trace("Forwarded transactions to E2C leader", "leader", leader, "count", len(hashes))
+	}
+	return true
+}
+
+// retargetE2CTransactions forwards everything pending in the pool to the new leader
+// after a view change, the old leader may never include them
+func (pm *ProtocolManager) retargetE2CTransactions() {
+	pending, err := pm.txpool.Pending()
+	if err != nil {
+		log.Error("Failed to retrieve pending transactions", "err", err)
+		return
+	}
+	var txs types.Transactions
+	for _, batch := range pending {
+		txs = append(txs, batch...)
+	}
+	if len(txs) > 0 {
+		pm.forwardE2CTransactions(txs)
+	}
+}
+
+// requestE2CCheckpoint asks a new peer for the latest signed checkpoint so a fast
+// sync can start from it
+func (pm *ProtocolManager) requestE2CCheckpoint(p *peer) {
+	e2c, ok := pm.engine.(consensus.E2C)
+	if !ok {
+		return
+	}
+	if err := e2c.RequestCheckpoint(p); err != nil {
+		p.Log().Debug("Failed to request E2C checkpoint", "err", err)
+	}
+}
+
+// setE2CTrustedPivot hands the latest signed checkpoint to the downloader. The
+// checkpoint carries f+1 validator signatures, so the state synced from it doesn't
+// depend on the peer we happen to sync with
+func (pm *ProtocolManager) setE2CTrustedPivot() {
+	e2c, ok := pm.engine.(consensus.E2C)
+	if !ok {
+		return
+	}
+	cp := e2c.Checkpoint()
+	if cp == nil && atomic.CompareAndSwapUint32(&pm.e2cWaited, 0, 1) {
+		cp = pm.waitE2CCheckpoint(e2c)
+	}
+	if cp == nil {
+		log.Debug("No E2C checkpoint known, fast syncing from the peer's pivot")
+		return
+	}
+	log.Info("Fast syncing from E2C checkpoint", "number", cp.Number, "hash", cp.Hash, "signatures", len(cp.Signatures))
+	pm.downloader.SetTrustedPivot(cp.Number, cp.Hash, cp.Root)
+}
+
+// e2cBanned tells whether the peer is on the E2C ban list
+func (pm *ProtocolManager) e2cBanned(p *peer) bool {
+	e2c, ok := pm.engine.(consensus.E2C)
+	if !ok {
+		return false
+	}
+	return e2c.IsBanned(crypto.PubkeyToAddress(*p.Node().Pubkey()))
+}
+
+// waitE2CCheckpoint gives the peers we asked on connecting some time to answer with
+// a signed checkpoint, so the first fast sync doesn't start from a peer's pivot
+// just because it ran before the answers came in
+func (pm *ProtocolManager) waitE2CCheckpoint(e2c consensus.E2C) *e2cTypes.Checkpoint {
+	log.Info("Waiting for an E2C checkpoint before fast syncing", "timeout", e2cCheckpointWait)
+
+	timeout := time.NewTimer(e2cCheckpointWait)
+	defer timeout.Stop()
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if cp := e2c.Checkpoint(); cp != nil {
+				return cp
+			}
+		case <-timeout.C:
+			return nil
+		case <-pm.quitSync:
+			return nil
+		}
+	}
+}
+
+// peerByAddress returns the connected peer whose node key maps to the address
+func (pm *ProtocolManager) peerByAddress(addr common.Address) *peer {
+	for _, p := range pm.peers.Peers() {
+		if crypto.PubkeyToAddress(*p.Node().Pubkey()) == addr {
+			return p
+		}
+	}
+	return nil
+}
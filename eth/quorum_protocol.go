@@ -115,6 +115,10 @@ func (pm *ProtocolManager) handleConsensus(p *p2p.Peer, protoRW p2p.MsgReadWrite
 			"quorumConsensusProtocolName", quorumConsensusProtocolName, "err", err)
 		return err
 	}
+	// commit announcements of the E2C safety monitor are not the engine's business
+	if msg.Code == e2cCommitMsg && pm.e2cMonitor != nil {
+		return pm.e2cMonitor.handle(fmt.Sprintf("%x", p.ID().Bytes()[:8]), msg)
+	}
 
 	return nil
 }
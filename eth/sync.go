@@ -332,6 +332,10 @@ func (pm *ProtocolManager) doSync(op *chainSyncOp) error {
 			log.Warn("Update txLookup limit", "provided", limit, "updated", *stored)
 		}
 	}
+	// Quorum: pin fast sync to the latest signed checkpoint
+	if op.mode == downloader.FastSync {
+		pm.setE2CTrustedPivot()
+	}
 	// Run the sync cycle, and disable fast sync if we're past the pivot block
 	err := pm.downloader.Synchronise(op.peer.id, op.head, op.td, op.mode)
 	if err != nil {
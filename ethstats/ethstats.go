@@ -191,11 +191,20 @@ func (s *Service) loop() {
 	txSub := s.backend.SubscribeNewTxsEvent(txEventCh)
 	defer txSub.Unsubscribe()
 
+	// Quorum: report E2C view changes right away, they don't always come with a new head
+	var leaderCh chan common.Address
+	if engine, ok := s.engine.(consensus.E2C); ok {
+		leaderCh = make(chan common.Address, 1)
+		leaderSub := engine.SubscribeLeaderChange(leaderCh)
+		defer leaderSub.Unsubscribe()
+	}
+
 	// Start a goroutine that exhausts the subscriptions to avoid events piling up
 	var (
 		quitCh = make(chan struct{})
 		headCh = make(chan *types.Block, 1)
 		txCh   = make(chan struct{}, 1)
+		e2cCh  = make(chan struct{}, 1)
 	)
 	go func() {
 		var lastTx mclock.AbsTime
@@ -222,6 +231,13 @@ func (s *Service) loop() {
 				default:
 				}
 
+			// Notify of E2C leader changes, dropping them while one is pending
+			case <-leaderCh:
+				select {
+				case e2cCh <- struct{}{}:
+				default:
+				}
+
 			// node stopped
 			case <-txSub.Err():
 				break HandleLoop
@@ -312,10 +328,17 @@ func (s *Service) loop() {
 					if err = s.reportPending(conn); err != nil {
 						log.Warn("Post-block transaction stats report failed", "err", err)
 					}
+					if err = s.reportE2C(conn); err != nil {
+						log.Warn("Post-block E2C stats report failed", "err", err)
+					}
 				case <-txCh:
 					if err = s.reportPending(conn); err != nil {
 						log.Warn("Transaction stats report failed", "err", err)
 					}
+				case <-e2cCh:
+					if err = s.reportE2C(conn); err != nil {
+						log.Warn("E2C stats report failed", "err", err)
+					}
 				}
 			}
 			fullReport.Stop()
@@ -500,6 +523,9 @@ func (s *Service) report(conn *connWrapper) error {
 	if err := s.reportStats(conn); err != nil {
 		return err
 	}
+	if err := s.reportE2C(conn); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -785,3 +811,24 @@ func (s *Service) reportStats(conn *connWrapper) error {
 	}
 	return conn.WriteJSON(report)
 }
+
+// reportE2C sends the view, leader, status phase and consensus progress of the
+// node to the stats server if it runs E2C, so the dashboard can show who is
+// leading and which validators fall behind.
+func (s *Service) reportE2C(conn *connWrapper) error {
+	engine, ok := s.engine.(consensus.E2C)
+	if !ok {
+		return nil
+	}
+	e2cStats := engine.Stats()
+	log.Trace("Sending E2C stats to ethstats", "view", e2cStats.View, "leader", e2cStats.Leader, "status", e2cStats.Status)
+
+	stats := map[string]interface{}{
+		"id":    s.node,
+		"stats": e2cStats,
+	}
+	report := map[string][]interface{}{
+		"emit": {"e2c", stats},
+	}
+	return conn.WriteJSON(report)
+}
@@ -12,6 +12,8 @@ import (
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/multitenancy"
 	"github.com/ethereum/go-ethereum/permission/core"
+	"github.com/ethereum/go-ethereum/private/engine"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 var (
@@ -53,7 +55,7 @@ func (api *PrivateExtensionAPI) ActiveExtensionContracts(ctx context.Context) []
 // checks of the passed contract address is under extension process
 func (api *PrivateExtensionAPI) checkIfContractUnderExtension(ctx context.Context, toExtend common.Address) bool {
 	for _, v := range api.ActiveExtensionContracts(ctx) {
-		if v.ContractExtended == toExtend {
+		if checkAddressInList(toExtend, extendedContracts(&v)) {
 			return true
 		}
 	}
@@ -190,7 +192,14 @@ func (api *PrivateExtensionAPI) ApproveExtension(ctx context.Context, addressToV
 	if api.checkAlreadyVoted(addressToVoteOn, txArgs.From, psi) {
 		return "", errors.New("already voted")
 	}
-	uuid, err := generateUuid(addressToVoteOn, txArgs.PrivateFrom, txArgs.PrivateFor, api.privacyService.ptm)
+	// the vote agrees to share the contracts of the batch the node was told about
+	var batch []common.Address
+	api.privacyService.mu.Lock()
+	if extension, ok := api.privacyService.psiContracts[psi][addressToVoteOn]; ok {
+		batch = extension.Batch
+	}
+	api.privacyService.mu.Unlock()
+	uuid, err := generateUuid(addressToVoteOn, batch, txArgs.PrivateFrom, txArgs.PrivateFor, api.privacyService.ptm)
 	if err != nil {
 		return "", err
 	}
@@ -218,23 +227,50 @@ func (api *PrivateExtensionAPI) ApproveExtension(ctx context.Context, addressToV
 // - the contract address we want to extend
 // - the new PTM public key
 // - the Ethereum addresses of who can vote to extend the contract
-func (api *PrivateExtensionAPI) ExtendContract(ctx context.Context, toExtend common.Address, newRecipientPtmPublicKey string, recipientAddr common.Address, txa ethapi.SendTxArgs) (string, error) {
-	// check if the contract to be extended is already under extension
-	// if yes throw an error
-	if api.checkIfContractUnderExtension(ctx, toExtend) {
-		return "", errors.New("contract extension in progress for the given contract address")
-	}
+// - optionally, the block from which the contract rejects votes and the state share
+func (api *PrivateExtensionAPI) ExtendContract(ctx context.Context, toExtend common.Address, newRecipientPtmPublicKey string, recipientAddr common.Address, txa ethapi.SendTxArgs, expiryBlock *rpc.BlockNumber) (string, error) {
+	return api.extendContracts(ctx, []common.Address{toExtend}, newRecipientPtmPublicKey, recipientAddr, txa, expiryBlock)
+}
 
-	// check if a public contract is being extended
-	isPublic, err := api.checkIfPublicContract(toExtend)
-	if err != nil {
-		return "", err
-	}
-	if isPublic {
-		return "", errors.New("extending a public contract!!! not allowed")
+// ExtendContracts deploys one extension management contract extending all the
+// given contracts, e.g. a proxy, its implementation and its libraries, to a new
+// participant. They're voted on once, their state is taken at the same block and
+// the new participant gets all of them in the same transaction.
+//
+// The contracts must have the same privacy flag, contracts with private state
+// validation are extended one by one.
+func (api *PrivateExtensionAPI) ExtendContracts(ctx context.Context, toExtend []common.Address, newRecipientPtmPublicKey string, recipientAddr common.Address, txa ethapi.SendTxArgs, expiryBlock *rpc.BlockNumber) (string, error) {
+	if len(toExtend) == 0 {
+		return "", errors.New("no contracts to extend")
+	}
+	return api.extendContracts(ctx, toExtend, newRecipientPtmPublicKey, recipientAddr, txa, expiryBlock)
+}
+
+func (api *PrivateExtensionAPI) extendContracts(ctx context.Context, contracts []common.Address, newRecipientPtmPublicKey string, recipientAddr common.Address, txa ethapi.SendTxArgs, expiryBlock *rpc.BlockNumber) (string, error) {
+	seen := make(map[common.Address]bool)
+	for _, toExtend := range contracts {
+		if seen[toExtend] {
+			return "", fmt.Errorf("contract %s given more than once", toExtend.Hex())
+		}
+		seen[toExtend] = true
+
+		// check if the contract to be extended is already under extension
+		// if yes throw an error
+		if api.checkIfContractUnderExtension(ctx, toExtend) {
+			return "", fmt.Errorf("contract extension in progress for the given contract address %s", toExtend.Hex())
+		}
+
+		// check if a public contract is being extended
+		isPublic, err := api.checkIfPublicContract(toExtend)
+		if err != nil {
+			return "", err
+		}
+		if isPublic {
+			return "", errors.New("extending a public contract!!! not allowed")
+		}
 	}
 
-	err = api.doMultiTenantChecks(ctx, txa.From, txa)
+	err := api.doMultiTenantChecks(ctx, txa.From, txa)
 	if err != nil {
 		return "", err
 	}
@@ -244,23 +280,49 @@ func (api *PrivateExtensionAPI) ExtendContract(ctx context.Context, toExtend com
 		return "", errors.New("invalid recipient address")
 	}
 
-	psm, err := api.privacyService.apiBackendHelper.PSMR().ResolveForUserContext(ctx)
-	if err != nil {
-		return "", err
+	var expiry uint64
+	if expiryBlock != nil {
+		if *expiryBlock < 0 || uint64(*expiryBlock) <= api.privacyService.apiBackendHelper.CurrentBlock().NumberU64() {
+			return "", errors.New("expiry block must be after the current block")
+		}
+		expiry = uint64(*expiryBlock)
 	}
 
-	// check if a private contract exists
-	privateContractExists, err := api.checkIfPrivateStateExists(psm.ID, toExtend)
+	psm, err := api.privacyService.apiBackendHelper.PSMR().ResolveForUserContext(ctx)
 	if err != nil {
 		return "", err
 	}
-	if !privateContractExists {
-		return "", errors.New("extending a non-existent private contract!!! not allowed")
-	}
 
-	// check if contract creator
-	if !api.privacyService.CheckIfContractCreator(api.privacyService.stateFetcher.getCurrentBlockHash(), toExtend, psm.ID) {
-		return "", errors.New("operation not allowed")
+	currentBlockHash := api.privacyService.stateFetcher.getCurrentBlockHash()
+	var batchFlag *engine.PrivacyFlagType
+	for _, toExtend := range contracts {
+		// check if a private contract exists
+		privateContractExists, err := api.checkIfPrivateStateExists(psm.ID, toExtend)
+		if err != nil {
+			return "", err
+		}
+		if !privateContractExists {
+			return "", errors.New("extending a non-existent private contract!!! not allowed")
+		}
+
+		// check if contract creator
+		if !api.privacyService.CheckIfContractCreator(currentBlockHash, toExtend, psm.ID) {
+			return "", errors.New("operation not allowed")
+		}
+
+		if len(contracts) > 1 {
+			flag := engine.PrivacyFlagStandardPrivate
+			if privacyMetaData, err := api.privacyService.stateFetcher.GetPrivacyMetaData(currentBlockHash, toExtend, psm.ID); err == nil {
+				flag = privacyMetaData.PrivacyFlag
+			}
+			if flag == engine.PrivacyFlagStateValidation {
+				return "", fmt.Errorf("contract %s has private state validation, it can't be extended in a batch", toExtend.Hex())
+			}
+			if batchFlag != nil && *batchFlag != flag {
+				return "", errors.New("the contracts extended in a batch must have the same privacy flag")
+			}
+			batchFlag = &flag
+		}
 	}
 
 	// if running in permissioned mode with new permissions model
@@ -293,10 +355,12 @@ func (api *PrivateExtensionAPI) ExtendContract(ctx context.Context, toExtend com
 		return "", errors.New("invalid transaction manager keys given in privateFor argument")
 	}
 
-	// get all participants for the contract being extended
-	participants, err := api.privacyService.GetAllParticipants(api.privacyService.stateFetcher.getCurrentBlockHash(), toExtend, psm.ID)
-	if err == nil {
-		txa.PrivateFor = append(txa.PrivateFor, participants...)
+	// get all participants for the contracts being extended
+	for _, toExtend := range contracts {
+		participants, err := api.privacyService.GetAllParticipants(currentBlockHash, toExtend, psm.ID)
+		if err == nil {
+			txa.PrivateFor = common.AppendSkipDuplicates(txa.PrivateFor, participants...)
+		}
 	}
 
 	//generate some valid transaction options for sending in the transaction
@@ -308,7 +372,12 @@ func (api *PrivateExtensionAPI) ExtendContract(ctx context.Context, toExtend com
 	psiManagementContractClient := api.privacyService.managementContract(psm.ID)
 	defer psiManagementContractClient.Close()
 	//Deploy the contract
-	tx, err := psiManagementContractClient.Deploy(txArgs, toExtend, recipientAddr, newRecipientPtmPublicKey)
+	var tx *types.Transaction
+	if len(contracts) == 1 && expiry == 0 {
+		tx, err = psiManagementContractClient.Deploy(txArgs, contracts[0], recipientAddr, newRecipientPtmPublicKey)
+	} else {
+		tx, err = psiManagementContractClient.DeployWithOptions(txArgs, contracts, recipientAddr, newRecipientPtmPublicKey, expiry)
+	}
 	if err != nil {
 		return "", err
 	}
@@ -393,3 +462,109 @@ func (api *PrivateExtensionAPI) GetExtensionStatus(ctx context.Context, extensio
 
 	return extensionInProgress, nil
 }
+
+// ExtensionStatus returns the lifecycle of the extension: the vote of each voter,
+// the block the state of the contract is shared from, the hash of the state
+// share, the block the extension finished in and why the state share failed.
+// The extensions the node didn't track are read from the management contract.
+func (api *PrivateExtensionAPI) ExtensionStatus(ctx context.Context, extensionContract common.Address) (*ExtensionStatus, error) {
+	psm, err := api.privacyService.apiBackendHelper.PSMR().ResolveForUserContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	api.privacyService.mu.Lock()
+	status, ok := api.privacyService.statuses[psm.ID][extensionContract]
+	if ok {
+		status = status.copy()
+	}
+	api.privacyService.mu.Unlock()
+	if ok {
+		return status, nil
+	}
+
+	psiManagementContractClient := api.privacyService.managementContract(psm.ID)
+	defer psiManagementContractClient.Close()
+	caller, err := psiManagementContractClient.Caller(extensionContract)
+	if err != nil {
+		return nil, err
+	}
+	status = &ExtensionStatus{ManagementContractAddress: extensionContract, State: ExtensionVoting}
+	if status.ContractExtended, err = caller.ContractToExtend(nil); err != nil {
+		return nil, err
+	}
+	voters, err := psiManagementContractClient.GetAllVoters(extensionContract)
+	if err != nil {
+		return nil, err
+	}
+	allVoted := true
+	for _, voter := range voters {
+		vote := ExtensionVote{Voter: voter}
+		if vote.Voted, err = caller.CheckIfVoted(&bind.CallOpts{From: voter}); err != nil {
+			return nil, err
+		}
+		if vote.Accepted, err = caller.Votes(nil, voter); err != nil {
+			return nil, err
+		}
+		allVoted = allVoted && vote.Voted
+		status.Votes = append(status.Votes, vote)
+	}
+	if status.StateShareHash, err = caller.SharedDataHash(nil); err != nil {
+		return nil, err
+	}
+	finished, err := caller.IsFinished(nil)
+	if err != nil {
+		return nil, err
+	}
+	accepted, err := caller.VoteOutcome(nil)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case status.StateShareHash != "":
+		status.State = ExtensionCompleted
+	case !accepted:
+		status.State = ExtensionRejected
+	case finished:
+		status.State = ExtensionCancelled
+	case allVoted:
+		status.State = ExtensionSharing
+	}
+	return status, nil
+}
+
+// ExtensionEvents notifies on every change of the status of the extensions of
+// the private state, from their creation to their end
+func (api *PrivateExtensionAPI) ExtensionEvents(ctx context.Context) (*rpc.Subscription, error) {
+	psm, err := api.privacyService.apiBackendHelper.PSMR().ResolveForUserContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		events := make(chan ExtensionEvent, 16)
+		sub := api.privacyService.eventFeed.Subscribe(events)
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case event := <-events:
+				if event.psi == psm.ID {
+					notifier.Notify(rpcSub.ID, event)
+				}
+			case <-sub.Err():
+				return
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
@@ -33,6 +33,12 @@ type PrivacyService struct {
 
 	mu           sync.Mutex
 	psiContracts map[types.PrivateStateIdentifier]map[common.Address]*ExtensionContract
+	// the lifecycle of the extensions, finished ones included
+	statuses map[types.PrivateStateIdentifier]map[common.Address]*ExtensionStatus
+	// the status changes made while holding mu, sent once it's released
+	events    []ExtensionEvent
+	eventMu   sync.Mutex // keeps the events in order once mu is released
+	eventFeed event.Feed
 
 	node *node.Node
 }
@@ -90,6 +96,10 @@ func New(stack *node.Node, ptm private.PrivateTransactionManager, manager *accou
 	if err != nil {
 		return nil, errors.New("could not load existing extension contracts: " + err.Error())
 	}
+	service.statuses, err = service.dataHandler.LoadStatuses()
+	if err != nil {
+		return nil, errors.New("could not load extension statuses: " + err.Error())
+	}
 
 	// Register service to node
 	stack.RegisterAPIs(service.apis())
@@ -132,6 +142,11 @@ func (service *PrivacyService) watchForNewContracts(psi types.PrivateStateIdenti
 			service.mu.Unlock()
 			return
 		}
+		managementContractClient := service.managementContract(psi)
+		if caller, err := managementContractClient.Caller(foundLog.Address); err == nil {
+			newContractExtension.Batch, newContractExtension.ExpiryBlock = readOptions(caller, foundLog.BlockNumber)
+		}
+		managementContractClient.Close()
 
 		if service.psiContracts[psi] == nil {
 			service.psiContracts[psi] = make(map[common.Address]*ExtensionContract)
@@ -143,7 +158,8 @@ func (service *PrivacyService) watchForNewContracts(psi types.PrivateStateIdenti
 			service.mu.Unlock()
 			return
 		}
-		service.mu.Unlock()
+		service.trackCreated(psi, foundLog, &newContractExtension)
+		service.unlock()
 
 		// if party is sender then complete self voting
 
@@ -186,6 +202,7 @@ func (service *PrivacyService) watchForCancelledContracts(psi types.PrivateState
 	handler := NewSubscriptionHandler(service.node, psi, service.ptm, service)
 
 	cb := func(l types.Log) {
+		sharedHash, accepted := service.finishedOutcome(psi, l.Address)
 		service.mu.Lock()
 		if _, ok := service.psiContracts[psi][l.Address]; ok {
 			delete(service.psiContracts[psi], l.Address)
@@ -193,21 +210,42 @@ func (service *PrivacyService) watchForCancelledContracts(psi types.PrivateState
 				log.Error("Failed to store list of contracts being extended", "error", err)
 			}
 		}
-		service.mu.Unlock()
+		service.trackFinished(psi, l, sharedHash, accepted)
+		service.unlock()
 	}
 
 	return handler.createSub(finishedExtensionQuery, cb)
 }
 
+// finishedOutcome returns the hash of the state share and the outcome of the vote
+// of a finished extension
+func (service *PrivacyService) finishedOutcome(psi types.PrivateStateIdentifier, address common.Address) (string, bool) {
+	psiManagementContractClient := service.managementContract(psi)
+	defer psiManagementContractClient.Close()
+	caller, err := psiManagementContractClient.Caller(address)
+	if err != nil {
+		log.Error("service.managementContractFacade.Caller", "address", address.Hex(), "error", err)
+		return "", true
+	}
+	sharedHash, err := caller.SharedDataHash(nil)
+	if err != nil {
+		log.Error("[contract] caller.SharedDataHash", "error", err)
+	}
+	accepted, err := caller.VoteOutcome(nil)
+	if err != nil {
+		log.Error("[contract] caller.VoteOutcome", "error", err)
+		return sharedHash, true
+	}
+	return sharedHash, accepted
+}
+
 func (service *PrivacyService) watchForCompletionEvents(psi types.PrivateStateIdentifier) error {
 	handler := NewSubscriptionHandler(service.node, psi, service.ptm, service)
 
 	cb := func(l types.Log) {
 		log.Debug("Extension: Received a completion event", "address", l.Address.Hex(), "blockNumber", l.BlockNumber)
 		service.mu.Lock()
-		defer func() {
-			service.mu.Unlock()
-		}()
+		defer service.unlock()
 		extensionEntry, ok := service.psiContracts[psi][l.Address]
 		if !ok {
 			// we didn't have this management contract, so ignore it
@@ -234,11 +272,17 @@ func (service *PrivacyService) watchForCompletionEvents(psi types.PrivateStateId
 			return
 		}
 
+		// the state share of the extension fails from here on
+		fail := func(reason string, err error) {
+			service.trackFailure(psi, l.Address, fmt.Sprintf("%s: %v", reason, err))
+		}
+
 		// fetch all the participants and send
 		payload := common.BytesToEncryptedPayloadHash(extensionEntry.CreationData)
 		fetchedParties, err := service.ptm.GetParticipants(payload)
 		if err != nil || len(fetchedParties) == 0 {
 			log.Error("Extension: Unable to fetch all parties for extension management contract", "error", err)
+			fail("unable to fetch the parties of the extension", err)
 			return
 		}
 		log.Debug("Extension: able to fetch all parties", "parties", fetchedParties)
@@ -246,6 +290,7 @@ func (service *PrivacyService) watchForCompletionEvents(psi types.PrivateStateId
 		privateFrom, _, _, _, err := service.ptm.Receive(payload)
 		if err != nil || len(privateFrom) == 0 {
 			log.Error("Extension: unable to fetch privateFrom(sender) for extension management contract", "error", err)
+			fail("unable to fetch the sender of the extension", err)
 			return
 		}
 		log.Debug("Extension: able to fetch privateFrom(sender)", "privateFrom", privateFrom)
@@ -253,6 +298,7 @@ func (service *PrivacyService) watchForCompletionEvents(psi types.PrivateStateId
 		txPsi, err := service.apiBackendHelper.PSMR().ResolveForManagedParty(privateFrom)
 		if err != nil {
 			log.Error("Extension: unable to resolve private state metadata for sender", "error", err)
+			fail("unable to resolve the private state of the sender", err)
 			return
 		}
 		if txPsi.ID != psi {
@@ -261,6 +307,7 @@ func (service *PrivacyService) watchForCompletionEvents(psi types.PrivateStateId
 		txArgs, err := service.GenerateTransactOptions(ethapi.SendTxArgs{From: contractCreator, PrivateTxArgs: ethapi.PrivateTxArgs{PrivateFor: fetchedParties, PrivateFrom: privateFrom}})
 		if err != nil {
 			log.Error("service.accountManager.GenerateTransactOptions", "error", err, "contractCreator", contractCreator.Hex(), "privateFor", fetchedParties)
+			fail("unable to sign as the creator", err)
 			return
 		}
 
@@ -268,12 +315,18 @@ func (service *PrivacyService) watchForCompletionEvents(psi types.PrivateStateId
 		contractToExtend, err := caller.ContractToExtend(nil)
 		if err != nil {
 			log.Error("[contract] caller.ContractToExtend", "error", err)
+			fail("unable to read the contract to extend", err)
 			return
 		}
-		log.Debug("Extension: dump current state", "block", l.BlockHash, "contract", contractToExtend.Hex(), "psi", txPsi.ID)
-		entireStateData, err := service.stateFetcher.GetAddressStateFromBlock(l.BlockHash, contractToExtend, txPsi.ID)
+		contracts := []common.Address{contractToExtend}
+		if len(extensionEntry.Batch) > 0 && extensionEntry.Batch[0] == contractToExtend {
+			contracts = extensionEntry.Batch
+		}
+		log.Debug("Extension: dump current state", "block", l.BlockHash, "contracts", contracts, "psi", txPsi.ID)
+		entireStateData, err := service.stateFetcher.GetAddressesStateFromBlock(l.BlockHash, contracts, txPsi.ID)
 		if err != nil {
-			log.Error("[state] service.stateFetcher.GetAddressStateFromBlock", "block", l.BlockHash.Hex(), "contract", contractToExtend.Hex(), "error", err)
+			log.Error("[state] service.stateFetcher.GetAddressesStateFromBlock", "block", l.BlockHash.Hex(), "contracts", contracts, "error", err)
+			fail("unable to read the state of the contract", err)
 			return
 		}
 
@@ -281,6 +334,7 @@ func (service *PrivacyService) watchForCompletionEvents(psi types.PrivateStateId
 
 		// PSV & PP changes
 		// send the new transaction with state dump to all participants
+		// the contracts of a batch have the same privacy flag, checked on creation
 		extraMetaData := engine.ExtraMetadata{PrivacyFlag: engine.PrivacyFlagStandardPrivate}
 		privacyMetaData, err := service.stateFetcher.GetPrivacyMetaData(l.BlockHash, contractToExtend, txPsi.ID)
 		if err != nil {
@@ -288,6 +342,11 @@ func (service *PrivacyService) watchForCompletionEvents(psi types.PrivateStateId
 		} else {
 			extraMetaData.PrivacyFlag = privacyMetaData.PrivacyFlag
 			if privacyMetaData.PrivacyFlag == engine.PrivacyFlagStateValidation {
+				if len(contracts) > 1 {
+					log.Error("Extension: contracts with private state validation can't be extended in a batch", "address", l.Address.Hex())
+					fail("unable to extend a batch with private state validation", errors.New("private state validation"))
+					return
+				}
 				storageRoot, err := service.stateFetcher.GetStorageRoot(l.BlockHash, contractToExtend, txPsi.ID)
 				if err != nil {
 					log.Error("[storageRoot] fetch err", "err", err)
@@ -296,13 +355,23 @@ func (service *PrivacyService) watchForCompletionEvents(psi types.PrivateStateId
 			}
 			// Fetch mandatory recipients data from Tessera - only when privacy flag is 2
 			if privacyMetaData.PrivacyFlag == engine.PrivacyFlagMandatoryRecipients {
-				fetchedMandatoryRecipients, err := service.ptm.GetMandatory(privacyMetaData.CreationTxHash)
-				if err != nil || len(fetchedMandatoryRecipients) == 0 {
-					log.Error("Extension: Unable to fetch mandatory parties for extension management contract", "error", err)
-					return
+				for _, contract := range contracts {
+					if contract != contractToExtend {
+						if privacyMetaData, err = service.stateFetcher.GetPrivacyMetaData(l.BlockHash, contract, txPsi.ID); err != nil {
+							log.Error("[privacyMetaData] fetch err", "err", err)
+							fail("unable to fetch the mandatory recipients", err)
+							return
+						}
+					}
+					fetchedMandatoryRecipients, err := service.ptm.GetMandatory(privacyMetaData.CreationTxHash)
+					if err != nil || len(fetchedMandatoryRecipients) == 0 {
+						log.Error("Extension: Unable to fetch mandatory parties for extension management contract", "error", err)
+						fail("unable to fetch the mandatory recipients", err)
+						return
+					}
+					log.Debug("Extension: able to fetch mandatory recipients", "mandatory", fetchedMandatoryRecipients)
+					extraMetaData.MandatoryRecipients = common.AppendSkipDuplicates(extraMetaData.MandatoryRecipients, fetchedMandatoryRecipients...)
 				}
-				log.Debug("Extension: able to fetch mandatory recipients", "mandatory", fetchedMandatoryRecipients)
-				extraMetaData.MandatoryRecipients = fetchedMandatoryRecipients
 			}
 		}
 
@@ -310,6 +379,7 @@ func (service *PrivacyService) watchForCompletionEvents(psi types.PrivateStateId
 
 		if err != nil {
 			log.Error("[ptm] service.ptm.Send", "stateDataInHex", hex.EncodeToString(entireStateData[:]), "recipients", fetchedParties, "error", err)
+			fail("unable to send the state to the transaction manager", err)
 			return
 		}
 		hashofStateDataBase64 := hashOfStateData.ToBase64()
@@ -317,11 +387,13 @@ func (service *PrivacyService) watchForCompletionEvents(psi types.PrivateStateId
 		transactor, err := psiManagementContractClient.Transactor(l.Address)
 		if err != nil {
 			log.Error("service.managementContractFacade.Transactor", "address", l.Address.Hex(), "error", err)
+			fail("unable to reach the management contract", err)
 			return
 		}
 		log.Debug("Extension: store the encrypted payload hash of dump state", "contract", l.Address.Hex())
 		if tx, err := transactor.SetSharedStateHash(txArgs, hashofStateDataBase64); err != nil {
 			log.Error("[contract] transactor.SetSharedStateHash", "error", err, "hashOfStateInBase64", hashofStateDataBase64)
+			fail("unable to store the hash of the state share", err)
 		} else {
 			log.Debug("Extension: transaction carrying shared state", "txhash", tx.Hash(), "private", tx.IsPrivate())
 		}
@@ -354,6 +426,8 @@ func (service *PrivacyService) Start() error {
 			service.watchForNewContracts,       // watch for new extension contract creation event
 			service.watchForCancelledContracts, // watch for extension contract cancellation event
 			service.watchForCompletionEvents,   // watch for extension contract voting complete event
+			service.watchForLifecycleEvents,    // watch for votes, voting outcome and state share events
+			service.watchForExpiredContracts,   // watch for the expiry of the extensions created by the node
 		} {
 			if err := f(psi); err != nil {
 				return err
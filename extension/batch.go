@@ -0,0 +1,45 @@
+package extension
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/extension/extensionContracts"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// A batch extension extends a set of contracts with one management contract,
+// which tracks the first contract of the batch. The management contract keeps
+// the batch and the block the extension expires at, so every party, and every
+// node replaying the chain, reads them from its state.
+
+// extendedContracts returns the contracts the extension shares
+func extendedContracts(extension *ExtensionContract) []common.Address {
+	if len(extension.Batch) > 0 {
+		return extension.Batch
+	}
+	return []common.Address{extension.ContractExtended}
+}
+
+// readOptions returns the contracts of the batch the management contract
+// extends, nil if it extends a single contract, and the block it expires at, 0
+// if it doesn't expire, as of the block. Management contracts deployed before
+// batches and expiry blocks existed have neither.
+func readOptions(caller *extensionContracts.ContractExtenderCaller, blockNumber uint64) ([]common.Address, uint64) {
+	opts := &bind.CallOpts{BlockNumber: new(big.Int).SetUint64(blockNumber)}
+	batch, err := caller.GetContractsToExtend(opts)
+	if err != nil {
+		log.Debug("Extension: management contract has no batch", "error", err)
+		return nil, 0
+	}
+	expiry, err := caller.ExpiryBlock(opts)
+	if err != nil {
+		log.Debug("Extension: management contract has no expiry block", "error", err)
+		return nil, 0
+	}
+	if len(batch) < 2 {
+		batch = nil
+	}
+	return batch, expiry.Uint64()
+}
@@ -0,0 +1,84 @@
+package extension
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/extension/extensionContracts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSimulatedExtender returns a simulated chain with two funded accounts, the
+// creator and the recipient of the extensions
+func newSimulatedExtender(t *testing.T) (*bind.TransactOpts, *bind.TransactOpts, *backends.SimulatedBackend) {
+	var accounts [2]*bind.TransactOpts
+	alloc := make(core.GenesisAlloc)
+	for i := range accounts {
+		key, _ := crypto.GenerateKey()
+		opts, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+		require.NoError(t, err)
+		accounts[i] = opts
+		alloc[opts.From] = core.GenesisAccount{Balance: big.NewInt(1000000000000000000)}
+	}
+	return accounts[0], accounts[1], backends.NewSimulatedBackend(alloc, 10000000)
+}
+
+func TestReadOptions(t *testing.T) {
+	opts, _, backend := newSimulatedExtender(t)
+	defer backend.Close()
+
+	contracts := []common.Address{
+		common.HexToAddress("0x1111111111111111111111111111111111111111"),
+		common.HexToAddress("0x2222222222222222222222222222222222222222"),
+	}
+	recipient := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	batchAddress, _, caller, err := extensionContracts.DeployContractExtender(opts, backend, contracts[0], recipient, "recipientKey", contracts, big.NewInt(20))
+	require.NoError(t, err)
+	singleAddress, _, single, err := extensionContracts.DeployContractExtender(opts, backend, contracts[0], recipient, "recipientKey", nil, new(big.Int))
+	require.NoError(t, err)
+	backend.Commit()
+
+	batch, expiry := readOptions(&caller.ContractExtenderCaller, 1)
+	assert.Equal(t, contracts, batch)
+	assert.Equal(t, uint64(20), expiry)
+	extended, err := caller.ContractToExtend(nil)
+	require.NoError(t, err)
+	assert.Equal(t, contracts[0], extended)
+
+	batch, expiry = readOptions(&single.ContractExtenderCaller, 1)
+	assert.Nil(t, batch)
+	assert.Zero(t, expiry)
+
+	// the node reads the batch from the storage of the management contract
+	length, err := backend.StorageAt(context.Background(), batchAddress, common.BigToHash(big.NewInt(13)), nil)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(2), new(big.Int).SetBytes(length))
+	length, err = backend.StorageAt(context.Background(), singleAddress, common.BigToHash(big.NewInt(13)), nil)
+	require.NoError(t, err)
+	assert.Zero(t, new(big.Int).SetBytes(length).Sign())
+}
+
+func TestDeployRejectsBatchOfOtherContracts(t *testing.T) {
+	opts, _, backend := newSimulatedExtender(t)
+	defer backend.Close()
+
+	contracts := []common.Address{
+		common.HexToAddress("0x1111111111111111111111111111111111111111"),
+		common.HexToAddress("0x2222222222222222222222222222222222222222"),
+	}
+	opts.GasLimit = 3000000
+	address, _, _, err := extensionContracts.DeployContractExtender(opts, backend, contracts[1], contracts[0], "recipientKey", contracts, new(big.Int))
+	require.NoError(t, err)
+	backend.Commit()
+
+	code, err := backend.CodeAt(context.Background(), address, nil)
+	require.NoError(t, err)
+	assert.Empty(t, code)
+}
@@ -11,6 +11,7 @@ import (
 
 type Client interface {
 	SubscribeToLogs(query ethereum.FilterQuery) (<-chan types.Log, ethereum.Subscription, error)
+	SubscribeToHeads() (<-chan *types.Header, ethereum.Subscription, error)
 	NextNonce(from common.Address) (uint64, error)
 	TransactionByHash(hash common.Hash) (*types.Transaction, error)
 	TransactionInBlock(blockHash common.Hash, txIndex uint) (*types.Transaction, error)
@@ -33,6 +34,12 @@ func (client *InProcessClient) SubscribeToLogs(query ethereum.FilterQuery) (<-ch
 	return retrievedLogsChan, sub, err
 }
 
+func (client *InProcessClient) SubscribeToHeads() (<-chan *types.Header, ethereum.Subscription, error) {
+	headsChan := make(chan *types.Header)
+	sub, err := client.client.SubscribeNewHead(context.Background(), headsChan)
+	return headsChan, sub, err
+}
+
 func (client *InProcessClient) NextNonce(from common.Address) (uint64, error) {
 	return client.client.PendingNonceAt(context.Background(), from)
 }
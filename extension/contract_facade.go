@@ -14,6 +14,7 @@ type ManagementContractFacade interface {
 	Transactor(managementAddress common.Address) (*extensionContracts.ContractExtenderTransactor, error)
 	Caller(managementAddress common.Address) (*extensionContracts.ContractExtenderCaller, error)
 	Deploy(args *bind.TransactOpts, toExtend common.Address, recipientAddress common.Address, recipientHash string) (*types.Transaction, error)
+	DeployWithOptions(args *bind.TransactOpts, toExtend []common.Address, recipientAddress common.Address, recipientHash string, expiryBlock uint64) (*types.Transaction, error)
 
 	GetAllVoters(addressToVoteOn common.Address) ([]common.Address, error)
 	Close()
@@ -36,7 +37,16 @@ func (facade EthclientManagementContractFacade) Caller(managementAddress common.
 }
 
 func (facade EthclientManagementContractFacade) Deploy(args *bind.TransactOpts, toExtend common.Address, recipientAddress common.Address, recipientHash string) (*types.Transaction, error) {
-	_, tx, _, err := extensionContracts.DeployContractExtender(args, facade.client, toExtend, recipientAddress, recipientHash)
+	_, tx, _, err := extensionContracts.DeployContractExtender(args, facade.client, toExtend, recipientAddress, recipientHash, nil, new(big.Int))
+	return tx, err
+}
+
+func (facade EthclientManagementContractFacade) DeployWithOptions(args *bind.TransactOpts, toExtend []common.Address, recipientAddress common.Address, recipientHash string, expiryBlock uint64) (*types.Transaction, error) {
+	var batch []common.Address
+	if len(toExtend) > 1 {
+		batch = toExtend
+	}
+	_, tx, _, err := extensionContracts.DeployContractExtender(args, facade.client, toExtend[0], recipientAddress, recipientHash, batch, new(big.Int).SetUint64(expiryBlock))
 	return tx, err
 }
 
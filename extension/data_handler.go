@@ -35,19 +35,28 @@ import (
 
 const extensionContractData = "activeExtensions.json"
 
+// extensionStatusData keeps the lifecycle of the extensions, including the finished ones
+const extensionStatusData = "extensionStatuses.json"
+
 type DataHandler interface {
 	Load() (map[types.PrivateStateIdentifier]map[common.Address]*ExtensionContract, error)
 
 	Save(extensionContracts map[types.PrivateStateIdentifier]map[common.Address]*ExtensionContract) error
+
+	LoadStatuses() (map[types.PrivateStateIdentifier]map[common.Address]*ExtensionStatus, error)
+
+	SaveStatuses(statuses map[types.PrivateStateIdentifier]map[common.Address]*ExtensionStatus) error
 }
 
 type JsonFileDataHandler struct {
-	saveFile string
+	saveFile   string
+	statusFile string
 }
 
 func NewJsonFileDataHandler(dataDirectory string) *JsonFileDataHandler {
 	return &JsonFileDataHandler{
-		saveFile: filepath.Join(dataDirectory, extensionContractData),
+		saveFile:   filepath.Join(dataDirectory, extensionContractData),
+		statusFile: filepath.Join(dataDirectory, extensionStatusData),
 	}
 }
 
@@ -105,3 +114,28 @@ func (handler *JsonFileDataHandler) Save(extensionContracts map[types.PrivateSta
 	}
 	return nil
 }
+
+func (handler *JsonFileDataHandler) LoadStatuses() (map[types.PrivateStateIdentifier]map[common.Address]*ExtensionStatus, error) {
+	statuses := make(map[types.PrivateStateIdentifier]map[common.Address]*ExtensionStatus)
+	blob, err := ioutil.ReadFile(handler.statusFile)
+	if os.IsNotExist(err) {
+		return statuses, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(blob, &statuses); err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
+
+func (handler *JsonFileDataHandler) SaveStatuses(statuses map[types.PrivateStateIdentifier]map[common.Address]*ExtensionStatus) error {
+	output, _ := json.Marshal(statuses)
+
+	if errSaving := ioutil.WriteFile(handler.statusFile, output, 0644); errSaving != nil {
+		log.Error("Couldn't save extension statuses")
+		return errSaving
+	}
+	return nil
+}
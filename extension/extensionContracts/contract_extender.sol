@@ -26,6 +26,12 @@ contract ContractExtender {
     //if creator cancelled this extension
     bool public isFinished;
 
+    //all the contracts of a batch extension, contractToExtend first, empty if it extends contractToExtend alone
+    address[] contractsToExtend;
+
+    //the block from which no votes or state share are accepted, 0 if the extension doesn't expire
+    uint256 public expiryBlock;
+
     // General housekeeping
     event NewContractExtensionContractCreated(address toExtend, string recipientPTMKey, address recipientAddress); //to tell nodes a new extension is happening
     event AllNodesHaveAccepted(bool outcome); //when all nodes have voted
@@ -35,7 +41,8 @@ contract ContractExtender {
     event StateShared(address toExtend, string tesserahash, string uuid); //when the state is shared and can be replayed into the database
     event UpdateMembers(address toExtend, string uuid); //to update the original transaction hash for the new party member
 
-    constructor(address contractAddress, address recipientAddress, string memory recipientPTMKey) public {
+    constructor(address contractAddress, address recipientAddress, string memory recipientPTMKey, address[] memory batch, uint256 expiry) public {
+        require(batch.length == 0 || batch[0] == contractAddress, "batch must start with the contract to extend");
         creator = msg.sender;
 
         targetRecipientPTMKey = recipientPTMKey;
@@ -54,6 +61,9 @@ contract ContractExtender {
         }
         totalNumberOfVoters = walletAddressesToVote.length;
         emit NewContractExtensionContractCreated(contractAddress, recipientPTMKey, recipientAddress);
+
+        contractsToExtend = batch;
+        expiryBlock = expiry;
     }
 
     /////////////////////////////////////////////////////////////////////////////////////
@@ -69,6 +79,11 @@ contract ContractExtender {
         _;
     }
 
+    modifier notExpired() {
+        require(expiryBlock == 0 || block.number < expiryBlock, "extension has expired");
+        _;
+    }
+
     /////////////////////////////////////////////////////////////////////////////////////
     //main
     /////////////////////////////////////////////////////////////////////////////////////
@@ -87,9 +102,15 @@ contract ContractExtender {
         return isFinished;
     }
 
+    // returns the contracts of a batch extension, empty if it extends
+    // contractToExtend alone
+    function getContractsToExtend() public view returns (address[] memory) {
+        return contractsToExtend;
+    }
+
     // single node vote to either extend or not
     // can't have voted before
-    function doVote(bool vote, string memory nextuuid) public notFinished() {
+    function doVote(bool vote, string memory nextuuid) public notExpired() notFinished() {
         cast(vote);
         if (vote) {
             setUuid(nextuuid);
@@ -108,7 +129,7 @@ contract ContractExtender {
     }
 
     //state has been shared off chain via a private transaction, the hash the PTM generated is set here
-    function setSharedStateHash(string memory hash) public onlyCreator() notFinished() {
+    function setSharedStateHash(string memory hash) public notExpired() onlyCreator() notFinished() {
         bytes memory hashAsBytes = bytes(sharedDataHash);
         bytes memory incomingAsBytes = bytes(hash);
 
@@ -16,3 +16,17 @@ func UnpackNewExtensionCreatedLog(data []byte) (*ContractExtenderNewContractExte
 
 	return newExtensionEvent, err
 }
+
+func UnpackNewVoteLog(data []byte) (*ContractExtenderNewVote, error) {
+	newVoteEvent := new(ContractExtenderNewVote)
+	err := ContractExtenderParsedABI.UnpackIntoInterface(newVoteEvent, "NewVote", data)
+
+	return newVoteEvent, err
+}
+
+func UnpackAllNodesHaveAcceptedLog(data []byte) (*ContractExtenderAllNodesHaveAccepted, error) {
+	acceptedEvent := new(ContractExtenderAllNodesHaveAccepted)
+	err := ContractExtenderParsedABI.UnpackIntoInterface(acceptedEvent, "AllNodesHaveAccepted", data)
+
+	return acceptedEvent, err
+}
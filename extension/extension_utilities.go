@@ -9,14 +9,19 @@ import (
 // generateUuid sends some data to the linked Private Transaction Manager which
 // uses a randomly generated key to encrypt the data and then hash it this
 // means we get a effectively random hash, whilst also having a reference
-// transaction inside the PTM
-func generateUuid(contractAddress common.Address, privateFrom string, privateFor []string, ptm private.PrivateTransactionManager) (string, error) {
+// transaction inside the PTM. The data is the address of the management
+// contract, followed by the contracts of the batch it extends, if any.
+func generateUuid(contractAddress common.Address, batch []common.Address, privateFrom string, privateFor []string, ptm private.PrivateTransactionManager) (string, error) {
 
 	// to ensure recoverability , the UUID generation logic is as below:
 	// 1. Call Tessera to encrypt the management contract address
 	// 2. Send the encrypted payload to all participants on the contract extension
 	// 3. Use the received hash as the UUID
-	payloadHash, err := ptm.EncryptPayload(contractAddress.Bytes(), privateFrom, []string{}, &engine.ExtraMetadata{})
+	contractDetails := contractAddress.Bytes()
+	for _, address := range batch {
+		contractDetails = append(contractDetails, address.Bytes()...)
+	}
+	payloadHash, err := ptm.EncryptPayload(contractDetails, privateFrom, []string{}, &engine.ExtraMetadata{})
 	if err != nil {
 		return "", err
 	}
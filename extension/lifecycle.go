@@ -0,0 +1,299 @@
+package extension
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/extension/extensionContracts"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// The statuses of the extensions are fed by the watchers of the extension
+// events. The management contract rejects votes and the state share from its
+// expiry block on; the node of the creator then cancels the expired extension,
+// as the creator alone can, so it's marked as finished.
+
+// updateStatus applies the change to the status of the extension, if the node
+// knows it, then saves the statuses and queues the notification of the
+// subscribers if the change did anything. The caller must hold service.mu and
+// release it with service.unlock.
+func (service *PrivacyService) updateStatus(psi types.PrivateStateIdentifier, address common.Address, event string, blockNumber uint64, change func(status *ExtensionStatus) bool) {
+	status, ok := service.statuses[psi][address]
+	if !ok || !change(status) {
+		return
+	}
+	if err := service.dataHandler.SaveStatuses(service.statuses); err != nil {
+		log.Error("Failed to store the statuses of the extensions", "error", err)
+	}
+	log.Debug("Extension: status changed", "address", address.Hex(), "event", event, "state", status.State)
+	service.events = append(service.events, ExtensionEvent{Event: event, BlockNumber: blockNumber, Status: status.copy(), psi: psi})
+}
+
+// unlock releases service.mu, then notifies the subscribers of the status
+// changes made while holding it, so slow subscribers don't hold up the service
+func (service *PrivacyService) unlock() {
+	events := service.events
+	service.events = nil
+	service.eventMu.Lock()
+	defer service.eventMu.Unlock()
+	service.mu.Unlock()
+	for _, event := range events {
+		service.eventFeed.Send(event)
+	}
+}
+
+// trackCreated starts the status of a new extension. The caller must hold service.mu.
+func (service *PrivacyService) trackCreated(psi types.PrivateStateIdentifier, l types.Log, extension *ExtensionContract) {
+	if service.statuses[psi] == nil {
+		service.statuses[psi] = make(map[common.Address]*ExtensionStatus)
+	}
+	if _, ok := service.statuses[psi][l.Address]; ok {
+		return
+	}
+	status := &ExtensionStatus{
+		ManagementContractAddress: l.Address,
+		ContractExtended:          extension.ContractExtended,
+		Batch:                     extension.Batch,
+		State:                     ExtensionVoting,
+		Votes:                     []ExtensionVote{{Voter: extension.Initiator}, {Voter: extension.Recipient}},
+		CreationBlock:             l.BlockNumber,
+		ExpiryBlock:               extension.ExpiryBlock,
+	}
+	service.statuses[psi][l.Address] = status
+	service.updateStatus(psi, l.Address, "created", l.BlockNumber, func(*ExtensionStatus) bool { return true })
+}
+
+// trackVote records the vote of a voter. The caller must hold service.mu.
+func (service *PrivacyService) trackVote(psi types.PrivateStateIdentifier, l types.Log, voter common.Address, accepted bool) {
+	service.updateStatus(psi, l.Address, "voted", l.BlockNumber, func(status *ExtensionStatus) bool {
+		vote := ExtensionVote{Voter: voter, Voted: true, Accepted: accepted, BlockNumber: l.BlockNumber}
+		for i := range status.Votes {
+			if status.Votes[i].Voter == voter {
+				if status.Votes[i] == vote {
+					return false
+				}
+				status.Votes[i] = vote
+				return true
+			}
+		}
+		status.Votes = append(status.Votes, vote)
+		return true
+	})
+}
+
+// trackVotingOutcome moves the extension on to the state share, from the state
+// of the contract at the block all voters accepted it, or rejects it. The
+// caller must hold service.mu.
+func (service *PrivacyService) trackVotingOutcome(psi types.PrivateStateIdentifier, l types.Log, accepted bool) {
+	event := "accepted"
+	if !accepted {
+		event = "rejected"
+	}
+	service.updateStatus(psi, l.Address, event, l.BlockNumber, func(status *ExtensionStatus) bool {
+		if status.State != ExtensionVoting {
+			return false
+		}
+		if accepted {
+			status.State = ExtensionSharing
+			status.SnapshotBlock = l.BlockNumber
+		} else {
+			status.State = ExtensionRejected
+			status.CompletionBlock = l.BlockNumber
+		}
+		return true
+	})
+}
+
+// trackStateShared records the hash of the state share. The caller must hold service.mu.
+func (service *PrivacyService) trackStateShared(psi types.PrivateStateIdentifier, l types.Log, hash string) {
+	service.updateStatus(psi, l.Address, "stateShared", l.BlockNumber, func(status *ExtensionStatus) bool {
+		if status.StateShareHash == hash {
+			return false
+		}
+		status.StateShareHash = hash
+		return true
+	})
+}
+
+// trackFailure records why the node of the creator couldn't share the state.
+// The caller must hold service.mu.
+func (service *PrivacyService) trackFailure(psi types.PrivateStateIdentifier, address common.Address, reason string) {
+	service.updateStatus(psi, address, "failed", 0, func(status *ExtensionStatus) bool {
+		if status.State.finished() {
+			return false
+		}
+		status.State = ExtensionFailed
+		status.FailureReason = reason
+		return true
+	})
+}
+
+// trackFinished ends the extension, given the hash of the state share and the
+// outcome of the vote the management contract has. The caller must hold service.mu.
+func (service *PrivacyService) trackFinished(psi types.PrivateStateIdentifier, l types.Log, sharedHash string, accepted bool) {
+	status, ok := service.statuses[psi][l.Address]
+	if !ok || status.State.finished() {
+		return
+	}
+	var state ExtensionState
+	switch {
+	case sharedHash != "" || status.StateShareHash != "":
+		state = ExtensionCompleted
+	case !accepted:
+		state = ExtensionRejected
+	case status.ExpiryCancelled:
+		state = ExtensionExpired
+	default:
+		state = ExtensionCancelled
+	}
+	service.updateStatus(psi, l.Address, strings.ToLower(string(state)), l.BlockNumber, func(status *ExtensionStatus) bool {
+		if status.StateShareHash == "" {
+			status.StateShareHash = sharedHash
+		}
+		status.State = state
+		status.CompletionBlock = l.BlockNumber
+		return true
+	})
+}
+
+// watchForLifecycleEvents tracks the votes, the outcome of the vote and the state
+// share of the extensions
+func (service *PrivacyService) watchForLifecycleEvents(psi types.PrivateStateIdentifier) error {
+	handler := NewSubscriptionHandler(service.node, psi, service.ptm, service)
+
+	cb := func(l types.Log) {
+		if len(l.Topics) == 0 {
+			return
+		}
+		service.mu.Lock()
+		defer service.unlock()
+		switch l.Topics[0] {
+		case common.HexToHash(extensionContracts.NewVoteTopicHash):
+			vote, err := extensionContracts.UnpackNewVoteLog(l.Data)
+			if err != nil {
+				log.Error("Error unpacking extension vote log", "error", err)
+				return
+			}
+			service.trackVote(psi, l, vote.Voter, vote.Vote)
+		case common.HexToHash(extensionContracts.AllNodesHaveAcceptedTopicHash):
+			outcome, err := extensionContracts.UnpackAllNodesHaveAcceptedLog(l.Data)
+			if err != nil {
+				log.Error("Error unpacking extension voting outcome log", "error", err)
+				return
+			}
+			service.trackVotingOutcome(psi, l, outcome.Outcome)
+		case common.HexToHash(extensionContracts.StateSharedTopicHash):
+			_, hash, _, err := extensionContracts.UnpackStateSharedLog(l.Data)
+			if err != nil {
+				log.Error("Error unpacking extension state share log", "error", err)
+				return
+			}
+			service.trackStateShared(psi, l, hash)
+		}
+	}
+
+	return handler.createSub(votingQuery, cb)
+}
+
+// watchForExpiredContracts cancels the extensions the node created once the
+// chain reaches their expiry block. The management contract already rejects
+// them from then on, cancelling only marks them as finished.
+func (service *PrivacyService) watchForExpiredContracts(psi types.PrivateStateIdentifier) error {
+	handler := NewSubscriptionHandler(service.node, psi, service.ptm, service)
+
+	cb := func(head *types.Header) {
+		service.mu.Lock()
+		var expired []common.Address
+		for address, status := range service.statuses[psi] {
+			if status.ExpiryBlock != 0 && head.Number.Uint64() >= status.ExpiryBlock && !status.State.finished() && !status.ExpiryCancelled {
+				if extension, ok := service.psiContracts[psi][address]; ok && service.isCreator(extension) {
+					expired = append(expired, address)
+				}
+			}
+		}
+		service.mu.Unlock()
+
+		for _, address := range expired {
+			// flagged first, the cancellation may be mined before it's sent back
+			service.mu.Lock()
+			service.updateStatus(psi, address, "expiring", head.Number.Uint64(), func(status *ExtensionStatus) bool {
+				status.ExpiryCancelled = true
+				return true
+			})
+			service.unlock()
+			if err := service.cancelExpired(psi, address); err != nil {
+				log.Error("Extension: failed to cancel expired extension", "address", address.Hex(), "error", err)
+				service.mu.Lock()
+				service.updateStatus(psi, address, "failed", head.Number.Uint64(), func(status *ExtensionStatus) bool {
+					status.ExpiryCancelled = false
+					status.FailureReason = fmt.Sprintf("failed to cancel at the expiry block: %v", err)
+					return true
+				})
+				service.unlock()
+			}
+		}
+	}
+
+	return handler.createHeadSub(cb)
+}
+
+// isCreator tells whether the node sent the creation of the management contract,
+// the other parties leave the cancellation of the expired extension to it
+func (service *PrivacyService) isCreator(extension *ExtensionContract) bool {
+	isSender, err := service.ptm.IsSender(common.BytesToEncryptedPayloadHash(extension.CreationData))
+	return err == nil && isSender
+}
+
+// cancelExpired sends the transaction finishing the extension from its creator
+func (service *PrivacyService) cancelExpired(psi types.PrivateStateIdentifier, address common.Address) error {
+	service.mu.Lock()
+	extension, ok := service.psiContracts[psi][address]
+	service.mu.Unlock()
+	if !ok {
+		return errors.New("extension no longer active")
+	}
+
+	psiManagementContractClient := service.managementContract(psi)
+	defer psiManagementContractClient.Close()
+	caller, err := psiManagementContractClient.Caller(address)
+	if err != nil {
+		return err
+	}
+	finished, err := caller.CheckIfExtensionFinished(&bind.CallOpts{Pending: true})
+	if err != nil || finished {
+		return err
+	}
+	creator, err := caller.Creator(nil)
+	if err != nil {
+		return err
+	}
+
+	payload := common.BytesToEncryptedPayloadHash(extension.CreationData)
+	fetchedParties, err := service.ptm.GetParticipants(payload)
+	if err != nil {
+		return err
+	}
+	privateFrom, _, _, _, err := service.ptm.Receive(payload)
+	if err != nil {
+		return err
+	}
+	txArgs, err := service.GenerateTransactOptions(ethapi.SendTxArgs{From: creator, PrivateTxArgs: ethapi.PrivateTxArgs{PrivateFor: fetchedParties, PrivateFrom: privateFrom}})
+	if err != nil {
+		return err
+	}
+	transactor, err := psiManagementContractClient.Transactor(address)
+	if err != nil {
+		return err
+	}
+	tx, err := transactor.Finish(txArgs)
+	if err != nil {
+		return err
+	}
+	log.Info("Extension: cancelling expired extension", "address", address.Hex(), "txhash", tx.Hash())
+	return nil
+}
@@ -0,0 +1,128 @@
+package extension
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/extension/extensionContracts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtensionLifecycle(t *testing.T) {
+	datadir, err := ioutil.TempDir("", t.Name())
+	require.NoError(t, err)
+	defer os.RemoveAll(datadir)
+
+	var (
+		psi         = types.PrivateStateIdentifier("psi1")
+		completed   = common.HexToAddress("0x2222222222222222222222222222222222222222")
+		expired     = common.HexToAddress("0x5555555555555555555555555555555555555555")
+		creator     = common.HexToAddress("0x3333333333333333333333333333333333333333")
+		recipient   = common.HexToAddress("0x4444444444444444444444444444444444444444")
+		toExtend    = common.HexToAddress("0x1111111111111111111111111111111111111111")
+		dataHandler = NewJsonFileDataHandler(datadir)
+		service     = &PrivacyService{
+			dataHandler: dataHandler,
+			statuses:    make(map[types.PrivateStateIdentifier]map[common.Address]*ExtensionStatus),
+		}
+		extension = &ExtensionContract{ContractExtended: toExtend, Initiator: creator, Recipient: recipient, ExpiryBlock: 20}
+	)
+	events := make(chan ExtensionEvent, 16)
+	sub := service.eventFeed.Subscribe(events)
+	defer sub.Unsubscribe()
+	nextEvent := func() string {
+		select {
+		case ev := <-events:
+			return ev.Event
+		default:
+			return ""
+		}
+	}
+
+	// the subscribers are notified once the service is unlocked
+	service.mu.Lock()
+	service.trackCreated(psi, types.Log{Address: completed, BlockNumber: 1}, extension)
+	assert.Equal(t, "", nextEvent())
+	service.trackVote(psi, types.Log{Address: completed, BlockNumber: 2}, creator, true)
+	service.trackVote(psi, types.Log{Address: completed, BlockNumber: 2}, creator, true)
+	service.trackVote(psi, types.Log{Address: completed, BlockNumber: 3}, recipient, true)
+	service.trackVotingOutcome(psi, types.Log{Address: completed, BlockNumber: 3}, true)
+	service.trackFailure(psi, completed, "unable to send the state to the transaction manager")
+	// the extension finished before the state share log is handled
+	service.trackFinished(psi, types.Log{Address: completed, BlockNumber: 5}, "hash", true)
+	service.trackStateShared(psi, types.Log{Address: completed, BlockNumber: 5}, "hash")
+	service.unlock()
+	for _, expected := range []string{"created", "voted", "voted", "accepted", "failed", "completed", ""} {
+		assert.Equal(t, expected, nextEvent())
+	}
+
+	status := service.statuses[psi][completed]
+	assert.Equal(t, ExtensionCompleted, status.State)
+	assert.Equal(t, uint64(20), status.ExpiryBlock)
+	assert.Equal(t, []ExtensionVote{
+		{Voter: creator, Voted: true, Accepted: true, BlockNumber: 2},
+		{Voter: recipient, Voted: true, Accepted: true, BlockNumber: 3},
+	}, status.Votes)
+	assert.Equal(t, uint64(3), status.SnapshotBlock)
+	assert.Equal(t, "hash", status.StateShareHash)
+	assert.Equal(t, uint64(5), status.CompletionBlock)
+	assert.NotEmpty(t, status.FailureReason)
+
+	// cancelled by the node of the creator at the expiry block
+	extension.ExpiryBlock = 6
+	service.mu.Lock()
+	service.trackCreated(psi, types.Log{Address: expired, BlockNumber: 5}, extension)
+	service.updateStatus(psi, expired, "expiring", 6, func(status *ExtensionStatus) bool {
+		status.ExpiryCancelled = true
+		return true
+	})
+	service.trackFinished(psi, types.Log{Address: expired, BlockNumber: 7}, "", true)
+	service.trackVotingOutcome(psi, types.Log{Address: expired, BlockNumber: 8}, false)
+	service.unlock()
+	for _, expected := range []string{"created", "expiring", "expired", ""} {
+		assert.Equal(t, expected, nextEvent())
+	}
+	assert.Equal(t, ExtensionExpired, service.statuses[psi][expired].State)
+	assert.Equal(t, uint64(6), service.statuses[psi][expired].ExpiryBlock)
+
+	loaded, err := dataHandler.LoadStatuses()
+	require.NoError(t, err)
+	assert.Equal(t, service.statuses, loaded)
+}
+
+func TestManagementContractRejectsExpiredExtension(t *testing.T) {
+	opts, recipientOpts, backend := newSimulatedExtender(t)
+	defer backend.Close()
+
+	toExtend := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	// deployed in block 1, expires at block 3
+	_, _, extender, err := extensionContracts.DeployContractExtender(opts, backend, toExtend, recipientOpts.From, "recipientKey", nil, big.NewInt(3))
+	require.NoError(t, err)
+	backend.Commit()
+
+	// block 2 is before the expiry block
+	_, err = extender.DoVote(opts, true, "creatorUuid")
+	require.NoError(t, err)
+	backend.Commit()
+
+	// block 3 is the expiry block
+	_, err = extender.DoVote(recipientOpts, true, "recipientUuid")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "extension has expired")
+	_, err = extender.SetSharedStateHash(opts, "hash")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "extension has expired")
+
+	// the creator can still cancel it
+	_, err = extender.Finish(opts)
+	require.NoError(t, err)
+	backend.Commit()
+	finished, err := extender.IsFinished(nil)
+	require.NoError(t, err)
+	assert.True(t, finished)
+}
@@ -3,11 +3,13 @@ package privacyExtension
 import (
 	"bytes"
 	"encoding/json"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/mps"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	extension "github.com/ethereum/go-ethereum/extension/extensionContracts"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/private"
@@ -58,21 +60,34 @@ func (handler *ExtensionHandler) CheckExtensionAndSetPrivateState(txLogs []*type
 			// check the privacy flag of the contract. if its other than
 			// 0 then need to update the privacy metadata for the contract
 			//TODO: validate the old and new parties to ensure that all old parties are there
-			setPrivacyMetadata(privateState, address, hash)
-			if handler.isMultitenant {
-				setManagedParties(handler.ptm, privateState, address, hash)
+			for _, sharedAddress := range sharedAddresses(privateState, txLog.Address, address) {
+				if privateState.GetCode(sharedAddress) == nil {
+					continue
+				}
+				setPrivacyMetadata(privateState, sharedAddress, hash)
+				if handler.isMultitenant {
+					setManagedParties(handler.ptm, privateState, sharedAddress, hash)
+				}
 			}
 			extraMetaDataUpdated = true
 		} else {
-			managedParties, accounts, privacyMetaData, found := handler.FetchStateData(txLog.Address, hash, uuid, psi)
+			managedParties, accounts, privacyMetaData, batch, found := handler.FetchStateData(txLog.Address, hash, uuid, psi)
 			if !found {
 				continue
 			}
 			if !handler.isMultitenant {
 				managedParties = nil
 			}
-			if !validateAccountsExist([]common.Address{address}, accounts) {
-				log.Error("Account mismatch", "expected", address, "found", accounts)
+			expectedAccounts := []common.Address{address}
+			if len(batch) > 0 {
+				expectedAccounts = batch
+			}
+			if batch != nil && batch[0] != address {
+				log.Error("Extension: batch doesn't start with the extended contract", "expected", address, "found", batch[0])
+				continue
+			}
+			if !validateAccountsExist(expectedAccounts, accounts) {
+				log.Error("Account mismatch", "expected", expectedAccounts, "found", accounts)
 				continue
 			}
 			snapshotId := privateState.Snapshot()
@@ -84,25 +99,57 @@ func (handler *ExtensionHandler) CheckExtensionAndSetPrivateState(txLogs []*type
 	}
 }
 
-func (handler *ExtensionHandler) FetchStateData(address common.Address, hash string, uuid string, psi types.PrivateStateIdentifier) ([]string, map[string]extension.AccountWithMetadata, *state.PrivacyMetadata, bool) {
-	if uuidIsSentByUs := handler.UuidIsOwn(address, uuid, psi); !uuidIsSentByUs {
-		return nil, nil, nil, false
+// FetchStateData returns the state shared with the node, if the node voted for
+// the extension, and the contracts of the batch it voted to extend, if any
+func (handler *ExtensionHandler) FetchStateData(address common.Address, hash string, uuid string, psi types.PrivateStateIdentifier) ([]string, map[string]extension.AccountWithMetadata, *state.PrivacyMetadata, []common.Address, bool) {
+	batch, uuidIsSentByUs := handler.uuidContracts(address, uuid, psi)
+	if !uuidIsSentByUs {
+		return nil, nil, nil, nil, false
 	}
 
 	managedParties, stateData, privacyMetaData, ok := handler.FetchDataFromPTM(hash)
 	if !ok {
 		//there is nothing to do here, the state wasn't shared with us
 		log.Error("Extension: No state shared with us")
-		return nil, nil, nil, false
+		return nil, nil, nil, nil, false
 	}
 
 	var accounts map[string]extension.AccountWithMetadata
 	if err := json.Unmarshal(stateData, &accounts); err != nil {
 		log.Error("Extension: Could not unmarshal data")
-		return nil, nil, nil, false
+		return nil, nil, nil, nil, false
 	}
 
-	return managedParties, accounts, privacyMetaData, true
+	return managedParties, accounts, privacyMetaData, batch, true
+}
+
+// contractsToExtendSlot is the storage slot of the contracts of a batch
+// extension in the management contract
+var contractsToExtendSlot = common.BigToHash(big.NewInt(13))
+
+// sharedAddresses returns the addresses whose privacy metadata the state share
+// updates: the contracts of the batch the management contract extends, read
+// from its storage, the extended contract alone if it isn't a batch. The other
+// accounts of the shared state are ignored.
+func sharedAddresses(privateState *state.StateDB, managementContract common.Address, address common.Address) []common.Address {
+	length := privateState.GetState(managementContract, contractsToExtendSlot).Big()
+	if !length.IsUint64() || length.Uint64() < 2 {
+		return []common.Address{address}
+	}
+	// the elements of a dynamic array start at the hash of its slot; the
+	// length is only trusted as far as the elements are set, as any contract
+	// can log a state share
+	first := crypto.Keccak256Hash(contractsToExtendSlot.Bytes()).Big()
+	var contracts []common.Address
+	for i := uint64(0); i < length.Uint64(); i++ {
+		slot := common.BigToHash(new(big.Int).Add(first, new(big.Int).SetUint64(i)))
+		contract := common.BytesToAddress(privateState.GetState(managementContract, slot).Bytes())
+		if contract == (common.Address{}) || (i == 0 && contract != address) {
+			return []common.Address{address}
+		}
+		contracts = append(contracts, contract)
+	}
+	return contracts
 }
 
 // Checks
@@ -125,39 +172,47 @@ func (handler *ExtensionHandler) FetchDataFromPTM(hash string) ([]string, []byte
 }
 
 func (handler *ExtensionHandler) UuidIsOwn(address common.Address, uuid string, psi types.PrivateStateIdentifier) bool {
+	_, isOwn := handler.uuidContracts(address, uuid, psi)
+	return isOwn
+}
+
+// uuidContracts checks the uuid was generated by the node voting on the
+// management contract at the address, and returns the contracts of the batch
+// the vote agreed to extend, nil if it extends a single contract
+func (handler *ExtensionHandler) uuidContracts(address common.Address, uuid string, psi types.PrivateStateIdentifier) ([]common.Address, bool) {
 	if uuid == "" {
 		//we never called accept
 		log.Warn("Extension: State shared by accept never called")
-		return false
+		return nil, false
 	}
 	encryptedTxHash := common.BytesToEncryptedPayloadHash(common.FromHex(uuid))
 	isSender, err := handler.ptm.IsSender(encryptedTxHash)
 	if err != nil {
 		log.Debug("Extension: could not determine if we are sender", "err", err.Error())
-		return false
+		return nil, false
 	}
 
 	if !isSender {
-		return false
+		return nil, false
 	}
 
 	senderPublicKey, _, encryptedPayload, _, err := handler.ptm.Receive(encryptedTxHash)
 	if err != nil {
 		log.Debug("Extension: payload not found", "err", err)
-		return false
+		return nil, false
 	}
 
 	//check the given PSI is same as PSI of sender key
 	senderPsm, err := handler.psmr.ResolveForManagedParty(senderPublicKey)
 	if err != nil {
 		log.Debug("Extension: unable to determine sender public key PSI", "err", err)
-		return false
+		return nil, false
 	}
 
 	if senderPsm.ID != psi {
 		// sender was another tenant on this node
 		//not an error case, so no need to log an error
-		return false
+		return nil, false
 	}
 
 	var payload common.DecryptRequest
@@ -170,9 +225,15 @@ func (handler *ExtensionHandler) UuidIsOwn(address common.Address, uuid string,
 		log.Debug("Extension: payload decrypt failed", "err", err)
 	}
 
-	if !bytes.Equal(contractDetails, address.Bytes()) {
+	// the management contract address, then the contracts of the batch
+	if len(contractDetails) < common.AddressLength || len(contractDetails)%common.AddressLength != 0 ||
+		!bytes.Equal(contractDetails[:common.AddressLength], address.Bytes()) {
 		log.Error("Extension: wrong address in retrieved UUID")
-		return false
+		return nil, false
+	}
+	var batch []common.Address
+	for i := common.AddressLength; i < len(contractDetails); i += common.AddressLength {
+		batch = append(batch, common.BytesToAddress(contractDetails[i:i+common.AddressLength]))
 	}
-	return true
+	return batch, true
 }
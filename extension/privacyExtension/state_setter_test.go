@@ -2,12 +2,15 @@ package privacyExtension
 
 import (
 	"errors"
+	"math/big"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/mps"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -216,3 +219,55 @@ func TestExtensionHandler_UuidIsOwn_PrivatePSMRSucceeds(t *testing.T) {
 
 	assert.True(t, isOwn)
 }
+
+func TestExtensionHandler_UuidContracts_Batch(t *testing.T) {
+	uuid := "0xabcd"
+	address := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	batch := []common.Address{
+		common.HexToAddress("0x3333333333333333333333333333333333333333"),
+		common.HexToAddress("0x4444444444444444444444444444444444444444"),
+	}
+
+	ptm := &mockPrivateTransactionManager{
+		returns: map[string][]interface{}{
+			"IsSender":       {true, nil},
+			"Receive":        {"psi1", nil, []byte(`{"somedata": "val"}`), nil, nil},
+			"DecryptPayload": {append(append(address.Bytes(), batch[0].Bytes()...), batch[1].Bytes()...), nil, nil},
+		},
+	}
+	handler := NewExtensionHandler(ptm)
+	psmr := &mockPSMR{
+		returns: map[string][]interface{}{
+			"ResolveForManagedParty": {&mps.PrivateStateMetadata{ID: "psi1", Type: mps.Resident}, nil},
+		},
+	}
+	handler.SetPSMR(psmr)
+
+	contracts, isOwn := handler.uuidContracts(address, uuid, "psi1")
+
+	assert.True(t, isOwn)
+	assert.Equal(t, batch, contracts)
+	assert.True(t, handler.UuidIsOwn(address, uuid, "psi1"))
+}
+
+func TestSharedAddresses(t *testing.T) {
+	managementContract := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	address := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	batch := []common.Address{address, common.HexToAddress("0x3333333333333333333333333333333333333333")}
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	assert.Equal(t, []common.Address{address}, sharedAddresses(statedb, managementContract, address))
+
+	first := crypto.Keccak256Hash(contractsToExtendSlot.Bytes()).Big()
+	statedb.SetState(managementContract, contractsToExtendSlot, common.BigToHash(big.NewInt(2)))
+	for i, contract := range batch {
+		statedb.SetState(managementContract, common.BigToHash(new(big.Int).Add(first, big.NewInt(int64(i)))), common.BytesToHash(contract.Bytes()))
+	}
+	assert.Equal(t, batch, sharedAddresses(statedb, managementContract, address))
+	// batches of other contracts update the extended contract alone
+	assert.Equal(t, []common.Address{batch[1]}, sharedAddresses(statedb, managementContract, batch[1]))
+
+	// so do batches longer than their elements
+	statedb.SetState(managementContract, contractsToExtendSlot, common.BigToHash(big.NewInt(3)))
+	assert.Equal(t, []common.Address{address}, sharedAddresses(statedb, managementContract, address))
+}
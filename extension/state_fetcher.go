@@ -56,11 +56,17 @@ func (fetcher *StateFetcher) getCurrentBlockHash() common.Hash {
 // functions of a StateFetcher, retrieving the state of an address at a given
 // block, represented in JSON.
 func (fetcher *StateFetcher) GetAddressStateFromBlock(blockHash common.Hash, addressToFetch common.Address, psi types.PrivateStateIdentifier) ([]byte, error) {
+	return fetcher.GetAddressesStateFromBlock(blockHash, []common.Address{addressToFetch}, psi)
+}
+
+// GetAddressesStateFromBlock retrieves the state of several addresses at a
+// given block, represented in JSON, as for a batch extension.
+func (fetcher *StateFetcher) GetAddressesStateFromBlock(blockHash common.Hash, addressesToFetch []common.Address, psi types.PrivateStateIdentifier) ([]byte, error) {
 	privateState, err := fetcher.privateState(blockHash, psi)
 	if err != nil {
 		return nil, err
 	}
-	stateData, err := fetcher.addressStateAsJson(privateState, addressToFetch)
+	stateData, err := fetcher.addressStateAsJson(privateState, addressesToFetch...)
 	if err != nil {
 		return nil, err
 	}
@@ -75,17 +81,19 @@ func (fetcher *StateFetcher) privateState(blockHash common.Hash, psi types.Priva
 	return privateState, err
 }
 
-// addressStateAsJson returns the state of an address, including the balance,
+// addressStateAsJson returns the state of the addresses, including the balance,
 // nonce, code and state data as a JSON map.
-func (fetcher *StateFetcher) addressStateAsJson(privateState *state.StateDB, addressToShare common.Address) ([]byte, error) {
+func (fetcher *StateFetcher) addressStateAsJson(privateState *state.StateDB, addressesToShare ...common.Address) ([]byte, error) {
 	keepAddresses := make(map[string]extensionContracts.AccountWithMetadata)
 
-	if account, found := privateState.DumpAddress(addressToShare); found {
+	for _, addressToShare := range addressesToShare {
+		account, found := privateState.DumpAddress(addressToShare)
+		if !found {
+			return nil, fmt.Errorf("error in contract state fetch")
+		}
 		keepAddresses[addressToShare.Hex()] = extensionContracts.AccountWithMetadata{
 			State: account,
 		}
-	} else {
-		return nil, fmt.Errorf("error in contract state fetch")
 	}
 	//types can be marshalled, so errors can't occur
 	out, _ := json.Marshal(&keepAddresses)
@@ -56,3 +56,30 @@ func (handler *subscriptionHandler) createSub(query ethereum.FilterQuery, logHan
 
 	return nil
 }
+
+func (handler *subscriptionHandler) createHeadSub(headHandlerCb func(*types.Header)) error {
+	incomingHeads, subscription, err := handler.client.SubscribeToHeads()
+
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		stopChan, stopSubscription := handler.service.subscribeStopEvent()
+		defer stopSubscription.Unsubscribe()
+
+		for {
+			select {
+			case err := <-subscription.Err():
+				log.Error("Contract extension head subscription error", "error", err)
+				return
+			case head := <-incomingHeads:
+				headHandlerCb(head)
+			case <-stopChan:
+				return
+			}
+		}
+	}()
+
+	return nil
+}
@@ -3,6 +3,7 @@ package extension
 import (
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/extension/extensionContracts"
 )
 
@@ -28,6 +29,17 @@ var (
 		Topics:    [][]common.Hash{{common.HexToHash(extensionContracts.CanPerformStateShareTopicHash)}},
 		Addresses: []common.Address{},
 	}
+
+	votingQuery = ethereum.FilterQuery{
+		FromBlock: nil,
+		ToBlock:   nil,
+		Topics: [][]common.Hash{{
+			common.HexToHash(extensionContracts.NewVoteTopicHash),
+			common.HexToHash(extensionContracts.AllNodesHaveAcceptedTopicHash),
+			common.HexToHash(extensionContracts.StateSharedTopicHash),
+		}},
+		Addresses: []common.Address{},
+	}
 )
 
 type ExtensionContract struct {
@@ -37,4 +49,76 @@ type ExtensionContract struct {
 	ManagementContractAddress common.Address `json:"managementContractAddress"`
 	RecipientPtmKey           string         `json:"recipientPtmKey"`
 	CreationData              []byte         `json:"creationData"`
+	// all the contracts of a batch extension, ContractExtended first
+	Batch []common.Address `json:"batch,omitempty"`
+	// the block from which the management contract rejects votes and the state share, 0 if it doesn't expire
+	ExpiryBlock uint64 `json:"expiryBlock,omitempty"`
+}
+
+// ExtensionState is the stage of the lifecycle an extension has reached
+type ExtensionState string
+
+const (
+	// ExtensionVoting waits for the votes of the creator and the recipient
+	ExtensionVoting ExtensionState = "VOTING"
+	// ExtensionSharing waits for the creator to share the state of the contract
+	ExtensionSharing ExtensionState = "SHARING"
+	// ExtensionFailed couldn't share the state of the contract, the creator can
+	// only cancel it
+	ExtensionFailed ExtensionState = "FAILED"
+	// ExtensionCompleted shared the state of the contract with the recipient
+	ExtensionCompleted ExtensionState = "COMPLETED"
+	// ExtensionRejected was voted against
+	ExtensionRejected ExtensionState = "REJECTED"
+	// ExtensionCancelled was cancelled by the creator
+	ExtensionCancelled ExtensionState = "CANCELLED"
+	// ExtensionExpired reached its expiry block before completing, the node of
+	// the creator cancels it
+	ExtensionExpired ExtensionState = "EXPIRED"
+)
+
+// finished reports whether the extension can't change anymore
+func (s ExtensionState) finished() bool {
+	return s == ExtensionCompleted || s == ExtensionRejected || s == ExtensionCancelled || s == ExtensionExpired
+}
+
+// ExtensionVote is the vote of a voter of an extension
+type ExtensionVote struct {
+	Voter       common.Address `json:"voter"`
+	Voted       bool           `json:"voted"`
+	Accepted    bool           `json:"accepted"`
+	BlockNumber uint64         `json:"blockNumber,omitempty"`
+}
+
+// ExtensionStatus is the lifecycle of an extension, as seen by the node
+type ExtensionStatus struct {
+	ManagementContractAddress common.Address   `json:"managementContractAddress"`
+	ContractExtended          common.Address   `json:"contractExtended"`
+	Batch                     []common.Address `json:"batch,omitempty"`
+	State                     ExtensionState   `json:"state"`
+	Votes                     []ExtensionVote  `json:"votes"`
+	CreationBlock             uint64           `json:"creationBlock,omitempty"`
+	ExpiryBlock               uint64           `json:"expiryBlock,omitempty"`
+	SnapshotBlock             uint64           `json:"snapshotBlock,omitempty"`
+	StateShareHash            string           `json:"stateShareHash,omitempty"`
+	CompletionBlock           uint64           `json:"completionBlock,omitempty"`
+	FailureReason             string           `json:"failureReason,omitempty"`
+	// the node of the creator sent the transaction cancelling the expired extension
+	ExpiryCancelled bool `json:"expiryCancelled,omitempty"`
+}
+
+func (status *ExtensionStatus) copy() *ExtensionStatus {
+	cpy := *status
+	cpy.Votes = append([]ExtensionVote(nil), status.Votes...)
+	cpy.Batch = append([]common.Address(nil), status.Batch...)
+	return &cpy
+}
+
+// ExtensionEvent is sent to the quorumExtension_subscribe("extensionEvents")
+// subscribers on every change of the status of an extension
+type ExtensionEvent struct {
+	Event       string                       `json:"event"`
+	BlockNumber uint64                       `json:"blockNumber,omitempty"`
+	Status      *ExtensionStatus             `json:"status"`
+	psi         types.PrivateStateIdentifier // who may see it
 }
@@ -1054,13 +1054,14 @@ func (e *revertError) ErrorData() interface{} {
 // Quorum
 // - replaced the default 5s time out with the value passed in vm.calltimeout
 // - multi tenancy verification
+// - gas capped by the quota of the tenant
 func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *map[common.Address]account) (hexutil.Bytes, error) {
 	var accounts map[common.Address]account
 	if overrides != nil {
 		accounts = *overrides
 	}
 
-	result, err := DoCall(ctx, s.b, args, blockNrOrHash, accounts, vm.Config{}, s.b.CallTimeOut(), s.b.RPCGasCap())
+	result, err := DoCall(ctx, s.b, args, blockNrOrHash, accounts, vm.Config{}, s.b.CallTimeOut(), callGasCap(ctx, s.b))
 	if err != nil {
 		return nil, err
 	}
@@ -1222,7 +1223,18 @@ func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs, bl
 	if blockNrOrHash != nil {
 		bNrOrHash = *blockNrOrHash
 	}
-	return DoEstimateGas(ctx, s.b, args, bNrOrHash, s.b.RPCGasCap())
+	return DoEstimateGas(ctx, s.b, args, bNrOrHash, callGasCap(ctx, s.b))
+}
+
+// Quorum
+// callGasCap returns the gas cap of the calls, lowered to the quota of the
+// tenant making the call, if any
+func callGasCap(ctx context.Context, b Backend) uint64 {
+	gasCap := b.RPCGasCap()
+	if quota := rpc.TenantQuotaFromContext(ctx); quota != nil && quota.MaxCallGas != 0 && (gasCap == 0 || quota.MaxCallGas < gasCap) {
+		gasCap = quota.MaxCallGas
+	}
+	return gasCap
 }
 
 // ExecutionResult groups all structured logs emitted by the EVM
@@ -2539,6 +2551,13 @@ func (api *PrivateDebugAPI) SetHead(number hexutil.Uint64) {
 	api.b.SetHead(uint64(number))
 }
 
+// Quorum
+// PrivateTransactionManagerHealth reports whether the private transaction manager
+// is available, with the state of each of its endpoints.
+func (api *PrivateDebugAPI) PrivateTransactionManagerHealth() *private.Health {
+	return private.GetHealth()
+}
+
 // PublicNetAPI offers network related RPC methods
 type PublicNetAPI struct {
 	net            *p2p.Server
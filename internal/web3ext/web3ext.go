@@ -38,6 +38,7 @@ var Modules = map[string]string{
 	"istanbul":         Istanbul_JS,
 	"quorumPermission": QUORUM_NODE_JS,
 	"quorumExtension":  Extension_JS,
+	"privacy":          Privacy_JS,
 	"plugin_account":   Account_Plugin_Js,
 }
 
@@ -192,6 +193,38 @@ web3._extend({
 			call: 'admin_importChain',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'createPrivateState',
+			call: 'admin_createPrivateState',
+			params: 2,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'retirePrivateState',
+			call: 'admin_retirePrivateState',
+			params: 3,
+			inputFormatter: [null, null, null]
+		}),
+		new web3._extend.Method({
+			name: 'e2cPeerScores',
+			call: 'admin_e2cPeerScores'
+		}),
+		new web3._extend.Method({
+			name: 'e2cBans',
+			call: 'admin_e2cBans'
+		}),
+		new web3._extend.Method({
+			name: 'e2cBan',
+			call: 'admin_e2cBan',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'e2cUnban',
+			call: 'admin_e2cUnban',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter]
+		}),
 		new web3._extend.Method({
 			name: 'sleepBlocks',
 			call: 'admin_sleepBlocks',
@@ -296,6 +329,12 @@ web3._extend({
 			params: 2,
 			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputBlockNumberFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'exportPrivateState',
+			call: 'debug_exportPrivateState',
+			params: 3,
+			inputFormatter: [null, null, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
 		new web3._extend.Method({
 			name: 'chaindbProperty',
 			call: 'debug_chaindbProperty',
@@ -306,6 +345,10 @@ web3._extend({
 			name: 'chaindbCompact',
 			call: 'debug_chaindbCompact',
 		}),
+		new web3._extend.Method({
+			name: 'privateTransactionManagerHealth',
+			call: 'debug_privateTransactionManagerHealth',
+		}),
 		new web3._extend.Method({
 			name: 'verbosity',
 			call: 'debug_verbosity',
@@ -894,6 +937,21 @@ web3._extend({
                        call: 'raft_promoteToPeer',
                        params: 1
                }),
+               new web3._extend.Method({
+                       name: 'transferLeadership',
+                       call: 'raft_transferLeadership',
+                       params: 1
+               }),
+               new web3._extend.Method({
+                       name: 'startMaintenance',
+                       call: 'raft_startMaintenance',
+                       params: 0
+               }),
+               new web3._extend.Method({
+                       name: 'stopMaintenance',
+                       call: 'raft_stopMaintenance',
+                       params: 0
+               }),
                new web3._extend.Method({
                        name: 'removePeer',
                        call: 'raft_removePeer',
@@ -907,6 +965,19 @@ web3._extend({
                        name: 'cluster',
                        getter: 'raft_cluster'
                }),
+               new web3._extend.Property({
+                       name: 'health',
+                       getter: 'raft_health'
+               }),
+               new web3._extend.Property({
+                       name: 'mintingPolicy',
+                       getter: 'raft_mintingPolicy'
+               }),
+               new web3._extend.Method({
+                       name: 'setMintingPolicy',
+                       call: 'raft_setMintingPolicy',
+                       params: 1
+               }),
        ]
 })
 `
@@ -1229,6 +1300,12 @@ web3._extend({
 			params: 4,
 			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null, web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputTransactionFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'extendContracts',
+			call: 'quorumExtension_extendContracts',
+			params: 4,
+			inputFormatter: [null, null, web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputTransactionFormatter]
+		}),
 		new web3._extend.Method({
 			name: 'cancelExtension',
 			call: 'quorumExtension_cancelExtension',
@@ -1246,6 +1323,12 @@ web3._extend({
 			params: 1,
 			inputFormatter: [web3._extend.formatters.inputAddressFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'extensionStatus',
+			call: 'quorumExtension_extensionStatus',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter]
+		}),
 
 	],
 	properties:
@@ -1258,6 +1341,21 @@ web3._extend({
 });
 `
 
+const Privacy_JS = `
+web3._extend({
+	property: 'privacy',
+	methods:
+	[
+		new web3._extend.Method({
+			name: 'verifyPrivateState',
+			call: 'privacy_verifyPrivateState',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, null, null]
+		}),
+	]
+});
+`
+
 const Account_Plugin_Js = `
 web3._extend({
 	property: 'plugin_account',
@@ -28,6 +28,8 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/e2c"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/forkid"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -338,6 +340,7 @@ type serverPeer struct {
 	// Advertised checkpoint fields
 	checkpointNumber uint64                   // The block height which the checkpoint is registered.
 	checkpoint       params.TrustedCheckpoint // The advertised checkpoint sent by server.
+	e2cCheckpoint    *e2c.Checkpoint          // The checkpoint signed by e2c validators sent by server.
 
 	fcServer         *flowcontrol.ServerNode // Client side mirror token bucket.
 	vtLock           sync.Mutex
@@ -642,6 +645,7 @@ func (p *serverPeer) Handshake(genesis common.Hash, forkid forkid.ID, forkFilter
 
 		recv.get("checkpoint/value", &p.checkpoint)
 		recv.get("checkpoint/registerHeight", &p.checkpointNumber)
+		recv.get("e2c/checkpoint", &p.e2cCheckpoint)
 
 		if !p.onlyAnnounce {
 			for msgCode := range reqAvgTimeCost {
@@ -994,6 +998,13 @@ func (p *clientPeer) Handshake(td *big.Int, head common.Hash, headNum uint64, ge
 		p.fcCosts = costList.decode(ProtocolLengths[uint(p.version)])
 		p.fcParams = server.defParams
 
+		// Hand over the e2c checkpoint, so the client can check the headers
+		// it gets are the ones the validators committed to.
+		if engine, ok := server.handler.blockchain.Engine().(consensus.E2C); ok {
+			if cp := engine.Checkpoint(); cp != nil {
+				*lists = (*lists).add("e2c/checkpoint", cp)
+			}
+		}
 		// Add advertised checkpoint and register block height which
 		// client can verify the checkpoint validity.
 		if server.oracle != nil && server.oracle.IsRunning() {
@@ -169,6 +169,11 @@ func (h *clientHandler) synchronise(peer *serverPeer) {
 		//
 		// For the clique consensus engine, the start header is the block header
 		// of the latest epoch covered by checkpoint.
+		//
+		// For the e2c consensus engine, the start header is the block header
+		// of the checkpoint. The validator set is followed through the headers
+		// from the genesis block, or taken from the checkpoint signed by f+1
+		// validators that the servers hand over in the handshake.
 		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 		defer cancel()
 		if !checkpoint.Empty() && !h.backend.blockchain.SyncCheckpoint(ctx, checkpoint) {
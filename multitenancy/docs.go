@@ -12,4 +12,8 @@
 // * Specific:
 //   `psi://MY_PSI?node.eoa=0xdf08aad9d60f2227fdaed44dffd22753faf3d676`
 //   `psi://MY_PSI?self.eoa=0x1234aad9d60f2227fdaed44dffd22753faf3d676`
+//
+// A scope can also grant the quota of a client, which takes precedence over the
+// quota policy file of the node. Limits not given are not enforced:
+//   `quota://MY_CLIENT?rps=10&burst=20&subscriptions=5&logs.range=1000&call.gas=50000000`
 package multitenancy
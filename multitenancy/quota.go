@@ -0,0 +1,134 @@
+package multitenancy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strconv"
+
+	"github.com/jpmorganchase/quorum-security-plugin-sdk-go/proto"
+)
+
+const (
+	// SchemeQuota represents an URL scheme for the granted quota of a client,
+	// e.g. quota://client1?rps=10&burst=20&subscriptions=5&logs.range=1000&call.gas=50000000
+	SchemeQuota = "quota"
+	// QueryRequestsPerSecond query parameter captures the requests per second a tenant can make
+	QueryRequestsPerSecond = "rps"
+	// QueryBurst query parameter captures the requests a tenant can make at once
+	QueryBurst = "burst"
+	// QuerySubscriptions query parameter captures the concurrent subscriptions a tenant can have
+	QuerySubscriptions = "subscriptions"
+	// QueryLogsBlockRange query parameter captures the blocks an eth_getLogs call can span
+	QueryLogsBlockRange = "logs.range"
+	// QueryCallGas query parameter captures the gas an eth_call can use
+	QueryCallGas = "call.gas"
+)
+
+var ErrQuotaFoundMultiple = errors.New("found multiple granted quotas")
+
+// Quota limits the use a tenant makes of the RPC APIs of a multitenant node.
+// A zero limit means no limit.
+type Quota struct {
+	RequestsPerSecond float64 `json:"requestsPerSecond,omitempty"`
+	// requests that can be made at once, RequestsPerSecond if not set
+	Burst             int    `json:"burst,omitempty"`
+	MaxSubscriptions  int    `json:"maxSubscriptions,omitempty"`
+	MaxLogsBlockRange uint64 `json:"maxLogsBlockRange,omitempty"`
+	MaxCallGas        uint64 `json:"maxCallGas,omitempty"`
+}
+
+// QuotaPolicy holds the quotas of the tenants, identified by their PSI or by
+// the client ID of their granted quota, for a node
+type QuotaPolicy struct {
+	// applies to the tenants without a quota of their own
+	Default *Quota            `json:"default,omitempty"`
+	Tenants map[string]*Quota `json:"tenants,omitempty"`
+}
+
+// LoadQuotaPolicy reads a JSON quota policy file
+func LoadQuotaPolicy(path string) (*QuotaPolicy, error) {
+	blob, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	policy := new(QuotaPolicy)
+	if err := json.Unmarshal(blob, policy); err != nil {
+		return nil, fmt.Errorf("invalid quota policy %s: %v", path, err)
+	}
+	return policy, nil
+}
+
+// Quota returns the quota of the tenant, nil if it has none
+func (p *QuotaPolicy) Quota(tenant string) *Quota {
+	if p == nil {
+		return nil
+	}
+	if quota, ok := p.Tenants[tenant]; ok {
+		return quota
+	}
+	return p.Default
+}
+
+// ExtractQuota returns the client ID and the quota granted in the token, if any.
+// If there are multiple, return error
+func ExtractQuota(authToken *proto.PreAuthenticatedAuthenticationToken) (string, *Quota, error) {
+	var (
+		clientID string
+		quota    *Quota
+	)
+	for _, granted := range authToken.GetAuthorities() {
+		grantedValue, err := url.Parse(granted.GetRaw())
+		if err != nil || grantedValue.Scheme != SchemeQuota {
+			continue
+		}
+		if quota != nil {
+			return "", nil, ErrQuotaFoundMultiple
+		}
+		if quota, err = parseQuota(grantedValue.Query()); err != nil {
+			return "", nil, err
+		}
+		clientID = grantedValue.Host
+	}
+	return clientID, quota, nil
+}
+
+func parseQuota(query url.Values) (*Quota, error) {
+	var (
+		quota = new(Quota)
+		err   error
+	)
+	parse := func(name string, parseValue func(value string) error) {
+		if value := query.Get(name); value != "" && err == nil {
+			if parseErr := parseValue(value); parseErr != nil {
+				err = fmt.Errorf("invalid granted quota %s=%s", name, value)
+			}
+		}
+	}
+	parse(QueryRequestsPerSecond, func(value string) (err error) {
+		quota.RequestsPerSecond, err = strconv.ParseFloat(value, 64)
+		return
+	})
+	parse(QueryBurst, func(value string) (err error) {
+		quota.Burst, err = strconv.Atoi(value)
+		return
+	})
+	parse(QuerySubscriptions, func(value string) (err error) {
+		quota.MaxSubscriptions, err = strconv.Atoi(value)
+		return
+	})
+	parse(QueryLogsBlockRange, func(value string) (err error) {
+		quota.MaxLogsBlockRange, err = strconv.ParseUint(value, 10, 64)
+		return
+	})
+	parse(QueryCallGas, func(value string) (err error) {
+		quota.MaxCallGas, err = strconv.ParseUint(value, 10, 64)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return quota, nil
+}
@@ -0,0 +1,74 @@
+package multitenancy
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jpmorganchase/quorum-security-plugin-sdk-go/proto"
+	testifyassert "github.com/stretchr/testify/assert"
+)
+
+func TestExtractQuota_whenTypical(t *testing.T) {
+	assert := testifyassert.New(t)
+
+	clientID, quota, err := ExtractQuota(&proto.PreAuthenticatedAuthenticationToken{
+		Authorities: []*proto.GrantedAuthority{
+			{Raw: "psi://PS1?node.eoa=0x0"},
+			{Raw: "quota://client1?rps=2.5&burst=5&subscriptions=3&logs.range=1000&call.gas=50000000"},
+		},
+	})
+
+	assert.NoError(err)
+	assert.Equal("client1", clientID)
+	assert.Equal(&Quota{RequestsPerSecond: 2.5, Burst: 5, MaxSubscriptions: 3, MaxLogsBlockRange: 1000, MaxCallGas: 50000000}, quota)
+}
+
+func TestExtractQuota_whenNone(t *testing.T) {
+	assert := testifyassert.New(t)
+
+	clientID, quota, err := ExtractQuota(&proto.PreAuthenticatedAuthenticationToken{
+		Authorities: []*proto.GrantedAuthority{{Raw: "psi://PS1?node.eoa=0x0"}},
+	})
+
+	assert.NoError(err)
+	assert.Empty(clientID)
+	assert.Nil(quota)
+}
+
+func TestExtractQuota_whenInvalid(t *testing.T) {
+	assert := testifyassert.New(t)
+
+	_, _, err := ExtractQuota(&proto.PreAuthenticatedAuthenticationToken{
+		Authorities: []*proto.GrantedAuthority{{Raw: "quota://client1?logs.range=-1"}},
+	})
+
+	assert.Error(err)
+}
+
+func TestExtractQuota_whenMultiple(t *testing.T) {
+	assert := testifyassert.New(t)
+
+	_, _, err := ExtractQuota(&proto.PreAuthenticatedAuthenticationToken{
+		Authorities: []*proto.GrantedAuthority{{Raw: "quota://client1?rps=1"}, {Raw: "quota://client1?rps=2"}},
+	})
+
+	assert.Equal(ErrQuotaFoundMultiple, err)
+}
+
+func TestLoadQuotaPolicy_whenTypical(t *testing.T) {
+	assert := testifyassert.New(t)
+	dir, err := ioutil.TempDir("", "quota")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "quotas.json")
+	assert.NoError(ioutil.WriteFile(path, []byte(`{"default": {"requestsPerSecond": 10}, "tenants": {"PS1": {"maxCallGas": 1000}}}`), 0600))
+
+	policy, err := LoadQuotaPolicy(path)
+
+	assert.NoError(err)
+	assert.Equal(&Quota{MaxCallGas: 1000}, policy.Quota("PS1"))
+	assert.Equal(&Quota{RequestsPerSecond: 10}, policy.Quota("PS2"))
+	assert.Nil((*QuotaPolicy)(nil).Quota("PS1"))
+}
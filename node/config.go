@@ -193,6 +193,8 @@ type Config struct {
 	// Quorum: EnableNodePermission comes from EnableNodePermissionFlag --permissioned.
 	EnableNodePermission bool `toml:",omitempty"`
 	EnableMultitenancy   bool `toml:",omitempty"` // comes from MultitenancyFlag flag
+	// Quorum: MultitenancyQuotas is the quota policy file of the tenants, comes from MultitenancyQuotasFlag flag
+	MultitenancyQuotas string `toml:",omitempty"`
 }
 
 // IPCEndpoint resolves an IPC endpoint based on a configured value, taking into
@@ -33,6 +33,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/multitenancy"
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/plugin"
 	"github.com/ethereum/go-ethereum/plugin/security"
@@ -103,6 +104,21 @@ func New(conf *Config) (*Node, error) {
 		return nil, errors.New(`Config.Name cannot end in ".ipc"`)
 	}
 
+	// Quorum
+	// the quotas of the tenants are shared by the HTTP and WebSocket servers
+	var tenantQuotas *rpc.TenantQuotas
+	if conf.EnableMultitenancy {
+		var policy *multitenancy.QuotaPolicy
+		if conf.MultitenancyQuotas != "" {
+			var err error
+			if policy, err = multitenancy.LoadQuotaPolicy(conf.MultitenancyQuotas); err != nil {
+				return nil, err
+			}
+		}
+		tenantQuotas = rpc.NewTenantQuotas(policy)
+	}
+	// End Quorum
+
 	node := &Node{
 		config:        conf,
 		inprocHandler: rpc.NewProtectedServer(nil, conf.EnableMultitenancy),
@@ -150,8 +166,8 @@ func New(conf *Config) (*Node, error) {
 	// End Quorum
 
 	// Configure RPC servers.
-	node.http = newHTTPServer(node.log, conf.HTTPTimeouts).withMultitenancy(node.config.EnableMultitenancy)
-	node.ws = newHTTPServer(node.log, rpc.DefaultHTTPTimeouts).withMultitenancy(node.config.EnableMultitenancy)
+	node.http = newHTTPServer(node.log, conf.HTTPTimeouts).withMultitenancy(node.config.EnableMultitenancy).withTenantQuotas(tenantQuotas)
+	node.ws = newHTTPServer(node.log, rpc.DefaultHTTPTimeouts).withMultitenancy(node.config.EnableMultitenancy).withTenantQuotas(tenantQuotas)
 	node.ipc = newIPCServer(node.log, conf.IPCEndpoint()).withMultitenancy(node.config.EnableMultitenancy)
 
 	return node, nil
@@ -80,6 +80,8 @@ type httpServer struct {
 	// Quorum
 	// isMultitenant determines if the server supports mutlitenancy
 	isMultitenant bool
+	// tenantQuotas enforces the quotas of the tenants when multitenancy is supported
+	tenantQuotas *rpc.TenantQuotas
 }
 
 func newHTTPServer(log log.Logger, timeouts rpc.HTTPTimeouts) *httpServer {
@@ -96,6 +98,13 @@ func (h *httpServer) withMultitenancy(b bool) *httpServer {
 	return h
 }
 
+// Quorum
+// withTenantQuotas sets the quotas of the tenants this server enforces
+func (h *httpServer) withTenantQuotas(quotas *rpc.TenantQuotas) *httpServer {
+	h.tenantQuotas = quotas
+	return h
+}
+
 // setListenAddr configures the listening address of the server.
 // The address can only be set while the server isn't running.
 func (h *httpServer) setListenAddr(host string, port int) error {
@@ -251,6 +260,7 @@ func (h *httpServer) enableRPC(apis []rpc.API, config httpConfig, authManager se
 
 	// Create RPC server and handler.
 	srv := rpc.NewProtectedServer(authManager, h.isMultitenant)
+	srv.SetTenantQuotas(h.tenantQuotas)
 	if err := RegisterApisFromWhitelist(apis, config.Modules, srv, false); err != nil {
 		return err
 	}
@@ -284,6 +294,7 @@ func (h *httpServer) enableWS(apis []rpc.API, config wsConfig, authManager secur
 
 	// Create RPC server and handler.
 	srv := rpc.NewProtectedServer(authManager, h.isMultitenant)
+	srv.SetTenantQuotas(h.tenantQuotas)
 	if err := RegisterApisFromWhitelist(apis, config.Modules, srv, false); err != nil {
 		return err
 	}
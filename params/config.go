@@ -397,6 +397,9 @@ type E2CConfig struct {
 	Period    uint64        `json:"period"`
 	Delta     time.Duration `json:"delta"`
 	BlockSize uint64        `json:"blockSize"`
+
+	CheckpointInterval uint64   `json:"checkpointInterval,omitempty"` // Number of blocks between signed checkpoints
+	ExtraV2Block       *big.Int `json:"extraV2Block,omitempty"`       // Fork block from which headers record the view and view changes
 }
 
 // String implements the stringer interface, returning the consensus engine details.
@@ -777,6 +780,9 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int, isQuor
 	if c.Istanbul != nil && newcfg.Istanbul != nil && isForkIncompatible(c.Istanbul.Ceil2Nby3Block, newcfg.Istanbul.Ceil2Nby3Block, head) {
 		return newCompatError("Ceil 2N/3 fork block", c.Istanbul.Ceil2Nby3Block, newcfg.Istanbul.Ceil2Nby3Block)
 	}
+	if c.E2C != nil && newcfg.E2C != nil && isForkIncompatible(c.E2C.ExtraV2Block, newcfg.E2C.ExtraV2Block, head) {
+		return newCompatError("E2C extra-data v2 fork block", c.E2C.ExtraV2Block, newcfg.E2C.ExtraV2Block)
+	}
 	if c.Istanbul != nil && newcfg.Istanbul != nil && isForkIncompatible(c.Istanbul.TestQBFTBlock, newcfg.Istanbul.TestQBFTBlock, head) {
 		return newCompatError("Test QBFT fork block", c.Istanbul.TestQBFTBlock, newcfg.Istanbul.TestQBFTBlock)
 	}
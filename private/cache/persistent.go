@@ -0,0 +1,313 @@
+package cache
+
+import (
+	"container/list"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/leveldb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/private/engine"
+	"github.com/ethereum/go-ethereum/rlp"
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+var (
+	hitMeter      = metrics.NewRegisteredMeter("private/cache/hit", nil)
+	missMeter     = metrics.NewRegisteredMeter("private/cache/miss", nil)
+	evictionMeter = metrics.NewRegisteredMeter("private/cache/evictions", nil)
+	sizeGauge     = metrics.NewRegisteredGauge("private/cache/size", nil)
+)
+
+// PayloadKey is the key of the payload of a private transaction
+func PayloadKey(hash common.EncryptedPayloadHash) []byte {
+	return append([]byte("p"), hash.Bytes()...)
+}
+
+// RawPayloadKey is the key of the payload of a raw private transaction, before it's sent
+func RawPayloadKey(hash common.EncryptedPayloadHash) []byte {
+	return append([]byte("r"), hash.Bytes()...)
+}
+
+var errCacheDecryptionFailed = errors.New("unable to decrypt cached private payload")
+
+// Persistent is a disk backed cache of the payloads received from the private
+// transaction manager, so they survive restarts. It holds up to maxSize bytes and
+// evicts the least recently used payloads first. Only insertions are persisted, so
+// after a restart the payloads are evicted in the order they were cached.
+//
+// The payloads are decrypted, so the cache seals them with a node-local key
+// before writing them to disk.
+type Persistent struct {
+	db      ethdb.KeyValueStore
+	key     [32]byte // secretbox key the stored payloads are sealed with
+	maxSize uint64
+	size    uint64
+	seq     uint64                   // insertion counter, orders the entries after a restart
+	lru     *list.List               // of *persistentEntry, most recently used first
+	entries map[string]*list.Element // key -> element of lru
+	closed  bool
+	lock    sync.Mutex
+}
+
+type persistentEntry struct {
+	key  string
+	size uint64
+}
+
+// persistentItem is how a PrivateCacheItem is stored, Sealed is the secretbox of
+// its persistentPayload
+type persistentItem struct {
+	Seq    uint64
+	Nonce  []byte
+	Sealed []byte
+}
+
+type persistentPayload struct {
+	Payload             []byte
+	ACHashes            []common.EncryptedPayloadHash
+	ACMerkleRoot        common.Hash
+	PrivacyFlag         uint64
+	ManagedParties      []string
+	Sender              string
+	MandatoryRecipients []string
+}
+
+// NewPersistent opens the cache in dir, holding up to maxSize bytes sealed with key
+func NewPersistent(dir string, maxSize uint64, key [32]byte) (*Persistent, error) {
+	db, err := leveldb.New(dir, 16, 16, "private/cache/db/")
+	if err != nil {
+		return nil, fmt.Errorf("unable to open private payload cache %s: %v", dir, err)
+	}
+	c, err := newPersistent(db, maxSize, key)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	log.Info("Opened persistent private payload cache", "dir", dir, "payloads", len(c.entries), "size", common.StorageSize(c.size), "max", common.StorageSize(maxSize))
+	return c, nil
+}
+
+// newPersistent loads the index of the entries in db
+func newPersistent(db ethdb.KeyValueStore, maxSize uint64, key [32]byte) (*Persistent, error) {
+	c := &Persistent{
+		db:      db,
+		key:     key,
+		maxSize: maxSize,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+	}
+	type stored struct {
+		entry *persistentEntry
+		seq   uint64
+	}
+	var all []stored
+	it := db.NewIterator(nil, nil)
+	for it.Next() {
+		var item persistentItem
+		if err := rlp.DecodeBytes(it.Value(), &item); err != nil {
+			it.Release()
+			return nil, fmt.Errorf("invalid private payload cache entry %x: %v", it.Key(), err)
+		}
+		all = append(all, stored{
+			entry: &persistentEntry{key: string(it.Key()), size: uint64(len(it.Key()) + len(it.Value()))},
+			seq:   item.Seq,
+		})
+	}
+	it.Release()
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].seq < all[j].seq })
+	for _, s := range all {
+		c.entries[s.entry.key] = c.lru.PushFront(s.entry)
+		c.size += s.entry.size
+		c.seq = s.seq + 1
+	}
+	// the bound may have been lowered since the last run
+	c.evict()
+	sizeGauge.Update(int64(c.size))
+	return c, nil
+}
+
+// Get returns the cached item, marking it as recently used
+func (c *Persistent) Get(key []byte) (PrivateCacheItem, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	elem, ok := c.entries[string(key)]
+	if !ok || c.closed {
+		missMeter.Mark(1)
+		return PrivateCacheItem{}, false
+	}
+	enc, err := c.db.Get(key)
+	if err != nil {
+		log.Warn("Failed to read cached private payload", "key", fmt.Sprintf("%x", key), "err", err)
+		missMeter.Mark(1)
+		return PrivateCacheItem{}, false
+	}
+	item, err := c.open(enc)
+	if err != nil {
+		log.Warn("Invalid cached private payload", "key", fmt.Sprintf("%x", key), "err", err)
+		missMeter.Mark(1)
+		return PrivateCacheItem{}, false
+	}
+	c.lru.MoveToFront(elem)
+	hitMeter.Mark(1)
+
+	extra := engine.ExtraMetadata{
+		ACHashes:            common.EncryptedPayloadHashes{},
+		ACMerkleRoot:        item.ACMerkleRoot,
+		PrivacyFlag:         engine.PrivacyFlagType(item.PrivacyFlag),
+		ManagedParties:      item.ManagedParties,
+		Sender:              item.Sender,
+		MandatoryRecipients: item.MandatoryRecipients,
+	}
+	for _, hash := range item.ACHashes {
+		extra.ACHashes.Add(hash)
+	}
+	return PrivateCacheItem{Payload: item.Payload, Extra: extra}, true
+}
+
+// Set caches the item, evicting the least recently used ones if the cache is full
+func (c *Persistent) Set(key []byte, item PrivateCacheItem) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return
+	}
+	stored := persistentPayload{
+		Payload:             item.Payload,
+		ACHashes:            make([]common.EncryptedPayloadHash, 0, len(item.Extra.ACHashes)),
+		ACMerkleRoot:        item.Extra.ACMerkleRoot,
+		PrivacyFlag:         uint64(item.Extra.PrivacyFlag),
+		ManagedParties:      item.Extra.ManagedParties,
+		Sender:              item.Extra.Sender,
+		MandatoryRecipients: item.Extra.MandatoryRecipients,
+	}
+	for hash := range item.Extra.ACHashes {
+		stored.ACHashes = append(stored.ACHashes, hash)
+	}
+	enc, err := c.seal(c.seq, &stored)
+	if err != nil {
+		log.Warn("Failed to encode private payload for the cache", "err", err)
+		return
+	}
+	size := uint64(len(key) + len(enc))
+	if size > c.maxSize {
+		return
+	}
+	if err := c.db.Put(key, enc); err != nil {
+		log.Warn("Failed to cache private payload", "key", fmt.Sprintf("%x", key), "err", err)
+		return
+	}
+	c.seq++
+	if elem, ok := c.entries[string(key)]; ok {
+		entry := elem.Value.(*persistentEntry)
+		c.size -= entry.size
+		entry.size = size
+		c.lru.MoveToFront(elem)
+	} else {
+		c.entries[string(key)] = c.lru.PushFront(&persistentEntry{key: string(key), size: size})
+	}
+	c.size += size
+	c.evict()
+	sizeGauge.Update(int64(c.size))
+}
+
+// seal encodes the payload and seals it with the key of the cache
+func (c *Persistent) seal(seq uint64, payload *persistentPayload) ([]byte, error) {
+	enc, err := rlp.EncodeToBytes(payload)
+	if err != nil {
+		return nil, err
+	}
+	var nonce [24]byte
+	if _, err := io.ReadFull(rand.Reader, nonce[:]); err != nil {
+		return nil, err
+	}
+	return rlp.EncodeToBytes(&persistentItem{
+		Seq:    seq,
+		Nonce:  nonce[:],
+		Sealed: secretbox.Seal(nil, enc, &nonce, &c.key),
+	})
+}
+
+// open decrypts and decodes a stored entry
+func (c *Persistent) open(enc []byte) (*persistentPayload, error) {
+	var item persistentItem
+	if err := rlp.DecodeBytes(enc, &item); err != nil {
+		return nil, err
+	}
+	if len(item.Nonce) != 24 {
+		return nil, errCacheDecryptionFailed
+	}
+	var nonce [24]byte
+	copy(nonce[:], item.Nonce)
+	opened, ok := secretbox.Open(nil, item.Sealed, &nonce, &c.key)
+	if !ok {
+		return nil, errCacheDecryptionFailed
+	}
+	payload := new(persistentPayload)
+	if err := rlp.DecodeBytes(opened, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// evict removes the least recently used entries until the cache fits. Assumes
+// lock is held.
+func (c *Persistent) evict() {
+	for c.size > c.maxSize {
+		elem := c.lru.Back()
+		entry := elem.Value.(*persistentEntry)
+		if err := c.db.Delete([]byte(entry.key)); err != nil {
+			log.Warn("Failed to evict cached private payload", "key", fmt.Sprintf("%x", entry.key), "err", err)
+			return
+		}
+		c.lru.Remove(elem)
+		delete(c.entries, entry.key)
+		c.size -= entry.size
+		evictionMeter.Mark(1)
+	}
+}
+
+// Len returns the number of cached payloads
+func (c *Persistent) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return len(c.entries)
+}
+
+// Closed reports whether the cache was closed
+func (c *Persistent) Closed() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.closed
+}
+
+// Start implements node.Lifecycle, the cache is open already
+func (c *Persistent) Start() error {
+	return nil
+}
+
+// Stop implements node.Lifecycle, closing the cache
+func (c *Persistent) Stop() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	return c.db.Close()
+}
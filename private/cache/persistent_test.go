@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/private/engine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testKey = [32]byte{1}
+
+func testItem(size int) PrivateCacheItem {
+	return PrivateCacheItem{
+		Payload: make([]byte, size),
+		Extra: engine.ExtraMetadata{
+			ACHashes:     common.EncryptedPayloadHashes{},
+			PrivacyFlag:  engine.PrivacyFlagStateValidation,
+			ACMerkleRoot: common.HexToHash("0x1"),
+			Sender:       "sender",
+		},
+	}
+}
+
+func TestPersistent_EvictsLeastRecentlyUsed(t *testing.T) {
+	c, err := newPersistent(memorydb.New(), 1024, testKey)
+	require.NoError(t, err)
+	c.Set([]byte{1}, testItem(100))
+	// room for three entries
+	c.maxSize = 3*c.size + c.size/2
+
+	for i := byte(2); i <= 3; i++ {
+		c.Set([]byte{i}, testItem(100))
+	}
+	// 1 is used, so 2 is evicted by 4
+	_, found := c.Get([]byte{1})
+	assert.True(t, found)
+	c.Set([]byte{4}, testItem(100))
+
+	_, found = c.Get([]byte{2})
+	assert.False(t, found)
+	for _, key := range []byte{1, 3, 4} {
+		_, found = c.Get([]byte{key})
+		assert.True(t, found, "key %d", key)
+	}
+	assert.Equal(t, 3, c.Len())
+}
+
+func TestPersistent_ReopenKeepsPayloadsAndOrder(t *testing.T) {
+	db := memorydb.New()
+	c, err := newPersistent(db, 1024, testKey)
+	require.NoError(t, err)
+	c.Set([]byte{2}, testItem(100))
+	entrySize := c.size
+
+	item := testItem(100)
+	hash := common.BytesToEncryptedPayloadHash([]byte("ac"))
+	item.Extra.ACHashes.Add(hash)
+	item.Extra.ManagedParties = []string{"party"}
+	item.Extra.MandatoryRecipients = []string{"recipient"}
+	c.Set([]byte{1}, item)
+	c.Set([]byte{3}, testItem(100))
+	total := c.size
+
+	// a smaller bound evicts the oldest entry on load
+	c, err = newPersistent(db, total-entrySize/2, testKey)
+	require.NoError(t, err)
+	assert.Equal(t, 2, c.Len())
+	_, found := c.Get([]byte{2})
+	assert.False(t, found)
+
+	c, err = newPersistent(db, 1024, testKey)
+	require.NoError(t, err)
+	c.Set([]byte{1}, item)
+	c, err = newPersistent(db, 1024, testKey)
+	require.NoError(t, err)
+
+	cached, found := c.Get([]byte{1})
+	require.True(t, found)
+	assert.Equal(t, item.Payload, cached.Payload)
+	assert.Equal(t, item.Extra.PrivacyFlag, cached.Extra.PrivacyFlag)
+	assert.Equal(t, item.Extra.ACMerkleRoot, cached.Extra.ACMerkleRoot)
+	assert.True(t, cached.Extra.ACHashes.NotExist(common.EncryptedPayloadHash{}))
+	assert.False(t, cached.Extra.ACHashes.NotExist(hash))
+	assert.Equal(t, item.Extra.ManagedParties, cached.Extra.ManagedParties)
+	assert.Equal(t, item.Extra.MandatoryRecipients, cached.Extra.MandatoryRecipients)
+	assert.Equal(t, "sender", cached.Extra.Sender)
+}
+
+func TestPersistent_SealsPayloads(t *testing.T) {
+	db := memorydb.New()
+	c, err := newPersistent(db, 1024, testKey)
+	require.NoError(t, err)
+	item := testItem(0)
+	item.Payload = []byte("private payload")
+	c.Set([]byte{1}, item)
+
+	enc, err := db.Get([]byte{1})
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(enc, item.Payload), "payload stored in plaintext")
+
+	// another key can't read the entries
+	c, err = newPersistent(db, 1024, [32]byte{2})
+	require.NoError(t, err)
+	_, found := c.Get([]byte{1})
+	assert.False(t, found)
+
+	c, err = newPersistent(db, 1024, testKey)
+	require.NoError(t, err)
+	cached, found := c.Get([]byte{1})
+	require.True(t, found)
+	assert.Equal(t, item.Payload, cached.Payload)
+}
@@ -0,0 +1,97 @@
+package private
+
+import (
+	"errors"
+	"io"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/private/cache"
+	"github.com/ethereum/go-ethereum/private/engine"
+)
+
+// cachedPrivateTransactionManager serves the payloads it already received from a
+// persistent cache, so restarts and reorgs don't fetch them again
+type cachedPrivateTransactionManager struct {
+	PrivateTransactionManager
+	cache *cache.Persistent
+}
+
+// WithPersistentCache puts the cache in front of the Receive and ReceiveRaw calls of the
+// private transaction manager. Only payloads we are a party to are cached.
+func WithPersistentCache(ptm PrivateTransactionManager, c *cache.Persistent) PrivateTransactionManager {
+	return &cachedPrivateTransactionManager{PrivateTransactionManager: ptm, cache: c}
+}
+
+func (ptm *cachedPrivateTransactionManager) Receive(hash common.EncryptedPayloadHash) (string, []string, []byte, *engine.ExtraMetadata, error) {
+	if common.EmptyEncryptedPayloadHash(hash) {
+		return ptm.PrivateTransactionManager.Receive(hash)
+	}
+	key := cache.PayloadKey(hash)
+	if item, found := ptm.cache.Get(key); found {
+		return item.Extra.Sender, item.Extra.ManagedParties, item.Payload, &item.Extra, nil
+	}
+	sender, managedParties, data, extra, err := ptm.PrivateTransactionManager.Receive(hash)
+	if err == nil && data != nil && extra != nil {
+		item := cache.PrivateCacheItem{Payload: data, Extra: *extra}
+		item.Extra.Sender, item.Extra.ManagedParties = sender, managedParties
+		ptm.cache.Set(key, item)
+	}
+	return sender, managedParties, data, extra, err
+}
+
+func (ptm *cachedPrivateTransactionManager) ReceiveRaw(hash common.EncryptedPayloadHash) ([]byte, string, *engine.ExtraMetadata, error) {
+	key := cache.RawPayloadKey(hash)
+	if item, found := ptm.cache.Get(key); found {
+		return item.Payload, item.Extra.Sender, &item.Extra, nil
+	}
+	data, sender, extra, err := ptm.PrivateTransactionManager.ReceiveRaw(hash)
+	if err == nil && data != nil && extra != nil {
+		item := cache.PrivateCacheItem{Payload: data, Extra: *extra}
+		item.Extra.Sender = sender
+		ptm.cache.Set(key, item)
+	}
+	return data, sender, extra, err
+}
+
+// Health reports the endpoints of the underlying private transaction manager
+func (ptm *cachedPrivateTransactionManager) Health() []engine.EndpointHealth {
+	if reporter, ok := ptm.PrivateTransactionManager.(HealthReporter); ok {
+		return reporter.Health()
+	}
+	return nil
+}
+
+// Close closes the underlying private transaction manager, the cache is closed
+// with the node
+func (ptm *cachedPrivateTransactionManager) Close() error {
+	if closer, ok := ptm.PrivateTransactionManager.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// WarmCache receives the payloads, so the persistent cache holds them and keeps
+// them over older ones. It's meant for the private transactions of the latest
+// blocks, oldest first, and returns how many payloads are cached.
+func WarmCache(hashes []common.EncryptedPayloadHash) int {
+	ptm, ok := P.(*cachedPrivateTransactionManager)
+	if !ok {
+		return 0
+	}
+	cached := 0
+	for _, hash := range hashes {
+		if ptm.cache.Closed() {
+			break
+		}
+		_, _, data, _, err := ptm.Receive(hash)
+		if errors.Is(err, engine.ErrPrivateTxManagerUnavailable) {
+			log.Warn("Stopped warming the private payload cache", "err", err)
+			break
+		}
+		if data != nil {
+			cached++
+		}
+	}
+	return cached
+}
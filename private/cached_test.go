@@ -0,0 +1,45 @@
+package private
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/private/cache"
+	"github.com/ethereum/go-ethereum/private/engine"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachedPrivateTransactionManager_Receive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	c, err := cache.NewPersistent(t.TempDir(), 1024*1024, [32]byte{1})
+	require.NoError(t, err)
+	defer c.Stop()
+
+	hash := common.BytesToEncryptedPayloadHash([]byte("payload"))
+	unknown := common.BytesToEncryptedPayloadHash([]byte("unknown"))
+	extra := &engine.ExtraMetadata{ACHashes: common.EncryptedPayloadHashes{}, PrivacyFlag: engine.PrivacyFlagPartyProtection}
+
+	mock := NewMockPrivateTransactionManager(ctrl)
+	mock.EXPECT().Receive(hash).Return("sender", []string{"party"}, []byte("data"), extra, nil).Times(1)
+	mock.EXPECT().Receive(unknown).Return("", nil, nil, nil, nil).Times(2)
+
+	ptm := WithPersistentCache(mock, c)
+	for i := 0; i < 2; i++ {
+		sender, managedParties, data, cachedExtra, err := ptm.Receive(hash)
+		require.NoError(t, err)
+		assert.Equal(t, "sender", sender)
+		assert.Equal(t, []string{"party"}, managedParties)
+		assert.Equal(t, []byte("data"), data)
+		assert.Equal(t, engine.PrivacyFlagPartyProtection, cachedExtra.PrivacyFlag)
+
+		// payloads we aren't a party to aren't cached
+		_, _, data, _, err = ptm.Receive(unknown)
+		require.NoError(t, err)
+		assert.Nil(t, data)
+	}
+	assert.Equal(t, 1, c.Len())
+}
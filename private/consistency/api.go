@@ -0,0 +1,29 @@
+package consistency
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// PrivacyAPI checks the private states of the node with the other parties
+type PrivacyAPI struct {
+	checker *Checker
+}
+
+// NewPrivacyAPI creates the privacy API of the checker
+func NewPrivacyAPI(checker *Checker) *PrivacyAPI {
+	return &PrivacyAPI{checker: checker}
+}
+
+// VerifyPrivateState checks that the other parties to the contracts have the same
+// private state for them at the block. The participants are required for contracts
+// that aren't party protected.
+func (api *PrivacyAPI) VerifyPrivateState(ctx context.Context, blockNr rpc.BlockNumber, contracts []common.Address, participants *[]string) (*Report, error) {
+	var parties []string
+	if participants != nil {
+		parties = *participants
+	}
+	return api.checker.Verify(ctx, blockNr, contracts, parties)
+}
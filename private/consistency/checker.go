@@ -0,0 +1,524 @@
+// Package consistency checks that the parties to private contracts computed the
+// same private state for them.
+//
+// The node that runs a check computes a commitment to the private state of each
+// contract at a block and sends the commitments of the contracts a party is
+// involved in to that party through the private transaction manager, in a request
+// signed with its node key. The payload hash of the request is announced to the
+// peers over the pscheck protocol. The node of the party compares the commitments
+// with its own private state and answers the same way, through the private
+// transaction manager.
+//
+// A node only compares the contracts the party sending the request, as told by
+// the private transaction manager, takes part in. It reports all the others as
+// missing, whether it has them or not, so a check doesn't reveal which contracts
+// a party has to nodes that aren't participants. A participant does learn whether
+// the private state of a contract it shares with the party matches its own.
+package consistency
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/mps"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/private"
+	"github.com/ethereum/go-ethereum/private/engine"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// responseTimeout is how long a check waits for the parties to answer, unless
+// the context ends earlier
+const responseTimeout = 10 * time.Second
+
+var (
+	errNoContracts         = errors.New("no contracts to verify")
+	errUnknownBlock        = errors.New("unknown block")
+	errUnknownRecipient    = errors.New("request isn't addressed to a party of this node")
+	errInvalidSignature    = errors.New("invalid signature")
+	errUnknownParticipants = errors.New("participants unknown, the contract isn't party protected so they must be given")
+)
+
+// Status is the outcome of the check of a contract with a party
+type Status string
+
+const (
+	StatusConsistent Status = "consistent" // same private state
+	StatusDiverged   Status = "diverged"   // different private state
+	StatusMissing    Status = "missing"    // the party doesn't have the contract, or doesn't share it with us
+	StatusNoResponse Status = "noResponse" // the party didn't answer in time
+	StatusError      Status = "error"      // the party couldn't check the contract
+)
+
+// Backend is the part of the blockchain the checker reads the private states from
+type Backend interface {
+	CurrentBlock() *types.Block
+	GetBlockByHash(hash common.Hash) *types.Block
+	GetBlockByNumber(number uint64) *types.Block
+	StateAtPSI(root common.Hash, psi types.PrivateStateIdentifier) (*state.StateDB, *state.StateDB, error)
+	PrivateStateManager() mps.PrivateStateManager
+}
+
+// Report is the outcome of a consistency check
+type Report struct {
+	BlockNumber uint64            `json:"blockNumber"`
+	BlockHash   common.Hash       `json:"blockHash"`
+	Consistent  bool              `json:"consistent"` // all parties answered and agree on all contracts
+	Contracts   []*ContractReport `json:"contracts"`
+}
+
+// ContractReport is the outcome of the check of one contract
+type ContractReport struct {
+	Address    common.Address `json:"address"`
+	Commitment common.Hash    `json:"commitment"`
+	Consistent bool           `json:"consistent"`
+	Parties    []*PartyReport `json:"parties"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// PartyReport is the outcome of the check of a contract with one party
+type PartyReport struct {
+	Party  string `json:"party"`
+	Status Status `json:"status"`
+	Node   string `json:"node,omitempty"` // ID of the node that answered for the party
+	Error  string `json:"error,omitempty"`
+}
+
+// contractCommitment is the commitment to the private state of a contract
+type contractCommitment struct {
+	Address    common.Address
+	Commitment common.Hash
+}
+
+// request asks a party to compare its private state with the commitments
+type request struct {
+	ID        common.Hash
+	BlockHash common.Hash
+	Recipient string // the party the request is for
+	Contracts []contractCommitment
+	Sig       []byte
+}
+
+type contractResult struct {
+	Address common.Address
+	Status  Status
+}
+
+// response tells whether the private state of a party matches the commitments
+type response struct {
+	ID        common.Hash // of the request
+	Party     string
+	Contracts []contractResult
+	Error     string
+	Sig       []byte
+}
+
+// pendingRequest is a request sent to a party that didn't answer yet
+type pendingRequest struct {
+	party string
+	resp  chan *signedResponse
+}
+
+type signedResponse struct {
+	*response
+	node enode.ID
+}
+
+// Checker runs consistency checks and answers the ones of the other nodes
+type Checker struct {
+	backend Backend
+	ptm     private.PrivateTransactionManager
+	key     *ecdsa.PrivateKey // node key, signs requests and responses
+	workers chan struct{}     // bounds the requests of peers handled at once
+
+	peers   map[enode.ID]*peer
+	peersMu sync.RWMutex
+
+	pending   map[common.Hash]*pendingRequest
+	pendingMu sync.Mutex
+}
+
+// New creates a checker that reads the private states from the backend
+func New(backend Backend, ptm private.PrivateTransactionManager, key *ecdsa.PrivateKey) *Checker {
+	return &Checker{
+		backend: backend,
+		ptm:     ptm,
+		key:     key,
+		peers:   make(map[enode.ID]*peer),
+		pending: make(map[common.Hash]*pendingRequest),
+		workers: make(chan struct{}, maxWorkers),
+	}
+}
+
+// APIs returns the privacy RPC API
+func (c *Checker) APIs() []rpc.API {
+	return []rpc.API{{
+		Namespace: "privacy",
+		Version:   "1.0",
+		Service:   NewPrivacyAPI(c),
+		Public:    false,
+	}}
+}
+
+// Commitment commits to the private state of the contract at a block. Any change
+// to the code or the storage of the contract changes it.
+func Commitment(privateState *state.StateDB, address common.Address, blockHash common.Hash) (common.Hash, error) {
+	if !privateState.Exist(address) {
+		return common.Hash{}, fmt.Errorf("contract %s not found in the private state", address.Hex())
+	}
+	storageRoot, err := privateState.GetStorageRoot(address)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	enc, err := rlp.EncodeToBytes([]interface{}{address, blockHash, storageRoot, privateState.GetCodeHash(address)})
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(enc), nil
+}
+
+// Verify checks the private state of the contracts at the block with the other
+// parties. Without participants, they are those of the transaction that created
+// each contract, which only party protected contracts keep.
+func (c *Checker) Verify(ctx context.Context, blockNr rpc.BlockNumber, contracts []common.Address, participants []string) (*Report, error) {
+	if len(contracts) == 0 {
+		return nil, errNoContracts
+	}
+	block := c.blockByNumber(blockNr)
+	if block == nil {
+		return nil, errUnknownBlock
+	}
+	psm, err := c.backend.PrivateStateManager().ResolveForUserContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, privateState, err := c.backend.StateAtPSI(block.Root(), psm.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{BlockNumber: block.NumberU64(), BlockHash: block.Hash(), Consistent: true}
+	requests := make(map[string]*request) // by party
+	var parties []string                  // in the order of the contracts
+	for _, address := range contracts {
+		contract := &ContractReport{Address: address}
+		report.Contracts = append(report.Contracts, contract)
+
+		contract.Commitment, err = Commitment(privateState, address, block.Hash())
+		if err != nil {
+			contract.Error = err.Error()
+			continue
+		}
+		contractParties := participants
+		if len(contractParties) == 0 {
+			if contractParties, err = c.participants(privateState, address); err != nil {
+				contract.Error = err.Error()
+				continue
+			}
+		}
+		for _, party := range contractParties {
+			if !psm.NotIncludeAny(party) {
+				continue // ours
+			}
+			contract.Parties = append(contract.Parties, &PartyReport{Party: party, Status: StatusNoResponse})
+			req, ok := requests[party]
+			if !ok {
+				req = &request{BlockHash: block.Hash(), Recipient: party}
+				requests[party] = req
+				parties = append(parties, party)
+			}
+			req.Contracts = append(req.Contracts, contractCommitment{Address: address, Commitment: contract.Commitment})
+		}
+	}
+
+	from := ""
+	if len(psm.Addresses) > 0 {
+		from = psm.Addresses[0]
+	}
+	responses := make(chan *signedResponse, len(parties))
+	sent := 0
+	for _, party := range parties {
+		if err := c.send(requests[party], from, responses); err != nil {
+			log.Warn("Failed to send private state check", "party", party, "err", err)
+			report.setError(party, err)
+			continue
+		}
+		sent++
+	}
+	defer func() {
+		c.pendingMu.Lock()
+		for _, req := range requests {
+			delete(c.pending, req.ID)
+		}
+		c.pendingMu.Unlock()
+	}()
+
+	timeout := time.NewTimer(responseTimeout)
+	defer timeout.Stop()
+	for answered := 0; answered < sent; {
+		select {
+		case resp := <-responses:
+			report.add(resp)
+			answered++
+		case <-timeout.C:
+			answered = sent
+		case <-ctx.Done():
+			answered = sent
+		}
+	}
+	report.summarize()
+	return report, nil
+}
+
+// participants returns the other parties of a party protected contract
+func (c *Checker) participants(privateState *state.StateDB, address common.Address) ([]string, error) {
+	metadata, err := privateState.GetPrivacyMetadata(address)
+	if err != nil || metadata == nil {
+		return nil, errUnknownParticipants
+	}
+	participants, err := c.ptm.GetParticipants(metadata.CreationTxHash)
+	if err != nil {
+		return nil, err
+	}
+	// the parties of this node don't take part in the check
+	_, managedParties, _, _, err := c.ptm.Receive(metadata.CreationTxHash)
+	if err != nil {
+		return nil, err
+	}
+	others := make([]string, 0, len(participants))
+	for _, party := range participants {
+		if !contains(managedParties, party) {
+			others = append(others, party)
+		}
+	}
+	return others, nil
+}
+
+// send signs the request, sends it to its recipient through the private transaction
+// manager and announces it to the peers
+func (c *Checker) send(req *request, from string, responses chan *signedResponse) error {
+	id, err := randomID()
+	if err != nil {
+		return err
+	}
+	req.ID = id
+	if req.Sig, err = crypto.Sign(req.sigHash().Bytes(), c.key); err != nil {
+		return err
+	}
+	enc, err := rlp.EncodeToBytes(req)
+	if err != nil {
+		return err
+	}
+	c.pendingMu.Lock()
+	c.pending[req.ID] = &pendingRequest{party: req.Recipient, resp: responses}
+	c.pendingMu.Unlock()
+
+	_, _, hash, err := c.ptm.Send(enc, from, []string{req.Recipient}, &engine.ExtraMetadata{PrivacyFlag: engine.PrivacyFlagStandardPrivate})
+	if err != nil {
+		return err
+	}
+	c.announce(hash)
+	return nil
+}
+
+// answer checks the request of a peer against the private state of the party it's
+// addressed to. It returns nil for requests that aren't for this node.
+func (c *Checker) answer(hash common.EncryptedPayloadHash, from enode.ID) (*response, string, error) {
+	sender, managedParties, data, _, err := c.ptm.Receive(hash)
+	if err != nil || data == nil {
+		return nil, "", err
+	}
+	var req request
+	if err := rlp.DecodeBytes(data, &req); err != nil {
+		return nil, "", fmt.Errorf("invalid private state check: %v", err)
+	}
+	if !signedBy(req.sigHash(), req.Sig, from) {
+		return nil, "", errInvalidSignature
+	}
+	if len(managedParties) > 0 && !contains(managedParties, req.Recipient) {
+		return nil, "", errUnknownRecipient
+	}
+	resp := &response{ID: req.ID, Party: req.Recipient}
+	results, err := c.compare(&req, sender)
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	resp.Contracts = results
+	return resp, sender, nil
+}
+
+// compare compares the commitments with the private state of the recipient, for
+// the contracts the sender of the request takes part in
+func (c *Checker) compare(req *request, sender string) ([]contractResult, error) {
+	block := c.backend.GetBlockByHash(req.BlockHash)
+	if block == nil {
+		return nil, errUnknownBlock
+	}
+	psm, err := c.backend.PrivateStateManager().ResolveForManagedParty(req.Recipient)
+	if err != nil {
+		return nil, err
+	}
+	_, privateState, err := c.backend.StateAtPSI(block.Root(), psm.ID)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]contractResult, len(req.Contracts))
+	for i, contract := range req.Contracts {
+		results[i].Address = contract.Address
+		if !c.isParticipant(privateState, contract.Address, sender) {
+			results[i].Status = StatusMissing
+			continue
+		}
+		commitment, err := Commitment(privateState, contract.Address, req.BlockHash)
+		switch {
+		case err != nil:
+			results[i].Status = StatusMissing
+		case commitment == contract.Commitment:
+			results[i].Status = StatusConsistent
+		default:
+			results[i].Status = StatusDiverged
+		}
+	}
+	return results, nil
+}
+
+// isParticipant reports whether the party created the contract or is one of the
+// participants of its creation. Only party protected contracts keep track of
+// their creation, so the parties of the others are unknown.
+func (c *Checker) isParticipant(privateState *state.StateDB, address common.Address, party string) bool {
+	if party == "" || !privateState.Exist(address) {
+		return false
+	}
+	metadata, err := privateState.GetPrivacyMetadata(address)
+	if err != nil || metadata == nil {
+		return false
+	}
+	if creator, _, _, _, err := c.ptm.Receive(metadata.CreationTxHash); err == nil && creator == party {
+		return true
+	}
+	participants, err := c.ptm.GetParticipants(metadata.CreationTxHash)
+	return err == nil && contains(participants, party)
+}
+
+// deliver hands the response of a peer to the check waiting for it
+func (c *Checker) deliver(hash common.EncryptedPayloadHash, from enode.ID) error {
+	sender, _, data, _, err := c.ptm.Receive(hash)
+	if err != nil || data == nil {
+		return err
+	}
+	var resp response
+	if err := rlp.DecodeBytes(data, &resp); err != nil {
+		return fmt.Errorf("invalid private state check response: %v", err)
+	}
+	if !signedBy(resp.sigHash(), resp.Sig, from) {
+		return errInvalidSignature
+	}
+	c.pendingMu.Lock()
+	defer c.pendingMu.Unlock()
+
+	pending, ok := c.pending[resp.ID]
+	if !ok || pending.party != sender || pending.party != resp.Party {
+		return nil // late, or not from the party we asked
+	}
+	delete(c.pending, resp.ID)
+	pending.resp <- &signedResponse{response: &resp, node: from}
+	return nil
+}
+
+func (c *Checker) blockByNumber(blockNr rpc.BlockNumber) *types.Block {
+	if blockNr == rpc.LatestBlockNumber || blockNr == rpc.PendingBlockNumber {
+		return c.backend.CurrentBlock()
+	}
+	return c.backend.GetBlockByNumber(uint64(blockNr.Int64()))
+}
+
+func (req *request) sigHash() common.Hash {
+	enc, _ := rlp.EncodeToBytes([]interface{}{req.ID, req.BlockHash, req.Recipient, req.Contracts})
+	return crypto.Keccak256Hash(enc)
+}
+
+func (resp *response) sigHash() common.Hash {
+	enc, _ := rlp.EncodeToBytes([]interface{}{resp.ID, resp.Party, resp.Contracts, resp.Error})
+	return crypto.Keccak256Hash(enc)
+}
+
+// signedBy reports whether the hash was signed with the key of the node
+func signedBy(hash common.Hash, sig []byte, node enode.ID) bool {
+	pub, err := crypto.SigToPub(hash.Bytes(), sig)
+	return err == nil && enode.PubkeyToIDV4(pub) == node
+}
+
+// add records the response of a party
+func (r *Report) add(resp *signedResponse) {
+	status := make(map[common.Address]Status, len(resp.Contracts))
+	for _, result := range resp.Contracts {
+		status[result.Address] = result.Status
+	}
+	for _, contract := range r.Contracts {
+		for _, party := range contract.Parties {
+			if party.Party != resp.Party {
+				continue
+			}
+			party.Node = resp.node.String()
+			switch s, ok := status[contract.Address]; {
+			case resp.Error != "":
+				party.Status, party.Error = StatusError, resp.Error
+			case !ok:
+				party.Status, party.Error = StatusError, "contract not checked"
+			default:
+				party.Status = s
+			}
+		}
+	}
+}
+
+// setError records that the check couldn't be sent to a party
+func (r *Report) setError(party string, err error) {
+	for _, contract := range r.Contracts {
+		for _, p := range contract.Parties {
+			if p.Party == party {
+				p.Status, p.Error = StatusError, err.Error()
+			}
+		}
+	}
+}
+
+func (r *Report) summarize() {
+	for _, contract := range r.Contracts {
+		contract.Consistent = contract.Error == ""
+		for _, party := range contract.Parties {
+			if party.Status != StatusConsistent {
+				contract.Consistent = false
+			}
+		}
+		if !contract.Consistent {
+			r.Consistent = false
+		}
+	}
+}
+
+func randomID() (common.Hash, error) {
+	var id common.Hash
+	_, err := rand.Read(id[:])
+	return id, err
+}
+
+func contains(parties []string, party string) bool {
+	for _, p := range parties {
+		if p == party {
+			return true
+		}
+	}
+	return false
+}
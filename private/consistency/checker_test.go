@@ -0,0 +1,225 @@
+package consistency
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/mps"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/private"
+	"github.com/ethereum/go-ethereum/private/engine"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	sameContract     = common.HexToAddress("0x1")
+	divergedContract = common.HexToAddress("0x2")
+	missingContract  = common.HexToAddress("0x3")
+	unsharedContract = common.HexToAddress("0x4")
+
+	// creation payloads of the contracts, A and B share all but unsharedContract
+	sharedCreation   = common.BytesToEncryptedPayloadHash([]byte("shared"))
+	unsharedCreation = common.BytesToEncryptedPayloadHash([]byte("unshared"))
+)
+
+type testBackend struct {
+	block        *types.Block
+	privateState *state.StateDB
+	psm          mps.PrivateStateManager
+}
+
+func (b *testBackend) CurrentBlock() *types.Block { return b.block }
+
+func (b *testBackend) GetBlockByHash(hash common.Hash) *types.Block {
+	if hash == b.block.Hash() {
+		return b.block
+	}
+	return nil
+}
+
+func (b *testBackend) GetBlockByNumber(number uint64) *types.Block {
+	if number == b.block.NumberU64() {
+		return b.block
+	}
+	return nil
+}
+
+func (b *testBackend) StateAtPSI(common.Hash, types.PrivateStateIdentifier) (*state.StateDB, *state.StateDB, error) {
+	return nil, b.privateState, nil
+}
+
+func (b *testBackend) PrivateStateManager() mps.PrivateStateManager { return b.psm }
+
+// testPTM is a private transaction manager shared by the nodes, each node only
+// receives the payloads of its key
+type testPTM struct {
+	private.PrivateTransactionManager
+	key      string
+	payloads *testPayloads
+}
+
+type testPayloads struct {
+	lock     sync.Mutex
+	payloads map[common.EncryptedPayloadHash]testPayload
+}
+
+type testPayload struct {
+	sender, recipient string
+	data              []byte
+}
+
+func (ptm *testPTM) Send(data []byte, from string, to []string, _ *engine.ExtraMetadata) (string, []string, common.EncryptedPayloadHash, error) {
+	ptm.payloads.lock.Lock()
+	defer ptm.payloads.lock.Unlock()
+
+	hash := common.BytesToEncryptedPayloadHash(crypto.Keccak256(data))
+	ptm.payloads.payloads[hash] = testPayload{sender: ptm.key, recipient: to[0], data: data}
+	return ptm.key, nil, hash, nil
+}
+
+func (ptm *testPTM) Receive(hash common.EncryptedPayloadHash) (string, []string, []byte, *engine.ExtraMetadata, error) {
+	ptm.payloads.lock.Lock()
+	defer ptm.payloads.lock.Unlock()
+
+	payload, ok := ptm.payloads.payloads[hash]
+	if !ok || (payload.recipient != ptm.key && payload.sender != ptm.key) {
+		return "", nil, nil, nil, nil
+	}
+	return payload.sender, []string{ptm.key}, payload.data, &engine.ExtraMetadata{}, nil
+}
+
+func (ptm *testPTM) GetParticipants(hash common.EncryptedPayloadHash) ([]string, error) {
+	ptm.payloads.lock.Lock()
+	defer ptm.payloads.lock.Unlock()
+
+	payload, ok := ptm.payloads.payloads[hash]
+	if !ok || (payload.recipient != ptm.key && payload.sender != ptm.key) {
+		return nil, nil
+	}
+	return []string{payload.sender, payload.recipient}, nil
+}
+
+func newTestState(t *testing.T, divergedValue byte, withMissing bool) *state.StateDB {
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(t, err)
+	statedb.SetCode(sameContract, []byte{0x60, 0x00})
+	statedb.SetState(sameContract, common.Hash{}, common.HexToHash("0x1"))
+	statedb.SetCode(divergedContract, []byte{0x60, 0x00})
+	statedb.SetState(divergedContract, common.Hash{}, common.BytesToHash([]byte{divergedValue}))
+	statedb.SetCode(unsharedContract, []byte{0x60, 0x00})
+	if withMissing {
+		statedb.SetCode(missingContract, []byte{0x60, 0x00})
+	}
+	for _, address := range []common.Address{sameContract, divergedContract, missingContract} {
+		if statedb.Exist(address) {
+			statedb.SetPrivacyMetadata(address, state.NewStatePrivacyMetadata(sharedCreation, engine.PrivacyFlagPartyProtection))
+		}
+	}
+	statedb.SetPrivacyMetadata(unsharedContract, state.NewStatePrivacyMetadata(unsharedCreation, engine.PrivacyFlagPartyProtection))
+	root, err := statedb.Commit(true)
+	require.NoError(t, err)
+	statedb, err = state.New(root, statedb.Database(), nil)
+	require.NoError(t, err)
+	return statedb
+}
+
+func newTestChecker(t *testing.T, ctrl *gomock.Controller, block *types.Block, privateState *state.StateDB, ptmKey string, payloads *testPayloads) (*Checker, *ecdsa.PrivateKey) {
+	psm := mps.NewMockPrivateStateManager(ctrl)
+	psm.EXPECT().ResolveForUserContext(gomock.Any()).Return(mps.DefaultPrivateStateMetadata, nil).AnyTimes()
+	psm.EXPECT().ResolveForManagedParty(gomock.Any()).Return(mps.DefaultPrivateStateMetadata, nil).AnyTimes()
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	backend := &testBackend{block: block, privateState: privateState, psm: psm}
+	return New(backend, &testPTM{key: ptmKey, payloads: payloads}, key), key
+}
+
+// connect runs the pscheck protocol between the checkers
+func connect(t *testing.T, a *Checker, aKey *ecdsa.PrivateKey, b *Checker, bKey *ecdsa.PrivateKey) {
+	rwA, rwB := p2p.MsgPipe()
+	t.Cleanup(func() { rwA.Close() })
+	peerB := p2p.NewPeer(enode.PubkeyToIDV4(&bKey.PublicKey), "b", nil)
+	peerA := p2p.NewPeer(enode.PubkeyToIDV4(&aKey.PublicKey), "a", nil)
+	go a.runPeer(peerB, rwA)
+	go b.runPeer(peerA, rwB)
+}
+
+func TestVerify(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		block    = types.NewBlockWithHeader(&types.Header{Number: common.Big1})
+		payloads = &testPayloads{payloads: map[common.EncryptedPayloadHash]testPayload{
+			sharedCreation:   {sender: "A", recipient: "B"},
+			unsharedCreation: {sender: "B", recipient: "D"},
+		}}
+	)
+	a, aKey := newTestChecker(t, ctrl, block, newTestState(t, 1, true), "A", payloads)
+	b, bKey := newTestChecker(t, ctrl, block, newTestState(t, 2, false), "B", payloads)
+	connect(t, a, aKey, b, bKey)
+	// wait for the peers to be registered
+	for {
+		a.peersMu.RLock()
+		b.peersMu.RLock()
+		ready := len(a.peers) == 1 && len(b.peers) == 1
+		a.peersMu.RUnlock()
+		b.peersMu.RUnlock()
+		if ready {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// C has no node, so the check waits until the context ends
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	participants := []string{"B", "C"}
+	report, err := a.Verify(ctx, rpc.LatestBlockNumber, []common.Address{sameContract, divergedContract, missingContract, unsharedContract}, participants)
+	require.NoError(t, err)
+
+	assert.False(t, report.Consistent)
+	assert.Equal(t, block.Hash(), report.BlockHash)
+	require.Len(t, report.Contracts, 4)
+
+	// B has unsharedContract, but doesn't tell A as A isn't a participant
+	expected := []Status{StatusConsistent, StatusDiverged, StatusMissing, StatusMissing}
+	for i, contract := range report.Contracts {
+		require.Len(t, contract.Parties, 2)
+		assert.Equal(t, "B", contract.Parties[0].Party)
+		assert.Equal(t, expected[i], contract.Parties[0].Status, "contract %s", contract.Address.Hex())
+		assert.Equal(t, enode.PubkeyToIDV4(&bKey.PublicKey).String(), contract.Parties[0].Node)
+		assert.Equal(t, StatusNoResponse, contract.Parties[1].Status)
+		assert.False(t, contract.Consistent)
+	}
+}
+
+func TestCommitment(t *testing.T) {
+	block := common.HexToHash("0x1")
+	same, err := Commitment(newTestState(t, 1, false), sameContract, block)
+	require.NoError(t, err)
+	other, err := Commitment(newTestState(t, 2, false), sameContract, block)
+	require.NoError(t, err)
+	assert.Equal(t, same, other)
+
+	same, err = Commitment(newTestState(t, 1, false), divergedContract, block)
+	require.NoError(t, err)
+	other, err = Commitment(newTestState(t, 2, false), divergedContract, block)
+	require.NoError(t, err)
+	assert.NotEqual(t, same, other)
+
+	_, err = Commitment(newTestState(t, 1, false), missingContract, block)
+	assert.Error(t, err)
+}
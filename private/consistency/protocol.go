@@ -0,0 +1,141 @@
+package consistency
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/private/engine"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	protocolName    = "pscheck"
+	protocolVersion = 1
+	protocolLength  = 2
+
+	protocolMaxMsgSize = 1024
+
+	maxWorkers = 16
+)
+
+// pscheck protocol message codes, both carry the hash of a private payload
+const (
+	requestMsg  = 0x00 // a private state check, for one of the parties of the peer
+	responseMsg = 0x01 // the answer to a check of ours
+)
+
+// peer is a node we exchange private state checks with
+type peer struct {
+	*p2p.Peer
+	rw p2p.MsgReadWriter
+}
+
+// Protocols returns the pscheck protocol, to register on the node
+func (c *Checker) Protocols() []p2p.Protocol {
+	return []p2p.Protocol{{
+		Name:    protocolName,
+		Version: protocolVersion,
+		Length:  protocolLength,
+		Run:     c.runPeer,
+	}}
+}
+
+// runPeer serves the messages of the peer until it disconnects
+func (c *Checker) runPeer(p *p2p.Peer, rw p2p.MsgReadWriter) error {
+	peer := &peer{Peer: p, rw: rw}
+	c.peersMu.Lock()
+	c.peers[p.ID()] = peer
+	c.peersMu.Unlock()
+	defer func() {
+		c.peersMu.Lock()
+		delete(c.peers, p.ID())
+		c.peersMu.Unlock()
+	}()
+
+	for {
+		if err := c.handleMsg(peer); err != nil {
+			log.Debug("pscheck peer disconnected", "peer", p.ID(), "err", err)
+			return err
+		}
+	}
+}
+
+func (c *Checker) handleMsg(p *peer) error {
+	msg, err := p.rw.ReadMsg()
+	if err != nil {
+		return err
+	}
+	defer msg.Discard()
+
+	if msg.Size > protocolMaxMsgSize {
+		return fmt.Errorf("pscheck message too large: %v > %v", msg.Size, protocolMaxMsgSize)
+	}
+	var hash common.EncryptedPayloadHash
+	if err := msg.Decode(&hash); err != nil {
+		return fmt.Errorf("invalid pscheck message: %v", err)
+	}
+	// the private transaction manager may be slow, so it's called off the peer loop
+	select {
+	case c.workers <- struct{}{}:
+	default:
+		log.Warn("Dropping private state check, too many in progress", "peer", p.ID())
+		return nil
+	}
+	switch msg.Code {
+	case requestMsg:
+		go func() {
+			defer func() { <-c.workers }()
+			if err := c.handleRequest(p, hash); err != nil {
+				log.Warn("Failed to answer private state check", "peer", p.ID(), "err", err)
+			}
+		}()
+	case responseMsg:
+		go func() {
+			defer func() { <-c.workers }()
+			if err := c.deliver(hash, p.ID()); err != nil {
+				log.Warn("Invalid private state check response", "peer", p.ID(), "err", err)
+			}
+		}()
+	default:
+		<-c.workers
+		return fmt.Errorf("unknown pscheck message code %d", msg.Code)
+	}
+	return nil
+}
+
+// handleRequest answers the request of the peer through the private transaction
+// manager, if it's for one of our parties
+func (c *Checker) handleRequest(p *peer, hash common.EncryptedPayloadHash) error {
+	resp, sender, err := c.answer(hash, p.ID())
+	if resp == nil || err != nil {
+		return err
+	}
+	if resp.Sig, err = crypto.Sign(resp.sigHash().Bytes(), c.key); err != nil {
+		return err
+	}
+	enc, err := rlp.EncodeToBytes(resp)
+	if err != nil {
+		return err
+	}
+	_, _, respHash, err := c.ptm.Send(enc, resp.Party, []string{sender}, &engine.ExtraMetadata{PrivacyFlag: engine.PrivacyFlagStandardPrivate})
+	if err != nil {
+		return err
+	}
+	log.Debug("Answered private state check", "peer", p.ID(), "party", resp.Party, "contracts", len(resp.Contracts), "err", resp.Error)
+	return p2p.Send(p.rw, responseMsg, respHash)
+}
+
+// announce tells the peers about a request, the recipient's node answers it
+func (c *Checker) announce(hash common.EncryptedPayloadHash) {
+	c.peersMu.RLock()
+	defer c.peersMu.RUnlock()
+
+	for _, p := range c.peers {
+		if err := p2p.Send(p.rw, requestMsg, hash); err != nil {
+			log.Debug("Failed to announce private state check", "peer", p.ID(), "err", err)
+		}
+	}
+}
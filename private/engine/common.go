@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -17,6 +18,7 @@ const (
 var (
 	ErrPrivateTxManagerNotinUse                          = errors.New("private transaction manager is not in use")
 	ErrPrivateTxManagerNotReady                          = errors.New("private transaction manager is not ready")
+	ErrPrivateTxManagerUnavailable                       = errors.New("private transaction manager is unavailable")
 	ErrPrivateTxManagerNotSupported                      = errors.New("private transaction manager does not support this operation")
 	ErrPrivateTxManagerDoesNotSupportPrivacyEnhancements = errors.New("private transaction manager does not support privacy enhancements")
 	ErrPrivateTxManagerDoesNotSupportMandatoryRecipients = errors.New("private transaction manager does not support mandatory recipients")
@@ -51,6 +53,24 @@ type ExtraMetadata struct {
 type Client struct {
 	HttpClient *http.Client
 	BaseURL    string
+	// Monitor tracks the endpoints the client fails over between, nil if it doesn't
+	Monitor EndpointMonitor
+}
+
+// EndpointMonitor tracks the health of private transaction manager endpoints
+type EndpointMonitor interface {
+	Health() []EndpointHealth
+	// Close stops the health checks
+	Close()
+}
+
+// EndpointHealth is the state of a private transaction manager endpoint
+type EndpointHealth struct {
+	URL                 string    `json:"url"`
+	Available           bool      `json:"available"` // false while the circuit breaker is open
+	ConsecutiveFailures int       `json:"consecutiveFailures"`
+	LastError           string    `json:"lastError,omitempty"`
+	LastChecked         time.Time `json:"lastChecked"` // last health check, zero if they are disabled
 }
 
 func (c *Client) FullPath(path string) string {
@@ -60,7 +80,7 @@ func (c *Client) FullPath(path string) string {
 func (c *Client) Get(path string) (*http.Response, error) {
 	response, err := c.HttpClient.Get(c.FullPath(path))
 	if err != nil {
-		return response, fmt.Errorf("unable to submit request (method:%s,path:%s). Cause: %v", "GET", path, err)
+		return response, fmt.Errorf("unable to submit request (method:%s,path:%s). Cause: %w", "GET", path, err)
 	}
 	return response, err
 }
@@ -12,8 +12,9 @@ import (
 )
 
 type constellation struct {
-	node *Client
-	c    *gocache.Cache
+	node    *Client
+	c       *gocache.Cache
+	monitor engine.EndpointMonitor
 }
 
 func Is(ptm interface{}) bool {
@@ -26,10 +27,27 @@ func New(client *engine.Client) *constellation {
 		node: &Client{
 			httpClient: client.HttpClient,
 		},
-		c: gocache.New(cache.DefaultExpiration, cache.CleanupInterval),
+		c:       gocache.New(cache.DefaultExpiration, cache.CleanupInterval),
+		monitor: client.Monitor,
 	}
 }
 
+// Health reports the endpoints of the connection to Constellation
+func (g *constellation) Health() []engine.EndpointHealth {
+	if g.monitor == nil {
+		return nil
+	}
+	return g.monitor.Health()
+}
+
+// Close stops the health checks of the connection to Constellation
+func (g *constellation) Close() error {
+	if g.monitor != nil {
+		g.monitor.Close()
+	}
+	return nil
+}
+
 func (g *constellation) Send(data []byte, from string, to []string, extra *engine.ExtraMetadata) (string, []string, common.EncryptedPayloadHash, error) {
 	if extra.PrivacyFlag.IsNotStandardPrivate() {
 		return "", nil, common.EncryptedPayloadHash{}, engine.ErrPrivateTxManagerDoesNotSupportPrivacyEnhancements
@@ -0,0 +1,572 @@
+// Package embedded implements a private transaction manager that runs inside geth,
+// so private transactions can be used on development and test networks without
+// Tessera. Payloads are encrypted with NaCl boxes, stored in LevelDB and sent to
+// the nodes of the recipients over the ptm devp2p protocol.
+package embedded
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/leveldb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/private/engine"
+	"github.com/ethereum/go-ethereum/rlp"
+	"golang.org/x/crypto/nacl/box"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	keySize   = 32
+	nonceSize = 24
+
+	// leveldb settings, the store only holds private payloads
+	databaseCache   = 16
+	databaseHandles = 16
+)
+
+var (
+	keyPairKey    = []byte("ptm-key")
+	payloadPrefix = []byte("ptm-payload-") // payloadPrefix + hash -> encryptedPayload
+	usagePrefix   = []byte("ptm-usage-")   // usagePrefix + peer id -> size of the payloads it sent (uint64 big endian)
+)
+
+var (
+	errUnknownSender            = errors.New("sender key is not managed by the embedded private transaction manager")
+	errPayloadNotFound          = errors.New("payload not found")
+	errNotRecipient             = errors.New("payload is not addressed to any of our keys")
+	errDecryptionFailed         = errors.New("unable to decrypt payload")
+	errMissingMandatoryRecipent = errors.New("mandatory recipients must be part of the recipients")
+)
+
+// keyPair is the NaCl key pair the manager encrypts and decrypts with
+type keyPair struct {
+	Public  [keySize]byte
+	Private [keySize]byte
+}
+
+// encryptedPayload is a private payload as it's stored and sent to recipients.
+// The payload is encrypted with a random master key, which is boxed for each of
+// the recipients. Recipients only receive their own box.
+type encryptedPayload struct {
+	Sender              []byte
+	CipherText          []byte
+	CipherTextNonce     []byte
+	RecipientBoxes      [][]byte
+	RecipientNonce      []byte
+	RecipientKeys       [][]byte
+	ACHashes            []common.EncryptedPayloadHash
+	ACMerkleRoot        common.Hash
+	PrivacyFlag         uint64
+	MandatoryRecipients [][]byte
+}
+
+// hash returns the SHA3-512 of the cipher text, as Tessera does
+func (p *encryptedPayload) hash() common.EncryptedPayloadHash {
+	return common.EncryptedPayloadHash(sha3.Sum512(p.CipherText))
+}
+
+// forRecipient returns a copy of the payload with only the box of the recipient
+func (p *encryptedPayload) forRecipient(i int) *encryptedPayload {
+	cpy := *p
+	cpy.RecipientBoxes = [][]byte{p.RecipientBoxes[i]}
+	cpy.RecipientKeys = [][]byte{p.RecipientKeys[i]}
+	return &cpy
+}
+
+// PrivateTransactionManager is the embedded private transaction manager. Besides
+// the PTM interface it's a node.Lifecycle and provides the ptm protocol.
+type PrivateTransactionManager struct {
+	db       ethdb.KeyValueStore
+	key      *keyPair
+	features *engine.FeatureSet
+
+	peers        map[string]*peer // base64 public key -> peer managing it
+	peersMu      sync.RWMutex
+	receiveMu    sync.Mutex // serialises storing payloads received from peers
+	storageLimit uint64     // size of the payloads a single peer can have us store
+	acks         map[ackKey]chan struct{}
+	ackId        uint64
+	acksMu       sync.Mutex
+	closeMu      sync.Mutex
+	isClosed     bool
+}
+
+// New opens the payload store in dir, generating the key pair of the manager the
+// first time.
+//
+// Like Tessera, it supports privacy enhancements and mandatory recipients. It
+// doesn't check the participants of affected contracts, quorum does that when
+// simulating and applying the transactions.
+func New(dir string) (*PrivateTransactionManager, error) {
+	db, err := leveldb.New(dir, databaseCache, databaseHandles, "ptm/db/")
+	if err != nil {
+		return nil, fmt.Errorf("unable to open embedded private transaction manager store %s: %v", dir, err)
+	}
+	key, err := loadOrGenerateKey(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	ptm := &PrivateTransactionManager{
+		db:           db,
+		key:          key,
+		features:     engine.NewFeatureSet(engine.PrivacyEnhancements, engine.MandatoryRecipients),
+		peers:        make(map[string]*peer),
+		storageLimit: peerStorageLimit,
+		acks:         make(map[ackKey]chan struct{}),
+	}
+	log.Info("Embedded private transaction manager started", "key", ptm.PublicKey(), "dir", dir)
+	return ptm, nil
+}
+
+func loadOrGenerateKey(db ethdb.KeyValueStore) (*keyPair, error) {
+	key := new(keyPair)
+	if enc, err := db.Get(keyPairKey); err == nil {
+		if err := rlp.DecodeBytes(enc, key); err != nil {
+			return nil, fmt.Errorf("invalid embedded private transaction manager key: %v", err)
+		}
+		return key, nil
+	}
+	public, private, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	key.Public, key.Private = *public, *private
+	enc, err := rlp.EncodeToBytes(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Put(keyPairKey, enc); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// PublicKey returns the base64 public key of the manager, to use in privateFor
+func (ptm *PrivateTransactionManager) PublicKey() string {
+	return base64.StdEncoding.EncodeToString(ptm.key.Public[:])
+}
+
+// Start implements node.Lifecycle, the manager is already running
+func (ptm *PrivateTransactionManager) Start() error {
+	return nil
+}
+
+// Stop implements node.Lifecycle, closing the payload store
+func (ptm *PrivateTransactionManager) Stop() error {
+	return ptm.Close()
+}
+
+// Close closes the payload store, it's safe to call more than once
+func (ptm *PrivateTransactionManager) Close() error {
+	ptm.closeMu.Lock()
+	defer ptm.closeMu.Unlock()
+
+	if ptm.isClosed {
+		return nil
+	}
+	ptm.isClosed = true
+	return ptm.db.Close()
+}
+
+func (ptm *PrivateTransactionManager) Name() string {
+	return "Embedded"
+}
+
+func (ptm *PrivateTransactionManager) HasFeature(f engine.PrivateTransactionManagerFeature) bool {
+	return ptm.features.HasFeature(f)
+}
+
+func (ptm *PrivateTransactionManager) Send(data []byte, from string, to []string, extra *engine.ExtraMetadata) (string, []string, common.EncryptedPayloadHash, error) {
+	if err := checkMandatoryRecipients(to, extra); err != nil {
+		return "", nil, common.EncryptedPayloadHash{}, err
+	}
+	if err := ptm.checkSender(from); err != nil {
+		return "", nil, common.EncryptedPayloadHash{}, err
+	}
+	recipients, err := ptm.recipients(to)
+	if err != nil {
+		return "", nil, common.EncryptedPayloadHash{}, err
+	}
+	payload, err := ptm.encrypt(data, recipients)
+	if err != nil {
+		return "", nil, common.EncryptedPayloadHash{}, err
+	}
+	setExtra(payload, extra)
+
+	hash := payload.hash()
+	if err := ptm.store(hash, payload); err != nil {
+		return "", nil, common.EncryptedPayloadHash{}, err
+	}
+	if err := ptm.distribute(payload); err != nil {
+		return "", nil, common.EncryptedPayloadHash{}, err
+	}
+	return ptm.PublicKey(), []string{ptm.PublicKey()}, hash, nil
+}
+
+func (ptm *PrivateTransactionManager) StoreRaw(data []byte, from string) (common.EncryptedPayloadHash, error) {
+	if err := ptm.checkSender(from); err != nil {
+		return common.EncryptedPayloadHash{}, err
+	}
+	payload, err := ptm.encrypt(data, [][]byte{ptm.key.Public[:]})
+	if err != nil {
+		return common.EncryptedPayloadHash{}, err
+	}
+	hash := payload.hash()
+	return hash, ptm.store(hash, payload)
+}
+
+// SendSignedTx boxes the master key of a raw payload for the recipients and sends
+// it to them. The cipher text doesn't change, so neither does the hash.
+func (ptm *PrivateTransactionManager) SendSignedTx(hash common.EncryptedPayloadHash, to []string, extra *engine.ExtraMetadata) (string, []string, []byte, error) {
+	if err := checkMandatoryRecipients(to, extra); err != nil {
+		return "", nil, nil, err
+	}
+	payload, err := ptm.load(hash)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if payload == nil {
+		return "", nil, nil, errPayloadNotFound
+	}
+	masterKey, err := ptm.openMasterKey(payload)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	recipients, err := ptm.recipients(to)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if err := ptm.boxMasterKey(payload, masterKey, recipients); err != nil {
+		return "", nil, nil, err
+	}
+	setExtra(payload, extra)
+
+	if err := ptm.store(hash, payload); err != nil {
+		return "", nil, nil, err
+	}
+	if err := ptm.distribute(payload); err != nil {
+		return "", nil, nil, err
+	}
+	return ptm.PublicKey(), []string{ptm.PublicKey()}, hash.Bytes(), nil
+}
+
+func (ptm *PrivateTransactionManager) Receive(hash common.EncryptedPayloadHash) (string, []string, []byte, *engine.ExtraMetadata, error) {
+	if common.EmptyEncryptedPayloadHash(hash) {
+		return "", nil, nil, nil, nil
+	}
+	payload, err := ptm.load(hash)
+	if err != nil || payload == nil {
+		return "", nil, nil, nil, err
+	}
+	data, err := ptm.decrypt(payload)
+	if err != nil {
+		return "", nil, nil, nil, err
+	}
+	sender := base64.StdEncoding.EncodeToString(payload.Sender)
+	extra := &engine.ExtraMetadata{
+		ACHashes:            common.EncryptedPayloadHashes{},
+		ACMerkleRoot:        payload.ACMerkleRoot,
+		PrivacyFlag:         engine.PrivacyFlagType(payload.PrivacyFlag),
+		ManagedParties:      []string{ptm.PublicKey()},
+		Sender:              sender,
+		MandatoryRecipients: toBase64s(payload.MandatoryRecipients),
+	}
+	for _, h := range payload.ACHashes {
+		extra.ACHashes.Add(h)
+	}
+	return sender, extra.ManagedParties, data, extra, nil
+}
+
+func (ptm *PrivateTransactionManager) ReceiveRaw(hash common.EncryptedPayloadHash) ([]byte, string, *engine.ExtraMetadata, error) {
+	sender, managedParties, data, _, err := ptm.Receive(hash)
+	if err != nil || data == nil {
+		return nil, "", nil, err
+	}
+	return data, sender, &engine.ExtraMetadata{ManagedParties: managedParties, Sender: sender}, nil
+}
+
+func (ptm *PrivateTransactionManager) IsSender(hash common.EncryptedPayloadHash) (bool, error) {
+	payload, err := ptm.load(hash)
+	if err != nil {
+		return false, err
+	}
+	if payload == nil {
+		return false, errPayloadNotFound
+	}
+	return ptm.isOurs(payload.Sender), nil
+}
+
+// GetParticipants returns the recipients of the payload. Only the sender knows
+// all of them, recipients just know themselves.
+func (ptm *PrivateTransactionManager) GetParticipants(hash common.EncryptedPayloadHash) ([]string, error) {
+	payload, err := ptm.load(hash)
+	if err != nil {
+		return nil, err
+	}
+	if payload == nil {
+		return nil, errPayloadNotFound
+	}
+	return toBase64s(payload.RecipientKeys), nil
+}
+
+func (ptm *PrivateTransactionManager) GetMandatory(hash common.EncryptedPayloadHash) ([]string, error) {
+	payload, err := ptm.load(hash)
+	if err != nil {
+		return nil, err
+	}
+	if payload == nil {
+		return nil, errPayloadNotFound
+	}
+	return toBase64s(payload.MandatoryRecipients), nil
+}
+
+// EncryptPayload encrypts the data without storing or sending it, the result is
+// the JSON encoded common.DecryptRequest that DecryptPayload takes
+func (ptm *PrivateTransactionManager) EncryptPayload(data []byte, from string, to []string, extra *engine.ExtraMetadata) ([]byte, error) {
+	if err := ptm.checkSender(from); err != nil {
+		return nil, err
+	}
+	recipients, err := ptm.recipients(to)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := ptm.encrypt(data, recipients)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&common.DecryptRequest{
+		SenderKey:       payload.Sender,
+		CipherText:      payload.CipherText,
+		CipherTextNonce: payload.CipherTextNonce,
+		RecipientBoxes:  toBase64s(payload.RecipientBoxes),
+		RecipientNonce:  payload.RecipientNonce,
+		RecipientKeys:   toBase64s(payload.RecipientKeys),
+	})
+}
+
+func (ptm *PrivateTransactionManager) DecryptPayload(request common.DecryptRequest) ([]byte, *engine.ExtraMetadata, error) {
+	payload := &encryptedPayload{
+		Sender:          request.SenderKey,
+		CipherText:      request.CipherText,
+		CipherTextNonce: request.CipherTextNonce,
+		RecipientNonce:  request.RecipientNonce,
+	}
+	var err error
+	if payload.RecipientBoxes, err = fromBase64s(request.RecipientBoxes); err != nil {
+		return nil, nil, err
+	}
+	if payload.RecipientKeys, err = fromBase64s(request.RecipientKeys); err != nil {
+		return nil, nil, err
+	}
+	data, err := ptm.decrypt(payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, &engine.ExtraMetadata{}, nil
+}
+
+func (ptm *PrivateTransactionManager) Groups() ([]engine.PrivacyGroup, error) {
+	return nil, engine.ErrPrivateTxManagerNotSupported
+}
+
+// checkSender verifies the sender is our key, an empty sender means our key
+func (ptm *PrivateTransactionManager) checkSender(from string) error {
+	if from == "" || from == ptm.PublicKey() {
+		return nil
+	}
+	return errUnknownSender
+}
+
+// recipients decodes the recipient keys and adds the sender, who must be able to
+// decrypt the payload too
+func (ptm *PrivateTransactionManager) recipients(to []string) ([][]byte, error) {
+	recipients := [][]byte{ptm.key.Public[:]}
+	seen := map[string]bool{ptm.PublicKey(): true}
+	for _, key := range to {
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		decoded, err := base64.StdEncoding.DecodeString(key)
+		if err != nil || len(decoded) != keySize {
+			return nil, fmt.Errorf("invalid recipient key %s", key)
+		}
+		recipients = append(recipients, decoded)
+	}
+	return recipients, nil
+}
+
+// encrypt encrypts the data with a random master key and boxes it for the recipients
+func (ptm *PrivateTransactionManager) encrypt(data []byte, recipients [][]byte) (*encryptedPayload, error) {
+	var (
+		masterKey [keySize]byte
+		nonce     [nonceSize]byte
+	)
+	if _, err := io.ReadFull(rand.Reader, masterKey[:]); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(rand.Reader, nonce[:]); err != nil {
+		return nil, err
+	}
+	payload := &encryptedPayload{
+		Sender:          ptm.key.Public[:],
+		CipherText:      secretbox.Seal(nil, data, &nonce, &masterKey),
+		CipherTextNonce: nonce[:],
+	}
+	if err := ptm.boxMasterKey(payload, &masterKey, recipients); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// boxMasterKey replaces the recipients of the payload
+func (ptm *PrivateTransactionManager) boxMasterKey(payload *encryptedPayload, masterKey *[keySize]byte, recipients [][]byte) error {
+	var nonce [nonceSize]byte
+	if _, err := io.ReadFull(rand.Reader, nonce[:]); err != nil {
+		return err
+	}
+	payload.RecipientNonce = nonce[:]
+	payload.RecipientBoxes = make([][]byte, len(recipients))
+	payload.RecipientKeys = recipients
+	for i, recipient := range recipients {
+		var recipientKey [keySize]byte
+		copy(recipientKey[:], recipient)
+		payload.RecipientBoxes[i] = box.Seal(nil, masterKey[:], &nonce, &recipientKey, &ptm.key.Private)
+	}
+	return nil
+}
+
+// openMasterKey opens the box of our key
+func (ptm *PrivateTransactionManager) openMasterKey(payload *encryptedPayload) (*[keySize]byte, error) {
+	if len(payload.Sender) != keySize || len(payload.RecipientNonce) != nonceSize || len(payload.RecipientBoxes) != len(payload.RecipientKeys) {
+		return nil, errDecryptionFailed
+	}
+	var (
+		sender [keySize]byte
+		nonce  [nonceSize]byte
+	)
+	copy(sender[:], payload.Sender)
+	copy(nonce[:], payload.RecipientNonce)
+	for i, recipient := range payload.RecipientKeys {
+		if !ptm.isOurs(recipient) {
+			continue
+		}
+		opened, ok := box.Open(nil, payload.RecipientBoxes[i], &nonce, &sender, &ptm.key.Private)
+		if !ok || len(opened) != keySize {
+			return nil, errDecryptionFailed
+		}
+		var masterKey [keySize]byte
+		copy(masterKey[:], opened)
+		return &masterKey, nil
+	}
+	return nil, errNotRecipient
+}
+
+func (ptm *PrivateTransactionManager) decrypt(payload *encryptedPayload) ([]byte, error) {
+	masterKey, err := ptm.openMasterKey(payload)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload.CipherTextNonce) != nonceSize {
+		return nil, errDecryptionFailed
+	}
+	var nonce [nonceSize]byte
+	copy(nonce[:], payload.CipherTextNonce)
+	data, ok := secretbox.Open(nil, payload.CipherText, &nonce, masterKey)
+	if !ok {
+		return nil, errDecryptionFailed
+	}
+	return data, nil
+}
+
+func (ptm *PrivateTransactionManager) isOurs(key []byte) bool {
+	return string(key) == string(ptm.key.Public[:])
+}
+
+func (ptm *PrivateTransactionManager) store(hash common.EncryptedPayloadHash, payload *encryptedPayload) error {
+	enc, err := rlp.EncodeToBytes(payload)
+	if err != nil {
+		return err
+	}
+	return ptm.db.Put(append(payloadPrefix, hash.Bytes()...), enc)
+}
+
+// load returns the stored payload, nil if there is none
+func (ptm *PrivateTransactionManager) load(hash common.EncryptedPayloadHash) (*encryptedPayload, error) {
+	key := append(payloadPrefix, hash.Bytes()...)
+	if ok, err := ptm.db.Has(key); err != nil || !ok {
+		return nil, err
+	}
+	enc, err := ptm.db.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	payload := new(encryptedPayload)
+	if err := rlp.DecodeBytes(enc, payload); err != nil {
+		return nil, fmt.Errorf("invalid stored payload %s: %v", hash.TerminalString(), err)
+	}
+	return payload, nil
+}
+
+func setExtra(payload *encryptedPayload, extra *engine.ExtraMetadata) {
+	if extra == nil {
+		return
+	}
+	payload.ACHashes = make([]common.EncryptedPayloadHash, 0, len(extra.ACHashes))
+	for h := range extra.ACHashes {
+		payload.ACHashes = append(payload.ACHashes, h)
+	}
+	payload.ACMerkleRoot = extra.ACMerkleRoot
+	payload.PrivacyFlag = uint64(extra.PrivacyFlag)
+	payload.MandatoryRecipients, _ = fromBase64s(extra.MandatoryRecipients)
+}
+
+// checkMandatoryRecipients verifies the mandatory recipients are recipients too
+func checkMandatoryRecipients(to []string, extra *engine.ExtraMetadata) error {
+	if extra == nil {
+		return nil
+	}
+	if extra.PrivacyFlag == engine.PrivacyFlagMandatoryRecipients && len(extra.MandatoryRecipients) == 0 {
+		return errMissingMandatoryRecipent
+	}
+	recipients := make(map[string]bool)
+	for _, key := range to {
+		recipients[key] = true
+	}
+	for _, key := range extra.MandatoryRecipients {
+		if !recipients[key] {
+			return errMissingMandatoryRecipent
+		}
+	}
+	return nil
+}
+
+func toBase64s(keys [][]byte) []string {
+	encoded := make([]string, len(keys))
+	for i, key := range keys {
+		encoded[i] = base64.StdEncoding.EncodeToString(key)
+	}
+	return encoded
+}
+
+func fromBase64s(encoded []string) ([][]byte, error) {
+	keys := make([][]byte, len(encoded))
+	for i, s := range encoded {
+		key, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 value %s: %v", s, err)
+		}
+		keys[i] = key
+	}
+	return keys, nil
+}
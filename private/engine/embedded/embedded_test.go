@@ -0,0 +1,311 @@
+package embedded
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/private/engine"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestManager(t *testing.T) *PrivateTransactionManager {
+	dir, err := ioutil.TempDir("", "embedded-ptm")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	ptm, err := New(dir)
+	require.NoError(t, err)
+	t.Cleanup(func() { ptm.Close() })
+	return ptm
+}
+
+// connect runs the ptm protocol between the managers and waits for the handshake
+func connect(t *testing.T, a, b *PrivateTransactionManager) {
+	rwA, rwB := p2p.MsgPipe()
+	t.Cleanup(func() { rwA.Close() })
+
+	go a.runPeer(p2p.NewPeer(enode.ID(b.key.Public), "b", nil), rwA)
+	go b.runPeer(p2p.NewPeer(enode.ID(a.key.Public), "a", nil), rwB)
+
+	for i := 0; i < 100; i++ {
+		a.peersMu.RLock()
+		_, okA := a.peers[b.PublicKey()]
+		a.peersMu.RUnlock()
+		b.peersMu.RLock()
+		_, okB := b.peers[a.PublicKey()]
+		b.peersMu.RUnlock()
+		if okA && okB {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("ptm handshake didn't complete")
+}
+
+func TestSendAndReceive(t *testing.T) {
+	sender, recipient, other := newTestManager(t), newTestManager(t), newTestManager(t)
+	connect(t, sender, recipient)
+	connect(t, sender, other)
+
+	extra := &engine.ExtraMetadata{
+		ACMerkleRoot:        common.HexToHash("0x1234"),
+		PrivacyFlag:         engine.PrivacyFlagMandatoryRecipients,
+		MandatoryRecipients: []string{recipient.PublicKey()},
+	}
+	senderKey, _, hash, err := sender.Send([]byte("private"), "", []string{recipient.PublicKey()}, extra)
+	require.NoError(t, err)
+	assert.Equal(t, sender.PublicKey(), senderKey)
+
+	for _, ptm := range []*PrivateTransactionManager{sender, recipient} {
+		from, managedParties, data, received, err := ptm.Receive(hash)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("private"), data)
+		assert.Equal(t, sender.PublicKey(), from)
+		assert.Equal(t, []string{ptm.PublicKey()}, managedParties)
+		assert.Equal(t, extra.ACMerkleRoot, received.ACMerkleRoot)
+		assert.Equal(t, extra.PrivacyFlag, received.PrivacyFlag)
+		assert.Equal(t, extra.MandatoryRecipients, received.MandatoryRecipients)
+	}
+
+	// not a recipient
+	_, _, data, _, err := other.Receive(hash)
+	require.NoError(t, err)
+	assert.Nil(t, data)
+
+	isSender, err := sender.IsSender(hash)
+	require.NoError(t, err)
+	assert.True(t, isSender)
+	isSender, err = recipient.IsSender(hash)
+	require.NoError(t, err)
+	assert.False(t, isSender)
+
+	participants, err := sender.GetParticipants(hash)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{sender.PublicKey(), recipient.PublicKey()}, participants)
+}
+
+func TestSend_Failures(t *testing.T) {
+	sender, recipient := newTestManager(t), newTestManager(t)
+
+	_, _, _, err := sender.Send([]byte("private"), recipient.PublicKey(), []string{sender.PublicKey()}, &engine.ExtraMetadata{})
+	assert.Equal(t, errUnknownSender, err)
+
+	_, _, _, err = sender.Send([]byte("private"), "", []string{"not base64"}, &engine.ExtraMetadata{})
+	assert.Error(t, err)
+
+	_, _, _, err = sender.Send([]byte("private"), "", []string{recipient.PublicKey()}, &engine.ExtraMetadata{
+		PrivacyFlag: engine.PrivacyFlagMandatoryRecipients,
+	})
+	assert.Equal(t, errMissingMandatoryRecipent, err)
+
+	// the recipient isn't connected
+	_, _, _, err = sender.Send([]byte("private"), "", []string{recipient.PublicKey()}, &engine.ExtraMetadata{})
+	assert.Error(t, err)
+}
+
+func TestStoreRawAndSendSignedTx(t *testing.T) {
+	sender, recipient := newTestManager(t), newTestManager(t)
+	connect(t, sender, recipient)
+
+	hash, err := sender.StoreRaw([]byte("signed"), sender.PublicKey())
+	require.NoError(t, err)
+
+	data, from, _, err := sender.ReceiveRaw(hash)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("signed"), data)
+	assert.Equal(t, sender.PublicKey(), from)
+
+	_, _, returned, err := sender.SendSignedTx(hash, []string{recipient.PublicKey()}, &engine.ExtraMetadata{})
+	require.NoError(t, err)
+	assert.Equal(t, hash.Bytes(), returned)
+
+	_, _, data, _, err = recipient.Receive(hash)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("signed"), data)
+}
+
+func TestEncryptAndDecryptPayload(t *testing.T) {
+	sender, recipient, other := newTestManager(t), newTestManager(t), newTestManager(t)
+
+	encrypted, err := sender.EncryptPayload([]byte("payload"), "", []string{recipient.PublicKey()}, &engine.ExtraMetadata{})
+	require.NoError(t, err)
+	var request common.DecryptRequest
+	require.NoError(t, json.Unmarshal(encrypted, &request))
+
+	for _, ptm := range []*PrivateTransactionManager{sender, recipient} {
+		data, _, err := ptm.DecryptPayload(request)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("payload"), data)
+	}
+	_, _, err = other.DecryptPayload(request)
+	assert.Equal(t, errNotRecipient, err)
+}
+
+func TestKeyPersists(t *testing.T) {
+	dir, err := ioutil.TempDir("", "embedded-ptm")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ptm, err := New(dir)
+	require.NoError(t, err)
+	key := ptm.PublicKey()
+	hash, err := ptm.StoreRaw([]byte("stored"), "")
+	require.NoError(t, err)
+	require.NoError(t, ptm.Close())
+
+	ptm, err = New(dir)
+	require.NoError(t, err)
+	defer ptm.Close()
+	assert.Equal(t, key, ptm.PublicKey())
+	data, _, _, err := ptm.ReceiveRaw(hash)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("stored"), data)
+}
+
+// usage returns the size of the payloads the peer had the manager store
+func usage(t *testing.T, ptm *PrivateTransactionManager, p *peer) uint64 {
+	enc, err := ptm.db.Get(append(usagePrefix, p.ID().Bytes()...))
+	require.NoError(t, err)
+	return binary.BigEndian.Uint64(enc)
+}
+
+func TestStoreReceived(t *testing.T) {
+	sender, recipient, attacker := newTestManager(t), newTestManager(t), newTestManager(t)
+	senderPeer := &peer{Peer: p2p.NewPeer(enode.ID(sender.key.Public), "sender", nil), keys: [][]byte{sender.key.Public[:]}}
+	attackerPeer := &peer{Peer: p2p.NewPeer(enode.ID(attacker.key.Public), "attacker", nil), keys: [][]byte{attacker.key.Public[:]}}
+
+	payload, err := sender.encrypt([]byte("private"), [][]byte{sender.key.Public[:], recipient.key.Public[:]})
+	require.NoError(t, err)
+	hash := payload.hash()
+
+	// only the peer of the sender can deliver its payloads
+	assert.Equal(t, errSenderNotPeer, recipient.storeReceived(attackerPeer, hash, payload.forRecipient(1)))
+	require.NoError(t, recipient.storeReceived(senderPeer, hash, payload.forRecipient(1)))
+
+	// a payload with the same cipher text from another sender doesn't replace it
+	forged, err := attacker.encrypt([]byte("forged"), [][]byte{recipient.key.Public[:]})
+	require.NoError(t, err)
+	forged.CipherText = payload.CipherText
+	assert.Equal(t, errSenderMismatch, recipient.storeReceived(attackerPeer, hash, forged))
+
+	from, _, data, _, err := recipient.Receive(hash)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("private"), data)
+	assert.Equal(t, sender.PublicKey(), from)
+
+	// the same payload again is free, a replacement counts with what it grew by
+	stored := usage(t, recipient, senderPeer)
+	require.NoError(t, recipient.storeReceived(senderPeer, hash, payload.forRecipient(1)))
+	assert.Equal(t, stored, usage(t, recipient, senderPeer))
+
+	grown := payload.forRecipient(1)
+	grown.MandatoryRecipients = [][]byte{recipient.key.Public[:]}
+	require.NoError(t, recipient.storeReceived(senderPeer, hash, grown))
+	before, _ := rlp.EncodeToBytes(payload.forRecipient(1))
+	after, _ := rlp.EncodeToBytes(grown)
+	assert.Equal(t, stored+uint64(len(after)-len(before)), usage(t, recipient, senderPeer))
+
+	// but the sender can't store beyond its limit
+	recipient.storageLimit = usage(t, recipient, senderPeer) + 10
+	other, err := sender.encrypt([]byte("other"), [][]byte{recipient.key.Public[:]})
+	require.NoError(t, err)
+	assert.Equal(t, errPeerStorageExceeded, recipient.storeReceived(senderPeer, other.hash(), other))
+	grown.MandatoryRecipients = append(grown.MandatoryRecipients, sender.key.Public[:])
+	assert.Equal(t, errPeerStorageExceeded, recipient.storeReceived(senderPeer, hash, grown))
+}
+
+func TestHandshakeRequiresKeyProof(t *testing.T) {
+	ptm, victim, attacker := newTestManager(t), newTestManager(t), newTestManager(t)
+	rw, remote := p2p.MsgPipe()
+	defer rw.Close()
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- ptm.runPeer(p2p.NewPeer(enode.ID(attacker.key.Public), "attacker", nil), rw)
+	}()
+	// the attacker claims the key of the victim, but can only box with its own
+	status := new(statusData)
+	go p2p.Send(remote, statusMsg, &statusData{Keys: [][]byte{victim.key.Public[:]}, Challenge: make([]byte, challengeSize)})
+	msg, err := remote.ReadMsg()
+	require.NoError(t, err)
+	require.NoError(t, readStatus(msg, status))
+
+	proof, err := attacker.prove(status)
+	require.NoError(t, err)
+	go p2p.Send(remote, proofMsg, proof)
+	msg, err = remote.ReadMsg()
+	require.NoError(t, err)
+	msg.Discard()
+
+	select {
+	case err := <-errc:
+		assert.Equal(t, errKeyNotProven, err)
+	case <-time.After(handshakeTimeout):
+		t.Fatal("peer with an unproven key wasn't rejected")
+	}
+	ptm.peersMu.RLock()
+	defer ptm.peersMu.RUnlock()
+	assert.Empty(t, ptm.peers)
+}
+
+func TestDuplicateKeyRejected(t *testing.T) {
+	a, b := newTestManager(t), newTestManager(t)
+	connect(t, a, b)
+	first := a.peerById(enode.ID(b.key.Public))
+	require.NotNil(t, first)
+
+	// a second connection claiming the same key doesn't take over its payloads
+	rwA, rwB := p2p.MsgPipe()
+	defer rwA.Close()
+	errc := make(chan error, 1)
+	go func() {
+		errc <- a.runPeer(p2p.NewPeer(enode.ID{0x01}, "b again", nil), rwA)
+	}()
+	go b.runPeer(p2p.NewPeer(enode.ID(a.key.Public), "a again", nil), rwB)
+
+	select {
+	case err := <-errc:
+		assert.True(t, errors.Is(err, errKeyClaimed), "have %v", err)
+	case <-time.After(handshakeTimeout):
+		t.Fatal("duplicate key claim wasn't rejected")
+	}
+	a.peersMu.RLock()
+	defer a.peersMu.RUnlock()
+	assert.Equal(t, first, a.peers[b.PublicKey()])
+}
+
+func TestAckFromRecipientOnly(t *testing.T) {
+	ptm := newTestManager(t)
+	recipient := enode.ID{0x01}
+	ack := make(chan struct{})
+	ptm.acks[ackKey{peer: recipient, id: 1}] = ack
+
+	deliver := func(id enode.ID) {
+		rw, remote := p2p.MsgPipe()
+		defer rw.Close()
+		go p2p.Send(remote, ackMsg, &ackData{Id: 1})
+		require.NoError(t, ptm.handleMsg(&peer{Peer: p2p.NewPeer(id, "peer", nil), rw: rw}))
+	}
+	deliver(enode.ID{0x02})
+	select {
+	case <-ack:
+		t.Fatal("payload acknowledged by another peer")
+	default:
+	}
+	deliver(recipient)
+	select {
+	case <-ack:
+	default:
+		t.Fatal("payload not acknowledged by its recipient")
+	}
+}
@@ -0,0 +1,445 @@
+package embedded
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/rlp"
+	"golang.org/x/crypto/nacl/box"
+)
+
+const (
+	protocolName    = "ptm"
+	protocolVersion = 2
+	protocolLength  = 4
+
+	protocolMaxMsgSize = 10 * 1024 * 1024
+
+	// peerStorageLimit caps the size of the payloads a peer can have us store
+	peerStorageLimit = 256 * 1024 * 1024
+
+	handshakeTimeout = 5 * time.Second
+	ackTimeout       = 10 * time.Second
+
+	challengeSize = 32
+)
+
+// ptm protocol message codes
+const (
+	statusMsg  = 0x00 // public keys of the manager and a challenge for the peer
+	payloadMsg = 0x01 // payload for one of the keys of the peer
+	ackMsg     = 0x02 // payload stored
+	proofMsg   = 0x03 // challenge of the peer boxed with each of our keys
+)
+
+var (
+	errHandshakeTimeout = errors.New("ptm handshake timed out")
+	errAckTimeout       = errors.New("recipient didn't acknowledge the payload")
+	errKeyNotProven     = errors.New("peer didn't prove it holds its ptm keys")
+	errKeyClaimed       = errors.New("ptm key already held by another peer")
+
+	errSenderNotPeer       = errors.New("payload sender is not a key of the peer")
+	errSenderMismatch      = errors.New("payload already stored from another sender")
+	errPeerStorageExceeded = errors.New("peer exceeded its payload storage limit")
+)
+
+type statusData struct {
+	Keys      [][]byte
+	Challenge []byte
+}
+
+// proofData proves the sender holds the private keys it announced: the challenge of
+// the recipient, boxed from each of the keys to the first key of the recipient
+type proofData struct {
+	Nonce []byte
+	Boxes [][]byte
+}
+
+type payloadData struct {
+	Id      uint64
+	Payload *encryptedPayload
+}
+
+type ackData struct {
+	Id uint64
+}
+
+// ackKey identifies a payload waiting for the acknowledgement of the peer it was sent to
+type ackKey struct {
+	peer enode.ID
+	id   uint64
+}
+
+// peer is a node we exchange payloads with
+type peer struct {
+	*p2p.Peer
+	rw   p2p.MsgReadWriter
+	keys [][]byte
+}
+
+// NodeInfo is what the ptm protocol reports about the node
+type NodeInfo struct {
+	Keys []string `json:"keys"`
+}
+
+// Protocols returns the ptm protocol, to register on the node
+func (ptm *PrivateTransactionManager) Protocols() []p2p.Protocol {
+	return []p2p.Protocol{{
+		Name:    protocolName,
+		Version: protocolVersion,
+		Length:  protocolLength,
+		Run:     ptm.runPeer,
+		NodeInfo: func() interface{} {
+			return &NodeInfo{Keys: []string{ptm.PublicKey()}}
+		},
+		PeerInfo: func(id enode.ID) interface{} {
+			if p := ptm.peerById(id); p != nil {
+				return &NodeInfo{Keys: toBase64s(p.keys)}
+			}
+			return nil
+		},
+	}}
+}
+
+// runPeer exchanges keys with the peer and then serves its messages until it disconnects
+func (ptm *PrivateTransactionManager) runPeer(p *p2p.Peer, rw p2p.MsgReadWriter) error {
+	keys, err := ptm.handshake(rw)
+	if err != nil {
+		return err
+	}
+	peer := &peer{Peer: p, rw: rw, keys: keys}
+	if err := ptm.registerPeer(peer); err != nil {
+		log.Warn("Rejecting ptm peer", "peer", p.ID(), "err", err)
+		return err
+	}
+	defer ptm.unregisterPeer(peer)
+
+	for {
+		if err := ptm.handleMsg(peer); err != nil {
+			log.Debug("ptm peer disconnected", "peer", p.ID(), "err", err)
+			return err
+		}
+	}
+}
+
+// handshake exchanges the keys with the peer. Each side challenges the other to box
+// a random value with every key it announced, so a peer can't claim keys it doesn't
+// hold and receive the payloads for them.
+func (ptm *PrivateTransactionManager) handshake(rw p2p.MsgReadWriter) ([][]byte, error) {
+	challenge := make([]byte, challengeSize)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, err
+	}
+	timeout := time.NewTimer(handshakeTimeout)
+	defer timeout.Stop()
+
+	status := new(statusData)
+	ours := &statusData{Keys: [][]byte{ptm.key.Public[:]}, Challenge: challenge}
+	if err := exchange(rw, timeout.C, statusMsg, ours, func(msg p2p.Msg) error { return readStatus(msg, status) }); err != nil {
+		return nil, err
+	}
+	proof, err := ptm.prove(status)
+	if err != nil {
+		return nil, err
+	}
+	remoteProof := new(proofData)
+	if err := exchange(rw, timeout.C, proofMsg, proof, func(msg p2p.Msg) error { return msg.Decode(remoteProof) }); err != nil {
+		return nil, err
+	}
+	if err := ptm.verifyProof(status.Keys, challenge, remoteProof); err != nil {
+		return nil, err
+	}
+	return status.Keys, nil
+}
+
+// exchange sends our message and reads the one of the peer, with the given code
+func exchange(rw p2p.MsgReadWriter, timeout <-chan time.Time, code uint64, data interface{}, read func(p2p.Msg) error) error {
+	errc := make(chan error, 2)
+	go func() {
+		errc <- p2p.Send(rw, code, data)
+	}()
+	go func() {
+		msg, err := rw.ReadMsg()
+		if err != nil {
+			errc <- err
+			return
+		}
+		defer msg.Discard()
+
+		if msg.Code != code {
+			errc <- fmt.Errorf("expected ptm message %d, got %d", code, msg.Code)
+			return
+		}
+		errc <- read(msg)
+	}()
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-errc:
+			if err != nil {
+				return err
+			}
+		case <-timeout:
+			return errHandshakeTimeout
+		}
+	}
+	return nil
+}
+
+func readStatus(msg p2p.Msg, status *statusData) error {
+	if err := msg.Decode(status); err != nil {
+		return fmt.Errorf("invalid ptm status: %v", err)
+	}
+	if len(status.Keys) == 0 {
+		return errors.New("invalid ptm status: no keys")
+	}
+	for _, key := range status.Keys {
+		if len(key) != keySize {
+			return fmt.Errorf("invalid ptm key of %d bytes", len(key))
+		}
+	}
+	if len(status.Challenge) != challengeSize {
+		return fmt.Errorf("invalid ptm challenge of %d bytes", len(status.Challenge))
+	}
+	return nil
+}
+
+// prove boxes the challenge of the peer from our key to the first key of the peer
+func (ptm *PrivateTransactionManager) prove(status *statusData) (*proofData, error) {
+	var (
+		nonce     [nonceSize]byte
+		remoteKey [keySize]byte
+	)
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, err
+	}
+	copy(remoteKey[:], status.Keys[0])
+	return &proofData{
+		Nonce: nonce[:],
+		Boxes: [][]byte{box.Seal(nil, status.Challenge, &nonce, &remoteKey, &ptm.key.Private)},
+	}, nil
+}
+
+// verifyProof checks the peer boxed our challenge with each of the keys it announced.
+// Only the holder of the private key, or we, could have sealed a box from the key to
+// ours, and we didn't.
+func (ptm *PrivateTransactionManager) verifyProof(keys [][]byte, challenge []byte, proof *proofData) error {
+	if len(proof.Nonce) != nonceSize || len(proof.Boxes) != len(keys) {
+		return errKeyNotProven
+	}
+	var nonce [nonceSize]byte
+	copy(nonce[:], proof.Nonce)
+	for i, key := range keys {
+		if ptm.isOurs(key) {
+			return errKeyNotProven
+		}
+		var remoteKey [keySize]byte
+		copy(remoteKey[:], key)
+		opened, ok := box.Open(nil, proof.Boxes[i], &nonce, &remoteKey, &ptm.key.Private)
+		if !ok || !bytes.Equal(opened, challenge) {
+			return errKeyNotProven
+		}
+	}
+	return nil
+}
+
+func (ptm *PrivateTransactionManager) handleMsg(p *peer) error {
+	msg, err := p.rw.ReadMsg()
+	if err != nil {
+		return err
+	}
+	defer msg.Discard()
+
+	if msg.Size > protocolMaxMsgSize {
+		return fmt.Errorf("ptm message too large: %v > %v", msg.Size, protocolMaxMsgSize)
+	}
+	switch msg.Code {
+	case payloadMsg:
+		var data payloadData
+		if err := msg.Decode(&data); err != nil {
+			return fmt.Errorf("invalid ptm payload: %v", err)
+		}
+		if data.Payload == nil {
+			return errors.New("invalid ptm payload: missing payload")
+		}
+		hash := data.Payload.hash()
+		if err := ptm.storeReceived(p, hash, data.Payload); err != nil {
+			log.Warn("Dropping private payload", "peer", p.ID(), "hash", hash.TerminalString(), "err", err)
+			return nil
+		}
+		log.Debug("Received private payload", "peer", p.ID(), "hash", hash.TerminalString())
+		return p2p.Send(p.rw, ackMsg, &ackData{Id: data.Id})
+
+	case ackMsg:
+		var data ackData
+		if err := msg.Decode(&data); err != nil {
+			return fmt.Errorf("invalid ptm ack: %v", err)
+		}
+		// only the peer we sent the payload to can acknowledge it
+		key := ackKey{peer: p.ID(), id: data.Id}
+		ptm.acksMu.Lock()
+		if ack, ok := ptm.acks[key]; ok {
+			close(ack)
+			delete(ptm.acks, key)
+		}
+		ptm.acksMu.Unlock()
+		return nil
+
+	default:
+		return fmt.Errorf("unknown ptm message code %d", msg.Code)
+	}
+}
+
+// storeReceived stores a payload sent by the peer. The payload must be for us and
+// from one of the keys of the peer, which the box of our key authenticates. A
+// payload we already have is only replaced by its own sender, and what the peer
+// has us store, including the growth of replaced payloads, counts towards its
+// storage limit.
+func (ptm *PrivateTransactionManager) storeReceived(p *peer, hash common.EncryptedPayloadHash, payload *encryptedPayload) error {
+	if !containsKey(p.keys, payload.Sender) {
+		return errSenderNotPeer
+	}
+	if _, err := ptm.openMasterKey(payload); err != nil {
+		return err
+	}
+	ptm.receiveMu.Lock()
+	defer ptm.receiveMu.Unlock()
+
+	enc, err := rlp.EncodeToBytes(payload)
+	if err != nil {
+		return err
+	}
+	var size uint64
+	existing, err := ptm.db.Get(append(payloadPrefix, hash.Bytes()...))
+	if err == nil {
+		stored := new(encryptedPayload)
+		if err := rlp.DecodeBytes(existing, stored); err != nil {
+			return fmt.Errorf("invalid stored payload %s: %v", hash.TerminalString(), err)
+		}
+		if !bytes.Equal(stored.Sender, payload.Sender) {
+			return errSenderMismatch
+		}
+		if bytes.Equal(existing, enc) {
+			return nil
+		}
+		size = uint64(len(existing))
+	}
+	usageKey := append(usagePrefix, p.ID().Bytes()...)
+	var usage uint64
+	if enc, err := ptm.db.Get(usageKey); err == nil && len(enc) == 8 {
+		usage = binary.BigEndian.Uint64(enc)
+	}
+	// a replaced payload only counts with what it grew by
+	usage += uint64(len(enc))
+	if usage > size {
+		usage -= size
+	} else {
+		usage = 0
+	}
+	if usage > ptm.storageLimit {
+		return errPeerStorageExceeded
+	}
+	if err := ptm.db.Put(append(payloadPrefix, hash.Bytes()...), enc); err != nil {
+		return err
+	}
+	usageEnc := make([]byte, 8)
+	binary.BigEndian.PutUint64(usageEnc, usage)
+	return ptm.db.Put(usageKey, usageEnc)
+}
+
+// distribute sends the payload to the nodes of the recipients and waits until
+// they stored it, so they can apply the transaction once it's in a block
+func (ptm *PrivateTransactionManager) distribute(payload *encryptedPayload) error {
+	for i, recipient := range payload.RecipientKeys {
+		if ptm.isOurs(recipient) {
+			continue
+		}
+		key := base64.StdEncoding.EncodeToString(recipient)
+		ptm.peersMu.RLock()
+		p := ptm.peers[key]
+		ptm.peersMu.RUnlock()
+		if p == nil {
+			return fmt.Errorf("recipient %s is not connected", key)
+		}
+
+		ptm.acksMu.Lock()
+		ptm.ackId++
+		id, ack := ackKey{peer: p.ID(), id: ptm.ackId}, make(chan struct{})
+		ptm.acks[id] = ack
+		ptm.acksMu.Unlock()
+
+		err := p2p.Send(p.rw, payloadMsg, &payloadData{Id: id.id, Payload: payload.forRecipient(i)})
+		if err == nil {
+			select {
+			case <-ack:
+			case <-time.After(ackTimeout):
+				err = errAckTimeout
+			}
+		}
+		if err != nil {
+			ptm.acksMu.Lock()
+			delete(ptm.acks, id)
+			ptm.acksMu.Unlock()
+			return fmt.Errorf("unable to send payload to %s: %v", key, err)
+		}
+	}
+	return nil
+}
+
+// registerPeer makes the peer the recipient of its keys. A key can only be held by
+// one connected peer, later claims are rejected until that peer disconnects.
+func (ptm *PrivateTransactionManager) registerPeer(p *peer) error {
+	ptm.peersMu.Lock()
+	defer ptm.peersMu.Unlock()
+
+	keys := toBase64s(p.keys)
+	for _, key := range keys {
+		if existing, ok := ptm.peers[key]; ok && existing != p {
+			return fmt.Errorf("%w: %s by %s", errKeyClaimed, key, existing.ID().TerminalString())
+		}
+	}
+	for _, key := range keys {
+		ptm.peers[key] = p
+	}
+	return nil
+}
+
+func (ptm *PrivateTransactionManager) unregisterPeer(p *peer) {
+	ptm.peersMu.Lock()
+	defer ptm.peersMu.Unlock()
+
+	for _, key := range toBase64s(p.keys) {
+		if ptm.peers[key] == p {
+			delete(ptm.peers, key)
+		}
+	}
+}
+
+func containsKey(keys [][]byte, key []byte) bool {
+	for _, k := range keys {
+		if bytes.Equal(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (ptm *PrivateTransactionManager) peerById(id enode.ID) *peer {
+	ptm.peersMu.RLock()
+	defer ptm.peersMu.RUnlock()
+
+	for _, p := range ptm.peers {
+		if p.ID() == id {
+			return p
+		}
+	}
+	return nil
+}
@@ -60,7 +60,7 @@ func (t *tesseraPrivateTxManager) submitJSON(method, path string, request interf
 	}
 	res, err := t.client.HttpClient.Do(req)
 	if err != nil {
-		return -1, fmt.Errorf("unable to submit request (method:%s,path:%s). Cause: %v", method, path, err)
+		return -1, fmt.Errorf("unable to submit request (method:%s,path:%s). Cause: %w", method, path, err)
 	}
 	defer res.Body.Close()
 	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated {
@@ -81,7 +81,7 @@ func (t *tesseraPrivateTxManager) submitJSONOld(method, path string, request int
 	}
 	res, err := t.client.HttpClient.Do(req)
 	if err != nil {
-		return -1, fmt.Errorf("unable to submit request (method:%s,path:%s). Cause: %v", method, path, err)
+		return -1, fmt.Errorf("unable to submit request (method:%s,path:%s). Cause: %w", method, path, err)
 	}
 	defer res.Body.Close()
 	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated {
@@ -488,6 +488,22 @@ func (t *tesseraPrivateTxManager) Name() string {
 	return "Tessera"
 }
 
+// Health reports the endpoints of the connection to Tessera
+func (t *tesseraPrivateTxManager) Health() []engine.EndpointHealth {
+	if t.client.Monitor == nil {
+		return nil
+	}
+	return t.client.Monitor.Health()
+}
+
+// Close stops the health checks of the connection to Tessera
+func (t *tesseraPrivateTxManager) Close() error {
+	if t.client.Monitor != nil {
+		t.client.Monitor.Close()
+	}
+	return nil
+}
+
 func (t *tesseraPrivateTxManager) HasFeature(f engine.PrivateTransactionManagerFeature) bool {
 	return t.features.HasFeature(f)
 }
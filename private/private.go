@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 
@@ -13,6 +14,7 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/private/engine"
 	"github.com/ethereum/go-ethereum/private/engine/constellation"
+	"github.com/ethereum/go-ethereum/private/engine/embedded"
 	"github.com/ethereum/go-ethereum/private/engine/notinuse"
 	"github.com/ethereum/go-ethereum/private/engine/tessera"
 )
@@ -49,6 +51,40 @@ type PrivateTransactionManager interface {
 	Groups() ([]engine.PrivacyGroup, error)
 }
 
+// HealthReporter is implemented by the private transaction managers that track the
+// health of their endpoints
+type HealthReporter interface {
+	Health() []engine.EndpointHealth
+}
+
+// Health is the state of the private transaction manager the node uses
+type Health struct {
+	Name      string                  `json:"name"`
+	Enabled   bool                    `json:"enabled"`
+	Available bool                    `json:"available"` // whether any endpoint is available
+	Endpoints []engine.EndpointHealth `json:"endpoints,omitempty"`
+}
+
+// GetHealth reports the state of the private transaction manager
+func GetHealth() *Health {
+	if P == nil {
+		return &Health{}
+	}
+	health := &Health{
+		Name:      P.Name(),
+		Enabled:   isPrivacyEnabled,
+		Available: isPrivacyEnabled,
+	}
+	if reporter, ok := P.(HealthReporter); ok {
+		health.Endpoints = reporter.Health()
+		health.Available = false
+		for _, endpoint := range health.Endpoints {
+			health.Available = health.Available || endpoint.Available
+		}
+	}
+	return health
+}
+
 // This loads any config specified via the legacy environment variable
 func GetLegacyEnvironmentConfig() (http2.Config, error) {
 	return FromEnvironmentOrNil("PRIVATE_CONFIG")
@@ -65,6 +101,12 @@ func FromEnvironmentOrNil(name string) (http2.Config, error) {
 }
 
 func InitialiseConnection(cfg http2.Config) error {
+	// release what the previous manager holds, e.g. the store of the embedded one
+	if closer, ok := P.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			return err
+		}
+	}
 	var err error
 	P, err = NewPrivateTxManager(cfg)
 	return err
@@ -81,6 +123,15 @@ func NewPrivateTxManager(cfg http2.Config) (PrivateTransactionManager, error) {
 		return &notinuse.PrivateTransactionManager{}, nil
 	}
 
+	if cfg.ConnectionType == http2.EmbeddedConnection {
+		ptm, err := embedded.New(cfg.WorkDir)
+		if err != nil {
+			return nil, fmt.Errorf("unable to start embedded private tx manager due to: %s", err)
+		}
+		isPrivacyEnabled = true
+		return ptm, nil
+	}
+
 	client, err := http2.CreateClient(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("unable to create connection to private tx manager due to: %s", err)
@@ -88,6 +139,9 @@ func NewPrivateTxManager(cfg http2.Config) (PrivateTransactionManager, error) {
 
 	ptm, err := selectPrivateTxManager(client)
 	if err != nil {
+		if client.Monitor != nil {
+			client.Monitor.Close()
+		}
 		return nil, fmt.Errorf("unable to connect to private tx manager due to: %s", err)
 	}
 
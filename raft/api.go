@@ -14,6 +14,7 @@ type RaftNodeInfo struct {
 	RemovedPeerIds []uint16   `json:"removedPeerIds"`
 	AppliedIndex   uint64     `json:"appliedIndex"`
 	SnapshotIndex  uint64     `json:"snapshotIndex"`
+	Maintenance    bool       `json:"maintenance"`
 }
 
 type PublicRaftAPI struct {
@@ -71,6 +72,58 @@ func (s *PublicRaftAPI) RemovePeer(raftId uint16) error {
 	return s.raftService.raftProtocolManager.ProposePeerRemoval(raftId)
 }
 
+// TransferLeadership hands leadership to the given voting peer and waits for it
+// to take over
+func (s *PublicRaftAPI) TransferLeadership(raftId uint16) (bool, error) {
+	if err := s.checkIfNodeInCluster(); err != nil {
+		return false, err
+	}
+	if err := s.raftService.raftProtocolManager.TransferLeadership(raftId); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// StartMaintenance stops the node from minting and hands off leadership, so that
+// it can be restarted without an election timeout
+func (s *PublicRaftAPI) StartMaintenance() (bool, error) {
+	if err := s.checkIfNodeInCluster(); err != nil {
+		return false, err
+	}
+	if err := s.raftService.raftProtocolManager.StartMaintenance(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// StopMaintenance lets the node become leader again
+func (s *PublicRaftAPI) StopMaintenance() (bool, error) {
+	if err := s.checkIfNodeInCluster(); err != nil {
+		return false, err
+	}
+	s.raftService.raftProtocolManager.StopMaintenance()
+	return true, nil
+}
+
+// Health returns the readiness verdict of the node, usable by load balancers
+func (s *PublicRaftAPI) Health() *RaftHealth {
+	return s.raftService.raftProtocolManager.Health()
+}
+
+// SetMintingPolicy replaces the policy this node mints blocks with while it's the
+// leader, from the next block on
+func (s *PublicRaftAPI) SetMintingPolicy(policy MintingPolicy) (bool, error) {
+	if err := s.raftService.minter.setPolicy(policy); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// MintingPolicy returns the policy this node mints blocks with
+func (s *PublicRaftAPI) MintingPolicy() MintingPolicy {
+	return s.raftService.minter.getPolicy()
+}
+
 func (s *PublicRaftAPI) Leader() (string, error) {
 
 	addr, err := s.raftService.raftProtocolManager.LeaderAddress()
@@ -114,7 +167,10 @@ func (s *PublicRaftAPI) Cluster() ([]ClusterInfo, error) {
 				role = "verifier"
 			}
 		}
-		clustInfo[i] = ClusterInfo{*a, role, s.checkIfNodeIsActive(a.RaftId)}
+		clustInfo[i] = ClusterInfo{*a, role, s.checkIfNodeIsActive(a.RaftId), nil}
+		if role == "learner" {
+			clustInfo[i].Promotion = s.raftService.raftProtocolManager.learnerPromotion(a.RaftId)
+		}
 	}
 	return clustInfo, nil
 }
@@ -2,6 +2,7 @@ package raft
 
 import (
 	"crypto/ecdsa"
+	"fmt"
 	"sync"
 	"time"
 
@@ -37,9 +38,11 @@ type RaftService struct {
 	calcGasLimitFunc func(block *types.Block) uint64
 
 	pendingLogsFeed *event.Feed
+
+	handOffOnStop bool // Whether to hand off leadership before stopping
 }
 
-func New(stack *node.Node, chainConfig *params.ChainConfig, raftId, raftPort uint16, joinExisting bool, blockTime time.Duration, e *eth.Ethereum, startPeers []*enode.Node, raftLogDir string, useDns bool) (*RaftService, error) {
+func New(stack *node.Node, chainConfig *params.ChainConfig, raftId, raftPort uint16, joinExisting bool, blockTime time.Duration, e *eth.Ethereum, startPeers []*enode.Node, raftLogDir string, useDns bool, compressSnapshots bool, handOffOnStop bool, promotion PromotionPolicy, minting MintingPolicy) (*RaftService, error) {
 	service := &RaftService{
 		eventMux:         stack.EventMux(),
 		chainDb:          e.ChainDb(),
@@ -51,12 +54,16 @@ func New(stack *node.Node, chainConfig *params.ChainConfig, raftId, raftPort uin
 		nodeKey:          stack.GetNodeKey(),
 		calcGasLimitFunc: e.CalcGasLimit,
 		pendingLogsFeed:  e.ConsensusServicePendingLogsFeed(),
+		handOffOnStop:    handOffOnStop,
 	}
 
-	service.minter = newMinter(chainConfig, service, blockTime)
+	if err := minting.validate(); err != nil {
+		return nil, fmt.Errorf("invalid minting policy: %v", err)
+	}
+	service.minter = newMinter(chainConfig, service, blockTime, minting)
 
 	var err error
-	if service.raftProtocolManager, err = NewProtocolManager(raftId, raftPort, service.blockchain, service.eventMux, startPeers, joinExisting, raftLogDir, service.minter, service.downloader, useDns, stack.Server()); err != nil {
+	if service.raftProtocolManager, err = NewProtocolManager(raftId, raftPort, service.blockchain, service.eventMux, startPeers, joinExisting, raftLogDir, service.minter, service.downloader, useDns, compressSnapshots, promotion, stack.Server()); err != nil {
 		return nil, err
 	}
 
@@ -100,6 +107,12 @@ func (service *RaftService) Start() error {
 // Stop implements node.Service, stopping the background data propagation thread
 // of the protocol.
 func (service *RaftService) Stop() error {
+	// if asked to, hand off leadership first so the cluster doesn't wait for an election timeout
+	if pm := service.raftProtocolManager; service.handOffOnStop && pm.unsafeRawNode != nil {
+		if err := pm.handOffLeadership(); err != nil && err != errNoTransferee {
+			log.Warn("failed to hand off raft leadership before stopping", "err", err)
+		}
+	}
 	service.blockchain.Stop()
 	service.raftProtocolManager.Stop()
 	service.minter.stop()
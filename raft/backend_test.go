@@ -33,7 +33,7 @@ func Test_New_RegistersEthServicePendingLogsFeed(t *testing.T) {
 		_ = os.RemoveAll(tmpWorkingDir)
 	}()
 
-	raftService, err := New(stack, &params.ChainConfig{}, 0, 0, false, time.Second, ethService, nil, tmpWorkingDir, false)
+	raftService, err := New(stack, &params.ChainConfig{}, 0, 0, false, time.Second, ethService, nil, tmpWorkingDir, false, false, false, PromotionPolicy{}, MintingPolicy{})
 	if err != nil {
 		t.Fatalf("failed to create raft service, err = %v", err)
 	}
@@ -16,6 +16,9 @@ const (
 	// Raft's ticker interval
 	tickerMS = 100
 
+	// Number of ticks without hearing from the leader before a follower starts an election
+	electionTicks = 10
+
 	// We use a bounded channel of constant size buffering incoming messages
 	//msgChanSize = 1000
 
@@ -28,6 +31,9 @@ const (
 	//peerUrlKeyPrefix = "peerUrl-"
 
 	chainExtensionMessage = "Successfully extended chain"
+
+	// Marks snappy compressed snapshot data, RLP lists start at 0xc0
+	snapshotCompressedPrefix = 0x01
 )
 
 var (
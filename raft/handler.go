@@ -48,6 +48,7 @@ type ProtocolManager struct {
 	// Local peer state (protected by mu vs concurrent access via JS)
 	address       *Address
 	role          int    // Role: minter or verifier
+	maintenance   bool   // Whether we hand off leadership whenever we get it
 	appliedIndex  uint64 // The index of the last-applied raft entry
 	snapshotIndex uint64 // The index of the latest snapshot.
 
@@ -55,6 +56,7 @@ type ProtocolManager struct {
 	leader       uint16
 	peers        map[uint16]*Peer
 	removedPeers mapset.Set // *Permanently removed* peers
+	promoter     *promoter  // Promotes caught up learners while we lead
 
 	// P2P transport
 	p2pServer *p2p.Server
@@ -71,6 +73,7 @@ type ProtocolManager struct {
 
 	// Raft proposal events
 	blockProposalC      chan *types.Block      // for mined blocks to raft
+	proposals           *proposalTracker       // when we proposed the blocks not yet applied
 	confChangeProposalC chan raftpb.ConfChange // for config changes from js console to raft
 
 	// Raft transport
@@ -80,9 +83,10 @@ type ProtocolManager struct {
 	httpdonec     chan struct{}
 
 	// Raft snapshotting
-	snapshotter *snap.Snapshotter
-	snapdir     string
-	confState   raftpb.ConfState
+	snapshotter       *snap.Snapshotter
+	snapdir           string
+	confState         raftpb.ConfState
+	compressSnapshots bool // Whether snapshots we take are snappy compressed
 
 	// Raft write-ahead log
 	waldir string
@@ -99,7 +103,7 @@ var errNoLeaderElected = errors.New("no leader is currently elected")
 // Public interface
 //
 
-func NewProtocolManager(raftId uint16, raftPort uint16, blockchain *core.BlockChain, mux *event.TypeMux, bootstrapNodes []*enode.Node, joinExisting bool, raftLogDir string, minter *minter, downloader *downloader.Downloader, useDns bool, p2pServer *p2p.Server) (*ProtocolManager, error) {
+func NewProtocolManager(raftId uint16, raftPort uint16, blockchain *core.BlockChain, mux *event.TypeMux, bootstrapNodes []*enode.Node, joinExisting bool, raftLogDir string, minter *minter, downloader *downloader.Downloader, useDns bool, compressSnapshots bool, promotion PromotionPolicy, p2pServer *p2p.Server) (*ProtocolManager, error) {
 	waldir := fmt.Sprintf("%s/raft-wal", raftLogDir)
 	snapdir := fmt.Sprintf("%s/raft-snap", raftLogDir)
 	quorumRaftDbLoc := fmt.Sprintf("%s/quorum-raft-state", raftLogDir)
@@ -113,6 +117,7 @@ func NewProtocolManager(raftId uint16, raftPort uint16, blockchain *core.BlockCh
 		blockchain:          blockchain,
 		eventMux:            mux,
 		blockProposalC:      make(chan *types.Block, 10),
+		proposals:           newProposalTracker(),
 		confChangeProposalC: make(chan raftpb.ConfChange),
 		httpstopc:           make(chan struct{}),
 		httpdonec:           make(chan struct{}),
@@ -126,6 +131,8 @@ func NewProtocolManager(raftId uint16, raftPort uint16, blockchain *core.BlockCh
 		minter:              minter,
 		downloader:          downloader,
 		useDns:              useDns,
+		compressSnapshots:   compressSnapshots,
+		promoter:            newPromoter(promotion),
 		p2pServer:           p2pServer,
 	}
 
@@ -227,6 +234,7 @@ func (pm *ProtocolManager) NodeInfo() *RaftNodeInfo {
 		RemovedPeerIds: removedPeerIds,
 		AppliedIndex:   pm.appliedIndex,
 		SnapshotIndex:  pm.snapshotIndex,
+		Maintenance:    pm.maintenance,
 	}
 }
 
@@ -424,6 +432,8 @@ func (pm *ProtocolManager) IsIDRemoved(id uint64) bool {
 
 func (pm *ProtocolManager) ReportUnreachable(id uint64) {
 	log.Info("peer is currently unreachable", "peer id", id)
+	unreachableMeter.Mark(1)
+	peerUnreachableMeter(uint16(id)).Mark(1)
 
 	pm.rawNode().ReportUnreachable(id)
 }
@@ -540,8 +550,8 @@ func (pm *ProtocolManager) startRaft() {
 	raftConfig := &etcdRaft.Config{
 		Applied:       lastAppliedIndex,
 		ID:            uint64(pm.raftId),
-		ElectionTick:  10, // NOTE: cockroach sets this to 15
-		HeartbeatTick: 1,  // NOTE: cockroach sets this to 5
+		ElectionTick:  electionTicks, // NOTE: cockroach sets this to 15
+		HeartbeatTick: 1,             // NOTE: cockroach sets this to 5
 		Storage:       pm.raftStorage,
 
 		// NOTE, from cockroach:
@@ -603,6 +613,7 @@ func (pm *ProtocolManager) startRaft() {
 	go pm.serveLocalProposals()
 	go pm.eventLoop()
 	go pm.handleRoleChange(pm.rawNode().RoleChan().Out())
+	go pm.promotionLoop()
 }
 
 func (pm *ProtocolManager) setLocalAddress(addr *Address) {
@@ -630,7 +641,7 @@ func (pm *ProtocolManager) serveRaft() {
 	if err != nil {
 		fatalf("Failed to listen rafthttp (%v)", err)
 	}
-	err = (&http.Server{Handler: pm.transport.Handler()}).Serve(listener)
+	err = (&http.Server{Handler: pm.httpHandler()}).Serve(listener)
 	select {
 	case <-pm.httpstopc:
 	default:
@@ -678,9 +689,12 @@ func (pm *ProtocolManager) handleRoleChange(roleC <-chan interface{}) {
 			if !ok {
 				panic("Couldn't cast role to int")
 			}
+			handOff := false
 			if intRole == minterRole {
 				log.EmitCheckpoint(log.BecameMinter)
-				pm.minter.start()
+				if handOff = pm.InMaintenance(); !handOff {
+					pm.minter.start()
+				}
 			} else { // verifier
 				if pm.isVerifierNode() {
 					log.EmitCheckpoint(log.BecameVerifier)
@@ -688,11 +702,20 @@ func (pm *ProtocolManager) handleRoleChange(roleC <-chan interface{}) {
 					log.EmitCheckpoint(log.BecameLearner)
 				}
 				pm.minter.stop()
+				pm.proposals.reset()
 			}
 
 			pm.mu.Lock()
 			pm.role = intRole
 			pm.mu.Unlock()
+
+			if handOff {
+				go func() {
+					if err := pm.handOffLeadership(); err != nil {
+						log.Warn("failed to hand off leadership in maintenance mode", "err", err)
+					}
+				}()
+			}
 		case <-pm.quitSync:
 			return
 		}
@@ -735,6 +758,7 @@ func (pm *ProtocolManager) serveLocalProposals() {
 			r.Read(buffer)
 
 			// blocks until accepted by the raft state machine
+			pm.proposals.propose(block.Hash())
 			pm.rawNode().Propose(context.TODO(), buffer)
 		case cc, ok := <-pm.confChangeProposalC:
 			if !ok {
@@ -897,6 +921,7 @@ func (pm *ProtocolManager) eventLoop() {
 							// stop eventloop
 							return
 						}
+						pm.proposals.apply(block.Hash())
 					}
 
 				case raftpb.EntryConfChange:
@@ -969,6 +994,7 @@ func (pm *ProtocolManager) eventLoop() {
 			}
 
 			pm.maybeTriggerSnapshot()
+			pm.updateMetrics(rd.HardState)
 
 			if exitAfterApplying {
 				log.Warn("permanently removing self from the cluster")
@@ -1025,6 +1051,7 @@ func (pm *ProtocolManager) applyNewChainHead(block *types.Block) bool {
 		headBlock := pm.blockchain.CurrentBlock()
 
 		log.Info("Non-extending block", "block", block.Hash(), "parent", block.ParentHash(), "head", headBlock.Hash())
+		invalidOrderingMeter.Mark(1)
 
 		pm.minter.invalidRaftOrderingChan <- InvalidRaftOrdering{headBlock: headBlock, invalidBlock: block}
 	} else {
@@ -1066,6 +1093,9 @@ func (pm *ProtocolManager) updateLeader(leader uint64) {
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
 
+	if uint16(leader) != pm.leader && leader != etcdRaft.None {
+		leaderChangeMeter.Mark(1)
+	}
 	pm.leader = uint16(leader)
 }
 
@@ -131,6 +131,7 @@ func nextPort(t *testing.T) uint16 {
 	if err != nil {
 		t.Fatal(err)
 	}
+	defer listener.Close()
 	return uint16(listener.Addr().(*net.TCPAddr).Port)
 }
 
@@ -152,6 +153,10 @@ func prepareServiceContext(key *ecdsa.PrivateKey) (stack *node.Node, cfg *node.C
 }
 
 func startRaftNode(id, port uint16, tmpWorkingDir string, key *ecdsa.PrivateKey, nodes []*enode.Node) (*RaftService, error) {
+	return startRaftNodeWithPolicy(id, port, tmpWorkingDir, key, nodes, false, PromotionPolicy{})
+}
+
+func startRaftNodeWithPolicy(id, port uint16, tmpWorkingDir string, key *ecdsa.PrivateKey, nodes []*enode.Node, joinExisting bool, promotion PromotionPolicy) (*RaftService, error) {
 	raftlogdir := fmt.Sprintf("%s/node%d", tmpWorkingDir, id)
 
 	stack, _, err := prepareServiceContext(key)
@@ -166,7 +171,7 @@ func startRaftNode(id, port uint16, tmpWorkingDir string, key *ecdsa.PrivateKey,
 		return nil, err
 	}
 
-	s, err := New(stack, params.QuorumTestChainConfig, id, port, false, 100*time.Millisecond, e, nodes, raftlogdir, false)
+	s, err := New(stack, params.QuorumTestChainConfig, id, port, joinExisting, 100*time.Millisecond, e, nodes, raftlogdir, false, false, false, promotion, MintingPolicy{})
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,71 @@
+package raft
+
+import (
+	"fmt"
+
+	raftTypes "github.com/coreos/etcd/pkg/types"
+	etcdRaft "github.com/coreos/etcd/raft"
+)
+
+// Number of committed entries a node may lag behind in applying and still be healthy
+const maxHealthyApplyLag = 100
+
+// RaftHealth is the readiness verdict of a raft node, with what it was based on
+type RaftHealth struct {
+	Healthy      bool     `json:"healthy"`
+	Role         string   `json:"role"`
+	Leader       uint16   `json:"leader"`
+	Term         uint64   `json:"term"`
+	CommitIndex  uint64   `json:"commitIndex"`
+	AppliedIndex uint64   `json:"appliedIndex"`
+	ActivePeers  int      `json:"activePeers"` // voting peers we are connected to, including us
+	Voters       int      `json:"voters"`
+	Maintenance  bool     `json:"maintenance"`
+	Reasons      []string `json:"reasons,omitempty"` // why the node isn't healthy
+}
+
+// Health reports whether the node is ready to serve: it's part of the cluster,
+// a leader is elected, it reaches a quorum of voters, it has applied what raft
+// committed and it isn't in maintenance mode
+func (pm *ProtocolManager) Health() *RaftHealth {
+	status := pm.rawNode().Status()
+	nodeInfo := pm.NodeInfo()
+
+	pm.mu.RLock()
+	voters := append([]uint64(nil), pm.confState.Nodes...)
+	stopped := pm.stopped
+	pm.mu.RUnlock()
+
+	health := &RaftHealth{
+		Role:         nodeInfo.Role,
+		Leader:       uint16(status.Lead),
+		Term:         status.Term,
+		CommitIndex:  status.Commit,
+		AppliedIndex: nodeInfo.AppliedIndex,
+		Voters:       len(voters),
+		Maintenance:  nodeInfo.Maintenance,
+	}
+	for _, id := range voters {
+		if uint16(id) == pm.raftId || !pm.transport.ActiveSince(raftTypes.ID(id)).IsZero() {
+			health.ActivePeers++
+		}
+	}
+
+	if stopped || pm.isRaftIdRemoved(pm.raftId) {
+		health.Reasons = append(health.Reasons, "node is not part of the raft cluster")
+	}
+	if status.Lead == etcdRaft.None {
+		health.Reasons = append(health.Reasons, errNoLeaderElected.Error())
+	}
+	if health.ActivePeers <= health.Voters/2 {
+		health.Reasons = append(health.Reasons, fmt.Sprintf("only %d of %d voters reachable", health.ActivePeers, health.Voters))
+	}
+	if health.CommitIndex > health.AppliedIndex+maxHealthyApplyLag {
+		health.Reasons = append(health.Reasons, fmt.Sprintf("applied index %d lags commit index %d", health.AppliedIndex, health.CommitIndex))
+	}
+	if health.Maintenance {
+		health.Reasons = append(health.Reasons, "node is in maintenance mode")
+	}
+	health.Healthy = len(health.Reasons) == 0
+	return health
+}
@@ -0,0 +1,173 @@
+package raft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	raftTypes "github.com/coreos/etcd/pkg/types"
+	etcdRaft "github.com/coreos/etcd/raft"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// etcd raft gives up on a transfer after an election timeout, we wait for two
+	leadershipTransferTimeout = 2 * electionTicks * tickerMS * time.Millisecond
+
+	// How long we wait for the blocks we minted to be applied before handing off
+	minterDrainTimeout = 5 * time.Second
+)
+
+var (
+	errNotLeader                = errors.New("only the leader can hand leadership to another peer")
+	errNoTransferee             = errors.New("no active peer to hand leadership to")
+	errLeadershipTransferFailed = errors.New("leadership transfer timed out")
+)
+
+// TransferLeadership asks raft to hand leadership to the given peer and waits for
+// it to take over. It must be called on the leader, or on the peer taking over:
+// followers forward the request to the leader as coming from themselves.
+func (pm *ProtocolManager) TransferLeadership(raftId uint16) error {
+	if pm.isLearnerNode() {
+		return errors.New("learner node can't transfer leadership")
+	}
+	if pm.isRaftIdRemoved(raftId) {
+		return fmt.Errorf("%d has been removed from the cluster", raftId)
+	}
+	if !pm.isVerifier(raftId) {
+		return fmt.Errorf("%d is not a voting peer, only voting peers can become leader", raftId)
+	}
+	if raftId != pm.raftId && pm.transport.ActiveSince(raftTypes.ID(raftId)).IsZero() {
+		return fmt.Errorf("%d is not active", raftId)
+	}
+
+	pm.mu.RLock()
+	leader := pm.leader
+	pm.mu.RUnlock()
+
+	if leader == uint16(etcdRaft.None) {
+		return errNoLeaderElected
+	}
+	if leader == raftId {
+		return nil
+	}
+	if leader != pm.raftId && raftId != pm.raftId {
+		return errNotLeader
+	}
+
+	log.Info("transferring raft leadership", "from", leader, "to", raftId)
+	ctx, cancel := context.WithTimeout(context.Background(), leadershipTransferTimeout)
+	defer cancel()
+
+	pm.rawNode().TransferLeadership(ctx, uint64(leader), uint64(raftId))
+	for {
+		pm.mu.RLock()
+		leader = pm.leader
+		pm.mu.RUnlock()
+
+		if leader == raftId {
+			log.Info("transferred raft leadership", "to", raftId)
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return errLeadershipTransferFailed
+		case <-time.After(tickerMS * time.Millisecond):
+		}
+	}
+}
+
+// StartMaintenance puts the node in maintenance mode ahead of a planned restart.
+// If it's the leader, it stops minting, waits for the blocks it proposed to be
+// applied and hands leadership to the most up to date peer. While in maintenance
+// it hands leadership off again whenever it gets elected.
+func (pm *ProtocolManager) StartMaintenance() error {
+	pm.mu.Lock()
+	pm.maintenance = true
+	pm.mu.Unlock()
+	log.Info("raft node entering maintenance mode")
+
+	if err := pm.handOffLeadership(); err != nil {
+		pm.StopMaintenance()
+		return err
+	}
+	return nil
+}
+
+// StopMaintenance lets the node lead the cluster again
+func (pm *ProtocolManager) StopMaintenance() {
+	pm.mu.Lock()
+	pm.maintenance = false
+	leading := pm.role == minterRole
+	pm.mu.Unlock()
+	log.Info("raft node leaving maintenance mode")
+
+	// if the hand-off failed we are still the leader, so go back to minting
+	if leading {
+		pm.minter.start()
+	}
+}
+
+// InMaintenance returns whether the node is in maintenance mode
+func (pm *ProtocolManager) InMaintenance() bool {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	return pm.maintenance
+}
+
+// handOffLeadership drains the minter and transfers leadership if we are the
+// leader, doing nothing otherwise
+func (pm *ProtocolManager) handOffLeadership() error {
+	pm.mu.RLock()
+	leading := pm.role == minterRole
+	pm.mu.RUnlock()
+
+	if !leading {
+		return nil
+	}
+	transferee, err := pm.nextLeader()
+	if err != nil {
+		return err
+	}
+	if !pm.minter.drain(minterDrainTimeout) {
+		log.Warn("not all minted blocks were applied before handing off leadership")
+	}
+	if err := pm.TransferLeadership(transferee); err != nil {
+		pm.minter.undrain()
+		return err
+	}
+	pm.minter.stop()
+	return nil
+}
+
+// nextLeader picks the active voting peer whose log is the most up to date,
+// so it can take over without catching up first. A leader only learns which
+// peers are active once they reply, so right after an election we wait for them.
+func (pm *ProtocolManager) nextLeader() (uint16, error) {
+	deadline := time.Now().Add(leadershipTransferTimeout)
+	for {
+		var (
+			best  uint16
+			match uint64
+		)
+		for id, progress := range pm.rawNode().Status().Progress {
+			raftId := uint16(id)
+			if raftId == pm.raftId || progress.IsLearner || !progress.RecentActive || pm.isRaftIdRemoved(raftId) {
+				continue
+			}
+			if best == 0 || progress.Match > match {
+				best, match = raftId, progress.Match
+			}
+		}
+		if best != 0 {
+			return best, nil
+		}
+		if time.Now().After(deadline) {
+			return 0, errNoTransferee
+		}
+		time.Sleep(tickerMS * time.Millisecond)
+	}
+}
@@ -0,0 +1,128 @@
+package raft
+
+import (
+	"crypto/ecdsa"
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+func startRaftCluster(t *testing.T, count int) []*RaftService {
+	tmpWorkingDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ports := make([]uint16, count)
+	nodeKeys := make([]*ecdsa.PrivateKey, count)
+	peers := make([]*enode.Node, count)
+	for i := 0; i < count; i++ {
+		ports[i] = nextPort(t)
+		nodeKeys[i] = mustNewNodeKey(t)
+		peers[i] = enode.NewV4Hostname(&(nodeKeys[i].PublicKey), net.IPv4(127, 0, 0, 1).String(), 0, 0, int(ports[i]))
+	}
+	raftNodes := make([]*RaftService, count)
+	for i := 0; i < count; i++ {
+		s, err := startRaftNode(uint16(i+1), ports[i], tmpWorkingDir, nodeKeys[i], peers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		raftNodes[i] = s
+	}
+	t.Cleanup(func() {
+		for _, s := range raftNodes {
+			s.Stop()
+		}
+		os.RemoveAll(tmpWorkingDir)
+	})
+	return raftNodes
+}
+
+// waits until all nodes agree on a leader that isn't excluded and returns its index
+func waitForLeader(t *testing.T, raftNodes []*RaftService, exclude uint16) int {
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+
+		leader, agreed := raftNodes[0].raftProtocolManager.leaderId(), true
+		for _, s := range raftNodes[1:] {
+			if s.raftProtocolManager.leaderId() != leader {
+				agreed = false
+			}
+		}
+		if !agreed || leader == 0 || leader == exclude {
+			continue
+		}
+		for i, s := range raftNodes {
+			if s.raftProtocolManager.raftId == leader && s.raftProtocolManager.NodeInfo().Role == "minter" {
+				return i
+			}
+		}
+	}
+	t.Fatal("no leader elected")
+	return -1
+}
+
+func (pm *ProtocolManager) leaderId() uint16 {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	return pm.leader
+}
+
+func TestProtocolManager_TransferLeadership(t *testing.T) {
+	raftNodes := startRaftCluster(t, 3)
+	leader := waitForLeader(t, raftNodes, 0)
+
+	// only the leader or the transferee can ask for a transfer
+	follower := raftNodes[(leader+1)%3].raftProtocolManager
+	transferee := raftNodes[(leader+2)%3].raftProtocolManager.raftId
+	if err := follower.TransferLeadership(transferee); err != errNotLeader {
+		t.Fatalf("error mismatch: have %v, want %v", err, errNotLeader)
+	}
+	if err := raftNodes[leader].raftProtocolManager.TransferLeadership(transferee); err != nil {
+		t.Fatalf("failed to transfer leadership: %v", err)
+	}
+	if got := waitForLeader(t, raftNodes, 0); raftNodes[got].raftProtocolManager.raftId != transferee {
+		t.Fatalf("leader mismatch: have %d, want %d", raftNodes[got].raftProtocolManager.raftId, transferee)
+	}
+	if err := follower.TransferLeadership(42); err == nil {
+		t.Fatal("transferred leadership to an unknown peer")
+	}
+}
+
+func TestProtocolManager_Maintenance(t *testing.T) {
+	raftNodes := startRaftCluster(t, 3)
+	leader := raftNodes[waitForLeader(t, raftNodes, 0)].raftProtocolManager
+
+	if err := leader.StartMaintenance(); err != nil {
+		t.Fatalf("failed to start maintenance: %v", err)
+	}
+	waitForLeader(t, raftNodes, leader.raftId)
+	if !leader.NodeInfo().Maintenance {
+		t.Fatal("node not reported in maintenance")
+	}
+	if health := leader.Health(); health.Healthy || !health.Maintenance {
+		t.Fatalf("node in maintenance reported ready: %+v", health)
+	}
+
+	// the node hands leadership off again if it gets elected during maintenance
+	if err := leader.TransferLeadership(leader.raftId); err != nil {
+		t.Fatalf("failed to transfer leadership back: %v", err)
+	}
+	waitForLeader(t, raftNodes, leader.raftId)
+
+	leader.StopMaintenance()
+	if health := leader.Health(); !health.Healthy {
+		t.Fatalf("node not ready after maintenance: %+v", health)
+	}
+	if err := leader.TransferLeadership(leader.raftId); err != nil {
+		t.Fatalf("failed to transfer leadership back: %v", err)
+	}
+	if got := waitForLeader(t, raftNodes, 0); raftNodes[got].raftProtocolManager != leader {
+		t.Fatal("node didn't take leadership back after maintenance")
+	}
+}
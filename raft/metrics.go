@@ -0,0 +1,87 @@
+package raft
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/coreos/etcd/raft/raftpb"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	termGauge          = metrics.NewRegisteredGauge("raft/term", nil)
+	commitIndexGauge   = metrics.NewRegisteredGauge("raft/index/commit", nil)
+	appliedIndexGauge  = metrics.NewRegisteredGauge("raft/index/applied", nil)
+	snapshotIndexGauge = metrics.NewRegisteredGauge("raft/index/snapshot", nil)
+
+	proposalTimer = metrics.NewRegisteredTimer("raft/proposal/latency", nil)
+
+	speculativeDepthGauge = metrics.NewRegisteredGauge("raft/speculative/depth", nil)
+	invalidOrderingMeter  = metrics.NewRegisteredMeter("raft/speculative/invalidordering", nil)
+
+	leaderChangeMeter = metrics.NewRegisteredMeter("raft/leader/changes", nil)
+	unreachableMeter  = metrics.NewRegisteredMeter("raft/peer/unreachable", nil)
+)
+
+// peerUnreachableMeter returns the meter counting the unreachable reports for a peer
+func peerUnreachableMeter(raftId uint16) metrics.Meter {
+	return metrics.GetOrRegisterMeter(fmt.Sprintf("raft/peer/%d/unreachable", raftId), nil)
+}
+
+// proposalTracker records when we proposed the blocks we minted, to measure how
+// long raft takes to apply them
+type proposalTracker struct {
+	proposed map[common.Hash]time.Time
+	lock     sync.Mutex
+}
+
+func newProposalTracker() *proposalTracker {
+	return &proposalTracker{proposed: make(map[common.Hash]time.Time)}
+}
+
+func (t *proposalTracker) propose(hash common.Hash) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.proposed[hash] = time.Now()
+}
+
+// apply reports the latency of the block if we proposed it
+func (t *proposalTracker) apply(hash common.Hash) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if proposed, ok := t.proposed[hash]; ok {
+		proposalTimer.UpdateSince(proposed)
+		delete(t.proposed, hash)
+	}
+}
+
+// reset forgets the proposals, which won't be applied once we lose leadership
+func (t *proposalTracker) reset() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.proposed = make(map[common.Hash]time.Time)
+}
+
+// updateMetrics updates the gauges after raft gave us a batch of updates
+func (pm *ProtocolManager) updateMetrics(hardState raftpb.HardState) {
+	if !metrics.Enabled {
+		return
+	}
+	if hardState.Term != 0 {
+		termGauge.Update(int64(hardState.Term))
+		commitIndexGauge.Update(int64(hardState.Commit))
+	}
+	pm.mu.RLock()
+	appliedIndexGauge.Update(int64(pm.appliedIndex))
+	snapshotIndexGauge.Update(int64(pm.snapshotIndex))
+	pm.mu.RUnlock()
+
+	// the minter holds its lock while minting, which mustn't hold up the raft loop
+	speculativeDepthGauge.Update(pm.minter.speculativeChain.depth())
+}
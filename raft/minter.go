@@ -50,6 +50,8 @@ type work struct {
 	privateState *state.StateDB
 	Block        *types.Block
 	header       *types.Header
+	gasPool      *core.GasPool
+	tcount       int // transactions committed so far
 }
 
 type minter struct {
@@ -61,9 +63,12 @@ type minter struct {
 	chainDb          ethdb.Database
 	coinbase         common.Address
 	minting          int32 // Atomic status counter
+	draining         int32 // Atomic, set while waiting for proposed blocks to be applied before a hand-off
 	shouldMine       *channels.RingChannel
 	blockTime        time.Duration
 	speculativeChain *speculativeChain
+	policy           MintingPolicy // protected by mu
+	fillDeadline     time.Time     // when we mint even without MinTxs pending, protected by mu
 
 	invalidRaftOrderingChan chan InvalidRaftOrdering
 	chainHeadChan           chan core.ChainHeadEvent
@@ -77,7 +82,7 @@ type extraSeal struct {
 	Signature []byte // Signature of the block minter
 }
 
-func newMinter(config *params.ChainConfig, eth *RaftService, blockTime time.Duration) *minter {
+func newMinter(config *params.ChainConfig, eth *RaftService, blockTime time.Duration, policy MintingPolicy) *minter {
 	minter := &minter{
 		config:           config,
 		eth:              eth,
@@ -87,6 +92,7 @@ func newMinter(config *params.ChainConfig, eth *RaftService, blockTime time.Dura
 		shouldMine:       channels.NewRingChannel(1),
 		blockTime:        blockTime,
 		speculativeChain: newSpeculativeChain(),
+		policy:           policy,
 
 		invalidRaftOrderingChan: make(chan InvalidRaftOrdering, 1),
 		chainHeadChan:           make(chan core.ChainHeadEvent, core.GetChainHeadChannleSize()),
@@ -105,6 +111,7 @@ func newMinter(config *params.ChainConfig, eth *RaftService, blockTime time.Dura
 }
 
 func (minter *minter) start() {
+	atomic.StoreInt32(&minter.draining, 0)
 	atomic.StoreInt32(&minter.minting, 1)
 	minter.requestMinting()
 }
@@ -114,7 +121,38 @@ func (minter *minter) stop() {
 	defer minter.mu.Unlock()
 
 	minter.speculativeChain.clear(minter.chain.CurrentBlock())
+	minter.fillDeadline = time.Time{}
 	atomic.StoreInt32(&minter.minting, 0)
+	atomic.StoreInt32(&minter.draining, 0)
+}
+
+// drain stops minting new blocks and waits until the ones we already proposed to
+// raft are applied, so handing off leadership doesn't leave them behind. It
+// returns false if they weren't all applied within the timeout.
+func (minter *minter) drain(timeout time.Duration) bool {
+	atomic.StoreInt32(&minter.draining, 1)
+
+	deadline := time.Now().Add(timeout)
+	for {
+		minter.mu.Lock()
+		drained := minter.speculativeChain.unappliedBlocks.Empty()
+		minter.mu.Unlock()
+
+		if drained {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(tickerMS * time.Millisecond)
+	}
+}
+
+// undrain resumes minting after a hand-off that didn't go through
+func (minter *minter) undrain() {
+	if atomic.CompareAndSwapInt32(&minter.draining, 1, 0) {
+		minter.requestMinting()
+	}
 }
 
 // Notify the minting loop that minting should occur, if it's not already been
@@ -230,7 +268,7 @@ func throttle(rate time.Duration, f func()) func() {
 //   2. We never mint a block more frequently than `blockTime`.
 func (minter *minter) mintingLoop() {
 	throttledMintNewBlock := throttle(minter.blockTime, func() {
-		if atomic.LoadInt32(&minter.minting) == 1 {
+		if atomic.LoadInt32(&minter.minting) == 1 && atomic.LoadInt32(&minter.draining) == 0 {
 			minter.mintNewBlock()
 		}
 	})
@@ -277,22 +315,39 @@ func (minter *minter) createWork() *work {
 		panic(fmt.Sprint("failed to get default private state: ", err))
 	}
 
+	gasLimit := header.GasLimit
+	if minter.policy.MaxGas > 0 && minter.policy.MaxGas < gasLimit {
+		gasLimit = minter.policy.MaxGas
+	}
+
 	return &work{
 		config:       minter.config,
 		publicState:  publicState,
 		privateState: defaultPrivateState,
 		header:       header,
+		gasPool:      new(core.GasPool).AddGas(gasLimit),
 	}
 }
 
-func (minter *minter) getTransactions() *types.TransactionsByPriceAndNonce {
+// Returns the pending transactions we haven't proposed yet, those of the policy's
+// priority senders first, along with their count. Assumes mu is held.
+func (minter *minter) getTransactions() ([]*types.TransactionsByPriceAndNonce, int) {
 	allAddrTxes, err := minter.eth.TxPool().Pending()
 	if err != nil { // TODO: handle
 		panic(err)
 	}
 	addrTxes := minter.speculativeChain.withoutProposedTxes(allAddrTxes)
+	count := 0
+	for _, txes := range addrTxes {
+		count += len(txes)
+	}
+	priorityTxes := splitPrioritySenders(addrTxes, minter.policy.PrioritySenders)
+
 	signer := types.MakeSigner(minter.chain.Config(), minter.chain.CurrentBlock().Number())
-	return types.NewTransactionsByPriceAndNonce(signer, addrTxes)
+	return []*types.TransactionsByPriceAndNonce{
+		types.NewTransactionsByPriceAndNonce(signer, priorityTxes),
+		types.NewTransactionsByPriceAndNonce(signer, addrTxes),
+	}, count
 }
 
 // Sends-off events asynchronously.
@@ -314,10 +369,23 @@ func (minter *minter) mintNewBlock() {
 	minter.mu.Lock()
 	defer minter.mu.Unlock()
 
+	transactions, pending := minter.getTransactions()
+	if !minter.filled(pending) {
+		return
+	}
 	work := minter.createWork()
-	transactions := minter.getTransactions()
 
-	committedTxes, publicReceipts, _, logs := work.commitTransactions(transactions, minter.chain)
+	var (
+		committedTxes  types.Transactions
+		publicReceipts types.Receipts
+		logs           []*types.Log
+	)
+	for _, txes := range transactions {
+		txes, receipts, _, txLogs := work.commitTransactions(txes, minter.chain, minter.policy.MaxTxs)
+		committedTxes = append(committedTxes, txes...)
+		publicReceipts = append(publicReceipts, receipts...)
+		logs = append(logs, txLogs...)
+	}
 	txCount := len(committedTxes)
 
 	if txCount == 0 {
@@ -365,30 +433,28 @@ func (minter *minter) mintNewBlock() {
 	log.Info("🔨  Mined block", "number", block.Number(), "hash", fmt.Sprintf("%x", block.Hash().Bytes()[:4]), "elapsed", elapsed)
 }
 
-func (env *work) commitTransactions(txes *types.TransactionsByPriceAndNonce, bc *core.BlockChain) (types.Transactions, types.Receipts, types.Receipts, []*types.Log) {
+// Commits the transactions until the block holds maxTxs of them, if maxTxs isn't 0
+func (env *work) commitTransactions(txes *types.TransactionsByPriceAndNonce, bc *core.BlockChain, maxTxs int) (types.Transactions, types.Receipts, types.Receipts, []*types.Log) {
 	var allLogs []*types.Log
 	var committedTxes types.Transactions
 	var publicReceipts types.Receipts
 	var privateReceipts types.Receipts
 
-	gp := new(core.GasPool).AddGas(env.header.GasLimit)
-	txCount := 0
-
-	for {
+	for maxTxs == 0 || env.tcount < maxTxs {
 		tx := txes.Peek()
 		if tx == nil {
 			break
 		}
 
-		env.publicState.Prepare(tx.Hash(), common.Hash{}, txCount)
+		env.publicState.Prepare(tx.Hash(), common.Hash{}, env.tcount)
 
-		publicReceipt, privateReceipt, err := env.commitTransaction(tx, bc, gp)
+		publicReceipt, privateReceipt, err := env.commitTransaction(tx, bc, env.gasPool)
 		switch {
 		case err != nil:
 			log.Info("TX failed, will be removed", "hash", tx.Hash(), "err", err)
 			txes.Pop() // skip rest of txes from this account
 		default:
-			txCount++
+			env.tcount++
 			committedTxes = append(committedTxes, tx)
 
 			publicReceipts = append(publicReceipts, publicReceipt)
@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"math/big"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/coreos/etcd/raft/raftpb"
 	mapset "github.com/deckarep/golang-set"
+	"github.com/eapache/channels"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -206,3 +208,89 @@ func newTestRaftService(t *testing.T, raftId uint16, nodes []uint64, learners []
 	raftService := &RaftService{nodeKey: nodeKey, raftProtocolManager: raftProtocolManager}
 	return raftService
 }
+
+func TestMintingPolicy_validate(t *testing.T) {
+	tests := []struct {
+		policy MintingPolicy
+		err    error
+	}{
+		{MintingPolicy{}, nil},
+		{MintingPolicy{MaxGas: 1000000, MaxTxs: 10, MinTxs: 5, MinTxsDeadline: 100}, nil},
+		{MintingPolicy{MaxTxs: -1}, errNegativeTxLimit},
+		{MintingPolicy{MinTxs: 5}, errMinTxsWithoutLimit},
+		{MintingPolicy{MaxTxs: 5, MinTxs: 10, MinTxsDeadline: 100}, errMinTxsAboveMax},
+	}
+	for i, tt := range tests {
+		if err := tt.policy.validate(); err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
+
+func TestMinter_filled(t *testing.T) {
+	minter := &minter{
+		shouldMine: channels.NewRingChannel(1),
+		policy:     MintingPolicy{MinTxs: 3, MinTxsDeadline: 50},
+	}
+	if !minter.filled(0) {
+		t.Error("nothing pending should not wait")
+	}
+	if minter.filled(1) {
+		t.Error("minted below minTxs before the deadline")
+	}
+	if !minter.filled(3) {
+		t.Error("didn't mint with minTxs pending")
+	}
+	if minter.filled(2) {
+		t.Error("minted below minTxs before the deadline")
+	}
+	// the deadline requests minting again once it passes
+	select {
+	case <-minter.shouldMine.Out():
+	case <-time.After(time.Second):
+		t.Fatal("minting not requested at the deadline")
+	}
+	if !minter.filled(2) {
+		t.Error("didn't mint after the deadline")
+	}
+}
+
+func TestMinter_undrain(t *testing.T) {
+	minter := &minter{shouldMine: channels.NewRingChannel(1)}
+
+	minter.undrain()
+	select {
+	case <-minter.shouldMine.Out():
+		t.Fatal("minting requested without draining first")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	atomic.StoreInt32(&minter.draining, 1)
+	minter.undrain()
+	if atomic.LoadInt32(&minter.draining) != 0 {
+		t.Error("minter still draining")
+	}
+	select {
+	case <-minter.shouldMine.Out():
+	case <-time.After(time.Second):
+		t.Fatal("minting not requested after the failed hand-off")
+	}
+}
+
+func TestSplitPrioritySenders(t *testing.T) {
+	var (
+		admin = common.HexToAddress("0x01")
+		user  = common.HexToAddress("0x02")
+		txes  = map[common.Address]types.Transactions{
+			admin: {types.NewTransaction(0, user, common.Big0, 21000, common.Big0, nil)},
+			user:  {types.NewTransaction(0, admin, common.Big0, 21000, common.Big0, nil)},
+		}
+	)
+	priority := splitPrioritySenders(txes, []common.Address{admin, common.HexToAddress("0x03")})
+	if len(priority) != 1 || priority[admin] == nil {
+		t.Errorf("priority transactions mismatch: have %v", priority)
+	}
+	if len(txes) != 1 || txes[user] == nil {
+		t.Errorf("remaining transactions mismatch: have %v", txes)
+	}
+}
@@ -0,0 +1,94 @@
+package raft
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// MintingPolicy controls when the minter creates a block and what goes in it.
+// The zero value mints every pending transaction as soon as the block time allows.
+type MintingPolicy struct {
+	MaxGas          uint64           `json:"maxGas"`          // gas per block, 0 for the block gas limit
+	MaxTxs          int              `json:"maxTxs"`          // transactions per block, 0 for no limit
+	MinTxs          int              `json:"minTxs"`          // pending transactions to wait for before minting
+	MinTxsDeadline  uint64           `json:"minTxsDeadline"`  // milliseconds after which we mint with fewer than MinTxs
+	PrioritySenders []common.Address `json:"prioritySenders"` // senders whose transactions go first, e.g. permissioning admins
+}
+
+var (
+	errNegativeTxLimit    = errors.New("transaction counts can't be negative")
+	errMinTxsWithoutLimit = errors.New("minTxs requires a minTxsDeadline, so transactions don't wait forever")
+	errMinTxsAboveMax     = errors.New("minTxs can't be above maxTxs")
+)
+
+func (p *MintingPolicy) validate() error {
+	if p.MaxTxs < 0 || p.MinTxs < 0 {
+		return errNegativeTxLimit
+	}
+	if p.MinTxs > 1 && p.MinTxsDeadline == 0 {
+		return errMinTxsWithoutLimit
+	}
+	if p.MaxTxs > 0 && p.MinTxs > p.MaxTxs {
+		return errMinTxsAboveMax
+	}
+	return nil
+}
+
+// setPolicy replaces the minting policy, it's used from the next block on
+func (minter *minter) setPolicy(policy MintingPolicy) error {
+	if err := policy.validate(); err != nil {
+		return err
+	}
+	minter.mu.Lock()
+	defer minter.mu.Unlock()
+
+	minter.policy = policy
+	minter.fillDeadline = time.Time{}
+	log.Info("raft minting policy updated", "max gas", policy.MaxGas, "max txs", policy.MaxTxs, "min txs", policy.MinTxs, "deadline", time.Duration(policy.MinTxsDeadline)*time.Millisecond, "priority senders", len(policy.PrioritySenders))
+	return nil
+}
+
+func (minter *minter) getPolicy() MintingPolicy {
+	minter.mu.Lock()
+	defer minter.mu.Unlock()
+
+	return minter.policy
+}
+
+// filled reports whether enough transactions are pending to mint a block. The
+// first time it isn't, the deadline starts and we mint with what we have once
+// it passes. Assumes mu is held.
+func (minter *minter) filled(pending int) bool {
+	if pending == 0 || pending >= minter.policy.MinTxs {
+		minter.fillDeadline = time.Time{}
+		return true
+	}
+	if minter.fillDeadline.IsZero() {
+		wait := time.Duration(minter.policy.MinTxsDeadline) * time.Millisecond
+		minter.fillDeadline = time.Now().Add(wait)
+		time.AfterFunc(wait, minter.requestMinting)
+	}
+	if time.Now().Before(minter.fillDeadline) {
+		log.Debug("Waiting for more transactions before minting", "pending", pending, "min", minter.policy.MinTxs)
+		return false
+	}
+	minter.fillDeadline = time.Time{}
+	return true
+}
+
+// splitPrioritySenders moves the transactions of the priority senders out of
+// txes, so they can be committed first
+func splitPrioritySenders(txes map[common.Address]types.Transactions, senders []common.Address) (priority map[common.Address]types.Transactions) {
+	priority = make(map[common.Address]types.Transactions)
+	for _, sender := range senders {
+		if senderTxes, ok := txes[sender]; ok {
+			priority[sender] = senderTxes
+			delete(txes, sender)
+		}
+	}
+	return priority
+}
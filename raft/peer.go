@@ -29,8 +29,9 @@ type Address struct {
 
 type ClusterInfo struct {
 	Address
-	Role       string `json:"role"`
-	NodeActive bool   `json:"nodeActive"`
+	Role       string            `json:"role"`
+	NodeActive bool              `json:"nodeActive"`
+	Promotion  *LearnerPromotion `json:"promotion,omitempty"` // set for learners on the leader when promoting automatically
 }
 
 func newAddress(raftId uint16, raftPort int, node *enode.Node, useDns bool) *Address {
@@ -0,0 +1,177 @@
+package raft
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// How often the leader checks whether learners can be promoted
+const promotionCheckInterval = time.Second
+
+// PromotionPolicy makes the leader promote learners to voters automatically once
+// they caught up with the chain. The zero value disables it.
+type PromotionPolicy struct {
+	Enabled   bool
+	MaxLag    uint64        // entries a learner may be behind the leader's applied index
+	Period    time.Duration // time a learner must stay caught up and active before its promotion
+	MaxVoters int           // learners are not promoted beyond this many voters
+}
+
+// Status of a learner waiting for automatic promotion
+const (
+	promotionCatchingUp = "catching up" // lagging too far behind or inactive
+	promotionWaiting    = "waiting"     // caught up, waiting for the period to elapse
+	promotionBlocked    = "blocked"     // eligible but the cluster has MaxVoters voters
+	promotionPromoting  = "promoting"   // promotion proposed to raft
+)
+
+// LearnerPromotion is the automatic promotion status of a learner, as seen by the leader
+type LearnerPromotion struct {
+	Lag         uint64 `json:"lag"`         // entries behind the leader's applied index
+	CaughtUpFor uint64 `json:"caughtUpFor"` // seconds it has stayed caught up
+	Status      string `json:"status"`
+}
+
+// promoter tracks the learners on the leader and promotes them per the policy
+type promoter struct {
+	policy   PromotionPolicy
+	learners map[uint16]*learnerProgress
+	lock     sync.Mutex
+}
+
+type learnerProgress struct {
+	lag           uint64
+	caughtUpSince time.Time // zero while the learner isn't caught up
+	status        string
+}
+
+func newPromoter(policy PromotionPolicy) *promoter {
+	return &promoter{
+		policy:   policy,
+		learners: make(map[uint16]*learnerProgress),
+	}
+}
+
+// promotionLoop periodically checks the learners while the policy is enabled
+func (pm *ProtocolManager) promotionLoop() {
+	if !pm.promoter.policy.Enabled {
+		return
+	}
+	log.Info("automatic learner promotion enabled", "max lag", pm.promoter.policy.MaxLag, "period", pm.promoter.policy.Period, "max voters", pm.promoter.policy.MaxVoters)
+
+	ticker := time.NewTicker(promotionCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			pm.checkLearners()
+		case <-pm.quitSync:
+			return
+		}
+	}
+}
+
+// checkLearners updates the progress of the learners and promotes the first one
+// that is eligible. Only the leader knows how far learners got, followers just
+// forget what they tracked while leading.
+func (pm *ProtocolManager) checkLearners() {
+	pm.mu.RLock()
+	leading := pm.role == minterRole && !pm.maintenance
+	appliedIndex := pm.appliedIndex
+	voters := len(pm.confState.Nodes)
+	pm.mu.RUnlock()
+
+	p := pm.promoter
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if !leading {
+		p.learners = make(map[uint16]*learnerProgress)
+		return
+	}
+	var (
+		now      = time.Now()
+		learners = make(map[uint16]*learnerProgress)
+		promote  uint16
+	)
+	for id, progress := range pm.rawNode().Status().Progress {
+		raftId := uint16(id)
+		if !progress.IsLearner || pm.isRaftIdRemoved(raftId) {
+			continue
+		}
+		learner, ok := p.learners[raftId]
+		if !ok {
+			learner = &learnerProgress{}
+		}
+		learners[raftId] = learner
+		if learner.status == promotionPromoting {
+			continue
+		}
+		learner.lag = 0
+		if progress.Match < appliedIndex {
+			learner.lag = appliedIndex - progress.Match
+		}
+		if learner.lag > p.policy.MaxLag || !progress.RecentActive {
+			learner.caughtUpSince, learner.status = time.Time{}, promotionCatchingUp
+			continue
+		}
+		if learner.caughtUpSince.IsZero() {
+			learner.caughtUpSince = now
+		}
+		switch {
+		case now.Sub(learner.caughtUpSince) < p.policy.Period:
+			learner.status = promotionWaiting
+		case p.policy.MaxVoters > 0 && voters >= p.policy.MaxVoters:
+			learner.status = promotionBlocked
+		case promote == 0:
+			promote = raftId
+		default:
+			// one promotion at a time, so the voter count is up to date for the next
+			learner.status = promotionWaiting
+		}
+	}
+	p.learners = learners
+
+	if promote != 0 {
+		log.Info("automatically promoting caught up learner", "raft id", promote, "lag", learners[promote].lag, "caught up for", now.Sub(learners[promote].caughtUpSince))
+		learners[promote].status = promotionPromoting
+		go pm.promote(promote)
+	}
+}
+
+// promote proposes the promotion of the learner. If that fails the learner goes
+// back to catching up, so it is promoted again once it stays caught up for the
+// period, instead of being stuck as promoting.
+func (pm *ProtocolManager) promote(raftId uint16) {
+	if _, err := pm.PromoteToPeer(raftId); err != nil {
+		log.Warn("failed to promote learner", "raft id", raftId, "err", err)
+
+		p := pm.promoter
+		p.lock.Lock()
+		if learner, ok := p.learners[raftId]; ok && learner.status == promotionPromoting {
+			learner.caughtUpSince, learner.status = time.Time{}, promotionCatchingUp
+		}
+		p.lock.Unlock()
+	}
+}
+
+// learnerPromotion returns the promotion status of the learner, nil if the node
+// isn't tracking it
+func (pm *ProtocolManager) learnerPromotion(raftId uint16) *LearnerPromotion {
+	p := pm.promoter
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	learner, ok := p.learners[raftId]
+	if !ok {
+		return nil
+	}
+	promotion := &LearnerPromotion{Lag: learner.lag, Status: learner.status}
+	if !learner.caughtUpSince.IsZero() {
+		promotion.CaughtUpFor = uint64(time.Since(learner.caughtUpSince) / time.Second)
+	}
+	return promotion
+}
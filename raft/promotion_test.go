@@ -0,0 +1,105 @@
+package raft
+
+import (
+	"crypto/ecdsa"
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+func TestProtocolManager_AutomaticPromotion(t *testing.T) {
+	tmpWorkingDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpWorkingDir)
+
+	policy := PromotionPolicy{Enabled: true, MaxLag: 10, Period: 500 * time.Millisecond, MaxVoters: 3}
+
+	count := 4
+	ports := make([]uint16, count)
+	nodeKeys := make([]*ecdsa.PrivateKey, count)
+	peers := make([]*enode.Node, count)
+	for i := 0; i < count; i++ {
+		ports[i] = nextPort(t)
+		nodeKeys[i] = mustNewNodeKey(t)
+		peers[i] = enode.NewV4Hostname(&(nodeKeys[i].PublicKey), net.IPv4(127, 0, 0, 1).String(), 30303+i, 0, int(ports[i]))
+	}
+	// Two voters, then two learners joining: only one of them fits under MaxVoters
+	raftNodes := make([]*RaftService, 0, count)
+	defer func() {
+		for _, s := range raftNodes {
+			s.Stop()
+		}
+	}()
+	for i := 0; i < 2; i++ {
+		s, err := startRaftNodeWithPolicy(uint16(i+1), ports[i], tmpWorkingDir, nodeKeys[i], peers[:2], false, policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		raftNodes = append(raftNodes, s)
+	}
+	leader := raftNodes[waitForLeader(t, raftNodes, 0)].raftProtocolManager
+
+	for i := 2; i < count; i++ {
+		raftId, err := leader.ProposeNewPeer(peers[i].String(), true)
+		if err != nil {
+			t.Fatalf("failed to add learner: %v", err)
+		}
+		s, err := startRaftNodeWithPolicy(raftId, ports[i], tmpWorkingDir, nodeKeys[i], nil, true, policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		raftNodes = append(raftNodes, s)
+
+		// wait for the learner to be added before proposing the next one
+		for !leader.isLearner(raftId) {
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+
+	deadline := time.Now().Add(20 * time.Second)
+	for !leader.isVerifier(3) && !leader.isVerifier(4) {
+		if time.Now().After(deadline) {
+			t.Fatalf("learner not promoted, cluster: %v", leader.confState)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	// Give the leader time to wrongly promote the other learner
+	time.Sleep(4 * policy.Period)
+
+	leader.mu.RLock()
+	voters, learners := len(leader.confState.Nodes), leader.confState.Learners
+	leader.mu.RUnlock()
+	if voters != policy.MaxVoters || len(learners) != 1 {
+		t.Fatalf("cluster mismatch: have %d voters and %d learners, want %d and 1", voters, len(learners), policy.MaxVoters)
+	}
+	if promotion := leader.learnerPromotion(uint16(learners[0])); promotion == nil || promotion.Status != promotionBlocked {
+		t.Fatalf("promotion status mismatch: have %+v, want %s", promotion, promotionBlocked)
+	}
+}
+
+func TestProtocolManager_FailedPromotion(t *testing.T) {
+	pm := &ProtocolManager{
+		raftId:    1,
+		confState: raftpb.ConfState{Nodes: []uint64{1}},
+		promoter:  newPromoter(PromotionPolicy{Enabled: true}),
+	}
+	pm.promoter.learners[2] = &learnerProgress{caughtUpSince: time.Now(), status: promotionPromoting}
+
+	// 2 isn't a learner in the cluster, so the promotion fails
+	pm.promote(2)
+
+	promotion := pm.learnerPromotion(2)
+	if promotion == nil || promotion.Status != promotionCatchingUp {
+		t.Fatalf("promotion status mismatch: have %+v, want %s", promotion, promotionCatchingUp)
+	}
+	if promotion.CaughtUpFor != 0 || !pm.promoter.learners[2].caughtUpSince.IsZero() {
+		t.Fatal("learner still caught up after the failed promotion")
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/ethereum/go-ethereum/p2p/enr"
 	"github.com/ethereum/go-ethereum/permission/core"
 	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/golang/snappy"
 )
 
 type SnapshotWithHostnames struct {
@@ -98,6 +99,9 @@ func (pm *ProtocolManager) triggerSnapshot(index uint64) {
 	//snapData := pm.blockchain.CurrentBlock().Hash().Bytes()
 	//snap, err := pm.raftStorage.CreateSnapshot(pm.appliedIndex, &pm.confState, snapData)
 	snapData := pm.buildSnapshot().toBytes()
+	if pm.compressSnapshots {
+		snapData = compressSnapshot(snapData)
+	}
 	snap, err := pm.raftStorage.CreateSnapshot(index, &pm.confState, snapData)
 	if err != nil {
 		panic(err)
@@ -249,9 +253,27 @@ func (snapshot *SnapshotWithHostnames) toBytes() []byte {
 	return buffer
 }
 
+// compressSnapshot snappy compresses the encoded snapshot. The result starts with
+// snapshotCompressedPrefix, which can't start an RLP list, so readers can tell
+// compressed snapshots from the plain ones.
+//
+// A snapshot only holds the cluster membership and the head block hash, the
+// chain and its state aren't part of it (see fetchChainUntil). Nodes that
+// predate compression can't decode compressed snapshots, so every node must be
+// upgraded before any of them takes snapshots with compression enabled.
+func compressSnapshot(data []byte) []byte {
+	return append([]byte{snapshotCompressedPrefix}, snappy.Encode(nil, data)...)
+}
+
 func bytesToSnapshot(input []byte) *SnapshotWithHostnames {
 	var err, errOld error
 
+	if len(input) > 0 && input[0] == snapshotCompressedPrefix {
+		if input, err = snappy.Decode(nil, input[1:]); err != nil {
+			fatalf("failed to decompress Snapshot: %v", err)
+		}
+	}
+
 	snapshot := new(SnapshotWithHostnames)
 	streamNewSnapshot := rlp.NewStream(bytes.NewReader(input), 0)
 	if err = streamNewSnapshot.Decode(snapshot); err == nil {
@@ -330,7 +352,10 @@ func (pm *ProtocolManager) applyRaftSnapshot(raftSnapshot raftpb.Snapshot) {
 	preSyncHead := pm.blockchain.CurrentBlock()
 
 	if latestBlock := pm.blockchain.GetBlockByHash(latestBlockHash); latestBlock == nil {
-		pm.syncBlockchainUntil(latestBlockHash)
+		if err := pm.fetchChainUntil(latestBlockHash); err != nil {
+			log.Warn("failed to fetch the chain from raft peers, synchronizing with the downloader", "err", err)
+			pm.syncBlockchainUntil(latestBlockHash)
+		}
 		pm.logNewlyAcceptedTransactions(preSyncHead)
 
 		log.Info(chainExtensionMessage, "hash", pm.blockchain.CurrentBlock().Hash())
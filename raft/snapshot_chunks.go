@@ -0,0 +1,305 @@
+package raft
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/snappy"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// A node applying a snapshot whose head block it doesn't have fetches the chain
+// up to it from its raft peers, in chunks of consecutive blocks served over the
+// raft HTTP transport, to the raft peers only. Chunks carry blocks only: neither
+// snapshots nor chunks ship the state, as the private states of a node can only
+// be rebuilt by executing the private transactions of every block, so the state
+// is rebuilt by executing the blocks on insertion. Chunks are requested from all peers in parallel and
+// inserted in order, so the sync doesn't hinge on a single peer. As inserted
+// blocks are persisted, an interrupted transfer resumes from our head once the
+// snapshot is applied again on restart.
+
+const (
+	chainChunkPrefix = "/raft/chain/" // followed by <head hash>/<first block number>
+
+	chainChunkSize      = 256               // blocks served per chunk
+	chainChunkMaxSize   = 128 * 1024 * 1024 // max decompressed size of a chunk
+	chainChunkTimeout   = 30 * time.Second  // time allowed to fetch a single chunk
+	chainChunkHeadField = "Raft-Chain-Head" // response header carrying the head number
+	chainChunkFromField = "Raft-Chain-From" // request header carrying the raft id of the peer
+	chainChunkRetries   = 3                 // failed chunks per peer before we give up
+)
+
+var (
+	errNoChunkPeers     = errors.New("no raft peers to fetch the chain from")
+	errChunkPeersFailed = errors.New("all raft peers failed to serve a chain chunk")
+	errUnknownChunkPeer = errors.New("unknown raft peer")
+	errRemovedChunkPeer = errors.New("removed raft peer")
+	errChunkPeerAddress = errors.New("request doesn't come from the address of the raft peer")
+)
+
+// httpHandler serves the raft transport along with the chain chunks
+func (pm *ProtocolManager) httpHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/", pm.transport.Handler())
+	mux.HandleFunc(chainChunkPrefix, pm.serveChainChunk)
+	return mux
+}
+
+// serveChainChunk writes the snappy compressed RLP of up to chainChunkSize blocks
+// starting at the requested number, on the canonical chain up to the requested head
+func (pm *ProtocolManager) serveChainChunk(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if status, err := pm.checkChainChunkPeer(r); err != nil {
+		log.Debug("rejected chain chunk request", "remote", r.RemoteAddr, "err", err)
+		http.Error(w, err.Error(), status)
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, chainChunkPrefix), "/")
+	if len(parts) != 2 {
+		http.Error(w, "malformed chain chunk request", http.StatusBadRequest)
+		return
+	}
+	from, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil || from == 0 {
+		http.Error(w, "malformed chain chunk request", http.StatusBadRequest)
+		return
+	}
+	head := pm.blockchain.GetHeaderByHash(common.HexToHash(parts[0]))
+	if head == nil || pm.blockchain.GetCanonicalHash(head.Number.Uint64()) != head.Hash() {
+		http.Error(w, "unknown head block", http.StatusNotFound)
+		return
+	}
+	number := head.Number.Uint64()
+	if from > number {
+		http.Error(w, "chunk beyond head block", http.StatusRequestedRangeNotSatisfiable)
+		return
+	}
+	to := from + chainChunkSize - 1
+	if to > number {
+		to = number
+	}
+	blocks := make([]*types.Block, 0, to-from+1)
+	for n := from; n <= to; n++ {
+		block := pm.blockchain.GetBlockByNumber(n)
+		if block == nil {
+			http.Error(w, "missing block", http.StatusInternalServerError)
+			return
+		}
+		blocks = append(blocks, block)
+	}
+	data, err := rlp.EncodeToBytes(blocks)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set(chainChunkHeadField, strconv.FormatUint(number, 10))
+	w.Write(snappy.Encode(nil, data))
+}
+
+// checkChainChunkPeer checks that the chain chunk request comes from a raft peer:
+// as with the raft transport, the peer must be known and not removed, and the
+// request must also come from the address the peer has in the cluster. It
+// returns the status to reply with otherwise.
+func (pm *ProtocolManager) checkChainChunkPeer(r *http.Request) (int, error) {
+	id, err := strconv.ParseUint(r.Header.Get(chainChunkFromField), 10, 16)
+	if err != nil {
+		return http.StatusForbidden, errUnknownChunkPeer
+	}
+	if pm.isRaftIdRemoved(uint16(id)) {
+		return http.StatusGone, errRemovedChunkPeer
+	}
+	pm.mu.RLock()
+	peer := pm.peers[uint16(id)]
+	pm.mu.RUnlock()
+	if peer == nil {
+		return http.StatusForbidden, errUnknownChunkPeer
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return http.StatusForbidden, errChunkPeerAddress
+	}
+	remote := net.ParseIP(host)
+	ips := []net.IP{net.ParseIP(peer.address.Hostname)}
+	if ips[0] == nil {
+		if ips, err = net.LookupIP(peer.address.Hostname); err != nil {
+			return http.StatusForbidden, errChunkPeerAddress
+		}
+	}
+	for _, ip := range ips {
+		if ip.Equal(remote) {
+			return http.StatusOK, nil
+		}
+	}
+	return http.StatusForbidden, errChunkPeerAddress
+}
+
+// chainChunk is a chunk being fetched, or fetched, from a peer
+type chainChunk struct {
+	from   uint64
+	blocks []*types.Block
+	head   uint64 // head number reported by the peer
+	err    error
+}
+
+// fetchChainUntil fetches and inserts the chain up to the block with the given
+// hash from the raft peers, chunk by chunk
+func (pm *ProtocolManager) fetchChainUntil(hash common.Hash) error {
+	pm.mu.RLock()
+	urls := make([]string, 0, len(pm.peers))
+	for _, peer := range pm.peers {
+		urls = append(urls, pm.raftUrl(peer.address))
+	}
+	pm.mu.RUnlock()
+
+	if len(urls) == 0 {
+		return errNoChunkPeers
+	}
+	client := &http.Client{Timeout: chainChunkTimeout}
+
+	// The first chunk tells us how far the chain goes
+	next := pm.blockchain.CurrentBlock().NumberU64() + 1
+	var (
+		head     uint64
+		started  bool
+		failures int
+	)
+	for i := 0; !started; i++ {
+		if i == len(urls) {
+			return errChunkPeersFailed
+		}
+		chunk := fetchChainChunk(client, urls[i], pm.raftId, hash, next)
+		if chunk.err != nil {
+			log.Info("failed to fetch chain chunk", "peer", urls[i], "from", next, "err", chunk.err)
+			continue
+		}
+		if err := pm.insertChainChunk(chunk); err != nil {
+			return err
+		}
+		head, started = chunk.head, true
+		next += uint64(len(chunk.blocks))
+	}
+	log.Info("fetching chain from raft peers", "from", next, "head", head, "hash", hash, "peers", len(urls))
+
+	// Then we fetch a chunk from every peer at once, rotating the peers so a
+	// failed chunk is retried with another one, and insert them in order
+	fetched := make(map[uint64]*chainChunk)
+	for round := 0; next <= head; round++ {
+		var froms []uint64
+		for from := next; from <= head && len(froms) < len(urls); from += chainChunkSize {
+			if fetched[from] == nil {
+				froms = append(froms, from)
+			}
+		}
+		chunks := make([]*chainChunk, len(froms))
+		var wg sync.WaitGroup
+		for i, from := range froms {
+			wg.Add(1)
+			go func(i int, url string, from uint64) {
+				defer wg.Done()
+				chunks[i] = fetchChainChunk(client, url, pm.raftId, hash, from)
+			}(i, urls[(i+round)%len(urls)], from)
+		}
+		wg.Wait()
+
+		for _, chunk := range chunks {
+			if chunk.err != nil {
+				log.Info("failed to fetch chain chunk", "from", chunk.from, "err", chunk.err)
+				if failures++; failures > chainChunkRetries*len(urls) {
+					return errChunkPeersFailed
+				}
+				continue
+			}
+			fetched[chunk.from] = chunk
+		}
+		for chunk := fetched[next]; chunk != nil; chunk = fetched[next] {
+			if err := pm.insertChainChunk(chunk); err != nil {
+				return err
+			}
+			delete(fetched, chunk.from)
+			next += uint64(len(chunk.blocks))
+		}
+	}
+	if current := pm.blockchain.CurrentBlock(); current.Hash() != hash {
+		return fmt.Errorf("chain fetched from raft peers ends at %x, expected %x", current.Hash(), hash)
+	}
+	return nil
+}
+
+// insertChainChunk inserts the fetched blocks into the chain
+func (pm *ProtocolManager) insertChainChunk(chunk *chainChunk) error {
+	if len(chunk.blocks) == 0 {
+		return nil
+	}
+	if _, err := pm.blockchain.InsertChain(chunk.blocks); err != nil {
+		return fmt.Errorf("failed to insert chain chunk from %d: %v", chunk.from, err)
+	}
+	log.Info("inserted chain chunk", "from", chunk.from, "count", len(chunk.blocks))
+	return nil
+}
+
+// fetchChainChunk requests the chunk starting at the given block number from
+// the peer at url, on behalf of the node with the given raft id
+func fetchChainChunk(client *http.Client, url string, raftId uint16, hash common.Hash, from uint64) *chainChunk {
+	chunk := &chainChunk{from: from}
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s%s%s/%d", url, chainChunkPrefix, hash.Hex(), from), nil)
+	if err != nil {
+		chunk.err = err
+		return chunk
+	}
+	req.Header.Set(chainChunkFromField, strconv.FormatUint(uint64(raftId), 10))
+	res, err := client.Do(req)
+	if err != nil {
+		chunk.err = err
+		return chunk
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		chunk.err = fmt.Errorf("unexpected status %s", res.Status)
+		return chunk
+	}
+	if chunk.head, err = strconv.ParseUint(res.Header.Get(chainChunkHeadField), 10, 64); err != nil {
+		chunk.err = fmt.Errorf("invalid head number: %v", err)
+		return chunk
+	}
+	compressed, err := ioutil.ReadAll(io.LimitReader(res.Body, chainChunkMaxSize))
+	if err != nil {
+		chunk.err = err
+		return chunk
+	}
+	if size, err := snappy.DecodedLen(compressed); err != nil || size > chainChunkMaxSize {
+		chunk.err = fmt.Errorf("invalid chunk size %d: %v", size, err)
+		return chunk
+	}
+	data, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		chunk.err = err
+		return chunk
+	}
+	if err := rlp.DecodeBytes(data, &chunk.blocks); err != nil {
+		chunk.err = err
+		return chunk
+	}
+	if len(chunk.blocks) == 0 || chunk.blocks[0].NumberU64() != from {
+		chunk.err = errors.New("chunk doesn't start at the requested block")
+	}
+	return chunk
+}
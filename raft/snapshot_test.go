@@ -0,0 +1,128 @@
+package raft
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	mapset "github.com/deckarep/golang-set"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestSnapshotCompression(t *testing.T) {
+	snapshot := &SnapshotWithHostnames{
+		Addresses: []Address{
+			{RaftId: 1, P2pPort: 21000, RaftPort: 50400, Hostname: "node1.example.com"},
+			{RaftId: 2, P2pPort: 21001, RaftPort: 50401, Hostname: "node2.example.com"},
+		},
+		RemovedRaftIds: []uint16{3},
+		HeadBlockHash:  common.HexToHash("0x1234"),
+	}
+	plain := snapshot.toBytes()
+	compressed := compressSnapshot(plain)
+	if compressed[0] != snapshotCompressedPrefix {
+		t.Fatalf("compressed snapshot prefix mismatch: have %#x, want %#x", compressed[0], snapshotCompressedPrefix)
+	}
+	for _, data := range [][]byte{plain, compressed} {
+		decoded := bytesToSnapshot(data)
+		if decoded.HeadBlockHash != snapshot.HeadBlockHash || len(decoded.Addresses) != 2 || decoded.Addresses[1].Hostname != "node2.example.com" || decoded.RemovedRaftIds[0] != 3 {
+			t.Errorf("snapshot mismatch: have %+v, want %+v", decoded, snapshot)
+		}
+	}
+}
+
+func newTestChain(t *testing.T, n int) *core.BlockChain {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		genesis = (&core.Genesis{Config: params.TestChainConfig}).MustCommit(db)
+	)
+	chain, err := core.NewBlockChain(db, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	blocks, _ := core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), db, n, nil)
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	return chain
+}
+
+func TestFetchChainUntil(t *testing.T) {
+	// Two peers serving the same chain, long enough to need several chunks
+	source := newTestChain(t, 3*chainChunkSize+10)
+	defer source.Stop()
+
+	// The peers only serve the target, raft peer 3
+	sourcePeers := map[uint16]*Peer{3: {address: &Address{RaftId: 3, Hostname: "127.0.0.1"}}}
+	peers := make(map[uint16]*Peer)
+	for i := uint16(1); i <= 2; i++ {
+		server := httptest.NewServer(http.HandlerFunc((&ProtocolManager{blockchain: source, peers: sourcePeers, removedPeers: mapset.NewSet()}).serveChainChunk))
+		defer server.Close()
+
+		host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+		raftPort, _ := strconv.Atoi(port)
+		peers[i] = &Peer{address: &Address{RaftId: i, Hostname: host, RaftPort: enr.RaftPort(raftPort)}}
+	}
+	// A node that already has part of the chain only fetches the rest
+	target := newTestChain(t, 0)
+	defer target.Stop()
+	if _, err := target.InsertChain([]*types.Block{source.GetBlockByNumber(1), source.GetBlockByNumber(2)}); err != nil {
+		t.Fatal(err)
+	}
+	pm := &ProtocolManager{raftId: 3, blockchain: target, peers: peers}
+	if err := pm.fetchChainUntil(source.CurrentBlock().Hash()); err != nil {
+		t.Fatalf("failed to fetch chain: %v", err)
+	}
+	if have, want := target.CurrentBlock().Hash(), source.CurrentBlock().Hash(); have != want {
+		t.Fatalf("head mismatch: have %x, want %x", have, want)
+	}
+
+	// Peers that don't have the block can't serve it
+	if err := pm.fetchChainUntil(common.HexToHash("0xdead")); err != errChunkPeersFailed {
+		t.Fatalf("error mismatch: have %v, want %v", err, errChunkPeersFailed)
+	}
+}
+
+func TestServeChainChunkToRaftPeersOnly(t *testing.T) {
+	source := newTestChain(t, 1)
+	defer source.Stop()
+
+	pm := &ProtocolManager{
+		blockchain: source,
+		peers: map[uint16]*Peer{
+			2: {address: &Address{RaftId: 2, Hostname: "127.0.0.1"}},
+			3: {address: &Address{RaftId: 3, Hostname: "10.0.0.3"}},
+		},
+		removedPeers: mapset.NewSet(),
+	}
+	pm.removedPeers.Add(uint16(4))
+	server := httptest.NewServer(http.HandlerFunc(pm.serveChainChunk))
+	defer server.Close()
+
+	client := &http.Client{Timeout: chainChunkTimeout}
+	hash := source.CurrentBlock().Hash()
+	for _, tt := range []struct {
+		raftId uint16
+		err    bool
+	}{
+		{raftId: 2},            // known peer at its address
+		{raftId: 3, err: true}, // known peer at another address
+		{raftId: 4, err: true}, // removed peer
+		{raftId: 5, err: true}, // unknown peer
+	} {
+		chunk := fetchChainChunk(client, server.URL, tt.raftId, hash, 1)
+		if (chunk.err != nil) != tt.err {
+			t.Errorf("raft peer %d: error mismatch: have %v, want error %t", tt.raftId, chunk.err, tt.err)
+		}
+	}
+}
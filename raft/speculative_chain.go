@@ -1,6 +1,8 @@
 package raft
 
 import (
+	"sync/atomic"
+
 	mapset "github.com/deckarep/golang-set"
 	"gopkg.in/oleiade/lane.v1"
 
@@ -19,6 +21,7 @@ import (
 // * clear state when we stop minting
 // * set the parent when we're not minting (so it's always current)
 type speculativeChain struct {
+	size                       int64 // length of unappliedBlocks, for readers not holding the minter lock. Accessed atomically.
 	head                       *types.Block
 	unappliedBlocks            *lane.Deque
 	expectedInvalidBlockHashes mapset.Set // This is thread-safe. This set is referred to as our "guard" below.
@@ -39,6 +42,7 @@ func (chain *speculativeChain) clear(block *types.Block) {
 	chain.unappliedBlocks = lane.NewDeque()
 	chain.expectedInvalidBlockHashes.Clear()
 	chain.proposedTxes.Clear()
+	chain.updateSize()
 }
 
 // Append a new speculative block
@@ -46,6 +50,17 @@ func (chain *speculativeChain) extend(block *types.Block) {
 	chain.head = block
 	chain.recordProposedTransactions(block.Transactions())
 	chain.unappliedBlocks.Append(block)
+	chain.updateSize()
+}
+
+// updateSize publishes the number of unapplied blocks
+func (chain *speculativeChain) updateSize() {
+	atomic.StoreInt64(&chain.size, int64(chain.unappliedBlocks.Size()))
+}
+
+// depth returns the number of unapplied blocks, it's safe to call without the minter lock
+func (chain *speculativeChain) depth() int64 {
+	return atomic.LoadInt64(&chain.size)
 }
 
 // Set the parent of the speculative chain
@@ -58,6 +73,7 @@ func (chain *speculativeChain) setHead(block *types.Block) {
 // Accept this block, removing it from the speculative chain
 func (chain *speculativeChain) accept(acceptedBlock *types.Block) {
 	earliestProposedI := chain.unappliedBlocks.Shift()
+	chain.updateSize()
 	var earliestProposed *types.Block
 	if nil != earliestProposedI {
 		earliestProposed = earliestProposedI.(*types.Block)
@@ -99,6 +115,7 @@ func (chain *speculativeChain) unwindFrom(invalidHash common.Hash, headBlock *ty
 	// our block, add to guard. in all cases, call removeProposedTxes
 	for {
 		currBlockI := chain.unappliedBlocks.Pop()
+		chain.updateSize()
 
 		if nil == currBlockI {
 			log.Info("(Popped all blocks from queue.)")
@@ -31,6 +31,9 @@ const (
 	// keys used to save values in request context
 	ctxAuthenticationError   = securityContextKey("AUTHENTICATION_ERROR")   // key to save error during authentication before processing the request body
 	ctxPreauthenticatedToken = securityContextKey("PREAUTHENTICATED_TOKEN") // key to save the preauthenticated token once authenticated
+	// keys used to enforce the quotas of the tenants
+	ctxTenantQuotas = securityContextKey("TENANT_QUOTAS") // key to save reference to the *TenantQuotas of the server
+	ctxTenantQuota  = securityContextKey("TENANT_QUOTA")  // key to save the quota of the tenant making the call
 )
 
 // WithIsMultitenant populates ctx with ctxIsMultitenant key and provided value
@@ -200,6 +200,8 @@ func (h *handler) addSubscriptions(nn []*Notifier) {
 	for _, n := range nn {
 		if sub := n.takeSubscription(); sub != nil {
 			h.serverSubs[sub.ID] = sub
+		} else if n.release != nil {
+			n.release()
 		}
 	}
 }
@@ -212,6 +214,7 @@ func (h *handler) cancelServerSubscriptions(err error) {
 	for id, s := range h.serverSubs {
 		s.err <- err
 		close(s.err)
+		s.releaseQuota()
 		delete(h.serverSubs, id)
 	}
 }
@@ -333,6 +336,18 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 		if psi, found := PrivateStateIdentifierFromContext(secCtx); found {
 			cp.ctx = WithPrivateStateIdentifier(cp.ctx, psi)
 		}
+		if quotas := tenantQuotasFromContext(secCtx); quotas != nil {
+			tq, err := quotas.resolve(secCtx)
+			if err != nil {
+				return securityErrorMessage(msg, err)
+			}
+			if tq != nil {
+				if err := tq.admit(msg.Method); err != nil {
+					return securityErrorMessage(msg, err)
+				}
+				cp.ctx = withTenantQuota(cp.ctx, tq)
+			}
+		}
 	}
 	// try to extract the PSI from the request ID if it is not already there in the context.
 	// this is mainly to serve IPC and InProc transport
@@ -401,6 +416,12 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMes
 
 	// Install notifier in context so the subscription handler can find it.
 	n := &Notifier{h: h, namespace: namespace}
+	// Quorum: the subscription counts against the quota of the tenant until it ends
+	if tq := tenantQuotaFromContext(cp.ctx); tq != nil {
+		if n.release, err = tq.subscribe(); err != nil {
+			return msg.errorResponse(err)
+		}
+	}
 	cp.notifiers = append(cp.notifiers, n)
 	ctx := context.WithValue(cp.ctx, notifierKey{}, n)
 
@@ -430,6 +451,7 @@ func (h *handler) unsubscribe(ctx context.Context, id ID) (bool, error) {
 		return false, ErrSubscriptionNotFound
 	}
 	close(s.err)
+	s.releaseQuota()
 	delete(h.serverSubs, id)
 	return true, nil
 }
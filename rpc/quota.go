@@ -0,0 +1,185 @@
+// Quorum
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/multitenancy"
+	"golang.org/x/time/rate"
+)
+
+type quotaError struct{ message string }
+
+func (e *quotaError) ErrorCode() int { return -32005 }
+
+func (e *quotaError) Error() string { return e.message }
+
+// TenantQuotas enforces the quotas of the tenants of a multitenant node. The
+// quota of a tenant is granted in its token by the security plugin or else
+// read from the quota policy of the node. A tenant is identified by the client
+// ID of its granted quota or else by its authorized PSI.
+//
+// It's shared by the RPC servers of the node, so a tenant has the same request
+// rate and subscriptions whatever the transport.
+type TenantQuotas struct {
+	policy *multitenancy.QuotaPolicy
+
+	mu      sync.Mutex
+	tenants map[string]*tenantUsage
+}
+
+// tenantUsage is what a tenant is using of its quota
+type tenantUsage struct {
+	quota         multitenancy.Quota // quota the limiter was made for
+	limiter       *rate.Limiter
+	subscriptions int
+
+	requestMeter       metrics.Meter
+	throttledMeter     metrics.Meter
+	subscriptionsGauge metrics.Gauge
+}
+
+// tenantQuota is the quota of the tenant making a call
+type tenantQuota struct {
+	tenant string
+	quota  *multitenancy.Quota
+	quotas *TenantQuotas
+}
+
+// NewTenantQuotas creates the quotas of the tenants, given the quota policy
+// of the node, which may be nil
+func NewTenantQuotas(policy *multitenancy.QuotaPolicy) *TenantQuotas {
+	return &TenantQuotas{policy: policy, tenants: make(map[string]*tenantUsage)}
+}
+
+// resolve returns the quota of the tenant making a call, nil if there is none
+func (q *TenantQuotas) resolve(secCtx SecurityContext) (*tenantQuota, error) {
+	tenant, quota := "", (*multitenancy.Quota)(nil)
+	if authToken := PreauthenticatedTokenFromContext(secCtx); authToken != nil {
+		clientID, granted, err := multitenancy.ExtractQuota(authToken)
+		if err != nil {
+			return nil, err
+		}
+		tenant, quota = clientID, granted
+	}
+	if tenant == "" {
+		psi, found := PrivateStateIdentifierFromContext(secCtx)
+		if !found {
+			return nil, nil
+		}
+		tenant = psi.String()
+	}
+	if quota == nil {
+		quota = q.policy.Quota(tenant)
+	}
+	if quota == nil {
+		return nil, nil
+	}
+	return &tenantQuota{tenant: tenant, quota: quota, quotas: q}, nil
+}
+
+// usage returns the usage of the tenant, making its limiter anew if its
+// quota changed. The caller must hold q.mu.
+func (q *TenantQuotas) usage(tenant string, quota *multitenancy.Quota) *tenantUsage {
+	usage, ok := q.tenants[tenant]
+	if !ok {
+		prefix := fmt.Sprintf("rpc/quota/%s/", tenant)
+		usage = &tenantUsage{
+			requestMeter:       metrics.GetOrRegisterMeter(prefix+"requests", nil),
+			throttledMeter:     metrics.GetOrRegisterMeter(prefix+"throttled", nil),
+			subscriptionsGauge: metrics.GetOrRegisterGauge(prefix+"subscriptions", nil),
+		}
+		q.tenants[tenant] = usage
+	}
+	if usage.limiter == nil || usage.quota != *quota {
+		limit, burst := rate.Inf, quota.Burst
+		if quota.RequestsPerSecond > 0 {
+			limit = rate.Limit(quota.RequestsPerSecond)
+			if burst <= 0 {
+				burst = int(math.Ceil(quota.RequestsPerSecond))
+			}
+		}
+		usage.quota, usage.limiter = *quota, rate.NewLimiter(limit, burst)
+	}
+	return usage
+}
+
+// admit takes a request from the request rate of the tenant
+func (tq *tenantQuota) admit(method string) error {
+	tq.quotas.mu.Lock()
+	defer tq.quotas.mu.Unlock()
+
+	usage := tq.quotas.usage(tq.tenant, tq.quota)
+	usage.requestMeter.Mark(1)
+	if !usage.limiter.Allow() {
+		usage.throttledMeter.Mark(1)
+		log.Debug("Tenant request rate exceeded", "tenant", tq.tenant, "method", method)
+		return &quotaError{fmt.Sprintf("request rate of %v per second exceeded", tq.quota.RequestsPerSecond)}
+	}
+	return nil
+}
+
+// subscribe takes a subscription from the tenant, returning the function that
+// gives it back
+func (tq *tenantQuota) subscribe() (func(), error) {
+	tq.quotas.mu.Lock()
+	defer tq.quotas.mu.Unlock()
+
+	usage := tq.quotas.usage(tq.tenant, tq.quota)
+	if tq.quota.MaxSubscriptions > 0 && usage.subscriptions >= tq.quota.MaxSubscriptions {
+		usage.throttledMeter.Mark(1)
+		return nil, &quotaError{fmt.Sprintf("limit of %d subscriptions reached", tq.quota.MaxSubscriptions)}
+	}
+	usage.subscriptions++
+	usage.subscriptionsGauge.Update(int64(usage.subscriptions))
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			tq.quotas.mu.Lock()
+			defer tq.quotas.mu.Unlock()
+			usage.subscriptions--
+			usage.subscriptionsGauge.Update(int64(usage.subscriptions))
+		})
+	}, nil
+}
+
+// withTenantQuotas populates ctx with ctxTenantQuotas key and provided value
+func withTenantQuotas(ctx context.Context, quotas *TenantQuotas) SecurityContext {
+	return context.WithValue(ctx, ctxTenantQuotas, quotas)
+}
+
+// tenantQuotasFromContext returns *TenantQuotas value from ctx with ctxTenantQuotas key
+// and returns nil if value does not exist in the ctx
+func tenantQuotasFromContext(ctx SecurityContext) *TenantQuotas {
+	if q, ok := ctx.Value(ctxTenantQuotas).(*TenantQuotas); ok {
+		return q
+	}
+	return nil
+}
+
+// withTenantQuota populates ctx with ctxTenantQuota key and provided value
+func withTenantQuota(ctx context.Context, tq *tenantQuota) context.Context {
+	return context.WithValue(ctx, ctxTenantQuota, tq)
+}
+
+func tenantQuotaFromContext(ctx context.Context) *tenantQuota {
+	if tq, ok := ctx.Value(ctxTenantQuota).(*tenantQuota); ok {
+		return tq
+	}
+	return nil
+}
+
+// TenantQuotaFromContext returns the quota of the tenant making the call,
+// nil if it has none
+func TenantQuotaFromContext(ctx context.Context) *multitenancy.Quota {
+	if tq := tenantQuotaFromContext(ctx); tq != nil {
+		return tq.quota
+	}
+	return nil
+}
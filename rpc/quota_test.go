@@ -0,0 +1,76 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/multitenancy"
+	"github.com/jpmorganchase/quorum-security-plugin-sdk-go/proto"
+	testifyassert "github.com/stretchr/testify/assert"
+)
+
+func TestTenantQuotas_whenGrantedInToken(t *testing.T) {
+	assert := testifyassert.New(t)
+	quotas := NewTenantQuotas(&multitenancy.QuotaPolicy{Default: &multitenancy.Quota{RequestsPerSecond: 100}})
+	secCtx := WithPreauthenticatedToken(WithPrivateStateIdentifier(context.Background(), "PS1"), &proto.PreAuthenticatedAuthenticationToken{
+		Authorities: []*proto.GrantedAuthority{{Raw: "quota://client1?rps=1&burst=2"}},
+	})
+
+	tq, err := quotas.resolve(secCtx)
+
+	assert.NoError(err)
+	assert.Equal("client1", tq.tenant)
+	assert.NoError(tq.admit("eth_call"))
+	assert.NoError(tq.admit("eth_call"))
+	assert.Error(tq.admit("eth_call"))
+}
+
+func TestTenantQuotas_whenFromPolicy(t *testing.T) {
+	assert := testifyassert.New(t)
+	quotas := NewTenantQuotas(&multitenancy.QuotaPolicy{Tenants: map[string]*multitenancy.Quota{"PS1": {MaxSubscriptions: 1}}})
+
+	tq, err := quotas.resolve(WithPrivateStateIdentifier(context.Background(), "PS1"))
+	assert.NoError(err)
+	assert.Equal("PS1", tq.tenant)
+	assert.NoError(tq.admit("eth_subscribe"))
+	release, err := tq.subscribe()
+	assert.NoError(err)
+	_, err = tq.subscribe()
+	assert.Error(err)
+	release()
+	release()
+	_, err = tq.subscribe()
+	assert.NoError(err)
+
+	tq, err = quotas.resolve(WithPrivateStateIdentifier(context.Background(), types.PrivateStateIdentifier("PS2")))
+	assert.NoError(err)
+	assert.Nil(tq)
+}
+
+func TestHandleCall_whenQuotaExceeded(t *testing.T) {
+	assert := testifyassert.New(t)
+	server := newTestServer()
+	defer server.Stop()
+	quotas := NewTenantQuotas(&multitenancy.QuotaPolicy{Default: &multitenancy.Quota{RequestsPerSecond: 1}})
+	secCtx := withTenantQuotas(WithPrivateStateIdentifier(context.Background(), "PS1"), quotas)
+	h := newHandler(context.Background(), &stubSecurityCodec{secCtx: secCtx}, randomIDGenerator(), &server.services)
+	defer h.close(nil, nil)
+	msg := &jsonrpcMessage{Version: vsn, ID: []byte("1"), Method: "test_echo", Params: []byte(`["x", 1]`)}
+
+	cp := &callProc{ctx: context.Background()}
+	assert.Nil(h.handleCall(cp, msg).Error)
+	assert.NotNil(TenantQuotaFromContext(cp.ctx))
+	answer := h.handleCall(&callProc{ctx: context.Background()}, msg)
+	assert.NotNil(answer.Error)
+	assert.Equal(-32005, answer.Error.Code)
+}
+
+type stubSecurityCodec struct {
+	jsonWriter
+	secCtx SecurityContext
+}
+
+func (c *stubSecurityCodec) Resolve() SecurityContext { return c.secCtx }
+
+func (c *stubSecurityCodec) remoteAddr() string { return "" }
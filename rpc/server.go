@@ -53,6 +53,7 @@ type Server struct {
 	// The implementation would authenticate the token coming from a request
 	authenticationManager security.AuthenticationManager
 	isMultitenant         bool
+	tenantQuotas          *TenantQuotas
 }
 
 // Quorum
@@ -155,6 +156,9 @@ func (s *Server) Stop() {
 func (s *Server) authenticateHttpRequest(r *http.Request, cfg securityContextConfigurer) {
 	securityContext := WithIsMultitenant(context.Background(), s.isMultitenant)
 	securityContext = AuthenticateHttpRequest(securityContext, r, s.authenticationManager)
+	if s.isMultitenant && s.tenantQuotas != nil {
+		securityContext = withTenantQuotas(securityContext, s.tenantQuotas)
+	}
 	cfg.Configure(securityContext)
 }
 
@@ -162,6 +166,12 @@ func (s *Server) EnableMultitenancy(b bool) {
 	s.isMultitenant = b
 }
 
+// SetTenantQuotas makes the server enforce the quotas of the tenants when
+// multitenancy is enabled
+func (s *Server) SetTenantQuotas(quotas *TenantQuotas) {
+	s.tenantQuotas = quotas
+}
+
 // RPCService gives meta information about the server.
 // e.g. gives information about the loaded modules.
 type RPCService struct {
@@ -99,6 +99,9 @@ type Notifier struct {
 	buffer       []json.RawMessage
 	callReturned bool
 	activated    bool
+
+	// Quorum: gives back the subscription to the quota of the tenant, if any
+	release func()
 }
 
 // CreateSubscription returns a new subscription that is coupled to the
@@ -114,7 +117,7 @@ func (n *Notifier) CreateSubscription() *Subscription {
 	} else if n.callReturned {
 		panic("can't create subscription after subscribe call has returned")
 	}
-	n.sub = &Subscription{ID: n.h.idgen(), namespace: n.namespace, err: make(chan error, 1)}
+	n.sub = &Subscription{ID: n.h.idgen(), namespace: n.namespace, err: make(chan error, 1), release: n.release}
 	return n.sub
 }
 
@@ -188,6 +191,14 @@ type Subscription struct {
 	ID        ID
 	namespace string
 	err       chan error // closed on unsubscribe
+	release   func()     // Quorum: gives back the subscription to the quota of the tenant
+}
+
+// releaseQuota gives back the subscription to the quota of the tenant, if any
+func (s *Subscription) releaseQuota() {
+	if s.release != nil {
+		s.release()
+	}
 }
 
 // Err returns a channel that is closed when the client send an unsubscribe request.